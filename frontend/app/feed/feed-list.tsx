@@ -17,17 +17,17 @@ export function FeedList() {
   const [loading, setLoading] = useState(true);
   const [loadingMore, setLoadingMore] = useState(false);
   const [hasMore, setHasMore] = useState(true);
-  const [page, setPage] = useState(1);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const [hasFetched, setHasFetched] = useState(false);
   const feedIdsRef = useRef(new Set<number>());
 
-  const fetchFeeds = async (pageNum: number, append = false) => {
+  const fetchFeeds = async (cursor: string | null, append = false) => {
     const authToken = localStorage.getItem('auth_token');
     if (!authToken) return;
 
     try {
       const res = await fetch(
-        `${process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api/v1'}/feed?page=${pageNum}&page_size=10`,
+        `${process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api/v1'}/feed?limit=10${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ''}`,
         { headers: { 'Authorization': `Bearer ${authToken}` } }
       );
       
@@ -44,7 +44,8 @@ export function FeedList() {
           setFeeds(items);
         }
         
-        setHasMore(items.length === 10);
+        setNextCursor(data.data.next_cursor || null);
+        setHasMore(Boolean(data.data.has_more));
       }
     } catch (e) {
       console.error('[FeedList] Fetch error:', e);
@@ -59,28 +60,31 @@ export function FeedList() {
     setToken(authToken);
 
     if (authToken) {
-      fetchFeeds(1).finally(() => setLoading(false));
+      fetchFeeds(null).finally(() => setLoading(false));
     } else {
       setLoading(false);
     }
   }, [hasFetched]);
 
   const loadMore = async () => {
-    if (loadingMore || !hasMore) return;
+    if (loadingMore || !hasMore || !nextCursor) return;
     
     setLoadingMore(true);
-    const nextPage = page + 1;
-    await fetchFeeds(nextPage, true);
-    setPage(nextPage);
+    await fetchFeeds(nextCursor, true);
     setLoadingMore(false);
   };
 
   const { isConnected } = useWebSocket({
     url: process.env.NEXT_PUBLIC_WS_URL || 'ws://localhost:8080/ws',
     token: token || undefined,
-    onMessage: (data: FeedItem) => {
+    onMessage: (data: FeedItem, type?: string) => {
       console.log('[FeedList] WebSocket message received:', data);
-      
+
+      // 只处理新交易，其他事件（地址更新等）由对应组件处理
+      if (type && type !== 'new_transaction') {
+        return;
+      }
+
       // 去重：如果已存在则忽略
       if (feedIdsRef.current.has(data.id)) {
         console.log('[FeedList] Duplicate feed item ignored:', data.id);
@@ -23,6 +23,7 @@ interface FeedItem {
   Transaction?: Transaction; // 兼容后端大写
   watched_address?: WatchedAddress;
   WatchedAddress?: WatchedAddress;
+  watched_addresses?: WatchedAddress[]; // 命中的全部监控地址（如同时监控了发送方与接收方）
 }
 
 function formatETH(weiValue: string): string {
@@ -50,6 +51,13 @@ export function FeedCard({ item }: { item: FeedItem }) {
   
   const direction = getDirection(tx, watched_address.address);
   const ethAmount = formatETH(tx.value);
+  const watchedList = item.watched_addresses?.length ? item.watched_addresses : [watched_address];
+  const watchedLabel = (address: string) => {
+    const match = watchedList.find((w) => w.address.toLowerCase() === address.toLowerCase());
+    return match ? match.label || '监控中' : null;
+  };
+  const fromLabel = watchedLabel(tx.from_address);
+  const toLabel = watchedLabel(tx.to_address);
   
   return (
     <Card className="backdrop-blur-sm bg-white/80 dark:bg-slate-900/80 border-slate-200 dark:border-slate-800 shadow-lg hover:shadow-xl transition-all">
@@ -81,12 +89,14 @@ export function FeedCard({ item }: { item: FeedItem }) {
                 <code className="text-xs bg-slate-100 dark:bg-slate-800 px-2 py-1 rounded">
                   {tx.from_address.slice(0, 10)}...{tx.from_address.slice(-8)}
                 </code>
+                {fromLabel && <Badge variant="outline" className="text-xs">{fromLabel}</Badge>}
               </div>
               <div className="flex items-center gap-2">
                 <span className="text-muted-foreground w-12">到:</span>
                 <code className="text-xs bg-slate-100 dark:bg-slate-800 px-2 py-1 rounded">
                   {tx.to_address.slice(0, 10)}...{tx.to_address.slice(-8)}
                 </code>
+                {toLabel && <Badge variant="outline" className="text-xs">{toLabel}</Badge>}
               </div>
             </div>
             
@@ -3,7 +3,7 @@ import { useEffect, useRef, useState } from 'react';
 interface UseWebSocketOptions {
   url: string;
   token?: string;
-  onMessage?: (data: any) => void;
+  onMessage?: (data: any, type?: string) => void;
   onError?: (error: Event) => void;
   reconnect?: boolean;
   reconnectInterval?: number;
@@ -52,7 +52,7 @@ export function useWebSocket({
           
           // 提取 Payload（后端推送的是 { user_id, type, payload } 结构）
           const data = message.payload || message;
-          onMessageRef.current?.(data);
+          onMessageRef.current?.(data, message.type);
         } catch (error) {
           console.error('Failed to parse message:', error);
         }
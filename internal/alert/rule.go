@@ -0,0 +1,282 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+const (
+	maxListSize = 100 // 地址类条件的最大数量
+)
+
+var validTxTypes = map[string]bool{
+	"ETH":      true,
+	"ERC20":    true,
+	"ERC721":   true,
+	"SWAP":     true,
+	"APPROVAL": true,
+}
+
+// ValidSeverity 判断告警级别是否合法
+func ValidSeverity(severity string) bool {
+	switch severity {
+	case models.AlertSeverityInfo, models.AlertSeverityWarning, models.AlertSeverityCritical:
+		return true
+	}
+	return false
+}
+
+// Normalize 校验并规范化告警条件：地址转小写、交易类型转大写、金额与美元价值去除多余精度
+func Normalize(c *models.AlertConditions) error {
+	switch c.Direction {
+	case "", models.DirectionIn, models.DirectionOut:
+	default:
+		return errors.New("direction must be in or out")
+	}
+
+	for i, t := range c.TxTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if !validTxTypes[t] {
+			return fmt.Errorf("unsupported tx type: %s", t)
+		}
+		c.TxTypes[i] = t
+	}
+
+	var err error
+	if c.TokenAddresses, err = normalizeAddresses(c.TokenAddresses, "token_addresses"); err != nil {
+		return err
+	}
+	if c.Counterparties, err = normalizeAddresses(c.Counterparties, "counterparties"); err != nil {
+		return err
+	}
+
+	min, err := normalizeAmount(c.MinAmount, "min_amount")
+	if err != nil {
+		return err
+	}
+	max, err := normalizeAmount(c.MaxAmount, "max_amount")
+	if err != nil {
+		return err
+	}
+	if min != nil && max != nil && min.Cmp(max) > 0 {
+		return errors.New("min_amount must not exceed max_amount")
+	}
+
+	minUSD, err := normalizeAmount(c.MinUSDValue, "min_usd_value")
+	if err != nil {
+		return err
+	}
+	maxUSD, err := normalizeAmount(c.MaxUSDValue, "max_usd_value")
+	if err != nil {
+		return err
+	}
+	if minUSD != nil && maxUSD != nil && minUSD.Cmp(maxUSD) > 0 {
+		return errors.New("min_usd_value must not exceed max_usd_value")
+	}
+
+	if w := c.TimeWindow; w != nil {
+		if _, err := parseClock(w.Start); err != nil {
+			return fmt.Errorf("invalid time_window.start: %w", err)
+		}
+		if _, err := parseClock(w.End); err != nil {
+			return fmt.Errorf("invalid time_window.end: %w", err)
+		}
+		for _, d := range w.Days {
+			if d < 0 || d > 6 {
+				return errors.New("time_window.days must be between 0 and 6")
+			}
+		}
+		if w.Timezone != "" {
+			if _, err := time.LoadLocation(w.Timezone); err != nil {
+				return fmt.Errorf("invalid time_window.timezone: %s", w.Timezone)
+			}
+		}
+	}
+
+	return nil
+}
+
+func normalizeAddresses(addresses []string, field string) ([]string, error) {
+	if len(addresses) > maxListSize {
+		return nil, fmt.Errorf("%s allows at most %d addresses", field, maxListSize)
+	}
+	for i, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid address in %s: %s", field, addr)
+		}
+		addresses[i] = strings.ToLower(addr)
+	}
+	return addresses, nil
+}
+
+func normalizeAmount(amount *string, field string) (*big.Rat, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(*amount))
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number", field)
+	}
+	s := strings.TrimRight(strings.TrimRight(r.FloatString(18), "0"), ".")
+	*amount = s
+	return r, nil
+}
+
+// parseClock 解析 HH:MM，返回当天分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Match 判断交易是否满足规则条件，wa 为命中该交易的监控记录
+func Match(rule *models.AlertRule, tx *models.Transaction, wa *models.WatchedAddress) bool {
+	if !rule.Enabled {
+		return false
+	}
+	c := &rule.Conditions
+
+	if len(c.WatchedAddressIDs) > 0 && !containsID(c.WatchedAddressIDs, wa.ID) {
+		return false
+	}
+
+	if len(c.TxTypes) > 0 && !containsFold(c.TxTypes, tx.TxType) {
+		return false
+	}
+
+	if c.RiskyApproval && (tx.Approval == nil || !tx.Approval.Risky) {
+		return false
+	}
+
+	if len(c.TokenAddresses) > 0 && !containsFold(c.TokenAddresses, tx.TokenAddress) {
+		return false
+	}
+
+	direction, counterparty := tx.Direction(wa)
+	if c.Direction != "" && c.Direction != direction {
+		return false
+	}
+
+	if len(c.Counterparties) > 0 {
+		if counterparty != "" {
+			if !containsFold(c.Counterparties, counterparty) {
+				return false
+			}
+		} else if !containsFold(c.Counterparties, tx.FromAddress) && !containsFold(c.Counterparties, tx.ToAddress) {
+			// 代币合约监控没有固定的一方，任一方命中即可
+			return false
+		}
+	}
+
+	if c.MinAmount != nil || c.MaxAmount != nil {
+		amount, ok := new(big.Rat).SetString(tx.Value)
+		if !ok {
+			return false
+		}
+		units.FromScaled(amount)
+		if c.MinAmount != nil {
+			if min, ok := new(big.Rat).SetString(*c.MinAmount); ok && amount.Cmp(min) < 0 {
+				return false
+			}
+		}
+		if c.MaxAmount != nil {
+			if max, ok := new(big.Rat).SetString(*c.MaxAmount); ok && amount.Cmp(max) > 0 {
+				return false
+			}
+		}
+	}
+
+	if c.MinUSDValue != nil || c.MaxUSDValue != nil {
+		// 无法计价的交易不满足美元价值条件
+		if tx.USDValue == nil {
+			return false
+		}
+		usd, ok := new(big.Rat).SetString(*tx.USDValue)
+		if !ok {
+			return false
+		}
+		if c.MinUSDValue != nil {
+			if min, ok := new(big.Rat).SetString(*c.MinUSDValue); ok && usd.Cmp(min) < 0 {
+				return false
+			}
+		}
+		if c.MaxUSDValue != nil {
+			if max, ok := new(big.Rat).SetString(*c.MaxUSDValue); ok && usd.Cmp(max) > 0 {
+				return false
+			}
+		}
+	}
+
+	if c.TimeWindow != nil && !inTimeWindow(c.TimeWindow, tx.BlockTimestamp) {
+		return false
+	}
+
+	return true
+}
+
+func inTimeWindow(w *models.AlertTimeWindow, at time.Time) bool {
+	loc := time.UTC
+	if w.Timezone != "" {
+		if l, err := time.LoadLocation(w.Timezone); err == nil {
+			loc = l
+		}
+	}
+	at = at.In(loc)
+
+	if len(w.Days) > 0 {
+		matched := false
+		for _, d := range w.Days {
+			if time.Weekday(d) == at.Weekday() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false
+	}
+
+	minute := at.Hour()*60 + at.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(list []int64, id int64) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const (
+	watched = "0x1111111111111111111111111111111111111111"
+	other   = "0x2222222222222222222222222222222222222222"
+	usdc    = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestMatch(t *testing.T) {
+	wa := &models.WatchedAddress{ID: 7, Kind: models.WatchKindWallet, Address: watched}
+	// 2024-01-01 是周一，UTC 14:30
+	tx := &models.Transaction{
+		FromAddress:    other,
+		ToAddress:      watched,
+		Value:          "2500000000000000000000", // 2500
+		TxType:         "ERC20",
+		TokenAddress:   usdc,
+		USDValue:       strPtr("2499.75"),
+		BlockTimestamp: time.Date(2024, 1, 1, 14, 30, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name       string
+		conditions models.AlertConditions
+		want       bool
+	}{
+		{"empty conditions", models.AlertConditions{}, true},
+		{"direction in", models.AlertConditions{Direction: models.DirectionIn}, true},
+		{"direction out", models.AlertConditions{Direction: models.DirectionOut}, false},
+		{"tx type", models.AlertConditions{TxTypes: []string{"ETH"}}, false},
+		{"token", models.AlertConditions{TokenAddresses: []string{usdc}}, true},
+		{"min amount", models.AlertConditions{MinAmount: strPtr("2500")}, true},
+		{"min amount above", models.AlertConditions{MinAmount: strPtr("2500.01")}, false},
+		{"max amount", models.AlertConditions{MaxAmount: strPtr("1000")}, false},
+		{"min usd value", models.AlertConditions{MinUSDValue: strPtr("2000")}, true},
+		{"min usd value above", models.AlertConditions{MinUSDValue: strPtr("2500")}, false},
+		{"counterparty", models.AlertConditions{Counterparties: []string{other}}, true},
+		{"counterparty self", models.AlertConditions{Counterparties: []string{watched}}, false},
+		{"watched address", models.AlertConditions{WatchedAddressIDs: []int64{8}}, false},
+		{"time window", models.AlertConditions{TimeWindow: &models.AlertTimeWindow{Start: "09:00", End: "18:00", Days: []int{1}}}, true},
+		{"overnight window", models.AlertConditions{TimeWindow: &models.AlertTimeWindow{Start: "22:00", End: "06:00"}}, false},
+		{"timezone window", models.AlertConditions{TimeWindow: &models.AlertTimeWindow{Start: "22:00", End: "06:00", Timezone: "Asia/Shanghai"}}, true},
+		{"risky approval", models.AlertConditions{RiskyApproval: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &models.AlertRule{Enabled: true, Conditions: tt.conditions}
+			assert.Equal(t, tt.want, Match(rule, tx, wa))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	c := models.AlertConditions{
+		TxTypes:        []string{"erc20"},
+		TokenAddresses: []string{"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+		MinAmount:      strPtr("100.500"),
+	}
+	require.NoError(t, Normalize(&c))
+	assert.Equal(t, []string{"ERC20"}, c.TxTypes)
+	assert.Equal(t, []string{usdc}, c.TokenAddresses)
+	assert.Equal(t, "100.5", *c.MinAmount)
+
+	assert.Error(t, Normalize(&models.AlertConditions{Direction: "sideways"}))
+	assert.Error(t, Normalize(&models.AlertConditions{MinAmount: strPtr("10"), MaxAmount: strPtr("1")}))
+	assert.Error(t, Normalize(&models.AlertConditions{TimeWindow: &models.AlertTimeWindow{Start: "25:00", End: "01:00"}}))
+}
+
+func TestMatchUnpriced(t *testing.T) {
+	wa := &models.WatchedAddress{ID: 7, Kind: models.WatchKindWallet, Address: watched}
+	tx := &models.Transaction{FromAddress: other, ToAddress: watched, Value: "1", TxType: "ERC20", TokenAddress: usdc}
+
+	// 无法计价的交易不满足任何美元价值条件
+	rule := &models.AlertRule{Enabled: true, Conditions: models.AlertConditions{MaxUSDValue: strPtr("100")}}
+	assert.False(t, Match(rule, tx, wa))
+}
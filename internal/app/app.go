@@ -13,6 +13,11 @@ import (
 
 	"github.com/bwmspring/chainfeed-go/internal/config"
 	"github.com/bwmspring/chainfeed-go/internal/database"
+	"github.com/bwmspring/chainfeed-go/internal/digest"
+	"github.com/bwmspring/chainfeed-go/internal/export"
+	"github.com/bwmspring/chainfeed-go/internal/mempool"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/server"
 	"github.com/bwmspring/chainfeed-go/internal/service"
 	"github.com/bwmspring/chainfeed-go/internal/websocket"
@@ -27,6 +32,12 @@ type App struct {
 	server    *server.Server
 	hub       *websocket.Hub
 	stream    *service.StreamService
+	webhooks  *notify.WebhookDispatcher
+	channels  *notify.ChannelDispatcher
+	digests   *digest.Scheduler
+	bursts    *notify.BurstFlusher
+	exports   *export.Worker
+	mempool   *mempool.Watcher
 	cancelCtx context.CancelFunc
 }
 
@@ -65,17 +76,60 @@ func New(configPath string) (*App, error) {
 	// Create Stream service
 	streamService := service.NewStreamService(rdb, hub, zapLogger)
 
+	// Create outbound webhook dispatcher
+	webhookDispatcher := notify.NewWebhookDispatcher(
+		repository.NewWebhookRepository(db), rdb, notify.NewWebhookSender(nil), zapLogger)
+
+	// Create Telegram / Slack notification dispatcher
+	channelDispatcher := notify.NewChannelDispatcher(
+		repository.NewChannelRepository(db), rdb, notify.ExplorerURL(cfg.Ethereum.Network), zapLogger,
+		notify.EnabledChannels(cfg.Telegram.BotToken)...)
+
+	// Create throttled notification summary flusher
+	gate := notify.NewGate(repository.NewPreferenceRepository(db), rdb, zapLogger)
+	burstFlusher := notify.NewBurstFlusher(rdb, gate, streamService, zapLogger)
+
+	// Create email digest scheduler (optional)
+	var digestScheduler *digest.Scheduler
+	if cfg.SMTP.Host != "" {
+		digestScheduler = digest.NewScheduler(
+			repository.NewDigestRepository(db), digest.NewSMTPMailer(cfg.SMTP),
+			notify.ExplorerURL(cfg.Ethereum.Network), cfg.Digest.PublicURL, zapLogger)
+	} else {
+		zapLogger.Warn("SMTP not configured, email digests disabled")
+	}
+
+	// Create async feed export worker
+	exportWorker := export.NewWorker(
+		repository.NewExportRepository(db), repository.NewFeedRepository(db), cfg.Export, zapLogger)
+
+	// Create pending transaction watcher (optional)
+	var mempoolWatcher *mempool.Watcher
+	if cfg.Mempool.WSURL != "" {
+		mempoolWatcher = mempool.NewWatcher(
+			cfg.Mempool, repository.NewWatchedAddressRepository(db),
+			gate, streamService, zapLogger)
+	} else {
+		zapLogger.Info("Mempool WebSocket RPC not configured, pending transaction tracking disabled")
+	}
+
 	// Create server
 	srv := server.New(cfg, zapLogger, db, rdb, hub)
 
 	return &App{
-		cfg:    cfg,
-		logger: zapLogger,
-		db:     db,
-		redis:  rdb,
-		server: srv,
-		hub:    hub,
-		stream: streamService,
+		cfg:      cfg,
+		logger:   zapLogger,
+		db:       db,
+		redis:    rdb,
+		server:   srv,
+		hub:      hub,
+		stream:   streamService,
+		webhooks: webhookDispatcher,
+		channels: channelDispatcher,
+		digests:  digestScheduler,
+		bursts:   burstFlusher,
+		exports:  exportWorker,
+		mempool:  mempoolWatcher,
 	}, nil
 }
 
@@ -92,6 +146,37 @@ func (a *App) Run() error {
 		}
 	}()
 
+	// Start outbound webhook dispatcher
+	go func() {
+		if err := a.webhooks.Consume(ctx); err != nil && err != context.Canceled {
+			a.logger.Error("Webhook dispatcher error", zap.Error(err))
+		}
+	}()
+	go a.webhooks.Run(ctx)
+
+	// Start notification channel dispatcher
+	go func() {
+		if err := a.channels.Consume(ctx); err != nil && err != context.Canceled {
+			a.logger.Error("Channel dispatcher error", zap.Error(err))
+		}
+	}()
+
+	// Start throttled notification summary flusher
+	go a.bursts.Run(ctx)
+
+	// Start email digest scheduler
+	if a.digests != nil {
+		go a.digests.Run(ctx)
+	}
+
+	// Start async feed export worker
+	go a.exports.Run(ctx)
+
+	// Start pending transaction watcher
+	if a.mempool != nil {
+		go a.mempool.Run(ctx)
+	}
+
 	// Start server in goroutine
 	go func() {
 		if err := a.server.Start(); err != nil {
@@ -0,0 +1,44 @@
+package approvals
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:embed data/spenders.json
+var bundledSpenders []byte
+
+// Spender 已认证的 spender（DEX 路由、Permit2、NFT 市场等）
+type Spender struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+}
+
+// LoadSpenders 读取 spender 列表文件，格式为 [{"address": "0x...", "name": "..."}]
+func LoadSpenders(path string) ([]Spender, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spender list: %w", err)
+	}
+	return parseSpenders(data)
+}
+
+func parseSpenders(data []byte) ([]Spender, error) {
+	var spenders []Spender
+	if err := json.Unmarshal(data, &spenders); err != nil {
+		return nil, fmt.Errorf("failed to parse spender list: %w", err)
+	}
+	for i := range spenders {
+		if !common.IsHexAddress(spenders[i].Address) {
+			return nil, fmt.Errorf("invalid address in spender list: %s", spenders[i].Address)
+		}
+		spenders[i].Address = strings.ToLower(spenders[i].Address)
+		spenders[i].Name = strings.TrimSpace(spenders[i].Name)
+	}
+	return spenders, nil
+}
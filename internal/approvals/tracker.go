@@ -0,0 +1,128 @@
+package approvals
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// TxType 授权交易的类型
+const TxType = "APPROVAL"
+
+// Store 钱包当前授权，由 repository.TokenAllowanceRepository 实现
+type Store interface {
+	Save(ctx context.Context, a *models.TokenAllowance) error
+}
+
+// Tracker 在交易入库前处理授权事件：标记无限授权给未认证 spender 的风险，并更新钱包的当前授权
+type Tracker struct {
+	spenders map[string]string // 小写地址 -> 名称
+	store    Store
+	logger   *zap.Logger
+}
+
+// NewTracker extra 为追加到内置列表的已认证 spender，同一地址以 extra 中的名称为准
+func NewTracker(store Store, extra []Spender, logger *zap.Logger) (*Tracker, error) {
+	bundled, err := parseSpenders(bundledSpenders)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tracker{spenders: make(map[string]string), store: store, logger: logger}
+	for _, s := range append(bundled, extra...) {
+		t.spenders[s.Address] = s.Name
+	}
+	return t, nil
+}
+
+// New 按配置创建，spenders_file 中的 spender 与内置列表合并
+func New(cfg config.ApprovalsConfig, store Store, logger *zap.Logger) (*Tracker, error) {
+	var extra []Spender
+	if cfg.SpendersFile != "" {
+		var err error
+		if extra, err = LoadSpenders(cfg.SpendersFile); err != nil {
+			return nil, err
+		}
+	}
+	return NewTracker(store, extra, logger)
+}
+
+// Verified 判断 spender 是否已认证，返回其名称
+func (t *Tracker) Verified(spender string) (string, bool) {
+	name, ok := t.spenders[strings.ToLower(spender)]
+	return name, ok
+}
+
+// Apply 处理一批交易中的 APPROVAL，返回需要入库的交易：
+// 附带发出的授权（Approval.Implicit）与同一交易哈希中已有转账的授权只更新当前授权，不单独入库
+func (t *Tracker) Apply(ctx context.Context, txs []*models.Transaction) []*models.Transaction {
+	transfers := make(map[string]bool)
+	for _, tx := range txs {
+		if tx.TxType != TxType {
+			transfers[strings.ToLower(tx.TxHash)] = true
+		}
+	}
+
+	result := make([]*models.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		a := tx.Approval
+		if tx.TxType != TxType || a == nil {
+			result = append(result, tx)
+			continue
+		}
+
+		name, verified := t.Verified(a.Spender)
+		a.SpenderName = name
+		a.Risky = a.Unlimited && !a.Revoked && !verified
+		// 授权不转移资产，注册表不会按链上精度修正 TokenDecimals
+		if tx.Token != nil && tx.Token.Decimals != nil {
+			tx.TokenDecimals = *tx.Token.Decimals
+		}
+
+		// NFT 的单个授权在转移后自动失效且不一定发出事件，无法重建，只记录 ApprovalForAll
+		if a.Standard == models.ApprovalStandardERC20 || a.All {
+			if err := t.store.Save(ctx, allowanceOf(tx)); err != nil {
+				t.logger.Error("Failed to save token allowance",
+					zap.String("tx_hash", tx.TxHash),
+					zap.String("owner", tx.FromAddress),
+					zap.Error(err))
+			}
+		}
+
+		if a.Implicit || transfers[strings.ToLower(tx.TxHash)] {
+			continue
+		}
+		if a.Risky {
+			t.logger.Info("Risky approval detected",
+				zap.String("tx_hash", tx.TxHash),
+				zap.String("owner", tx.FromAddress),
+				zap.String("spender", a.Spender))
+		}
+		result = append(result, tx)
+	}
+	return result
+}
+
+func allowanceOf(tx *models.Transaction) *models.TokenAllowance {
+	a := tx.Approval
+	return &models.TokenAllowance{
+		Owner:         strings.ToLower(tx.FromAddress),
+		TokenAddress:  strings.ToLower(tx.TokenAddress),
+		TokenSymbol:   tx.TokenSymbol,
+		TokenDecimals: tx.TokenDecimals,
+		Spender:       strings.ToLower(a.Spender),
+		SpenderName:   a.SpenderName,
+		Standard:      a.Standard,
+		Amount:        a.Amount,
+		Unlimited:     a.Unlimited,
+		Risky:         a.Risky,
+		Revoked:       a.Revoked,
+		TxHash:        tx.TxHash,
+		BlockNumber:   tx.BlockNumber,
+		LogIndex:      a.LogIndex,
+		ApprovedAt:    tx.BlockTimestamp,
+	}
+}
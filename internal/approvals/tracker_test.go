@@ -0,0 +1,67 @@
+package approvals
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const (
+	owner   = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+	usdc    = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	permit2 = "0x000000000022d473030f116ddee9f6b43ac78ba3"
+	drainer = "0x2222222222222222222222222222222222222222"
+)
+
+type fakeStore struct {
+	saved []*models.TokenAllowance
+}
+
+func (s *fakeStore) Save(_ context.Context, a *models.TokenAllowance) error {
+	s.saved = append(s.saved, a)
+	return nil
+}
+
+func approval(hash, spender string, a models.Approval) *models.Transaction {
+	a.Standard = models.ApprovalStandardERC20
+	a.Spender = spender
+	return &models.Transaction{TxHash: hash, TxType: TxType, FromAddress: owner, ToAddress: spender,
+		TokenAddress: usdc, TokenSymbol: "USDC", TokenDecimals: 6, Value: "0", Approval: &a}
+}
+
+func TestApply(t *testing.T) {
+	store := &fakeStore{}
+	tracker, err := NewTracker(store, []Spender{{Address: "0x3333333333333333333333333333333333333333", Name: "Vault"}}, zap.NewNop())
+	require.NoError(t, err)
+
+	verified := approval("0xaaa", permit2, models.Approval{Amount: "1", Unlimited: true})
+	risky := approval("0xbbb", drainer, models.Approval{Amount: "1", Unlimited: true})
+	implicit := approval("0xccc", drainer, models.Approval{Amount: "5", Implicit: true})
+	// 与转账同一交易发出的授权（如 permit 后立即转账）只更新授权
+	withTransfer := approval("0xddd", drainer, models.Approval{Amount: "0", Revoked: true})
+	transfer := &models.Transaction{TxHash: "0xDDD", TxType: "ERC20", FromAddress: owner, ToAddress: drainer, Value: "1"}
+
+	result := tracker.Apply(context.Background(), []*models.Transaction{verified, risky, implicit, withTransfer, transfer})
+	assert.Equal(t, []*models.Transaction{verified, risky, transfer}, result)
+
+	assert.Equal(t, "Uniswap Permit2", verified.Approval.SpenderName)
+	assert.False(t, verified.Approval.Risky)
+	assert.True(t, risky.Approval.Risky)
+	assert.Empty(t, risky.Approval.SpenderName)
+
+	require.Len(t, store.saved, 4)
+	assert.Equal(t, owner, store.saved[1].Owner)
+	assert.Equal(t, drainer, store.saved[1].Spender)
+	assert.True(t, store.saved[1].Risky)
+	assert.Equal(t, "5", store.saved[2].Amount)
+	assert.True(t, store.saved[3].Revoked)
+
+	name, ok := tracker.Verified("0x3333333333333333333333333333333333333333")
+	assert.True(t, ok)
+	assert.Equal(t, "Vault", name)
+}
@@ -9,14 +9,24 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Ethereum EthereumConfig `mapstructure:"ethereum"`
-	Alchemy  AlchemyConfig  `mapstructure:"alchemy"`
-	Webhook  WebhookConfig  `mapstructure:"webhook"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Ethereum  EthereumConfig  `mapstructure:"ethereum"`
+	Alchemy   AlchemyConfig   `mapstructure:"alchemy"`
+	Webhook   WebhookConfig   `mapstructure:"webhook"`
+	Telegram  TelegramConfig  `mapstructure:"telegram"`
+	SMTP      SMTPConfig      `mapstructure:"smtp"`
+	Digest    DigestConfig    `mapstructure:"digest"`
+	Export    ExportConfig    `mapstructure:"export"`
+	Pricing   PricingConfig   `mapstructure:"pricing"`
+	Tokens    TokensConfig    `mapstructure:"tokens"`
+	Spam      SpamConfig      `mapstructure:"spam"`
+	ABI       ABIConfig       `mapstructure:"abi"`
+	Approvals ApprovalsConfig `mapstructure:"approvals"`
+	Mempool   MempoolConfig   `mapstructure:"mempool"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Log       LogConfig       `mapstructure:"log"`
 }
 
 type ServerConfig struct {
@@ -61,6 +71,62 @@ type WebhookConfig struct {
 	Secret string `mapstructure:"secret"`
 }
 
+type TelegramConfig struct {
+	BotToken      string `mapstructure:"bot_token"`
+	BotUsername   string `mapstructure:"bot_username"`
+	WebhookSecret string `mapstructure:"webhook_secret"` // 校验 X-Telegram-Bot-Api-Secret-Token
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+type DigestConfig struct {
+	PublicURL string `mapstructure:"public_url"` // 退订链接使用的 API 外部地址
+}
+
+type ExportConfig struct {
+	Dir         string        `mapstructure:"dir"`           // 异步导出文件目录，多实例部署时需为共享存储
+	Retention   time.Duration `mapstructure:"retention"`     // 导出文件保留时间
+	MaxSyncRows int64         `mapstructure:"max_sync_rows"` // 同步导出的最大条目数，超过时需创建异步任务
+}
+
+type PricingConfig struct {
+	Provider     string `mapstructure:"provider"` // coingecko / static，为空时不计算美元价值
+	BaseURL      string `mapstructure:"base_url"` // CoinGecko 风格 API 地址
+	APIKey       string `mapstructure:"api_key"`
+	APIKeyHeader string `mapstructure:"api_key_header"` // 默认 x-cg-demo-api-key，Pro 计划为 x-cg-pro-api-key
+	Platform     string `mapstructure:"platform"`       // 代币合约所在的资产平台，默认 ethereum
+	NativeCoinID string `mapstructure:"native_coin_id"` // 原生 ETH 的币种 ID，默认 ethereum
+	File         string `mapstructure:"file"`           // static 价格文件路径
+}
+
+type TokensConfig struct {
+	ListFile string `mapstructure:"list_file"` // Uniswap 格式的代币列表文件，列表中的代币视为已认证
+}
+
+type SpamConfig struct {
+	ListFile              string `mapstructure:"list_file"`               // 代币允许 / 拒绝列表文件
+	MassTransferThreshold int    `mapstructure:"mass_transfer_threshold"` // 一笔交易的接收地址数达到该值视为空投，默认 10
+}
+
+type ABIConfig struct {
+	SignaturesFile string `mapstructure:"signatures_file"` // 额外的函数 / 事件签名文件，与内置签名库合并
+}
+
+type ApprovalsConfig struct {
+	SpendersFile string `mapstructure:"spenders_file"` // 额外的已认证 spender 列表文件，与内置列表合并
+}
+
+type MempoolConfig struct {
+	WSURL     string        `mapstructure:"ws_url"`     // WebSocket RPC 地址，为空时不跟踪待打包交易
+	DropAfter time.Duration `mapstructure:"drop_after"` // 待打包超过该时长视为丢弃，默认 30m
+}
+
 type AuthConfig struct {
 	JWTSecret   string        `mapstructure:"jwt_secret"`
 	TokenExpiry time.Duration `mapstructure:"token_expiry"`
@@ -0,0 +1,121 @@
+package decoder
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+//go:embed data/contracts.json data/signatures.txt data/abi/*.txt
+var data embed.FS
+
+// Contract 已知 ABI 的合约
+type Contract struct {
+	Address string
+	Name    string
+	ABI     abi.ABI
+	JSON    []byte // JSON ABI
+}
+
+type bundle struct {
+	contracts  map[string]*Contract // 小写地址 -> 合约
+	signatures []string             // 签名库（可读签名）
+}
+
+// loadBundled 加载内置的合约 ABI 与签名库，只执行一次
+var loadBundled = sync.OnceValues(func() (*bundle, error) {
+	raw, err := data.ReadFile("data/contracts.json")
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Address string `json:"address"`
+		Name    string `json:"name"`
+		ABI     string `json:"abi"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse bundled contracts: %w", err)
+	}
+
+	b := &bundle{contracts: make(map[string]*Contract, len(entries))}
+	parsed := make(map[string][]byte)
+	for _, e := range entries {
+		abiJSON, ok := parsed[e.ABI]
+		if !ok {
+			lines, err := readLines("data/abi/" + e.ABI + ".txt")
+			if err != nil {
+				return nil, err
+			}
+			if abiJSON, err = parseSignatures(lines); err != nil {
+				return nil, fmt.Errorf("failed to parse bundled abi %s: %w", e.ABI, err)
+			}
+			parsed[e.ABI] = abiJSON
+			b.signatures = append(b.signatures, lines...)
+		}
+		contract, err := newContract(e.Address, e.Name, abiJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bundled abi %s: %w", e.ABI, err)
+		}
+		b.contracts[contract.Address] = contract
+	}
+
+	lines, err := readLines("data/signatures.txt")
+	if err != nil {
+		return nil, err
+	}
+	b.signatures = append(b.signatures, lines...)
+	return b, nil
+})
+
+func readLines(name string) ([]string, error) {
+	raw, err := data.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(raw), "\n"), nil
+}
+
+func newContract(address, name string, abiJSON []byte) (*Contract, error) {
+	parsed, err := ParseABI(abiJSON)
+	if err != nil {
+		return nil, err
+	}
+	return &Contract{Address: strings.ToLower(address), Name: name, ABI: parsed, JSON: abiJSON}, nil
+}
+
+// ParseABI 解析 JSON ABI，至少需要包含一个函数或事件
+func ParseABI(abiJSON []byte) (abi.ABI, error) {
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return parsed, fmt.Errorf("invalid abi: %w", err)
+	}
+	if len(parsed.Methods) == 0 && len(parsed.Events) == 0 {
+		return parsed, fmt.Errorf("invalid abi: no functions or events")
+	}
+	return parsed, nil
+}
+
+// Bundled 返回内置的合约 ABI，未内置时返回 nil
+func Bundled(address string) *models.ContractABI {
+	b, err := loadBundled()
+	if err != nil {
+		return nil
+	}
+	c := b.contracts[strings.ToLower(address)]
+	if c == nil {
+		return nil
+	}
+	return &models.ContractABI{
+		Address: c.Address,
+		Name:    c.Name,
+		ABI:     c.JSON,
+		Source:  models.ContractABISourceBundled,
+	}
+}
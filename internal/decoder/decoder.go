@@ -0,0 +1,310 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpcbatch"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+const (
+	// maxEvents 每笔交易保留的事件数
+	maxEvents     = 100
+	decodeTimeout = 15 * time.Second
+)
+
+// Decoder 读取交易 input 与回执日志，按内置合约 ABI 解码为 Action；合约未收录 ABI 时按 4 字节签名库
+// 匹配函数选择器与事件 topic0。解码结果写入所有用户共享的 transactions.action，不使用用户上传的 ABI（见 Uploaded）
+type Decoder struct {
+	bundled map[string]*Contract
+	methods map[[4]byte][]abi.Method
+	events  map[common.Hash][]abi.Event
+	caller  tokens.Caller
+	logger  *zap.Logger
+}
+
+// NewDecoder signatures 为追加到内置签名库的可读签名
+func NewDecoder(caller tokens.Caller, signatures []string, logger *zap.Logger) (*Decoder, error) {
+	b, err := loadBundled()
+	if err != nil {
+		return nil, err
+	}
+	abiJSON, err := parseSignatures(append(append([]string{}, b.signatures...), signatures...))
+	if err != nil {
+		return nil, err
+	}
+	index, err := ParseABI(abiJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Decoder{
+		bundled: b.contracts,
+		methods: make(map[[4]byte][]abi.Method),
+		events:  make(map[common.Hash][]abi.Event),
+		caller:  caller,
+		logger:  logger,
+	}
+	seen := make(map[string]bool)
+	for _, m := range index.Methods {
+		if !seen[m.Sig] {
+			seen[m.Sig] = true
+			d.methods[[4]byte(m.ID)] = append(d.methods[[4]byte(m.ID)], m)
+		}
+	}
+	for _, e := range index.Events {
+		// 同一签名可能有不同的 indexed 组合（如 ERC20 与 ERC721 的 Transfer）
+		key := e.Sig + fmt.Sprint(indexedCount(e))
+		if !seen[key] {
+			seen[key] = true
+			d.events[e.ID] = append(d.events[e.ID], e)
+		}
+	}
+	return d, nil
+}
+
+// New 按配置创建解码器，未配置 RPC 地址时返回 nil
+func New(cfg config.ABIConfig, eth config.EthereumConfig, logger *zap.Logger) (*Decoder, error) {
+	if eth.RPCURL == "" {
+		return nil, nil
+	}
+
+	var signatures []string
+	if cfg.SignaturesFile != "" {
+		var err error
+		if signatures, err = LoadSignatures(cfg.SignaturesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := ethclient.Dial(eth.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ethereum: %w", err)
+	}
+	return NewDecoder(client.Client(), signatures, logger)
+}
+
+type rpcTransaction struct {
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+type rpcReceipt struct {
+	Logs []rpcLog `json:"logs"`
+}
+
+type rpcLog struct {
+	Address  common.Address `json:"address"`
+	Topics   []common.Hash  `json:"topics"`
+	Data     hexutil.Bytes  `json:"data"`
+	LogIndex hexutil.Uint   `json:"logIndex"`
+}
+
+// Decode 为一批交易设置 Action；同一交易哈希的多条记录共享同一个 Action，非合约调用不设置。
+// 节点请求失败时记录日志并跳过，不影响入库
+func (d *Decoder) Decode(ctx context.Context, txs []*models.Transaction) {
+	var hashes []string
+	seen := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		hash := strings.ToLower(tx.TxHash)
+		if hash != "" && !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, decodeTimeout)
+	defer cancel()
+
+	calls := make([]*rpcTransaction, len(hashes))
+	if err := d.batch(ctx, "eth_getTransactionByHash", hashes, func(i int) interface{} { return &calls[i] }); err != nil {
+		d.logger.Warn("Failed to get transactions", zap.Int("count", len(hashes)), zap.Error(err))
+		return
+	}
+
+	var contractCalls []string
+	inputs := make(map[string]*rpcTransaction)
+	for i, call := range calls {
+		if call != nil && call.To != nil && len(call.Input) >= 4 {
+			contractCalls = append(contractCalls, hashes[i])
+			inputs[hashes[i]] = call
+		}
+	}
+	if len(contractCalls) == 0 {
+		return
+	}
+
+	receipts := make([]*rpcReceipt, len(contractCalls))
+	if err := d.batch(ctx, "eth_getTransactionReceipt", contractCalls, func(i int) interface{} { return &receipts[i] }); err != nil {
+		d.logger.Warn("Failed to get transaction receipts", zap.Int("count", len(contractCalls)), zap.Error(err))
+	}
+
+	var addresses []string
+	for i, hash := range contractCalls {
+		addresses = append(addresses, strings.ToLower(inputs[hash].To.Hex()))
+		if receipts[i] != nil {
+			for _, log := range receipts[i].Logs {
+				addresses = append(addresses, strings.ToLower(log.Address.Hex()))
+			}
+		}
+	}
+	contracts := d.contracts(addresses)
+
+	actions := make(map[string]*models.Action, len(contractCalls))
+	for i, hash := range contractCalls {
+		call := inputs[hash]
+		action := d.decodeCall(contracts, strings.ToLower(call.To.Hex()), call.Input)
+		if call.From != (common.Address{}) {
+			action.From = strings.ToLower(call.From.Hex())
+		}
+		if receipts[i] != nil {
+			for _, log := range receipts[i].Logs {
+				if len(action.Events) >= maxEvents {
+					break
+				}
+				if event := d.decodeLog(contracts, log); event != nil {
+					action.Events = append(action.Events, *event)
+				}
+			}
+		}
+		actions[hash] = action
+	}
+
+	for _, tx := range txs {
+		if action := actions[strings.ToLower(tx.TxHash)]; action != nil {
+			tx.Action = action
+		}
+	}
+}
+
+// batch 分批调用以交易哈希为参数的 RPC 方法，result(i) 返回第 i 个哈希的结果指针；
+// 单个调用失败时对应结果保持为空
+func (d *Decoder) batch(ctx context.Context, method string, hashes []string, result func(i int) interface{}) error {
+	return rpcbatch.Call(ctx, d.caller, method, rpcbatch.HashArgs(hashes), result, func(i int, err error) {
+		d.logger.Debug("RPC call failed", zap.String("method", method), zap.String("tx_hash", hashes[i]), zap.Error(err))
+	})
+}
+
+// contracts 获取一组地址中已收录的内置 ABI
+func (d *Decoder) contracts(addresses []string) map[string]*Contract {
+	result := make(map[string]*Contract)
+	for _, addr := range addresses {
+		if c := d.bundled[addr]; c != nil {
+			result[addr] = c
+		}
+	}
+	return result
+}
+
+// decodeCall 解码合约调用的 input；contracts 中没有该合约的 ABI 时按签名库匹配
+func (d *Decoder) decodeCall(contracts map[string]*Contract, to string, input []byte) *models.Action {
+	action := &models.Action{Contract: to, Selector: hexutil.Encode(input[:4])}
+	if contract := contracts[to]; contract != nil {
+		action.ContractName = contract.Name
+		if m, err := contract.ABI.MethodById(input[:4]); err == nil {
+			// 已知合约的 calldata 末尾可能附带额外数据（如聚合器的来源标记），不要求严格一致
+			if args, ok := decodeArgs(m.Inputs, input[4:], false); ok {
+				action.Method, action.Signature, action.Args = m.RawName, m.Sig, args
+				return action
+			}
+		}
+	}
+
+	for _, m := range d.methods[[4]byte(input[:4])] {
+		if args, ok := decodeArgs(m.Inputs, input[4:], true); ok {
+			action.Method, action.Signature, action.Args = m.RawName, m.Sig, args
+			break
+		}
+	}
+	return action
+}
+
+// decodeLog 解码事件日志，无法识别时返回 nil
+func (d *Decoder) decodeLog(contracts map[string]*Contract, log rpcLog) *models.ActionEvent {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	address := strings.ToLower(log.Address.Hex())
+
+	var candidates []abi.Event
+	var contractName string
+	if contract := contracts[address]; contract != nil {
+		contractName = contract.Name
+		if e, err := contract.ABI.EventByID(log.Topics[0]); err == nil {
+			candidates = append(candidates, *e)
+		}
+	}
+	candidates = append(candidates, d.events[log.Topics[0]]...)
+
+	for _, e := range candidates {
+		if args, ok := decodeEvent(e, log.Topics[1:], log.Data); ok {
+			return &models.ActionEvent{
+				Address:      address,
+				ContractName: contractName,
+				LogIndex:     int(log.LogIndex),
+				Event:        e.RawName,
+				Signature:    e.Sig,
+				Args:         args,
+			}
+		}
+	}
+	return nil
+}
+
+// decodeEvent 按声明顺序返回事件参数；indexed 参数的数量需与 topic 数一致
+func decodeEvent(e abi.Event, topics []common.Hash, data []byte) ([]models.ActionArg, bool) {
+	if e.Anonymous || indexedCount(e) != len(topics) {
+		return nil, false
+	}
+	nonIndexed, ok := decodeArgs(e.Inputs.NonIndexed(), data, false)
+	if !ok {
+		return nil, false
+	}
+
+	args := make([]models.ActionArg, 0, len(e.Inputs))
+	var t, n int
+	for _, input := range e.Inputs {
+		if !input.Indexed {
+			args = append(args, nonIndexed[n])
+			n++
+			continue
+		}
+		topic := topics[t]
+		t++
+		if isHashedTopic(input.Type) {
+			args = append(args, models.ActionArg{Name: input.Name, Type: input.Type.String(), Value: topic.Hex()})
+			continue
+		}
+		value, ok := decodeArgs(abi.Arguments{{Name: input.Name, Type: input.Type}}, topic.Bytes(), false)
+		if !ok {
+			return nil, false
+		}
+		args = append(args, value[0])
+	}
+	return args, true
+}
+
+func indexedCount(e abi.Event) int {
+	n := 0
+	for _, input := range e.Inputs {
+		if input.Indexed {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,194 @@
+package decoder
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+const (
+	uniswapV2 = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	weth      = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	usdc      = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	pair      = "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc"
+	vault     = "0x9999999999999999999999999999999999999999"
+	wallet    = "0x1111111111111111111111111111111111111111"
+)
+
+// memoryStore 按用户返回可见的上传 ABI
+type memoryStore struct {
+	contracts map[int64][]models.ContractABI
+}
+
+func (s *memoryStore) ListVisible(_ context.Context, userID int64, _ []string) ([]models.ContractABI, error) {
+	return s.contracts[userID], nil
+}
+
+func topic(s string) string {
+	return crypto.Keccak256Hash([]byte(s)).Hex()
+}
+
+func addressTopic(addr string) string {
+	return common.BytesToHash(common.HexToAddress(addr).Bytes()).Hex()
+}
+
+func word(n int64) []byte {
+	return common.LeftPadBytes(big.NewInt(n).Bytes(), 32)
+}
+
+func TestDecode(t *testing.T) {
+	router := Bundled(uniswapV2)
+	require.NotNil(t, router)
+	routerABI, err := ParseABI(router.ABI)
+	require.NoError(t, err)
+	swapInput, err := routerABI.Pack("swapExactETHForTokens",
+		big.NewInt(1000), []common.Address{common.HexToAddress(weth), common.HexToAddress(usdc)}, common.HexToAddress(wallet), big.NewInt(1700000000))
+	require.NoError(t, err)
+
+	transferInput := append(crypto.Keccak256([]byte("transfer(address,uint256)"))[:4], append(common.LeftPadBytes(common.HexToAddress(wallet).Bytes(), 32), word(5)...)...)
+	vaultInput := append(crypto.Keccak256([]byte("stake(uint256)"))[:4], word(7)...)
+
+	results := map[string]map[string]interface{}{ // method -> 交易哈希 -> 结果
+		"eth_getTransactionByHash": {
+			"0xaa": map[string]interface{}{"to": uniswapV2, "input": hexutil.Encode(swapInput)},
+			"0xbb": map[string]interface{}{"to": usdc, "input": hexutil.Encode(transferInput)},
+			"0xcc": map[string]interface{}{"to": wallet, "input": "0x"},
+			"0xdd": map[string]interface{}{"to": vault, "input": hexutil.Encode(vaultInput)},
+		},
+		"eth_getTransactionReceipt": {
+			"0xaa": map[string]interface{}{"logs": []map[string]interface{}{
+				{"address": weth, "topics": []string{topic("Deposit(address,uint256)"), addressTopic(uniswapV2)}, "data": hexutil.Encode(word(1000)), "logIndex": "0x0"},
+				{"address": pair, "topics": []string{topic("Sync(uint112,uint112)")}, "data": hexutil.Encode(append(word(1), word(2)...)), "logIndex": "0x1"},
+				{"address": pair, "topics": []string{topic("Unknown()")}, "data": "0x", "logIndex": "0x2"},
+			}},
+			"0xbb": map[string]interface{}{"logs": []map[string]interface{}{
+				{"address": usdc, "topics": []string{topic("Transfer(address,address,uint256)"), addressTopic(wallet), addressTopic(vault)}, "data": hexutil.Encode(word(5)), "logIndex": "0x3"},
+			}},
+		},
+	}
+	caller := &rpctest.Caller{}
+	for method, byHash := range results {
+		for hash, result := range byHash {
+			caller.Set(method, hash, result)
+		}
+	}
+	d, err := NewDecoder(caller, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	swap := &models.Transaction{TxHash: "0xAA", TxType: "ETH"}
+	swapToken := &models.Transaction{TxHash: "0xaa", TxType: "ERC20"}
+	transfer := &models.Transaction{TxHash: "0xbb", TxType: "ERC20"}
+	plain := &models.Transaction{TxHash: "0xcc", TxType: "ETH"}
+	stake := &models.Transaction{TxHash: "0xdd", TxType: "ETH"}
+	d.Decode(context.Background(), []*models.Transaction{swap, swapToken, transfer, plain, stake})
+
+	require.NotNil(t, swap.Action)
+	assert.Same(t, swap.Action, swapToken.Action)
+	assert.Equal(t, "swapExactETHForTokens on Uniswap V2", swap.Action.String())
+	assert.Equal(t, "swapExactETHForTokens(uint256,address[],address,uint256)", swap.Action.Signature)
+	require.Len(t, swap.Action.Args, 4)
+	assert.Equal(t, models.ActionArg{Name: "amountOutMin", Type: "uint256", Value: "1000"}, swap.Action.Args[0])
+	assert.Equal(t, []interface{}{weth, usdc}, swap.Action.Args[1].Value)
+	require.Len(t, swap.Action.Events, 2, "unknown logs are skipped")
+	assert.Equal(t, "Deposit", swap.Action.Events[0].Event)
+	assert.Equal(t, "WETH", swap.Action.Events[0].ContractName)
+	assert.Equal(t, uniswapV2, swap.Action.Events[0].Args[0].Value)
+	assert.Equal(t, "Sync", swap.Action.Events[1].Event)
+
+	require.NotNil(t, transfer.Action)
+	assert.Equal(t, "transfer", transfer.Action.String(), "decoded from the signature database")
+	require.Len(t, transfer.Action.Events, 1)
+	assert.Equal(t, "Transfer", transfer.Action.Events[0].Event)
+	assert.Equal(t, "5", transfer.Action.Events[0].Args[2].Value)
+
+	assert.Nil(t, plain.Action)
+
+	// 未收录的合约只有选择器
+	require.NotNil(t, stake.Action)
+	assert.Empty(t, stake.Action.Method)
+	assert.Equal(t, hexutil.Encode(vaultInput[:4]), stake.Action.Selector)
+}
+
+func TestUploadedApply(t *testing.T) {
+	const uploader, teammate, stranger = 1, 2, 3
+	vaultABI := models.ContractABI{
+		Address:    vault,
+		Name:       "Test Vault",
+		ABI:        json.RawMessage(`[{"type":"function","name":"stake","inputs":[{"name":"amount","type":"uint256"}]}]`),
+		UploadedBy: uploader,
+		UpdatedAt:  time.Now(),
+	}
+	u := NewUploaded(&memoryStore{contracts: map[int64][]models.ContractABI{
+		uploader: {vaultABI},
+		teammate: {vaultABI},
+	}}, zap.NewNop())
+
+	input := hexutil.Encode(append(crypto.Keccak256([]byte("stake(uint256)"))[:4], word(7)...))
+	shared := &models.Action{Contract: vault, Selector: input[:10],
+		Events: []models.ActionEvent{{Address: vault, Event: "Staked"}}}
+	load := func() *models.Transaction {
+		return &models.Transaction{TxHash: "0xdd", TxType: "ETH", Action: shared, InputData: input}
+	}
+
+	// 上传者与团队成员按上传的 ABI 解码，事件保持入库时的结果
+	for _, userID := range []int64{uploader, teammate} {
+		tx := load()
+		u.Apply(context.Background(), userID, []*models.Transaction{tx})
+		require.NotNil(t, tx.Action)
+		assert.Equal(t, "stake on Test Vault", tx.Action.String())
+		assert.Equal(t, "amount", tx.Action.Args[0].Name)
+		assert.Equal(t, "7", tx.Action.Args[0].Value)
+		assert.Len(t, tx.Action.Events, 1)
+	}
+	assert.Empty(t, shared.Method, "the shared action is not modified")
+
+	// 其他用户看到的仍是入库时的结果
+	tx := load()
+	u.Apply(context.Background(), stranger, []*models.Transaction{tx})
+	assert.Same(t, shared, tx.Action)
+
+	// 没有 input 的交易不解码
+	tx = load()
+	tx.InputData = ""
+	u.Apply(context.Background(), uploader, []*models.Transaction{tx})
+	assert.Same(t, shared, tx.Action)
+
+	var none *Uploaded
+	none.Apply(context.Background(), uploader, []*models.Transaction{load()})
+}
+
+func TestParseSignature(t *testing.T) {
+	entry, err := parseSignature("function exactInputSingle((address tokenIn, uint24 fee, uint) params, uint[] amounts, address payable to) payable returns (uint256)")
+	require.NoError(t, err)
+	assert.Equal(t, "exactInputSingle", entry.Name)
+	require.Len(t, entry.Inputs, 3)
+	assert.Equal(t, "tuple", entry.Inputs[0].Type)
+	assert.Equal(t, "params", entry.Inputs[0].Name)
+	assert.Equal(t, "field2", entry.Inputs[0].Components[2].Name)
+	assert.Equal(t, "uint256", entry.Inputs[0].Components[2].Type)
+	assert.Equal(t, "uint256[]", entry.Inputs[1].Type)
+	assert.Equal(t, "to", entry.Inputs[2].Name)
+
+	entry, err = parseSignature("event Transfer(address indexed from, address indexed, uint256)")
+	require.NoError(t, err)
+	assert.Equal(t, "event", entry.Type)
+	assert.True(t, entry.Inputs[1].Indexed)
+	assert.Empty(t, entry.Inputs[1].Name)
+
+	for _, invalid := range []string{"transfer", "transfer(address", "1transfer()", "transfer(address,,uint256)"} {
+		_, err := parseSignature(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,105 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const (
+	// maxBytesLength bytes / string 参数保留的最大长度，超出部分截断
+	maxBytesLength = 1024
+	// maxArrayLength 数组参数保留的最大元素数
+	maxArrayLength = 100
+)
+
+// decodeArgs 解码 ABI 编码的参数；strict 时要求重新编码后与原数据一致，用于排除 4 字节签名冲突
+func decodeArgs(args abi.Arguments, data []byte, strict bool) (result []models.ActionArg, ok bool) {
+	// 不可信的输入可能触发 abi 包内部的 panic
+	defer func() {
+		if recover() != nil {
+			result, ok = nil, false
+		}
+	}()
+
+	values, err := args.Unpack(data)
+	if err != nil || len(values) != len(args) {
+		return nil, false
+	}
+	if strict {
+		packed, err := args.Pack(values...)
+		if err != nil || !bytes.Equal(packed, data) {
+			return nil, false
+		}
+	}
+
+	result = make([]models.ActionArg, len(args))
+	for i, arg := range args {
+		result[i] = models.ActionArg{Name: arg.Name, Type: arg.Type.String(), Value: formatValue(arg.Type, values[i])}
+	}
+	return result, true
+}
+
+// formatValue 将解码结果转换为 JSON 友好的形式：整数为十进制字符串，地址小写，bytes 为十六进制，tuple 为对象
+func formatValue(t abi.Type, v interface{}) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return fmt.Sprint(v)
+	case abi.AddressTy:
+		return strings.ToLower(v.(common.Address).Hex())
+	case abi.BoolTy:
+		return v
+	case abi.StringTy:
+		s := v.(string)
+		if len(s) > maxBytesLength {
+			s = s[:maxBytesLength] + "…"
+		}
+		return s
+	case abi.BytesTy:
+		return formatBytes(v.([]byte))
+	case abi.FixedBytesTy, abi.HashTy:
+		rv := reflect.ValueOf(v)
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return formatBytes(b)
+	case abi.SliceTy, abi.ArrayTy:
+		rv := reflect.ValueOf(v)
+		out := make([]interface{}, 0, min(rv.Len(), maxArrayLength))
+		for i := 0; i < rv.Len() && i < maxArrayLength; i++ {
+			out = append(out, formatValue(*t.Elem, rv.Index(i).Interface()))
+		}
+		return out
+	case abi.TupleTy:
+		rv := reflect.ValueOf(v)
+		out := make(map[string]interface{}, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			out[t.TupleRawNames[i]] = formatValue(*elem, rv.Field(i).Interface())
+		}
+		return out
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatBytes(b []byte) string {
+	if len(b) > maxBytesLength {
+		return hexutil.Encode(b[:maxBytesLength]) + "…"
+	}
+	return hexutil.Encode(b)
+}
+
+// isHashedTopic 动态类型的 indexed 参数在 topic 中只保存 keccak256 哈希
+func isHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package decoder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// abiEntry JSON ABI 中的一项，只保留解码需要的字段
+type abiEntry struct {
+	Type            string     `json:"type"`
+	Name            string     `json:"name"`
+	Inputs          []abiParam `json:"inputs"`
+	StateMutability string     `json:"stateMutability,omitempty"`
+	Anonymous       bool       `json:"anonymous,omitempty"`
+}
+
+type abiParam struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Components []abiParam `json:"components,omitempty"`
+	Indexed    bool       `json:"indexed,omitempty"`
+}
+
+// parseSignature 解析可读签名（ethers 风格），如
+// "function swapExactETHForTokens(uint256 amountOutMin, address[] path, address to, uint256 deadline) payable" 或
+// "event Transfer(address indexed from, address indexed to, uint256 value)"；
+// 省略 function / event 时视为函数，参数名可省略，右括号之后的修饰符与返回值被忽略
+func parseSignature(line string) (abiEntry, error) {
+	entry := abiEntry{Type: "function", StateMutability: "nonpayable"}
+	line = strings.TrimSpace(line)
+	if kind, rest, ok := strings.Cut(line, " "); ok && (kind == "function" || kind == "event") {
+		entry.Type, line = kind, strings.TrimSpace(rest)
+	}
+	if entry.Type == "event" {
+		entry.StateMutability = ""
+	}
+
+	open := strings.IndexByte(line, '(')
+	if open < 0 {
+		return entry, fmt.Errorf("invalid signature %q: missing parameters", line)
+	}
+	entry.Name = strings.TrimSpace(line[:open])
+	if !isIdentifier(entry.Name) {
+		return entry, fmt.Errorf("invalid signature %q: invalid name", line)
+	}
+
+	p := &sigParser{s: line, pos: open}
+	inputs, err := p.params()
+	if err != nil {
+		return entry, fmt.Errorf("invalid signature %q: %w", line, err)
+	}
+	entry.Inputs = inputs
+	return entry, nil
+}
+
+// parseSignatures 解析多行签名为 JSON ABI，忽略空行与 # 开头的注释
+func parseSignatures(lines []string) ([]byte, error) {
+	var entries []abiEntry
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parseSignature(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return json.Marshal(entries)
+}
+
+// LoadSignatures 读取签名文件，每行一个可读签名
+func LoadSignatures(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signatures file: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read signatures file: %w", err)
+	}
+	return lines, nil
+}
+
+type sigParser struct {
+	s   string
+	pos int
+}
+
+// params 解析以 s[pos] == '(' 开始的参数列表
+func (p *sigParser) params() ([]abiParam, error) {
+	p.pos++
+	params := []abiParam{}
+	p.skipSpace()
+	if p.peek() == ')' {
+		p.pos++
+		return params, nil
+	}
+	for {
+		param, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return params, nil
+		default:
+			return nil, fmt.Errorf("unexpected character at position %d", p.pos)
+		}
+	}
+}
+
+func (p *sigParser) param() (abiParam, error) {
+	var param abiParam
+	p.skipSpace()
+	if p.peek() == '(' {
+		components, err := p.params()
+		if err != nil {
+			return param, err
+		}
+		// go-ethereum 要求 tuple 的成员有名称
+		for i := range components {
+			if components[i].Name == "" {
+				components[i].Name = fmt.Sprintf("field%d", i)
+			}
+		}
+		param.Type = "tuple" + p.word(isArrayChar)
+		param.Components = components
+	} else {
+		param.Type = normalizeType(p.word(isTypeChar))
+		if param.Type == "" {
+			return param, fmt.Errorf("missing type at position %d", p.pos)
+		}
+	}
+
+	for {
+		p.skipSpace()
+		w := p.word(isIdentChar)
+		if w == "" {
+			return param, nil
+		}
+		switch w {
+		case "indexed":
+			param.Indexed = true
+		case "memory", "calldata", "storage", "payable":
+		default:
+			param.Name = w
+		}
+	}
+}
+
+func (p *sigParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *sigParser) skipSpace() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func (p *sigParser) word(accept func(byte) bool) string {
+	start := p.pos
+	for p.pos < len(p.s) && accept(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// normalizeType 展开 uint / int / byte 等别名，如 "uint[]" -> "uint256[]"
+func normalizeType(t string) string {
+	base, suffix := t, ""
+	if i := strings.IndexByte(t, '['); i >= 0 {
+		base, suffix = t[:i], t[i:]
+	}
+	switch base {
+	case "uint", "int":
+		base += "256"
+	case "byte":
+		base = "bytes1"
+	}
+	return base + suffix
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isArrayChar(c byte) bool {
+	return c == '[' || c == ']' || (c >= '0' && c <= '9')
+}
+
+func isTypeChar(c byte) bool {
+	return isIdentChar(c) || isArrayChar(c)
+}
@@ -0,0 +1,127 @@
+package decoder
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// maxCached 进程内缓存的上传 ABI 数，超过时清空重建
+const maxCached = 1000
+
+// Store 用户上传的合约 ABI，由 repository.ContractABIRepository 实现
+type Store interface {
+	// ListVisible 返回用户本人及其团队成员上传的合约 ABI，同一合约只返回一个（本人上传的优先）
+	ListVisible(ctx context.Context, userID int64, addresses []string) ([]models.ContractABI, error)
+}
+
+// Uploaded 按用户上传的 ABI 解码合约调用。上传的 ABI 只对上传者及其团队成员生效：
+// 入库时的解码结果（transactions.action）由所有用户共享，只使用内置 ABI 与签名库；
+// 用户读取交易时再按其可见的 ABI 重新解码 input_data，结果不写回数据库
+type Uploaded struct {
+	store  Store
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	cache map[uploadedKey]*uploadedContract
+}
+
+type uploadedKey struct {
+	address    string
+	uploadedBy int64
+}
+
+type uploadedContract struct {
+	updatedAt time.Time
+	contract  *Contract // ABI 无法解析时为 nil
+}
+
+func NewUploaded(store Store, logger *zap.Logger) *Uploaded {
+	return &Uploaded{store: store, logger: logger, cache: make(map[uploadedKey]*uploadedContract)}
+}
+
+// Apply 按 userID 可见的上传 ABI 重新解码 txs 中的合约调用，替换 Action 的合约名称、方法与参数；
+// 事件保持入库时的解码结果（回执日志不入库）。u 为 nil 或查询失败时保持原样
+func (u *Uploaded) Apply(ctx context.Context, userID int64, txs []*models.Transaction) {
+	if u == nil {
+		return
+	}
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, tx := range txs {
+		if tx.Action == nil || len(tx.InputData) < 10 {
+			continue
+		}
+		addr := strings.ToLower(tx.Action.Contract)
+		if !seen[addr] {
+			seen[addr] = true
+			addresses = append(addresses, addr)
+		}
+	}
+	if len(addresses) == 0 {
+		return
+	}
+
+	stored, err := u.store.ListVisible(ctx, userID, addresses)
+	if err != nil {
+		u.logger.Warn("Failed to get contract abis", zap.Int64("user_id", userID), zap.Error(err))
+		return
+	}
+	contracts := make(map[string]*Contract, len(stored))
+	for _, s := range stored {
+		if c := u.contract(&s); c != nil {
+			contracts[s.Address] = c
+		}
+	}
+
+	for _, tx := range txs {
+		if tx.Action == nil || len(tx.InputData) < 10 {
+			continue
+		}
+		contract := contracts[strings.ToLower(tx.Action.Contract)]
+		if contract == nil {
+			continue
+		}
+		input, err := hexutil.Decode(tx.InputData)
+		if err != nil || len(input) < 4 {
+			continue
+		}
+		m, err := contract.ABI.MethodById(input[:4])
+		if err != nil {
+			continue
+		}
+		args, ok := decodeArgs(m.Inputs, input[4:], false)
+		if !ok {
+			continue
+		}
+		action := *tx.Action
+		action.ContractName, action.Method, action.Signature, action.Args = contract.Name, m.RawName, m.Sig, args
+		tx.Action = &action
+	}
+}
+
+// contract 解析上传的 ABI，按上传者与更新时间缓存
+func (u *Uploaded) contract(s *models.ContractABI) *Contract {
+	key := uploadedKey{address: s.Address, uploadedBy: s.UploadedBy}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	cached := u.cache[key]
+	if cached == nil || !cached.updatedAt.Equal(s.UpdatedAt) {
+		contract, err := newContract(s.Address, s.Name, s.ABI)
+		if err != nil {
+			u.logger.Warn("Failed to parse contract abi", zap.String("address", s.Address), zap.Error(err))
+		}
+		if len(u.cache) >= maxCached {
+			u.cache = make(map[uploadedKey]*uploadedContract)
+		}
+		cached = &uploadedContract{updatedAt: s.UpdatedAt, contract: contract}
+		u.cache[key] = cached
+	}
+	return cached.contract
+}
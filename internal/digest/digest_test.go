@@ -0,0 +1,267 @@
+package digest
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	treasury = "0x2222222222222222222222222222222222222222"
+	alice    = "0x1111111111111111111111111111111111111111"
+	bob      = "0x3333333333333333333333333333333333333333"
+)
+
+func TestNextRun(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	daily := &models.DigestSubscription{Frequency: models.DigestDaily, SendHour: 8, Timezone: "America/New_York"}
+	weekly := &models.DigestSubscription{Frequency: models.DigestWeekly, SendHour: 8, Weekday: 1, Timezone: "America/New_York"}
+
+	tests := []struct {
+		name  string
+		sub   *models.DigestSubscription
+		after time.Time
+		want  time.Time
+	}{
+		{"daily later today", daily, time.Date(2026, 3, 2, 7, 0, 0, 0, ny), time.Date(2026, 3, 2, 8, 0, 0, 0, ny)},
+		{"daily at send hour", daily, time.Date(2026, 3, 2, 8, 0, 0, 0, ny), time.Date(2026, 3, 3, 8, 0, 0, 0, ny)},
+		{"daily across dst", daily, time.Date(2026, 3, 7, 9, 0, 0, 0, ny), time.Date(2026, 3, 8, 8, 0, 0, 0, ny)},
+		{"weekly same weekday", weekly, time.Date(2026, 3, 2, 7, 0, 0, 0, ny), time.Date(2026, 3, 2, 8, 0, 0, 0, ny)},
+		{"weekly next week", weekly, time.Date(2026, 3, 2, 9, 0, 0, 0, ny), time.Date(2026, 3, 9, 8, 0, 0, 0, ny)},
+		{"weekly mid week", weekly, time.Date(2026, 3, 4, 9, 0, 0, 0, ny), time.Date(2026, 3, 9, 8, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRun(tt.sub, tt.after)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got.In(ny))
+		})
+	}
+
+	start, err := PeriodStart(weekly, time.Date(2026, 3, 9, 8, 0, 0, 0, ny))
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 3, 2, 8, 0, 0, 0, ny).Equal(start))
+}
+
+func item(hash, from, to, value, txType, symbol string) repository.FeedItemDetail {
+	return repository.FeedItemDetail{
+		Transaction: models.Transaction{
+			TxHash: hash, FromAddress: from, ToAddress: to, Value: value, TxType: txType, TokenSymbol: symbol,
+			TokenAddress: map[string]string{"USDC": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"}[symbol],
+		},
+		WatchedAddress: models.WatchedAddress{ID: 1, Kind: models.WatchKindWallet, Address: treasury, Label: "Treasury"},
+	}
+}
+
+func testItems() []repository.FeedItemDetail {
+	return []repository.FeedItemDetail{
+		item("0x01", alice, treasury, "2000000000000000000", "ETH", ""),
+		item("0x02", treasury, bob, "500000000000000000", "ETH", ""),
+		item("0x03", alice, treasury, "1000000000000000000000", "ERC20", "USDC"),
+		item("0x03", alice, treasury, "1000000000000000000000", "ERC20", "USDC"), // 重复条目
+	}
+}
+
+func TestBuild(t *testing.T) {
+	s := Build(testItems(), time.Now().Add(-24*time.Hour), time.Now())
+
+	assert.Equal(t, 3, s.TotalTransactions)
+	require.Len(t, s.Addresses, 1)
+	assert.Equal(t, AddressSummary{Name: "Treasury", Address: treasury, Transactions: 3, Incoming: 2, Outgoing: 1}, s.Addresses[0])
+
+	require.Len(t, s.TokenFlows, 2)
+	assert.Equal(t, "ETH", s.TokenFlows[0].Symbol)
+	assert.Equal(t, "1500000000000000000", s.TokenFlows[0].Net().String())
+
+	require.Len(t, s.LargestTransfers, 2)
+	assert.Equal(t, "0x01", s.LargestTransfers[0].Transaction.TxHash)
+
+	assert.Equal(t, []string{alice, bob}, Counterparties(testItems()))
+}
+
+func TestBuild_ItemMatchingSeveralAddresses(t *testing.T) {
+	// 金库转给另一个监控钱包，ListItems 按命中的地址各返回一行
+	hot := models.WatchedAddress{ID: 2, Kind: models.WatchKindWallet, Address: alice, Label: "Hot"}
+	out := item("0x04", treasury, alice, "3000000000000000000", "ETH", "")
+	in := out
+	in.WatchedAddress = hot
+	items := append(testItems(), out, in)
+
+	s := Build(items, time.Now().Add(-24*time.Hour), time.Now())
+
+	assert.Equal(t, 4, s.TotalTransactions)
+	require.Len(t, s.Addresses, 2)
+	assert.Equal(t, AddressSummary{Name: "Treasury", Address: treasury, Transactions: 4, Incoming: 2, Outgoing: 2}, s.Addresses[0])
+	assert.Equal(t, AddressSummary{Name: "Hot", Address: alice, Transactions: 1, Incoming: 1}, s.Addresses[1])
+
+	require.Len(t, s.TokenFlows, 2)
+	assert.Equal(t, "ETH", s.TokenFlows[0].Symbol)
+	assert.Equal(t, 3, s.TokenFlows[0].Count)
+	// 监控钱包之间的转账流入流出相互抵消
+	assert.Equal(t, "1500000000000000000", s.TokenFlows[0].Net().String())
+	assert.Equal(t, "0x04", s.LargestTransfers[0].Transaction.TxHash)
+	assert.Equal(t, "Treasury", s.LargestTransfers[0].AddressName)
+}
+
+type fakeStore struct {
+	mu      sync.Mutex
+	jobs    []repository.DigestJob
+	nextRun map[int64]time.Time
+	sent    map[int64]bool
+}
+
+func (s *fakeStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]repository.DigestJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := s.jobs
+	s.jobs = nil
+	return jobs, nil
+}
+
+func (s *fakeStore) MarkRun(ctx context.Context, id int64, nextRunAt time.Time, sent bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun[id] = nextRunAt
+	s.sent[id] = sent
+	return nil
+}
+
+func (s *fakeStore) ListItems(ctx context.Context, userID int64, from, to time.Time) ([]repository.FeedItemDetail, error) {
+	if userID != 1 {
+		return nil, nil
+	}
+	return testItems(), nil
+}
+
+func (s *fakeStore) NewCounterparties(ctx context.Context, userID int64, before time.Time, candidates []string) ([]string, error) {
+	return []string{bob}, nil
+}
+
+// smtpStandIn 最小 SMTP 服务，记录收到的邮件
+type smtpStandIn struct {
+	listener net.Listener
+	mu       sync.Mutex
+	rcpts    []string
+	messages []string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &smtpStandIn{listener: l}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestScheduler_SendsDigestOverSMTP(t *testing.T) {
+	server := newSMTPStandIn(t)
+	host, port, err := net.SplitHostPort(server.listener.Addr().String())
+	require.NoError(t, err)
+	portNum, _ := strconv.Atoi(port)
+
+	mailer := NewSMTPMailer(config.SMTPConfig{Host: host, Port: portNum, From: "ChainFeed <digest@example.com>"})
+
+	runAt := time.Now().Add(-time.Minute)
+	store := &fakeStore{
+		nextRun: make(map[int64]time.Time),
+		sent:    make(map[int64]bool),
+		jobs: []repository.DigestJob{
+			{DigestSubscription: models.DigestSubscription{ID: 10, UserID: 1, Email: "user@example.com",
+				Frequency: models.DigestDaily, SendHour: 8, Timezone: "UTC", UnsubscribeToken: "tok"}, RunAt: runAt},
+			{DigestSubscription: models.DigestSubscription{ID: 20, UserID: 2, Email: "quiet@example.com",
+				Frequency: models.DigestDaily, SendHour: 8, Timezone: "UTC", UnsubscribeToken: "tok2"}, RunAt: runAt},
+		},
+	}
+
+	s := NewScheduler(store, mailer, "https://etherscan.io", "https://api.example.com", zap.NewNop())
+	assert.Equal(t, 1, s.ProcessDue(context.Background()))
+
+	// 无活动的用户不发送，但仍推进到下一期
+	assert.True(t, store.sent[10])
+	assert.False(t, store.sent[20])
+	assert.True(t, store.nextRun[20].After(time.Now()))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.messages, 1)
+	assert.Equal(t, []string{"user@example.com"}, server.rcpts)
+
+	msg := server.messages[0]
+	assert.Contains(t, msg, "List-Unsubscribe: <https://api.example.com/api/v1/digest/unsubscribe?token=tok>")
+	assert.Contains(t, msg, "multipart/alternative")
+	assert.Contains(t, msg, "Content-Type: text/html")
+	assert.Contains(t, msg, "1,000 USDC")
+	assert.Contains(t, msg, bob)
+}
@@ -0,0 +1,142 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"math/big"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+)
+
+// Email 渲染后的摘要邮件
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type emailData struct {
+	*Summary
+	Title          string
+	Period         string
+	Explorer       string
+	UnsubscribeURL string
+}
+
+var templateFuncs = map[string]interface{}{
+	"short":  notify.ShortAddress,
+	"amount": notify.FormatAmount,
+	"value": func(v *big.Int, symbol string) string {
+		return notify.FormatValue(v.String(), symbol)
+	},
+	"arrow": func(direction string) string {
+		switch direction {
+		case models.DirectionIn:
+			return "←"
+		case models.DirectionOut:
+			return "→"
+		}
+		return "·"
+	},
+}
+
+var textTemplate = texttemplate.Must(texttemplate.New("text").Funcs(templateFuncs).Parse(`{{.Title}}
+{{.Period}}
+
+{{.TotalTransactions}} transactions across {{len .Addresses}} watched addresses.
+
+WATCHED ADDRESSES
+{{range .Addresses}}- {{.Name}} ({{short .Address}}): {{.Transactions}} txs, {{.Incoming}} in / {{.Outgoing}} out
+{{end}}{{if .TokenFlows}}
+TOKEN FLOWS
+{{range .TokenFlows}}- {{.Symbol}}: in {{value .In .Symbol}}, out {{value .Out .Symbol}}, net {{value .Net .Symbol}} ({{.Count}} transfers)
+{{end}}{{end}}{{if .LargestTransfers}}
+LARGEST TRANSFERS
+{{range .LargestTransfers}}- {{amount .Transaction}} {{arrow .Direction}} {{.AddressName}}: {{$.Explorer}}/tx/{{.Transaction.TxHash}}
+{{end}}{{end}}{{if .NewCounterparties}}
+NEW COUNTERPARTIES
+{{range .NewCounterparties}}- {{.}}
+{{end}}{{end}}
+--
+Unsubscribe: {{.UnsubscribeURL}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1f2937; max-width: 640px; margin: 0 auto;">
+<h2 style="margin-bottom: 4px;">{{.Title}}</h2>
+<p style="color: #6b7280; margin-top: 0;">{{.Period}}</p>
+<p><strong>{{.TotalTransactions}}</strong> transactions across <strong>{{len .Addresses}}</strong> watched addresses.</p>
+
+<h3>Watched addresses</h3>
+<table cellpadding="6" style="border-collapse: collapse; width: 100%;">
+<tr style="background: #f3f4f6; text-align: left;"><th>Address</th><th>Txs</th><th>In</th><th>Out</th></tr>
+{{range .Addresses}}<tr><td>{{.Name}} <span style="color: #9ca3af;">{{short .Address}}</span></td><td>{{.Transactions}}</td><td>{{.Incoming}}</td><td>{{.Outgoing}}</td></tr>
+{{end}}</table>
+{{if .TokenFlows}}
+<h3>Token flows</h3>
+<table cellpadding="6" style="border-collapse: collapse; width: 100%;">
+<tr style="background: #f3f4f6; text-align: left;"><th>Asset</th><th>In</th><th>Out</th><th>Net</th></tr>
+{{range .TokenFlows}}<tr><td>{{.Symbol}}</td><td>{{value .In .Symbol}}</td><td>{{value .Out .Symbol}}</td><td>{{value .Net .Symbol}}</td></tr>
+{{end}}</table>
+{{end}}{{if .LargestTransfers}}
+<h3>Largest transfers</h3>
+<ul>
+{{range .LargestTransfers}}<li><a href="{{$.Explorer}}/tx/{{.Transaction.TxHash}}">{{amount .Transaction}}</a> {{arrow .Direction}} {{.AddressName}}</li>
+{{end}}</ul>
+{{end}}{{if .NewCounterparties}}
+<h3>New counterparties</h3>
+<ul>
+{{range .NewCounterparties}}<li><a href="{{$.Explorer}}/address/{{.}}">{{.}}</a></li>
+{{end}}</ul>
+{{end}}
+<hr style="border: none; border-top: 1px solid #e5e7eb;">
+<p style="color: #9ca3af; font-size: 12px;">You receive this digest because you subscribed in ChainFeed. <a href="{{.UnsubscribeURL}}">Unsubscribe</a></p>
+</body>
+</html>
+`))
+
+// Render 渲染摘要邮件，时间按订阅时区展示
+func Render(s *Summary, sub *models.DigestSubscription, explorer, unsubscribeURL string) (*Email, error) {
+	loc, err := LoadLocation(sub.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Your daily ChainFeed digest"
+	if sub.Frequency == models.DigestWeekly {
+		title = "Your weekly ChainFeed digest"
+	}
+
+	data := emailData{
+		Summary:        s,
+		Title:          title,
+		Period:         formatPeriod(s.From.In(loc), s.To.In(loc)),
+		Explorer:       strings.TrimRight(explorer, "/"),
+		UnsubscribeURL: unsubscribeURL,
+	}
+
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text digest: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html digest: %w", err)
+	}
+
+	return &Email{
+		Subject: fmt.Sprintf("ChainFeed digest: %d transactions (%s)", s.TotalTransactions, data.Period),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func formatPeriod(from, to time.Time) string {
+	const layout = "Jan 2 15:04"
+	return fmt.Sprintf("%s – %s %s", from.Format(layout), to.Format(layout), to.Format("MST"))
+}
@@ -0,0 +1,61 @@
+package digest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// LoadLocation 解析订阅时区，空值视为 UTC
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	return loc, nil
+}
+
+// NextRun 计算 after 之后的下一次发送时间：每天（或每周 weekday）本地时间 send_hour 整点
+func NextRun(sub *models.DigestSubscription, after time.Time) (time.Time, error) {
+	loc, err := LoadLocation(sub.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := after.In(loc)
+	day := t.Day()
+	if sub.Frequency == models.DigestWeekly {
+		day += (sub.Weekday - int(t.Weekday()) + 7) % 7
+	}
+
+	// 用 time.Date 逐日推进，夏令时切换时仍落在本地 send_hour
+	next := time.Date(t.Year(), t.Month(), day, sub.SendHour, 0, 0, 0, loc)
+	if !next.After(t) {
+		if sub.Frequency == models.DigestWeekly {
+			day += 7
+		} else {
+			day++
+		}
+		next = time.Date(t.Year(), t.Month(), day, sub.SendHour, 0, 0, 0, loc)
+	}
+	return next.UTC(), nil
+}
+
+// PeriodStart 返回以 runAt 结束的统计周期的开始时间（前一天或前一周的同一本地时间）
+func PeriodStart(sub *models.DigestSubscription, runAt time.Time) (time.Time, error) {
+	loc, err := LoadLocation(sub.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t := runAt.In(loc)
+	days := 1
+	if sub.Frequency == models.DigestWeekly {
+		days = 7
+	}
+	return time.Date(t.Year(), t.Month(), t.Day()-days, t.Hour(), t.Minute(), 0, 0, loc).UTC(), nil
+}
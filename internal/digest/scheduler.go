@@ -0,0 +1,160 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	pollInterval = time.Minute
+	claimBatch   = 50
+	claimLease   = 10 * time.Minute
+	retryDelay   = 15 * time.Minute
+	retryWindow  = 6 * time.Hour // 超过该时间仍发送失败则放弃本期，等待下一期
+)
+
+// Store 摘要任务的持久化，由 repository.DigestRepository 实现
+type Store interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]repository.DigestJob, error)
+	MarkRun(ctx context.Context, id int64, nextRunAt time.Time, sent bool) error
+	ListItems(ctx context.Context, userID int64, from, to time.Time) ([]repository.FeedItemDetail, error)
+	NewCounterparties(ctx context.Context, userID int64, before time.Time, candidates []string) ([]string, error)
+}
+
+// Scheduler 定时生成并发送邮件摘要
+type Scheduler struct {
+	store     Store
+	mailer    Mailer
+	explorer  string
+	publicURL string
+	logger    *zap.Logger
+}
+
+func NewScheduler(store Store, mailer Mailer, explorer, publicURL string, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		mailer:    mailer,
+		explorer:  explorer,
+		publicURL: publicURL,
+		logger:    logger,
+	}
+}
+
+// UnsubscribeURL 退订链接，无需登录
+func UnsubscribeURL(publicURL, token string) string {
+	return strings.TrimRight(publicURL, "/") + "/api/v1/digest/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// Run 每分钟检查到期的订阅
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.ProcessDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue 处理所有到期订阅，返回成功发送的数量
+func (s *Scheduler) ProcessDue(ctx context.Context) int {
+	sent := 0
+	for {
+		jobs, err := s.store.ClaimDue(ctx, claimBatch, claimLease)
+		if err != nil {
+			s.logger.Error("Failed to claim digest subscriptions", zap.Error(err))
+			return sent
+		}
+		for i := range jobs {
+			if s.process(ctx, &jobs[i]) {
+				sent++
+			}
+		}
+		if len(jobs) < claimBatch {
+			return sent
+		}
+	}
+}
+
+func (s *Scheduler) process(ctx context.Context, job *repository.DigestJob) bool {
+	sub := &job.DigestSubscription
+	logger := s.logger.With(zap.Int64("user_id", sub.UserID), zap.Time("run_at", job.RunAt))
+
+	// 以当前时间计算下一期，停机期间错过的周期不再补发
+	next, err := NextRun(sub, time.Now())
+	if err != nil {
+		logger.Error("Invalid digest schedule", zap.Error(err))
+		return false
+	}
+
+	sent, err := s.send(ctx, sub, job.RunAt)
+	if err != nil {
+		logger.Warn("Failed to send digest", zap.Error(err))
+		if time.Since(job.RunAt) < retryWindow {
+			next = time.Now().Add(retryDelay)
+		}
+	}
+
+	if err := s.store.MarkRun(ctx, sub.ID, next, sent); err != nil {
+		logger.Error("Failed to update digest subscription", zap.Error(err))
+	}
+	return sent
+}
+
+// send 生成 runAt 结束的周期摘要并发送，周期内无活动时不发送
+func (s *Scheduler) send(ctx context.Context, sub *models.DigestSubscription, runAt time.Time) (bool, error) {
+	from, err := PeriodStart(sub, runAt)
+	if err != nil {
+		return false, err
+	}
+	summary, err := Compose(ctx, s.store, sub.UserID, from, runAt)
+	if err != nil {
+		return false, err
+	}
+	if summary.Empty() {
+		return false, nil
+	}
+
+	unsubscribeURL := UnsubscribeURL(s.publicURL, sub.UnsubscribeToken)
+	email, err := Render(summary, sub, s.explorer, unsubscribeURL)
+	if err != nil {
+		return false, err
+	}
+	if err := s.mailer.Send(ctx, &Message{To: sub.Email, Email: email, UnsubscribeURL: unsubscribeURL}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Compose 汇总用户在 [from, to) 的 feed
+func Compose(ctx context.Context, store Store, userID int64, from, to time.Time) (*Summary, error) {
+	items, err := store.ListItems(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	summary := Build(items, from, to)
+
+	counterparties, err := store.NewCounterparties(ctx, userID, from, Counterparties(items))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counterparties: %w", err)
+	}
+	if len(counterparties) > maxNewCounterparties {
+		counterparties = counterparties[:maxNewCounterparties]
+	}
+	summary.NewCounterparties = counterparties
+
+	return summary, nil
+}
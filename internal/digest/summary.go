@@ -0,0 +1,193 @@
+package digest
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	maxLargestTransfers  = 10
+	maxNewCounterparties = 20
+)
+
+// Summary 一个统计周期内的 feed 摘要
+type Summary struct {
+	From              time.Time
+	To                time.Time
+	TotalTransactions int
+	Addresses         []AddressSummary
+	LargestTransfers  []Transfer
+	TokenFlows        []TokenFlow
+	NewCounterparties []string
+}
+
+// AddressSummary 单个监控地址的交易统计
+type AddressSummary struct {
+	Name         string
+	Address      string
+	Transactions int
+	Incoming     int
+	Outgoing     int
+}
+
+// Transfer 某资产在周期内的最大一笔转账
+type Transfer struct {
+	AddressName string
+	Direction   string // in / out，代币监控为空
+	Transaction models.Transaction
+}
+
+// TokenFlow 某资产的流入流出（仅统计钱包监控），金额为放大 1e18 的整数
+type TokenFlow struct {
+	Symbol string
+	Count  int
+	In     *big.Int
+	Out    *big.Int
+}
+
+// Net 净流入
+func (f TokenFlow) Net() *big.Int {
+	return new(big.Int).Sub(f.In, f.Out)
+}
+
+func (s *Summary) Empty() bool {
+	return s.TotalTransactions == 0
+}
+
+type assetStats struct {
+	flow    TokenFlow
+	largest *Transfer
+	max     *big.Int
+}
+
+// Build 汇总 feed 条目；NewCounterparties 需调用方通过 Counterparties 查询后填充
+func Build(items []repository.FeedItemDetail, from, to time.Time) *Summary {
+	s := &Summary{From: from, To: to}
+
+	addresses := make(map[int64]*AddressSummary)
+	var addressOrder []int64
+	assets := make(map[string]*assetStats)
+	var assetOrder []string
+	seen := make(map[string]bool)
+	seenTx := make(map[string]bool)
+
+	for i := range items {
+		tx := &items[i].Transaction
+		wa := &items[i].WatchedAddress
+
+		key := tx.TxHash + "/" + strings.ToLower(wa.Address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		// 同一交易可命中多个监控地址：地址统计与资产流向按地址计入，交易数与资产笔数只计一次
+		firstMatch := !seenTx[tx.TxHash]
+		seenTx[tx.TxHash] = true
+		if firstMatch {
+			s.TotalTransactions++
+		}
+
+		as, ok := addresses[wa.ID]
+		if !ok {
+			as = &AddressSummary{Name: notify.DisplayName(wa), Address: wa.Address}
+			addresses[wa.ID] = as
+			addressOrder = append(addressOrder, wa.ID)
+		}
+		as.Transactions++
+
+		direction, _ := tx.Direction(wa)
+		switch direction {
+		case models.DirectionIn:
+			as.Incoming++
+		case models.DirectionOut:
+			as.Outgoing++
+		}
+
+		// NFT、授权与执行失败的交易只计入地址统计
+		if tx.TxType == "ERC721" || tx.TxType == "APPROVAL" || tx.Status == models.TxStatusReverted {
+			continue
+		}
+		value, ok := new(big.Int).SetString(tx.Value, 10)
+		if !ok {
+			continue
+		}
+
+		// SWAP 按 token_out 统计，买入原生 ETH 时没有代币地址
+		asset := strings.ToLower(tx.TokenAddress)
+		if tx.TxType == "ETH" || asset == "" {
+			asset = "eth"
+		}
+		st, ok := assets[asset]
+		if !ok {
+			st = &assetStats{flow: TokenFlow{Symbol: notify.AssetSymbol(tx), In: new(big.Int), Out: new(big.Int)}}
+			assets[asset] = st
+			assetOrder = append(assetOrder, asset)
+		}
+		switch direction {
+		case models.DirectionIn:
+			st.flow.In.Add(st.flow.In, value)
+		case models.DirectionOut:
+			st.flow.Out.Add(st.flow.Out, value)
+		}
+		if !firstMatch {
+			continue
+		}
+		st.flow.Count++
+		if st.max == nil || value.Cmp(st.max) > 0 {
+			st.max = value
+			st.largest = &Transfer{AddressName: as.Name, Direction: direction, Transaction: *tx}
+		}
+	}
+
+	for _, id := range addressOrder {
+		s.Addresses = append(s.Addresses, *addresses[id])
+	}
+	sort.SliceStable(s.Addresses, func(i, j int) bool {
+		return s.Addresses[i].Transactions > s.Addresses[j].Transactions
+	})
+
+	// 不同资产的金额不可直接比较，按活跃度排序后取每种资产的最大一笔
+	sort.SliceStable(assetOrder, func(i, j int) bool {
+		return assets[assetOrder[i]].flow.Count > assets[assetOrder[j]].flow.Count
+	})
+	for _, key := range assetOrder {
+		st := assets[key]
+		s.TokenFlows = append(s.TokenFlows, st.flow)
+		if len(s.LargestTransfers) < maxLargestTransfers {
+			s.LargestTransfers = append(s.LargestTransfers, *st.largest)
+		}
+	}
+
+	return s
+}
+
+// Counterparties 返回钱包监控交易的对手方地址（小写、去重）
+func Counterparties(items []repository.FeedItemDetail) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for i := range items {
+		tx := &items[i].Transaction
+		wa := &items[i].WatchedAddress
+
+		var counterparty string
+		switch direction, _ := tx.Direction(wa); direction {
+		case models.DirectionIn:
+			counterparty = tx.FromAddress
+		case models.DirectionOut:
+			counterparty = tx.ToAddress
+		}
+		counterparty = strings.ToLower(counterparty)
+		if counterparty == "" || seen[counterparty] {
+			continue
+		}
+		seen[counterparty] = true
+		result = append(result, counterparty)
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+// batchSize 每次从数据库读取的条目数，导出过程中内存只保留一批
+const batchSize = 1000
+
+// Source feed 条目来源，由 repository.FeedRepository 实现
+type Source interface {
+	ListUserFeed(ctx context.Context, userID int64, filter *models.FeedFilter, q *pagination.Query) ([]repository.FeedItemDetail, bool, error)
+}
+
+// Write 按 (created_at, id) 升序分批读取满足过滤条件的 feed 条目并写出，返回写出的行数；
+// 不调用 w.Close，由调用方在成功后写入文件尾
+func Write(ctx context.Context, src Source, userID int64, filter *models.FeedFilter, loc *time.Location, w Writer) (int64, error) {
+	// 以最早的位置作为起点，使用轮询模式按升序翻页
+	after := &pagination.Cursor{}
+	var written *pagination.Cursor
+	var rows int64
+	for {
+		items, hasMore, err := src.ListUserFeed(ctx, userID, filter, &pagination.Query{Limit: batchSize, After: after})
+		if err != nil {
+			return rows, err
+		}
+		// 轮询模式的结果为降序，倒序遍历恢复升序
+		for i := len(items) - 1; i >= 0; i-- {
+			// 每个条目只写出一次：来源返回不晚于上一条的条目（如重叠的分页）时跳过
+			if written != nil && !cursorAfter(items[i].CreatedAt, items[i].ID, written) {
+				continue
+			}
+			written = &pagination.Cursor{Time: items[i].CreatedAt, ID: items[i].ID}
+			if err := w.Write(NewRow(&items[i], loc)); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if !hasMore || len(items) == 0 {
+			return rows, nil
+		}
+		after = &pagination.Cursor{Time: items[0].CreatedAt, ID: items[0].ID}
+	}
+}
@@ -0,0 +1,356 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	watched = "0x1111111111111111111111111111111111111111"
+	other   = "0x2222222222222222222222222222222222222222"
+)
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "1.5", FormatAmount("1500000000000000000"))
+	assert.Equal(t, "2500", FormatAmount("2500000000000000000000"))
+	assert.Equal(t, "0.000000000000000001", FormatAmount("1"))
+	assert.Equal(t, "0", FormatAmount("0"))
+	assert.Equal(t, "", FormatAmount("abc"))
+}
+
+// fakeSource 按 ID 降序保存条目，模拟 ListUserFeed 的轮询模式；overlap 为每页附带的游标及之前的条目数
+type fakeSource struct {
+	items   []repository.FeedItemDetail
+	calls   int
+	overlap int
+}
+
+func (s *fakeSource) ListUserFeed(_ context.Context, _ int64, _ *models.FeedFilter, q *pagination.Query) ([]repository.FeedItemDetail, bool, error) {
+	s.calls++
+	var page []repository.FeedItemDetail
+	for i := len(s.items) - 1; i >= 0 && len(page) < q.Limit; i-- {
+		if s.items[i].ID > q.After.ID {
+			page = append([]repository.FeedItemDetail{s.items[i]}, page...)
+		}
+	}
+	hasMore := page != nil && page[0].ID < s.items[0].ID
+	for i, n := 0, 0; i < len(s.items) && n < s.overlap; i++ {
+		if s.items[i].ID <= q.After.ID {
+			page = append(page, s.items[i])
+			n++
+		}
+	}
+	return page, hasMore, nil
+}
+
+func newItem(id int64) repository.FeedItemDetail {
+	usd := "4500.00"
+	item := repository.FeedItemDetail{}
+	item.ID = id
+	item.Transaction = models.Transaction{
+		TxHash:         "0xabc",
+		BlockNumber:    100 + id,
+		BlockTimestamp: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		FromAddress:    watched,
+		ToAddress:      other,
+		Value:          "1500000000000000000",
+		TxType:         "ETH",
+		USDValue:       &usd,
+	}
+	item.WatchedAddresses = []models.WatchedAddress{{ID: 1, Kind: models.WatchKindWallet, Address: watched, Label: "hot"}}
+	return item
+}
+
+func TestWrite(t *testing.T) {
+	src := &fakeSource{}
+	for id := int64(batchSize + 5); id >= 1; id-- {
+		src.items = append(src.items, newItem(id))
+	}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf)
+	require.NoError(t, err)
+	rows, err := Write(context.Background(), src, 1, nil, shanghai, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, int64(batchSize+5), rows)
+	assert.Equal(t, 2, src.calls)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, batchSize+6)
+	assert.True(t, strings.HasPrefix(lines[0], "id,block_timestamp,block_number,"))
+	assert.Equal(t, "1,2026-05-01T08:00:00+08:00,101,0xabc,ETH,out,"+watched+","+other+",1.5,4500.00,,,,"+watched+",hot,,,", lines[1])
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "1005,"), "rows are written in ascending order")
+
+	// 来源在后续页重复返回已写出的条目时不重复写出
+	src = &fakeSource{items: src.items, overlap: 100}
+	buf.Reset()
+	w, err = NewWriter(FormatCSV, &buf)
+	require.NoError(t, err)
+	rows, err = Write(context.Background(), src, 1, nil, shanghai, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, int64(batchSize+5), rows)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[1:] {
+		id, _, _ := strings.Cut(line, ",")
+		assert.False(t, seen[id], "row %s written twice", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, batchSize+5)
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatParquet, &buf)
+	require.NoError(t, err)
+	for i := 0; i < parquetRowGroupSize+1; i++ {
+		require.NoError(t, w.Write(&Row{ID: int64(i + 1), BlockNumber: 101, TxHash: "0xabc", Amount: "1.5", WatchedLabels: "热钱包"}))
+	}
+	require.NoError(t, w.Close())
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(parquetRowGroupSize+1), f.NumRows())
+	assert.Len(t, f.RowGroups(), 2)
+
+	fields := f.Schema().Fields()
+	require.Len(t, fields, len(columns))
+	for i, col := range columns {
+		assert.Equal(t, col.name, fields[i].Name())
+		assert.True(t, fields[i].Required(), col.name)
+		if _, ok := col.value(&Row{}).(int64); ok {
+			assert.Equal(t, parquet.Int64, fields[i].Type().Kind(), col.name)
+		} else {
+			assert.Equal(t, parquet.ByteArray, fields[i].Type().Kind(), col.name)
+			assert.Equal(t, "STRING", fields[i].Type().LogicalType().String(), col.name)
+		}
+	}
+
+	rows, err := parquet.Read[Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, rows, parquetRowGroupSize+1)
+	assert.Equal(t, Row{ID: 1, BlockNumber: 101, TxHash: "0xabc", Amount: "1.5", WatchedLabels: "热钱包"}, rows[0])
+	assert.Equal(t, int64(parquetRowGroupSize+1), rows[parquetRowGroupSize].ID)
+}
+
+type fakeTxSource struct {
+	txs []models.Transaction // 按时间降序
+}
+
+func (s *fakeTxSource) ListByAddress(_ context.Context, _ string, _, _ bool, q *pagination.Query) ([]models.Transaction, bool, error) {
+	var page []models.Transaction
+	for i := len(s.txs) - 1; i >= 0 && len(page) < q.Limit; i-- {
+		if s.txs[i].BlockTimestamp.After(q.After.Time) {
+			page = append([]models.Transaction{s.txs[i]}, page...)
+		}
+	}
+	return page, false, nil
+}
+
+func TestWriteLedger(t *testing.T) {
+	usdc := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 12, 0, 0, 0, time.UTC) }
+	src := &fakeTxSource{txs: []models.Transaction{
+		{ID: 4, TxHash: "0x4", BlockTimestamp: day(4), FromAddress: watched, ToAddress: other, Value: "500000000000000000", TxType: "ETH"},
+		{ID: 3, TxHash: "0x3", BlockTimestamp: day(3), FromAddress: other, ToAddress: watched, Value: "100000000000000000000",
+			TxType: "ERC20", TokenAddress: usdc, TokenSymbol: "USDC"},
+		{ID: 2, TxHash: "0x2", BlockTimestamp: day(2), FromAddress: watched, ToAddress: watched, Value: "1000000000000000000", TxType: "ETH"},
+		{ID: 1, TxHash: "0x1", BlockTimestamp: day(1), FromAddress: other, ToAddress: watched, Value: "2000000000000000000", TxType: "ETH"},
+	}}
+
+	var buf bytes.Buffer
+	w, err := NewLedgerWriter(LedgerGeneric, watched, &buf, time.UTC)
+	require.NoError(t, err)
+	from := day(2)
+	n, err := WriteLedger(context.Background(), src, watched, &from, nil, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, int64(3), n, "entries before from_time only count towards balances")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 7)
+	assert.Contains(t, lines[3], "wallet:"+watched+",USDC,"+usdc+",,100,,100,")
+	assert.Contains(t, lines[4], "external:"+other+",USDC,"+usdc+",,,100,,")
+	assert.Contains(t, lines[6], "wallet:"+watched+",ETH,,,,0.5,1.5,", "running balance includes the earlier inflow")
+
+	buf.Reset()
+	w, err = NewLedgerWriter(LedgerKoinly, watched, &buf, time.UTC)
+	require.NoError(t, err)
+	_, err = WriteLedger(context.Background(), src, watched, nil, nil, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4, "self transfers without fees are skipped")
+	assert.Equal(t, "2026-05-01 12:00:00 UTC,,,2,ETH,,,,,,,0x1", lines[1])
+	assert.Equal(t, "2026-05-04 12:00:00 UTC,0.5,ETH,,,,,,,,,0x4", lines[3])
+}
+
+// overlapTxSource 每页最多返回 pageSize 笔新交易，并像接口轮询一样附带游标及之前的交易
+type overlapTxSource struct {
+	txs      []models.Transaction // 按时间降序
+	pageSize int
+}
+
+func (s *overlapTxSource) ListByAddress(_ context.Context, _ string, _, _ bool, q *pagination.Query) ([]models.Transaction, bool, error) {
+	var page, late []models.Transaction
+	newer := 0
+	for i := len(s.txs) - 1; i >= 0; i-- {
+		tx := s.txs[i]
+		if !tx.BlockTimestamp.After(q.After.Time) {
+			late = append([]models.Transaction{tx}, late...)
+			continue
+		}
+		if newer++; newer <= s.pageSize {
+			page = append([]models.Transaction{tx}, page...)
+		}
+	}
+	return append(page, late...), newer > s.pageSize, nil
+}
+
+func TestWriteLedger_SkipsOverlappingRows(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 12, 0, 0, 0, time.UTC) }
+	fee := "1000000000000000"
+	gas := &models.Gas{Sender: watched}
+	src := &overlapTxSource{pageSize: 2, txs: []models.Transaction{
+		{ID: 4, TxHash: "0x4", BlockTimestamp: day(4), FromAddress: watched, ToAddress: other, Value: "500000000000000000", TxType: "ETH", Fee: &fee, Gas: gas},
+		{ID: 3, TxHash: "0x3", BlockTimestamp: day(3), FromAddress: other, ToAddress: watched, Value: "1000000000000000000", TxType: "ETH"},
+		{ID: 2, TxHash: "0x2", BlockTimestamp: day(2), FromAddress: watched, ToAddress: other, Value: "500000000000000000", TxType: "ETH", Fee: &fee, Gas: gas},
+		{ID: 1, TxHash: "0x1", BlockTimestamp: day(1), FromAddress: other, ToAddress: watched, Value: "2000000000000000000", TxType: "ETH"},
+	}}
+
+	var buf bytes.Buffer
+	w, err := NewLedgerWriter(LedgerGeneric, watched, &buf, time.UTC)
+	require.NoError(t, err)
+	n, err := WriteLedger(context.Background(), src, watched, nil, nil, w)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var hashes []string
+	for _, line := range lines[1:] {
+		hashes = append(hashes, strings.Split(line, ",")[1])
+	}
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, []string{"0x1", "0x1", "0x2", "0x2", "0x2", "0x2", "0x3", "0x3", "0x4", "0x4", "0x4", "0x4"}, hashes,
+		"each transaction is posted once even though later pages repeat earlier rows")
+	assert.Contains(t, lines[len(lines)-1], ",1.998,", "running balance counts each transfer and fee once")
+}
+
+// ledgerRecorder 记录写出的分录
+type ledgerRecorder struct{ entries []*LedgerEntry }
+
+func (r *ledgerRecorder) Write(e *LedgerEntry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *ledgerRecorder) Close() error { return nil }
+
+func TestWriteLedger_ChargesFeeOncePerTransaction(t *testing.T) {
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	usdc := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	dai := "0x6b175474e89094c44da98b954eedeac495271d0f"
+	fee := "1000000000000000"
+	gas := &models.Gas{Sender: watched}
+	// 同一交易的两条代币转账分行保存，回执信息（手续费）相同
+	src := &overlapTxSource{pageSize: 10, txs: []models.Transaction{
+		{ID: 2, TxHash: "0x1", LogIndex: 5, BlockTimestamp: at, FromAddress: other, ToAddress: watched, Value: "2000000000000000000",
+			TxType: "ERC20", TokenAddress: dai, TokenSymbol: "DAI", TokenDecimals: 18, Fee: &fee, Gas: gas},
+		{ID: 1, TxHash: "0x1", LogIndex: 3, BlockTimestamp: at, FromAddress: watched, ToAddress: other, Value: "1000000000000000000",
+			TxType: "ERC20", TokenAddress: usdc, TokenSymbol: "USDC", TokenDecimals: 6, Fee: &fee, Gas: gas},
+	}}
+
+	rec := &ledgerRecorder{}
+	n, err := WriteLedger(context.Background(), src, watched, nil, nil, rec)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	require.Len(t, rec.entries, 2)
+	require.NotNil(t, rec.entries[0].Fee)
+	assert.Equal(t, "-0.001", FormatDecimal(rec.entries[0].FeeBalance))
+	assert.Nil(t, rec.entries[1].Fee, "the fee is charged with the first log only")
+}
+
+func TestLedgerFee(t *testing.T) {
+	l := NewLedger(watched)
+	fee := big.NewInt(21000 * 1e9)
+	e := l.Post(&models.Transaction{FromAddress: watched, ToAddress: other, Value: "1000000000000000000", TxType: "ETH"}, fee)
+	require.NotNil(t, e)
+	assert.Equal(t, "-1", FormatDecimal(e.Balance))
+	assert.Equal(t, "0.000021", FormatDecimal(e.Fee))
+	assert.Equal(t, "-1.000021", FormatDecimal(e.FeeBalance))
+
+	assert.Nil(t, l.Post(&models.Transaction{FromAddress: other, ToAddress: watched, Value: "0", TxType: "ETH"}, nil))
+
+	// 失败的交易与授权只记手续费
+	e = l.Post(&models.Transaction{FromAddress: watched, ToAddress: other, Value: "1000000000000000000", TxType: "ETH",
+		Status: models.TxStatusReverted}, fee)
+	require.NotNil(t, e)
+	assert.Equal(t, EntryFee, e.Direction)
+	assert.Equal(t, "0", FormatDecimal(e.Amount))
+	assert.Equal(t, "-1.000042", FormatDecimal(e.FeeBalance))
+	e = l.Post(&models.Transaction{FromAddress: watched, ToAddress: other, Value: "0", TxType: "APPROVAL"}, fee)
+	require.NotNil(t, e)
+	assert.Equal(t, EntryFee, e.Direction)
+	assert.Nil(t, l.Post(&models.Transaction{FromAddress: watched, ToAddress: other, Value: "0", TxType: "APPROVAL"}, nil))
+
+	wei := "21000000000000"
+	tx := &models.Transaction{Fee: &wei, Gas: &models.Gas{Sender: strings.ToUpper(watched)}}
+	assert.Equal(t, fee, paidFee(tx, watched))
+	assert.Nil(t, paidFee(tx, other), "only the sender pays the fee")
+}
+
+func TestLedgerSwap(t *testing.T) {
+	usdc := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	pool := "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc"
+	swap := &models.Transaction{TxHash: "0x5", FromAddress: watched, ToAddress: watched, TxType: "SWAP",
+		TokenAddress: usdc, TokenSymbol: "USDC", Value: "2000000000000000000000", Swap: &models.Swap{
+			Pools:    []string{pool},
+			Trader:   watched,
+			TokenIn:  models.SwapAsset{Symbol: "ETH", Decimals: 18, Amount: "1000000000000000000"},
+			TokenOut: models.SwapAsset{Address: usdc, Symbol: "USDC", Decimals: 6, Amount: "2000000000"},
+		}}
+
+	l := NewLedger(watched)
+	e := l.Post(swap, nil)
+	require.NotNil(t, e)
+	assert.Equal(t, EntryTrade, e.Direction)
+	assert.Equal(t, pool, e.Counterparty)
+	assert.Equal(t, "USDC", e.Asset)
+	assert.Equal(t, "2000", FormatDecimal(e.Balance))
+	assert.Equal(t, "ETH", e.SentAsset)
+	assert.Equal(t, "-1", FormatDecimal(e.SentBalance))
+
+	var buf bytes.Buffer
+	w, err := NewLedgerWriter(LedgerKoinly, watched, &buf, time.UTC)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(e))
+	require.NoError(t, w.Close())
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], ",1,ETH,2000,USDC,")
+
+	// 兑换结果发给其他地址时只记转出
+	swap.ToAddress = other
+	e = NewLedger(watched).Post(swap, nil)
+	require.NotNil(t, e)
+	assert.Equal(t, EntryOut, e.Direction)
+	assert.Equal(t, "ETH", e.Asset)
+	assert.Equal(t, "1", FormatDecimal(e.Amount))
+}
@@ -0,0 +1,256 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+// 导出格式
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// ValidFormat 判断是否为支持的导出格式
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatNDJSON || format == FormatParquet
+}
+
+// ContentType 导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Row 导出的一行，对应一个 feed 条目；各格式的列名与 json / parquet 标签一致
+type Row struct {
+	ID               int64  `json:"id" parquet:"id"`
+	BlockTimestamp   string `json:"block_timestamp" parquet:"block_timestamp"` // 按导出时区格式化的 RFC3339
+	BlockNumber      int64  `json:"block_number" parquet:"block_number"`
+	TxHash           string `json:"tx_hash" parquet:"tx_hash"`
+	TxType           string `json:"tx_type" parquet:"tx_type"`
+	Direction        string `json:"direction" parquet:"direction"` // in / out / self，代币合约监控为空
+	FromAddress      string `json:"from_address" parquet:"from_address"`
+	ToAddress        string `json:"to_address" parquet:"to_address"`
+	Amount           string `json:"amount" parquet:"amount"`       // 十进制字符串，见 FormatAmount
+	USDValue         string `json:"usd_value" parquet:"usd_value"` // 区块时间的美元价值，无法计价时为空
+	TokenSymbol      string `json:"token_symbol" parquet:"token_symbol"`
+	TokenAddress     string `json:"token_address" parquet:"token_address"`
+	TokenID          string `json:"token_id" parquet:"token_id"`
+	WatchedAddresses string `json:"watched_addresses" parquet:"watched_addresses"` // 命中的监控地址，分号分隔
+	WatchedLabels    string `json:"watched_labels" parquet:"watched_labels"`       // 与 watched_addresses 一一对应
+	Note             string `json:"note" parquet:"note"`
+	Status           string `json:"status" parquet:"status"` // success / reverted，未获取回执时为空
+	Fee              string `json:"fee" parquet:"fee"`       // 发起方支付的手续费（ETH），未获取回执时为空
+}
+
+type column struct {
+	name  string
+	value func(r *Row) interface{} // int64 或 string
+}
+
+var columns = []column{
+	{"id", func(r *Row) interface{} { return r.ID }},
+	{"block_timestamp", func(r *Row) interface{} { return r.BlockTimestamp }},
+	{"block_number", func(r *Row) interface{} { return r.BlockNumber }},
+	{"tx_hash", func(r *Row) interface{} { return r.TxHash }},
+	{"tx_type", func(r *Row) interface{} { return r.TxType }},
+	{"direction", func(r *Row) interface{} { return r.Direction }},
+	{"from_address", func(r *Row) interface{} { return r.FromAddress }},
+	{"to_address", func(r *Row) interface{} { return r.ToAddress }},
+	{"amount", func(r *Row) interface{} { return r.Amount }},
+	{"usd_value", func(r *Row) interface{} { return r.USDValue }},
+	{"token_symbol", func(r *Row) interface{} { return r.TokenSymbol }},
+	{"token_address", func(r *Row) interface{} { return r.TokenAddress }},
+	{"token_id", func(r *Row) interface{} { return r.TokenID }},
+	{"watched_addresses", func(r *Row) interface{} { return r.WatchedAddresses }},
+	{"watched_labels", func(r *Row) interface{} { return r.WatchedLabels }},
+	{"note", func(r *Row) interface{} { return r.Note }},
+	{"status", func(r *Row) interface{} { return r.Status }},
+	{"fee", func(r *Row) interface{} { return r.Fee }},
+}
+
+// NewRow 将 feed 条目转换为导出行，时间按 loc 格式化
+func NewRow(item *repository.FeedItemDetail, loc *time.Location) *Row {
+	tx := &item.Transaction
+	watched := item.WatchedAddresses
+	if len(watched) == 0 {
+		watched = []models.WatchedAddress{item.WatchedAddress}
+	}
+
+	addresses := make([]string, len(watched))
+	labels := make([]string, len(watched))
+	for i, wa := range watched {
+		addresses[i] = strings.ToLower(wa.Address)
+		labels[i] = wa.Label
+	}
+
+	var usd, fee string
+	if tx.USDValue != nil {
+		usd = *tx.USDValue
+	}
+	// fee 为 wei，与 Value 同为放大 1e18 的整数
+	if tx.Fee != nil {
+		fee = FormatAmount(*tx.Fee)
+	}
+
+	return &Row{
+		ID:               item.ID,
+		BlockTimestamp:   tx.BlockTimestamp.In(loc).Format(time.RFC3339),
+		BlockNumber:      tx.BlockNumber,
+		TxHash:           tx.TxHash,
+		TxType:           tx.TxType,
+		Direction:        direction(tx, watched),
+		FromAddress:      tx.FromAddress,
+		ToAddress:        tx.ToAddress,
+		Amount:           FormatAmount(tx.Value),
+		USDValue:         usd,
+		TokenSymbol:      tx.TokenSymbol,
+		TokenAddress:     tx.TokenAddress,
+		TokenID:          tx.TokenID,
+		WatchedAddresses: strings.Join(addresses, ";"),
+		WatchedLabels:    strings.Join(labels, ";"),
+		Note:             item.Note,
+		Status:           tx.Status,
+		Fee:              fee,
+	}
+}
+
+// direction 交易相对于命中的监控钱包的方向，发送方与接收方都被监控时为 self
+func direction(tx *models.Transaction, watched []models.WatchedAddress) string {
+	var in, out bool
+	for _, wa := range watched {
+		if wa.Kind == models.WatchKindToken {
+			continue
+		}
+		if strings.EqualFold(tx.ToAddress, wa.Address) {
+			in = true
+		}
+		if strings.EqualFold(tx.FromAddress, wa.Address) {
+			out = true
+		}
+	}
+	switch {
+	case in && out:
+		return "self"
+	case in:
+		return models.DirectionIn
+	case out:
+		return models.DirectionOut
+	}
+	return ""
+}
+
+// FormatAmount 将放大 1e18 的整数金额格式化为十进制字符串（见 FormatDecimal）；无法解析时返回空字符串
+func FormatAmount(value string) string {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return ""
+	}
+	return FormatDecimal(units.FromScaled(r))
+}
+
+// FormatDecimal 导出统一的数字格式：小数点分隔、无千位分隔符与科学计数法、去掉末尾的 0，最多 18 位小数
+func FormatDecimal(r *big.Rat) string {
+	s := r.FloatString(18)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// LoadLocation 解析导出时区，空值视为 UTC
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	return loc, nil
+}
+
+// Writer 按格式逐行写出，Close 写入剩余数据（及文件尾），不关闭底层 io.Writer
+type Writer interface {
+	Write(row *Row) error
+	Close() error
+}
+
+// NewWriter 创建指定格式的 Writer
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return newParquetWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	for i, col := range columns {
+		cw.record[i] = col.name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row *Row) error {
+	for i, col := range columns {
+		switch v := col.value(row).(type) {
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case string:
+			cw.record[i] = v
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(row *Row) error {
+	return nw.enc.Encode(row)
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,483 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+// 账本 CSV 布局
+const (
+	LedgerGeneric     = "generic"     // 复式记账分录，每笔交易两行，兑换四行（手续费另计两行）
+	LedgerKoinly      = "koinly"      // Koinly Universal CSV
+	LedgerCoinTracker = "cointracker" // CoinTracker CSV
+)
+
+// ValidLedgerLayout 判断是否为支持的账本布局
+func ValidLedgerLayout(layout string) bool {
+	return layout == LedgerGeneric || layout == LedgerKoinly || layout == LedgerCoinTracker
+}
+
+// 账本分录方向
+const (
+	EntryIn    = "in"
+	EntryOut   = "out"
+	EntrySelf  = "self"  // 发送方与接收方均为该钱包，余额不变
+	EntryTrade = "trade" // 钱包发起并收到兑换：卖出 Sent*，买入 Asset
+	EntryFee   = "fee"   // 只有手续费：执行失败的交易与授权等不转移资产的交易
+)
+
+// LedgerEntry 钱包的一笔资产变动，Balance 为记账后该资产的余额
+type LedgerEntry struct {
+	Time         time.Time
+	TxHash       string
+	BlockNumber  int64
+	Direction    string
+	Asset        string // 展示用的资产符号，缺少符号时为合约地址
+	AssetAddress string // 代币合约地址，ETH 为空
+	TokenID      string
+	Amount       *big.Rat
+	Counterparty string
+	Balance      *big.Rat
+	Fee          *big.Rat // 钱包支付的 gas（ETH），nil 表示无手续费数据
+	FeeBalance   *big.Rat // 扣除手续费后的 ETH 余额
+
+	// 兑换中卖出的资产，仅 EntryTrade
+	SentAsset        string
+	SentAssetAddress string
+	SentAmount       *big.Rat
+	SentBalance      *big.Rat
+}
+
+// Ledger 按时间顺序累计单个钱包各资产的余额；余额从第一笔已存储的交易开始计算，
+// 开始监控之前的历史不在 transactions 中，因此余额可能为负
+type Ledger struct {
+	wallet   string
+	balances map[string]*big.Rat
+}
+
+func NewLedger(wallet string) *Ledger {
+	return &Ledger{wallet: strings.ToLower(wallet), balances: make(map[string]*big.Rat)}
+}
+
+// Post 记入一笔交易，fee 为钱包支付的 gas（wei），钱包不是发起方或无数据时为 nil；
+// 与钱包无关或金额为 0 且无手续费的交易返回 nil。
+// 兑换由钱包发起且由钱包接收时记为 EntryTrade，否则按转出 token_in 或转入 token_out 记账；
+// 执行失败与类型不转移资产的交易只记手续费（EntryFee）
+func (l *Ledger) Post(tx *models.Transaction, fee *big.Int) *LedgerEntry {
+	switch tx.TxType {
+	case "ETH", "ERC20", "ERC721":
+	case "SWAP":
+		if tx.Swap == nil {
+			return l.postFee(tx, fee)
+		}
+	default:
+		return l.postFee(tx, fee)
+	}
+	if tx.Status == models.TxStatusReverted {
+		return l.postFee(tx, fee)
+	}
+
+	from := strings.EqualFold(tx.FromAddress, l.wallet)
+	to := strings.EqualFold(tx.ToAddress, l.wallet)
+
+	entry := &LedgerEntry{
+		Time:         tx.BlockTimestamp,
+		TxHash:       tx.TxHash,
+		BlockNumber:  tx.BlockNumber,
+		AssetAddress: tx.TokenAddress,
+		TokenID:      tx.TokenID,
+	}
+	switch {
+	case from && to:
+		entry.Direction = EntrySelf
+		entry.Counterparty = l.wallet
+	case to:
+		entry.Direction = EntryIn
+		entry.Counterparty = strings.ToLower(tx.FromAddress)
+	case from:
+		entry.Direction = EntryOut
+		entry.Counterparty = strings.ToLower(tx.ToAddress)
+	default:
+		return nil
+	}
+
+	var key, sentKey string
+	switch {
+	case tx.TxType == "SWAP" && entry.Direction == EntrySelf:
+		entry.Direction = EntryTrade
+		if len(tx.Swap.Pools) > 0 {
+			entry.Counterparty = strings.ToLower(tx.Swap.Pools[0])
+		}
+		entry.Asset, entry.AssetAddress, key, entry.Amount = swapLeg(tx.Swap.TokenOut)
+		entry.SentAsset, entry.SentAssetAddress, sentKey, entry.SentAmount = swapLeg(tx.Swap.TokenIn)
+	case tx.TxType == "SWAP" && entry.Direction == EntryOut:
+		entry.Asset, entry.AssetAddress, key, entry.Amount = swapLeg(tx.Swap.TokenIn)
+	case tx.TxType == "SWAP":
+		entry.Asset, entry.AssetAddress, key, entry.Amount = swapLeg(tx.Swap.TokenOut)
+	default:
+		entry.Asset, entry.Amount = ledgerAmount(tx)
+		key = tx.TokenAddress
+		if tx.TxType == "ETH" {
+			key = "ETH"
+			entry.AssetAddress = ""
+		}
+	}
+
+	entry.Fee = feeOf(fee)
+	if entry.Amount.Sign() == 0 && entry.Fee == nil {
+		return nil
+	}
+
+	balance := l.balance(key)
+	switch entry.Direction {
+	case EntryIn:
+		balance.Add(balance, entry.Amount)
+	case EntryOut:
+		balance.Sub(balance, entry.Amount)
+	case EntryTrade:
+		balance.Add(balance, entry.Amount)
+		sent := l.balance(sentKey)
+		sent.Sub(sent, entry.SentAmount)
+		entry.SentBalance = new(big.Rat).Set(sent)
+	}
+	entry.Balance = new(big.Rat).Set(balance)
+
+	l.chargeFee(entry)
+	return entry
+}
+
+// postFee 只记手续费，资产为 ETH、金额为 0
+func (l *Ledger) postFee(tx *models.Transaction, fee *big.Int) *LedgerEntry {
+	entry := &LedgerEntry{
+		Time:         tx.BlockTimestamp,
+		TxHash:       tx.TxHash,
+		BlockNumber:  tx.BlockNumber,
+		Direction:    EntryFee,
+		Asset:        "ETH",
+		Amount:       new(big.Rat),
+		Counterparty: strings.ToLower(tx.ToAddress),
+		Fee:          feeOf(fee),
+	}
+	if entry.Fee == nil {
+		return nil
+	}
+	entry.Balance = new(big.Rat).Set(l.balance("ETH"))
+	l.chargeFee(entry)
+	return entry
+}
+
+func (l *Ledger) chargeFee(entry *LedgerEntry) {
+	if entry.Fee == nil {
+		return
+	}
+	eth := l.balance("ETH")
+	eth.Sub(eth, entry.Fee)
+	entry.FeeBalance = new(big.Rat).Set(eth)
+}
+
+func feeOf(fee *big.Int) *big.Rat {
+	if fee == nil || fee.Sign() <= 0 {
+		return nil
+	}
+	return units.FromScaled(new(big.Rat).SetInt(fee))
+}
+
+// paidFee 钱包作为交易发起方支付的手续费（wei），不是发起方或未获取回执时为 nil
+func paidFee(tx *models.Transaction, wallet string) *big.Int {
+	if tx.Fee == nil || tx.Gas == nil || !strings.EqualFold(tx.Gas.Sender, wallet) {
+		return nil
+	}
+	fee, ok := new(big.Int).SetString(*tx.Fee, 10)
+	if !ok {
+		return nil
+	}
+	return fee
+}
+
+func (l *Ledger) balance(key string) *big.Rat {
+	b, ok := l.balances[key]
+	if !ok {
+		b = new(big.Rat)
+		l.balances[key] = b
+	}
+	return b
+}
+
+// ledgerAmount 资产符号与数量；NFT 每笔转账数量为 1
+func ledgerAmount(tx *models.Transaction) (string, *big.Rat) {
+	asset := tx.TokenSymbol
+	switch {
+	case tx.TxType == "ETH":
+		asset = "ETH"
+	case asset == "":
+		asset = tx.TokenAddress
+	}
+
+	if tx.TxType == "ERC721" {
+		return asset, big.NewRat(1, 1)
+	}
+	amount, ok := new(big.Rat).SetString(tx.Value)
+	if !ok {
+		amount = new(big.Rat)
+	}
+	return asset, units.FromScaled(amount)
+}
+
+// swapLeg 兑换一端的资产符号、合约地址、余额键与数量；原生 ETH 与 ETH 转账共用余额
+func swapLeg(a models.SwapAsset) (asset, address, key string, amount *big.Rat) {
+	asset, address, key = a.Symbol, a.Address, a.Address
+	if a.Address == "" {
+		asset, key = "ETH", "ETH"
+	} else if asset == "" {
+		asset = a.Address
+	}
+	amount, ok := new(big.Rat).SetString(a.Amount)
+	if !ok {
+		amount = new(big.Rat)
+	}
+	return asset, address, key, units.ToDecimal(amount, a.Decimals)
+}
+
+// TransactionSource 地址交易来源，由 repository.TransactionRepository 实现
+type TransactionSource interface {
+	ListByAddress(ctx context.Context, address string, includeSpam, excludeReverted bool, q *pagination.Query) ([]models.Transaction, bool, error)
+}
+
+// WriteLedger 按区块时间升序读取钱包的全部交易并记账，只写出 [from, to) 区间内的分录（nil 表示不限），
+// 区间之前的交易仍计入余额；返回写出的分录数
+func WriteLedger(ctx context.Context, src TransactionSource, wallet string, from, to *time.Time, w LedgerWriter) (int64, error) {
+	ledger := NewLedger(wallet)
+	after := &pagination.Cursor{}
+	var posted *pagination.Cursor
+	// 同一交易的多条代币转账分行保存且区块时间相同，手续费只随其中第一行记账
+	charged := make(map[string]bool)
+	var written int64
+	for {
+		txs, hasMore, err := src.ListByAddress(ctx, wallet, true, false, &pagination.Query{Limit: batchSize, After: after})
+		if err != nil {
+			return written, err
+		}
+		for i := len(txs) - 1; i >= 0; i-- {
+			tx := &txs[i]
+			// 每笔交易只记账一次：来源返回不晚于上一笔的交易（如重叠的分页）时跳过，否则余额与手续费重复计算
+			if posted != nil && !cursorAfter(tx.BlockTimestamp, tx.ID, posted) {
+				continue
+			}
+			if posted == nil || !tx.BlockTimestamp.Equal(posted.Time) {
+				clear(charged)
+			}
+			posted = &pagination.Cursor{Time: tx.BlockTimestamp, ID: tx.ID}
+			if to != nil && !tx.BlockTimestamp.Before(*to) {
+				return written, nil
+			}
+			fee := paidFee(tx, wallet)
+			if charged[tx.TxHash] {
+				fee = nil
+			} else if fee != nil {
+				charged[tx.TxHash] = true
+			}
+			entry := ledger.Post(tx, fee)
+			if entry == nil || (from != nil && tx.BlockTimestamp.Before(*from)) {
+				continue
+			}
+			if err := w.Write(entry); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if !hasMore || len(txs) == 0 {
+			return written, nil
+		}
+		after = &pagination.Cursor{Time: txs[0].BlockTimestamp, ID: txs[0].ID}
+	}
+}
+
+// cursorAfter 判断 (t, id) 是否在 c 之后
+func cursorAfter(t time.Time, id int64, c *pagination.Cursor) bool {
+	if !t.Equal(c.Time) {
+		return t.After(c.Time)
+	}
+	return id > c.ID
+}
+
+// LedgerWriter 按布局写出账本分录
+type LedgerWriter interface {
+	Write(e *LedgerEntry) error
+	Close() error
+}
+
+// NewLedgerWriter 创建指定布局的 CSV 写入器；loc 只用于 generic 布局，税务工具布局固定使用 UTC
+func NewLedgerWriter(layout, wallet string, w io.Writer, loc *time.Location) (LedgerWriter, error) {
+	lw := &ledgerWriter{w: csv.NewWriter(w), layout: layout, wallet: strings.ToLower(wallet), loc: loc}
+	var header []string
+	switch layout {
+	case LedgerGeneric:
+		header = []string{"date", "tx_hash", "block_number", "account", "asset", "asset_address", "token_id",
+			"debit", "credit", "balance", "counterparty", "description"}
+	case LedgerKoinly:
+		header = []string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+			"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash"}
+	case LedgerCoinTracker:
+		header = []string{"Date", "Received Quantity", "Received Currency", "Sent Quantity", "Sent Currency",
+			"Fee Amount", "Fee Currency", "Tag"}
+	default:
+		return nil, fmt.Errorf("unsupported ledger layout: %s", layout)
+	}
+	if err := lw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return lw, nil
+}
+
+type ledgerWriter struct {
+	w      *csv.Writer
+	layout string
+	wallet string
+	loc    *time.Location
+}
+
+func (lw *ledgerWriter) Write(e *LedgerEntry) error {
+	switch lw.layout {
+	case LedgerKoinly:
+		return lw.writeKoinly(e)
+	case LedgerCoinTracker:
+		return lw.writeCoinTracker(e)
+	}
+	return lw.writeGeneric(e)
+}
+
+func (lw *ledgerWriter) Close() error {
+	lw.w.Flush()
+	return lw.w.Error()
+}
+
+// writeGeneric 复式记账：转入借记钱包、贷记对手方，转出相反；手续费借记 expenses:gas、贷记钱包 ETH
+func (lw *ledgerWriter) writeGeneric(e *LedgerEntry) error {
+	date := e.Time.In(lw.loc).Format(time.RFC3339)
+	block := strconv.FormatInt(e.BlockNumber, 10)
+	walletAccount := "wallet:" + lw.wallet
+	external := "external:" + e.Counterparty
+	amount := FormatDecimal(e.Amount)
+	balance := FormatDecimal(e.Balance)
+	description := e.Direction + " " + e.Asset
+	if e.TokenID != "" {
+		description += " #" + e.TokenID
+	}
+	if e.Direction == EntryTrade {
+		description = "trade " + e.SentAsset + " for " + e.Asset
+	}
+
+	row := func(account, debit, credit, balance string) error {
+		return lw.w.Write([]string{date, e.TxHash, block, account, e.Asset, e.AssetAddress, e.TokenID,
+			debit, credit, balance, e.Counterparty, description})
+	}
+	// 兑换卖出的一端：贷记钱包、借记对手方（池）
+	sentRow := func(account, debit, credit, balance string) error {
+		return lw.w.Write([]string{date, e.TxHash, block, account, e.SentAsset, e.SentAssetAddress, "",
+			debit, credit, balance, e.Counterparty, description})
+	}
+
+	if e.Amount.Sign() != 0 {
+		var err error
+		switch e.Direction {
+		case EntryIn:
+			if err = row(walletAccount, amount, "", balance); err == nil {
+				err = row(external, "", amount, "")
+			}
+		case EntryOut:
+			if err = row(external, amount, "", ""); err == nil {
+				err = row(walletAccount, "", amount, balance)
+			}
+		case EntrySelf:
+			if err = row(walletAccount, amount, "", balance); err == nil {
+				err = row(walletAccount, "", amount, balance)
+			}
+		case EntryTrade:
+			sent := FormatDecimal(e.SentAmount)
+			if err = row(walletAccount, amount, "", balance); err == nil {
+				err = row(external, "", amount, "")
+			}
+			if err == nil {
+				err = sentRow(external, sent, "", "")
+			}
+			if err == nil {
+				err = sentRow(walletAccount, "", sent, FormatDecimal(e.SentBalance))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.Fee != nil {
+		fee := FormatDecimal(e.Fee)
+		feeRow := func(account, debit, credit, balance string) error {
+			return lw.w.Write([]string{date, e.TxHash, block, account, "ETH", "", "",
+				debit, credit, balance, "", "gas fee"})
+		}
+		if err := feeRow("expenses:gas", fee, "", ""); err != nil {
+			return err
+		}
+		return feeRow(walletAccount, "", fee, FormatDecimal(e.FeeBalance))
+	}
+	return nil
+}
+
+// writeKoinly 每笔交易一行，自转账只记手续费
+func (lw *ledgerWriter) writeKoinly(e *LedgerEntry) error {
+	sent, sentCur, recv, recvCur := lw.sides(e)
+	if sent == "" && recv == "" && e.Fee == nil {
+		return nil
+	}
+	fee, feeCur := feeColumns(e)
+	description := ""
+	if e.TokenID != "" {
+		description = e.Asset + " #" + e.TokenID
+	}
+	return lw.w.Write([]string{e.Time.UTC().Format("2006-01-02 15:04:05 UTC"),
+		sent, sentCur, recv, recvCur, fee, feeCur, "", "", "", description, e.TxHash})
+}
+
+// writeCoinTracker 每笔交易一行，自转账只记手续费
+func (lw *ledgerWriter) writeCoinTracker(e *LedgerEntry) error {
+	sent, sentCur, recv, recvCur := lw.sides(e)
+	if sent == "" && recv == "" && e.Fee == nil {
+		return nil
+	}
+	fee, feeCur := feeColumns(e)
+	return lw.w.Write([]string{e.Time.UTC().Format("01/02/2006 15:04:05"),
+		recv, recvCur, sent, sentCur, fee, feeCur, ""})
+}
+
+func (lw *ledgerWriter) sides(e *LedgerEntry) (sent, sentCur, recv, recvCur string) {
+	if e.Amount.Sign() == 0 {
+		return
+	}
+	switch e.Direction {
+	case EntryIn:
+		recv, recvCur = FormatDecimal(e.Amount), e.Asset
+	case EntryOut:
+		sent, sentCur = FormatDecimal(e.Amount), e.Asset
+	case EntryTrade:
+		sent, sentCur = FormatDecimal(e.SentAmount), e.SentAsset
+		recv, recvCur = FormatDecimal(e.Amount), e.Asset
+	}
+	return
+}
+
+func feeColumns(e *LedgerEntry) (string, string) {
+	if e.Fee == nil {
+		return "", ""
+	}
+	return FormatDecimal(e.Fee), "ETH"
+}
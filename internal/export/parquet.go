@@ -0,0 +1,36 @@
+package export
+
+import (
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Parquet 使用 parquet-go 写出：列由 Row 的 parquet 标签定义，所有列为 REQUIRED，
+// 整数列为 INT64，其余为 BYTE_ARRAY（STRING）；行组按 parquetRowGroupSize 行写出，内存占用与导出总量无关
+
+const parquetRowGroupSize = 10000
+
+type parquetWriter struct {
+	w   *parquet.GenericWriter[Row]
+	row [1]Row
+}
+
+func newParquetWriter(w io.Writer) *parquetWriter {
+	return &parquetWriter{
+		w: parquet.NewGenericWriter[Row](w,
+			parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+			parquet.CreatedBy("chainfeed", "", ""),
+		),
+	}
+}
+
+func (pw *parquetWriter) Write(row *Row) error {
+	pw.row[0] = *row
+	_, err := pw.w.Write(pw.row[:])
+	return err
+}
+
+func (pw *parquetWriter) Close() error {
+	return pw.w.Close()
+}
@@ -0,0 +1,193 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	jobTimeout   = 30 * time.Minute
+	claimLease   = jobTimeout + 5*time.Minute // 超过租约仍在执行的任务视为中断，重新领取
+	expireBatch  = 100
+
+	defaultDir         = "./data/exports"
+	defaultRetention   = 24 * time.Hour
+	defaultMaxSyncRows = 50000
+)
+
+// WithDefaults 补全未配置的导出参数
+func WithDefaults(cfg config.ExportConfig) config.ExportConfig {
+	if cfg.Dir == "" {
+		cfg.Dir = defaultDir
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = defaultRetention
+	}
+	if cfg.MaxSyncRows <= 0 {
+		cfg.MaxSyncRows = defaultMaxSyncRows
+	}
+	return cfg
+}
+
+// Store 导出任务的持久化，由 repository.ExportRepository 实现
+type Store interface {
+	ClaimNext(ctx context.Context, lease time.Duration) (*models.ExportJob, error)
+	Complete(ctx context.Context, id int64, filePath string, rows, size int64, expiresAt time.Time) error
+	Fail(ctx context.Context, id int64, reason string) error
+	ListExpired(ctx context.Context, limit int) ([]models.ExportJob, error)
+	MarkExpired(ctx context.Context, id int64) error
+}
+
+// Worker 执行异步导出任务并清理过期文件
+type Worker struct {
+	store  Store
+	source Source
+	cfg    config.ExportConfig
+	logger *zap.Logger
+}
+
+func NewWorker(store Store, source Source, cfg config.ExportConfig, logger *zap.Logger) *Worker {
+	return &Worker{store: store, source: source, cfg: WithDefaults(cfg), logger: logger}
+}
+
+// FileName 导出文件的下载文件名
+func FileName(job *models.ExportJob) string {
+	return fmt.Sprintf("chainfeed-feed-%d-%s.%s", job.ID, job.CreatedAt.UTC().Format("20060102"), job.Format)
+}
+
+// Run 轮询待处理的任务
+func (w *Worker) Run(ctx context.Context) {
+	if err := os.MkdirAll(w.cfg.Dir, 0o750); err != nil {
+		w.logger.Error("Failed to create export directory", zap.String("dir", w.cfg.Dir), zap.Error(err))
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.ProcessPending(ctx)
+		w.RemoveExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending 依次处理待执行的任务，返回处理的数量
+func (w *Worker) ProcessPending(ctx context.Context) int {
+	processed := 0
+	for ctx.Err() == nil {
+		job, err := w.store.ClaimNext(ctx, claimLease)
+		if err != nil {
+			w.logger.Error("Failed to claim export job", zap.Error(err))
+			return processed
+		}
+		if job == nil {
+			return processed
+		}
+		w.process(ctx, job)
+		processed++
+	}
+	return processed
+}
+
+func (w *Worker) process(ctx context.Context, job *models.ExportJob) {
+	logger := w.logger.With(zap.Int64("export_job_id", job.ID), zap.Int64("user_id", job.UserID))
+
+	path := filepath.Join(w.cfg.Dir, fmt.Sprintf("%d.%s", job.ID, job.Format))
+	rows, size, err := w.writeFile(ctx, job, path)
+	if err != nil {
+		logger.Warn("Export job failed", zap.Error(err))
+		if err := w.store.Fail(ctx, job.ID, err.Error()); err != nil {
+			logger.Error("Failed to update export job", zap.Error(err))
+		}
+		return
+	}
+
+	err = w.store.Complete(ctx, job.ID, path, rows, size, time.Now().Add(w.cfg.Retention))
+	if err != nil {
+		// 任务在执行期间被删除
+		_ = os.Remove(path)
+		if !errors.Is(err, repository.ErrNotFound) {
+			logger.Error("Failed to update export job", zap.Error(err))
+		}
+		return
+	}
+	logger.Info("Export job completed", zap.Int64("rows", rows), zap.Int64("bytes", size))
+}
+
+// writeFile 先写入临时文件，成功后再重命名，避免下载到不完整的文件
+func (w *Worker) writeFile(ctx context.Context, job *models.ExportJob, path string) (int64, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+
+	loc, err := LoadLocation(job.Timezone)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	out, err := NewWriter(job.Format, f)
+	if err != nil {
+		return 0, 0, err
+	}
+	rows, err := Write(ctx, w.source, job.UserID, &job.Filter, loc, out)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, 0, fmt.Errorf("failed to move export file: %w", err)
+	}
+	return rows, info.Size(), nil
+}
+
+// RemoveExpired 删除过期的导出文件
+func (w *Worker) RemoveExpired(ctx context.Context) {
+	jobs, err := w.store.ListExpired(ctx, expireBatch)
+	if err != nil {
+		w.logger.Error("Failed to list expired export jobs", zap.Error(err))
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			w.logger.Warn("Failed to remove export file", zap.String("path", job.FilePath), zap.Error(err))
+			continue
+		}
+		if err := w.store.MarkExpired(ctx, job.ID); err != nil {
+			w.logger.Error("Failed to update export job", zap.Int64("export_job_id", job.ID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package feedfilter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+const (
+	maxListSize     = 100 // 列表类条件的最大数量
+	maxSymbolLength = 20
+)
+
+var validTxTypes = map[string]bool{
+	"ETH":      true,
+	"ERC20":    true,
+	"ERC721":   true,
+	"SWAP":     true,
+	"APPROVAL": true,
+}
+
+// Parse 从查询参数解析过滤条件，base 通常为过滤预设；出现的参数覆盖 base 中的同名条件
+// 列表参数支持逗号分隔或重复传参，如 tags=cex,hot 或 tags=cex&tags=hot
+func Parse(values url.Values, base *models.FeedFilter) (*models.FeedFilter, error) {
+	f := &models.FeedFilter{}
+	if base != nil {
+		*f = *base
+	}
+
+	if v, ok := list(values, "watched_address_ids"); ok {
+		f.WatchedAddressIDs = make([]int64, 0, len(v))
+		for _, s := range v {
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid watched_address_ids: %s", s)
+			}
+			f.WatchedAddressIDs = append(f.WatchedAddressIDs, id)
+		}
+	}
+	if v, ok := list(values, "tags"); ok {
+		f.Tags = v
+	}
+	if v, ok := list(values, "tx_types"); ok {
+		f.TxTypes = v
+	}
+	if v, ok := list(values, "tokens"); ok {
+		f.Tokens = v
+	}
+	if v, ok := list(values, "counterparties"); ok {
+		f.Counterparties = v
+	}
+	if values.Has("direction") {
+		f.Direction = values.Get("direction")
+	}
+	if values.Has("min_amount") {
+		f.MinAmount = optional(values.Get("min_amount"))
+	}
+	if values.Has("max_amount") {
+		f.MaxAmount = optional(values.Get("max_amount"))
+	}
+	if values.Has("min_usd_value") {
+		f.MinUSDValue = optional(values.Get("min_usd_value"))
+	}
+	if values.Has("max_usd_value") {
+		f.MaxUSDValue = optional(values.Get("max_usd_value"))
+	}
+
+	var err error
+	if f.Unread, err = parseBool(values, "unread", f.Unread); err != nil {
+		return nil, err
+	}
+	if f.Starred, err = parseBool(values, "starred", f.Starred); err != nil {
+		return nil, err
+	}
+	if f.IncludeSpam, err = parseBool(values, "include_spam", f.IncludeSpam); err != nil {
+		return nil, err
+	}
+	if f.ExcludeReverted, err = parseBool(values, "exclude_reverted", f.ExcludeReverted); err != nil {
+		return nil, err
+	}
+	if f.FromBlock, err = parseBlock(values, "from_block", f.FromBlock); err != nil {
+		return nil, err
+	}
+	if f.ToBlock, err = parseBlock(values, "to_block", f.ToBlock); err != nil {
+		return nil, err
+	}
+	if f.FromTime, err = parseTime(values, "from_time", f.FromTime); err != nil {
+		return nil, err
+	}
+	if f.ToTime, err = parseTime(values, "to_time", f.ToTime); err != nil {
+		return nil, err
+	}
+
+	if err := Normalize(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func list(values url.Values, key string) ([]string, bool) {
+	raw, ok := values[key]
+	if !ok {
+		return nil, false
+	}
+	var items []string
+	for _, v := range raw {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+	}
+	return items, true
+}
+
+func optional(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
+
+func parseBool(values url.Values, key string, current bool) (bool, error) {
+	if !values.Has(key) {
+		return current, nil
+	}
+	s := strings.TrimSpace(values.Get(key))
+	if s == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", key, s)
+	}
+	return b, nil
+}
+
+func parseBlock(values url.Values, key string, current *int64) (*int64, error) {
+	if !values.Has(key) {
+		return current, nil
+	}
+	s := strings.TrimSpace(values.Get(key))
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", key, s)
+	}
+	return &n, nil
+}
+
+func parseTime(values url.Values, key string, current *time.Time) (*time.Time, error) {
+	if !values.Has(key) {
+		return current, nil
+	}
+	s := strings.TrimSpace(values.Get(key))
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected RFC3339: %s", key, s)
+	}
+	return &t, nil
+}
+
+// Normalize 校验并规范化过滤条件：地址转小写、交易类型与代币符号转大写、金额与美元价值去除多余精度、时间转为 UTC
+func Normalize(f *models.FeedFilter) error {
+	switch f.Direction {
+	case "", models.DirectionIn, models.DirectionOut:
+	default:
+		return errors.New("direction must be in or out")
+	}
+
+	if len(f.WatchedAddressIDs) > maxListSize || len(f.Tags) > maxListSize ||
+		len(f.TxTypes) > maxListSize || len(f.Tokens) > maxListSize {
+		return fmt.Errorf("list filters allow at most %d values", maxListSize)
+	}
+
+	for i, t := range f.TxTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if !validTxTypes[t] {
+			return fmt.Errorf("unsupported tx type: %s", t)
+		}
+		f.TxTypes[i] = t
+	}
+
+	for i, tag := range f.Tags {
+		f.Tags[i] = strings.TrimSpace(tag)
+	}
+
+	for i, token := range f.Tokens {
+		token = strings.TrimSpace(token)
+		switch {
+		case common.IsHexAddress(token):
+			f.Tokens[i] = strings.ToLower(token)
+		case token != "" && len(token) <= maxSymbolLength && !strings.HasPrefix(token, "0x"):
+			f.Tokens[i] = strings.ToUpper(token)
+		default:
+			return fmt.Errorf("invalid token: %s", token)
+		}
+	}
+
+	if len(f.Counterparties) > maxListSize {
+		return fmt.Errorf("counterparties allows at most %d addresses", maxListSize)
+	}
+	for i, addr := range f.Counterparties {
+		addr = strings.TrimSpace(addr)
+		if !common.IsHexAddress(addr) {
+			return fmt.Errorf("invalid address in counterparties: %s", addr)
+		}
+		f.Counterparties[i] = strings.ToLower(addr)
+	}
+
+	min, err := normalizeAmount(f.MinAmount, "min_amount")
+	if err != nil {
+		return err
+	}
+	max, err := normalizeAmount(f.MaxAmount, "max_amount")
+	if err != nil {
+		return err
+	}
+	if min != nil && max != nil && min.Cmp(max) > 0 {
+		return errors.New("min_amount must not exceed max_amount")
+	}
+
+	minUSD, err := normalizeAmount(f.MinUSDValue, "min_usd_value")
+	if err != nil {
+		return err
+	}
+	maxUSD, err := normalizeAmount(f.MaxUSDValue, "max_usd_value")
+	if err != nil {
+		return err
+	}
+	if minUSD != nil && maxUSD != nil && minUSD.Cmp(maxUSD) > 0 {
+		return errors.New("min_usd_value must not exceed max_usd_value")
+	}
+
+	if (f.FromBlock != nil && *f.FromBlock < 0) || (f.ToBlock != nil && *f.ToBlock < 0) {
+		return errors.New("block numbers must be non-negative")
+	}
+	if f.FromBlock != nil && f.ToBlock != nil && *f.FromBlock > *f.ToBlock {
+		return errors.New("from_block must not exceed to_block")
+	}
+
+	if f.FromTime != nil {
+		t := f.FromTime.UTC()
+		f.FromTime = &t
+	}
+	if f.ToTime != nil {
+		t := f.ToTime.UTC()
+		f.ToTime = &t
+	}
+	if f.FromTime != nil && f.ToTime != nil && !f.FromTime.Before(*f.ToTime) {
+		return errors.New("from_time must be before to_time")
+	}
+
+	return nil
+}
+
+func normalizeAmount(amount *string, field string) (*big.Rat, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(*amount))
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number", field)
+	}
+	s := strings.TrimRight(strings.TrimRight(r.FloatString(18), "0"), ".")
+	*amount = s
+	return r, nil
+}
+
+// IsEmpty 判断是否未设置任何条件
+func IsEmpty(f *models.FeedFilter) bool {
+	return f == nil || (len(f.WatchedAddressIDs) == 0 && len(f.Tags) == 0 && len(f.TxTypes) == 0 &&
+		len(f.Tokens) == 0 && f.Direction == "" && f.MinAmount == nil && f.MaxAmount == nil &&
+		f.MinUSDValue == nil && f.MaxUSDValue == nil &&
+		f.FromBlock == nil && f.ToBlock == nil && f.FromTime == nil && f.ToTime == nil &&
+		len(f.Counterparties) == 0 && !f.Unread && !f.Starred && !f.ExcludeReverted)
+}
+
+// SplitTokens 将代币条件拆分为合约地址与符号
+func SplitTokens(tokens []string) (addresses, symbols []string) {
+	addresses, symbols = []string{}, []string{}
+	for _, t := range tokens {
+		if common.IsHexAddress(t) {
+			addresses = append(addresses, strings.ToLower(t))
+		} else {
+			symbols = append(symbols, strings.ToUpper(t))
+		}
+	}
+	return addresses, symbols
+}
+
+// ToWei 将规范化后的人类可读金额转换为放大 1e18 的整数，与 transactions.value 对齐
+func ToWei(amount string) string {
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return "0"
+	}
+	units.ToScaled(r)
+	return new(big.Int).Quo(r.Num(), r.Denom()).String()
+}
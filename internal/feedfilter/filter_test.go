@@ -0,0 +1,182 @@
+package feedfilter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/websocket"
+)
+
+const (
+	watched = "0x1111111111111111111111111111111111111111"
+	other   = "0x2222222222222222222222222222222222222222"
+	usdc    = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParse(t *testing.T) {
+	minAmount := "10"
+	base := &models.FeedFilter{TxTypes: []string{"ETH"}, MinAmount: &minAmount, Direction: models.DirectionIn}
+
+	values, err := url.ParseQuery("tx_types=erc20,eth&tokens=usdc&tokens=" + usdc +
+		"&direction=&from_block=100&to_time=2026-05-01T08:00:00%2B08:00&watched_address_ids=3,4")
+	require.NoError(t, err)
+
+	f, err := Parse(values, base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERC20", "ETH"}, f.TxTypes)
+	assert.Equal(t, []string{"USDC", usdc}, f.Tokens)
+	assert.Equal(t, []int64{3, 4}, f.WatchedAddressIDs)
+	assert.Equal(t, "", f.Direction, "empty parameter clears the preset condition")
+	assert.Equal(t, "10", *f.MinAmount, "absent parameter keeps the preset condition")
+	assert.Equal(t, int64(100), *f.FromBlock)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), *f.ToTime)
+
+	values, err = url.ParseQuery("unread=true&starred=&include_spam=1")
+	require.NoError(t, err)
+	f, err = Parse(values, &models.FeedFilter{Starred: true})
+	require.NoError(t, err)
+	assert.True(t, f.Unread)
+	assert.False(t, f.Starred)
+	assert.True(t, f.IncludeSpam)
+
+	for _, query := range []string{
+		"direction=sideways",
+		"tx_types=ERC1155",
+		"tokens=0x1234",
+		"counterparties=bob",
+		"min_amount=-1",
+		"min_amount=5&max_amount=1",
+		"from_block=10&to_block=5",
+		"from_time=yesterday",
+		"watched_address_ids=abc",
+		"unread=maybe",
+	} {
+		values, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		_, err = Parse(values, nil)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestToWei(t *testing.T) {
+	assert.Equal(t, "2500000000000000000000", ToWei("2500"))
+	assert.Equal(t, "1", ToWei("0.000000000000000001"))
+}
+
+func TestMatch(t *testing.T) {
+	wa := &models.WatchedAddress{ID: 7, Kind: models.WatchKindWallet, Address: watched, Tags: []string{"cex", "hot"}}
+	tx := &models.Transaction{
+		FromAddress:    other,
+		ToAddress:      watched,
+		Value:          "2500000000000000000000", // 2500
+		TxType:         "ERC20",
+		TokenAddress:   usdc,
+		TokenSymbol:    "USDC",
+		USDValue:       strPtr("2499.75"),
+		BlockNumber:    19000000,
+		BlockTimestamp: time.Date(2024, 1, 1, 14, 30, 0, 0, time.UTC),
+	}
+	from := time.Date(2024, 1, 1, 14, 30, 0, 0, time.UTC)
+	until := from
+	block := int64(19000001)
+
+	tests := []struct {
+		name   string
+		filter models.FeedFilter
+		want   bool
+	}{
+		{"empty filter", models.FeedFilter{}, true},
+		{"watched address", models.FeedFilter{WatchedAddressIDs: []int64{8}}, false},
+		{"tags", models.FeedFilter{Tags: []string{"cex"}}, true},
+		{"tags all required", models.FeedFilter{Tags: []string{"cex", "cold"}}, false},
+		{"token symbol", models.FeedFilter{Tokens: []string{"USDC"}}, true},
+		{"token address", models.FeedFilter{Tokens: []string{"DAI", usdc}}, true},
+		{"direction out", models.FeedFilter{Direction: models.DirectionOut}, false},
+		{"max amount", models.FeedFilter{MaxAmount: strPtr("2500")}, true},
+		{"min amount above", models.FeedFilter{MinAmount: strPtr("2500.01")}, false},
+		{"usd value range", models.FeedFilter{MinUSDValue: strPtr("2000"), MaxUSDValue: strPtr("2499.75")}, true},
+		{"min usd value above", models.FeedFilter{MinUSDValue: strPtr("2500")}, false},
+		{"from block", models.FeedFilter{FromBlock: &block}, false},
+		{"from time inclusive", models.FeedFilter{FromTime: &from}, true},
+		{"to time exclusive", models.FeedFilter{ToTime: &until}, false},
+		{"counterparty", models.FeedFilter{Counterparties: []string{other}}, true},
+		{"counterparty self", models.FeedFilter{Counterparties: []string{watched}}, false},
+		{"exclude reverted", models.FeedFilter{ExcludeReverted: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(&tt.filter, tx, wa))
+		})
+	}
+}
+
+func TestMatchSpam(t *testing.T) {
+	wa := &models.WatchedAddress{ID: 7, Kind: models.WatchKindWallet, Address: watched}
+	tx := &models.Transaction{FromAddress: other, ToAddress: watched, Value: "0", TxType: "ERC20",
+		TokenAddress: usdc, SpamReason: models.SpamReasonZeroValue}
+
+	assert.False(t, Match(nil, tx, wa))
+	assert.False(t, Match(&models.FeedFilter{TxTypes: []string{"ERC20"}}, tx, wa))
+	assert.True(t, Match(&models.FeedFilter{IncludeSpam: true}, tx, wa))
+}
+
+func TestMatchReverted(t *testing.T) {
+	wa := &models.WatchedAddress{ID: 7, Kind: models.WatchKindWallet, Address: watched}
+	tx := &models.Transaction{FromAddress: watched, ToAddress: other, Value: "1000000000000000000", TxType: "ETH",
+		Status: models.TxStatusReverted}
+
+	assert.True(t, Match(nil, tx, wa))
+	assert.False(t, Match(&models.FeedFilter{ExcludeReverted: true}, tx, wa))
+	tx.Status = ""
+	assert.True(t, Match(&models.FeedFilter{ExcludeReverted: true}, tx, wa), "transactions without a receipt are kept")
+}
+
+func TestMessageFilter(t *testing.T) {
+	assert.Nil(t, MessageFilter(&models.FeedFilter{}))
+
+	filter := MessageFilter(&models.FeedFilter{TxTypes: []string{"ETH"}})
+	require.NotNil(t, filter)
+
+	// 经 Redis Stream 转发后的消息 Payload 为 map
+	msg := &websocket.Message{
+		Type: "new_transaction",
+		Payload: map[string]interface{}{
+			"transaction":     map[string]interface{}{"tx_type": "ERC20", "value": "1"},
+			"watched_address": map[string]interface{}{"id": 7, "address": watched},
+		},
+	}
+	assert.False(t, filter(msg))
+
+	msg.Payload.(map[string]interface{})["transaction"].(map[string]interface{})["tx_type"] = "ETH"
+	assert.True(t, filter(msg))
+
+	assert.True(t, filter(&websocket.Message{Type: "alert"}))
+
+	// 同时命中多个监控地址时，任一地址满足条件即推送
+	filter = MessageFilter(&models.FeedFilter{Direction: models.DirectionOut})
+	msg = &websocket.Message{
+		Type: "new_transaction",
+		Payload: map[string]interface{}{
+			"transaction":     map[string]interface{}{"from_address": other, "to_address": watched, "value": "1"},
+			"watched_address": map[string]interface{}{"id": 7, "kind": "wallet", "address": watched},
+			"watched_addresses": []interface{}{
+				map[string]interface{}{"id": 7, "kind": "wallet", "address": watched},
+				map[string]interface{}{"id": 8, "kind": "wallet", "address": other},
+			},
+		},
+	}
+	assert.True(t, filter(msg))
+
+	// 新条目不会带星标
+	filter = MessageFilter(&models.FeedFilter{Starred: true})
+	assert.False(t, filter(msg))
+	assert.True(t, filter(&websocket.Message{Type: "feed_read"}))
+}
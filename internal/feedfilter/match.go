@@ -0,0 +1,185 @@
+package feedfilter
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/websocket"
+)
+
+// Match 判断交易经由监控记录 wa 命中时是否满足过滤条件；与 FeedRepository 的 SQL 条件保持一致
+// 已读与星标属于条目状态，不在此判断
+func Match(f *models.FeedFilter, tx *models.Transaction, wa *models.WatchedAddress) bool {
+	// 垃圾交易默认排除；用户隐藏的代币只能在查询时判断
+	if tx.SpamReason != "" && (f == nil || !f.IncludeSpam) {
+		return false
+	}
+	if IsEmpty(f) {
+		return true
+	}
+
+	if len(f.WatchedAddressIDs) > 0 && !containsID(f.WatchedAddressIDs, wa.ID) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !containsTag(wa.Tags, tag) {
+			return false
+		}
+	}
+
+	// 未获取回执的交易状态为空，不视为失败
+	if f.ExcludeReverted && tx.Status == models.TxStatusReverted {
+		return false
+	}
+
+	if len(f.TxTypes) > 0 && !containsFold(f.TxTypes, tx.TxType) {
+		return false
+	}
+
+	if len(f.Tokens) > 0 && !containsFold(f.Tokens, tx.TokenAddress) && !containsFold(f.Tokens, tx.TokenSymbol) {
+		return false
+	}
+
+	direction, counterparty := tx.Direction(wa)
+	if f.Direction != "" && f.Direction != direction {
+		return false
+	}
+
+	if len(f.Counterparties) > 0 {
+		if wa.Kind == models.WatchKindToken {
+			// 代币合约监控没有固定的一方，任一方命中即可
+			if !containsFold(f.Counterparties, tx.FromAddress) && !containsFold(f.Counterparties, tx.ToAddress) {
+				return false
+			}
+		} else if !containsFold(f.Counterparties, counterparty) {
+			return false
+		}
+	}
+
+	if f.MinAmount != nil || f.MaxAmount != nil {
+		value, ok := new(big.Int).SetString(tx.Value, 10)
+		if !ok {
+			return false
+		}
+		if f.MinAmount != nil {
+			if min, ok := new(big.Int).SetString(ToWei(*f.MinAmount), 10); ok && value.Cmp(min) < 0 {
+				return false
+			}
+		}
+		if f.MaxAmount != nil {
+			if max, ok := new(big.Int).SetString(ToWei(*f.MaxAmount), 10); ok && value.Cmp(max) > 0 {
+				return false
+			}
+		}
+	}
+
+	if (f.MinUSDValue != nil || f.MaxUSDValue != nil) && !inRange(tx.USDValue, f.MinUSDValue, f.MaxUSDValue) {
+		return false
+	}
+
+	if f.FromBlock != nil && tx.BlockNumber < *f.FromBlock {
+		return false
+	}
+	if f.ToBlock != nil && tx.BlockNumber > *f.ToBlock {
+		return false
+	}
+	if f.FromTime != nil && tx.BlockTimestamp.Before(*f.FromTime) {
+		return false
+	}
+	if f.ToTime != nil && !tx.BlockTimestamp.Before(*f.ToTime) {
+		return false
+	}
+
+	return true
+}
+
+// MessageFilter 返回 WebSocket 客户端的消息过滤函数：new_transaction 按条件过滤，其他事件照常推送
+func MessageFilter(f *models.FeedFilter) func(*websocket.Message) bool {
+	if IsEmpty(f) {
+		return nil
+	}
+	return func(msg *websocket.Message) bool {
+		if msg.Type != "new_transaction" {
+			return true
+		}
+		// 新条目均为未读且未加星标
+		if f.Starred {
+			return false
+		}
+		// 消息经 Redis Stream 转发后 Payload 为 map，重新解码为结构体
+		raw, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return true
+		}
+		var payload struct {
+			Transaction      models.Transaction      `json:"transaction"`
+			WatchedAddress   models.WatchedAddress   `json:"watched_address"`
+			WatchedAddresses []models.WatchedAddress `json:"watched_addresses"`
+		}
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return true
+		}
+		if len(payload.WatchedAddresses) == 0 {
+			payload.WatchedAddresses = []models.WatchedAddress{payload.WatchedAddress}
+		}
+		// 与 feed 查询一致：任一命中的监控地址满足条件即可
+		for i := range payload.WatchedAddresses {
+			if Match(f, &payload.Transaction, &payload.WatchedAddresses[i]) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// inRange 判断十进制数是否在 [min, max] 内，value 为 nil（无法计价）时不满足
+func inRange(value, min, max *string) bool {
+	if value == nil {
+		return false
+	}
+	v, ok := new(big.Rat).SetString(*value)
+	if !ok {
+		return false
+	}
+	if min != nil {
+		if m, ok := new(big.Rat).SetString(*min); ok && v.Cmp(m) < 0 {
+			return false
+		}
+	}
+	if max != nil {
+		if m, ok := new(big.Rat).SetString(*max); ok && v.Cmp(m) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, v := range tags {
+		if v == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(list []int64, id int64) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,447 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxImportRows      = 1000
+	maxImportBodySize  = 2 << 20 // 2MB
+	importConcurrency  = 8
+	importResolveLimit = 10 * time.Second
+)
+
+// 导入结果状态
+const (
+	ImportStatusCreated   = "created"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusInvalid   = "invalid"
+	ImportStatusError     = "error"
+)
+
+// ImportRow 导入的一行数据，Address 可以是以太坊地址或 ENS 域名
+type ImportRow struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Notes   string   `json:"notes"`
+	Tags    []string `json:"tags"`
+}
+
+type ImportRowResult struct {
+	Row     int    `json:"row"`
+	Input   string `json:"input"`
+	Address string `json:"address,omitempty"`
+	ENSName string `json:"ens_name,omitempty"`
+	ID      int64  `json:"id,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ImportResponse struct {
+	Total      int               `json:"total"`
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Failed     int               `json:"failed"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+// Import 批量导入监控地址
+// @Summary      批量导入监控地址
+// @Description  支持 CSV（表头 address,label,notes,tags，tags 以 ; 分隔）、JSON 数组或每行一个地址/ENS 的纯文本。
+// @Description  地址与 ENS 并行解析，逐行返回结果，已存在的地址标记为 duplicate，历史交易排队限流回填。
+// @Tags         监控地址
+// @Accept       json,text/csv,text/plain,multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format query string false "格式 csv|json|text，默认按 Content-Type 推断"
+// @Param        file formData file false "上传文件（multipart）"
+// @Success      200 {object} ImportResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /addresses/import [post]
+func (h *WatchedAddressHandler) Import(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	body, format, err := readImportBody(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	rows, err := parseImportRows(body, format)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		response.BadRequest(c, "no rows to import")
+		return
+	}
+	if len(rows) > maxImportRows {
+		response.BadRequest(c, fmt.Sprintf("at most %d rows per import", maxImportRows))
+		return
+	}
+
+	results := h.resolveImportRows(context.Background(), rows)
+
+	ctx := context.Background()
+	seen := make(map[string]int, len(rows))
+	resp := ImportResponse{Total: len(rows), Rows: results}
+	for i := range results {
+		res := &results[i]
+		if res.Status != "" {
+			resp.Failed++
+			continue
+		}
+
+		// 文件内去重
+		if first, dup := seen[strings.ToLower(res.Address)]; dup {
+			res.Status = ImportStatusDuplicate
+			res.Error = fmt.Sprintf("duplicate of row %d", first)
+			resp.Duplicates++
+			continue
+		}
+		seen[strings.ToLower(res.Address)] = res.Row
+
+		exists, err := h.repo.Exists(ctx, userID, res.Address)
+		if err != nil {
+			h.logger.Error("Failed to check address existence", zap.Error(err))
+			res.Status = ImportStatusError
+			res.Error = "internal server error"
+			resp.Failed++
+			continue
+		}
+		if exists {
+			res.Status = ImportStatusDuplicate
+			res.Error = "address already watched"
+			resp.Duplicates++
+			continue
+		}
+
+		row := rows[i]
+		tags, _ := normalizeTags(row.Tags)
+		watchedAddr := &models.WatchedAddress{
+			UserID:  userID,
+			Address: res.Address,
+			Label:   strings.TrimSpace(row.Label),
+			ENSName: res.ENSName,
+			Notes:   row.Notes,
+			Tags:    tags,
+		}
+		if err := h.repo.Create(ctx, watchedAddr); err != nil {
+			h.logger.Error("Failed to create watched address", zap.Error(err), zap.String("address", res.Address))
+			res.Status = ImportStatusError
+			res.Error = "internal server error"
+			resp.Failed++
+			continue
+		}
+
+		res.ID = watchedAddr.ID
+		res.Status = ImportStatusCreated
+		resp.Created++
+		h.backfill.Enqueue([]int64{userID}, watchedAddr)
+	}
+
+	h.logger.Info("Watched addresses imported",
+		zap.Int64("user_id", userID),
+		zap.Int("total", resp.Total),
+		zap.Int("created", resp.Created),
+		zap.Int("duplicates", resp.Duplicates),
+		zap.Int("failed", resp.Failed))
+
+	response.Success(c, resp)
+}
+
+// resolveImportRows 有限并发地校验并解析每一行，失败的行会带上 Status
+func (h *WatchedAddressHandler) resolveImportRows(ctx context.Context, rows []ImportRow) []ImportRowResult {
+	results := make([]ImportRowResult, len(rows))
+	sem := make(chan struct{}, importConcurrency)
+	var wg sync.WaitGroup
+
+	for i, row := range rows {
+		results[i] = ImportRowResult{Row: i + 1, Input: row.Address}
+
+		if err := validateLabelAndNotes(row.Label, row.Notes); err != nil {
+			results[i].Status = ImportStatusInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+		if _, err := normalizeTags(row.Tags); err != nil {
+			results[i].Status = ImportStatusInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(res *ImportRowResult, input string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resolveCtx, cancel := context.WithTimeout(ctx, importResolveLimit)
+			defer cancel()
+
+			address, ensName, err := h.resolveAddress(resolveCtx, input)
+			if err != nil {
+				res.Status = ImportStatusInvalid
+				res.Error = err.Error()
+				return
+			}
+			res.Address = address
+			res.ENSName = ensName
+		}(&results[i], strings.TrimSpace(row.Address))
+	}
+
+	wg.Wait()
+	return results
+}
+
+// readImportBody 读取请求体（或 multipart 文件）并推断格式
+func readImportBody(c *gin.Context) ([]byte, string, error) {
+	format := strings.ToLower(c.Query("format"))
+	contentType := c.ContentType()
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(contentType, "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("missing file field")
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", errors.New("failed to open uploaded file")
+		}
+		defer file.Close()
+		reader = file
+
+		if format == "" {
+			name := strings.ToLower(fileHeader.Filename)
+			switch {
+			case strings.HasSuffix(name, ".json"):
+				format = "json"
+			case strings.HasSuffix(name, ".csv"):
+				format = "csv"
+			default:
+				format = "text"
+			}
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxImportBodySize+1))
+	if err != nil {
+		return nil, "", errors.New("failed to read request body")
+	}
+	if len(body) > maxImportBodySize {
+		return nil, "", fmt.Errorf("import body exceeds %d bytes", maxImportBodySize)
+	}
+
+	if format == "" {
+		switch contentType {
+		case "application/json":
+			format = "json"
+		case "text/csv":
+			format = "csv"
+		default:
+			format = "text"
+		}
+	}
+
+	return body, format, nil
+}
+
+// parseImportRows 解析 CSV / JSON / 纯文本导入内容
+func parseImportRows(body []byte, format string) ([]ImportRow, error) {
+	switch format {
+	case "json":
+		return parseImportJSON(body)
+	case "csv", "text":
+		return parseImportCSV(body)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseImportJSON(body []byte) ([]ImportRow, error) {
+	body = bytes.TrimSpace(body)
+
+	// 支持 [{...}]、["0x..", "vitalik.eth"] 以及 {"addresses": [...]}
+	if len(body) > 0 && body[0] == '{' {
+		var wrapper struct {
+			Addresses json.RawMessage `json:"addresses"`
+		}
+		if err := json.Unmarshal(body, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		body = wrapper.Addresses
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("invalid json: expected an array of addresses")
+	}
+
+	rows := make([]ImportRow, 0, len(raw))
+	for i, item := range raw {
+		var row ImportRow
+		var plain string
+		if err := json.Unmarshal(item, &plain); err == nil {
+			row.Address = plain
+		} else if err := json.Unmarshal(item, &row); err != nil {
+			return nil, fmt.Errorf("invalid json at index %d: %w", i, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseImportCSV(body []byte) ([]ImportRow, error) {
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	// 默认列顺序 address,label,notes,tags；首行包含 address 列名时按表头映射
+	columns := map[string]int{"address": 0, "label": 1, "notes": 2, "tags": 3}
+	if len(records) > 0 {
+		header := make(map[string]int)
+		for i, name := range records[0] {
+			header[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := header["address"]; ok {
+			columns = map[string]int{"address": -1, "label": -1, "notes": -1, "tags": -1}
+			for name := range columns {
+				if idx, ok := header[name]; ok {
+					columns[name] = idx
+				}
+			}
+			records = records[1:]
+		}
+	}
+
+	field := func(record []string, name string) string {
+		idx := columns[name]
+		if idx < 0 || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	rows := make([]ImportRow, 0, len(records))
+	for _, record := range records {
+		address := field(record, "address")
+		if address == "" {
+			continue
+		}
+
+		var tags []string
+		if raw := field(record, "tags"); raw != "" {
+			tags = strings.FieldsFunc(raw, func(r rune) bool { return r == ';' || r == '|' })
+		}
+
+		rows = append(rows, ImportRow{
+			Address: address,
+			Label:   field(record, "label"),
+			Notes:   field(record, "notes"),
+			Tags:    tags,
+		})
+	}
+	return rows, nil
+}
+
+// Export 导出监控地址
+// @Summary      导出监控地址
+// @Description  以 CSV 或 JSON 导出当前用户的监控地址，导出的 CSV 可直接用于导入
+// @Tags         监控地址
+// @Produce      json,text/csv
+// @Security     BearerAuth
+// @Param        format query string false "导出格式 csv|json" default(csv)
+// @Param        tag query []string false "标签过滤" collectionFormat(multi)
+// @Success      200 {file} file
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /addresses/export [get]
+func (h *WatchedAddressHandler) Export(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "json" {
+		response.BadRequest(c, "format must be csv or json")
+		return
+	}
+
+	tags, err := normalizeTags(c.QueryArray("tag"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	addresses, err := h.repo.GetByUserIDAndTags(context.Background(), userID, tags)
+	if err != nil {
+		h.logger.Error("Failed to get watched addresses", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	filename := fmt.Sprintf("chainfeed-watchlist-%s.%s", time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "json" {
+		rows := make([]ImportRow, len(addresses))
+		for i, wa := range addresses {
+			rows[i] = ImportRow{
+				Address: wa.Address,
+				Label:   wa.Label,
+				Notes:   wa.Notes,
+				Tags:    wa.Tags,
+			}
+		}
+		c.JSON(http.StatusOK, rows)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"address", "label", "notes", "tags", "ens_name", "created_at"})
+	for _, wa := range addresses {
+		_ = w.Write([]string{
+			wa.Address,
+			wa.Label,
+			wa.Notes,
+			strings.Join(wa.Tags, ";"),
+			wa.ENSName,
+			wa.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+}
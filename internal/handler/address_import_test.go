@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportRows(t *testing.T) {
+	t.Run("CSV with header", func(t *testing.T) {
+		body := []byte("label,address,tags\n" +
+			"Vitalik,0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045,whale;kol\n" +
+			"# comment line\n" +
+			",,\n" +
+			"Binance,binance.eth,\n")
+
+		rows, err := parseImportRows(body, "csv")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+
+		assert.Equal(t, "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", rows[0].Address)
+		assert.Equal(t, "Vitalik", rows[0].Label)
+		assert.Equal(t, []string{"whale", "kol"}, rows[0].Tags)
+		assert.Equal(t, "binance.eth", rows[1].Address)
+		assert.Empty(t, rows[1].Tags)
+	})
+
+	t.Run("Plain ENS list", func(t *testing.T) {
+		rows, err := parseImportRows([]byte("vitalik.eth\n\nnick.eth\n"), "text")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, "nick.eth", rows[1].Address)
+	})
+
+	t.Run("JSON objects and strings", func(t *testing.T) {
+		body := []byte(`{"addresses": [
+			{"address": "vitalik.eth", "label": "V", "tags": ["kol"]},
+			"0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+		]}`)
+
+		rows, err := parseImportRows(body, "json")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, "V", rows[0].Label)
+		assert.Equal(t, []string{"kol"}, rows[0].Tags)
+		assert.Equal(t, "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", rows[1].Address)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := parseImportRows([]byte(`{"addresses": 1}`), "json")
+		assert.Error(t, err)
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" Whale ", "whale", "", "cex:binance"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"whale", "cex:binance"}, tags)
+
+	_, err = normalizeTags([]string{"bad tag"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/ingest"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+	"github.com/bwmspring/chainfeed-go/internal/service"
+)
+
+const (
+	defaultProfileWindow     = "30d"
+	maxProfileCounterparties = 10
+	maxProfileAssets         = 20
+
+	profileBackfillKeyPrefix = "profile:backfill:"
+	profileBackfillCooldown  = time.Hour // 同一地址两次按需回填的最小间隔
+	profileBackfillTimeout   = 5 * time.Minute
+	profileChainTimeout      = 10 * time.Second
+	profileCooldownTimeout   = 5 * time.Second // 回填失败后清除冷却标记的超时，ctx 可能已因获取超时而失效
+)
+
+// profileWindows 可选的统计区间，all 不限时间
+var profileWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
+	"all": 0,
+}
+
+type AddressProfileHandler struct {
+	profileRepo     *repository.ProfileRepository
+	watchedAddrRepo *repository.WatchedAddressRepository
+	ensService      *service.ENSService
+	alchemyService  *service.AlchemyService
+	ethClient       *ethclient.Client
+	pipeline        *ingest.Pipeline
+	redis           *redis.Client
+	logger          *zap.Logger
+}
+
+func NewAddressProfileHandler(
+	profileRepo *repository.ProfileRepository,
+	watchedAddrRepo *repository.WatchedAddressRepository,
+	ensService *service.ENSService,
+	alchemyService *service.AlchemyService,
+	ethClient *ethclient.Client,
+	pipeline *ingest.Pipeline,
+	redis *redis.Client,
+	logger *zap.Logger,
+) *AddressProfileHandler {
+	return &AddressProfileHandler{
+		profileRepo:     profileRepo,
+		watchedAddrRepo: watchedAddrRepo,
+		ensService:      ensService,
+		alchemyService:  alchemyService,
+		ethClient:       ethClient,
+		pipeline:        pipeline,
+		redis:           redis,
+		logger:          logger,
+	}
+}
+
+type AddressProfileResponse struct {
+	Address        string                     `json:"address"`
+	ENSName        string                     `json:"ens_name,omitempty"`
+	IsContract     *bool                      `json:"is_contract"` // 未配置 RPC 或查询失败时为 null
+	WatchedAddress *models.WatchedAddress     `json:"watched_address,omitempty"`
+	Lifetime       repository.AddressActivity `json:"lifetime"`
+	Window         string                     `json:"window"`
+	Since          *time.Time                 `json:"since"` // window=all 时为 null
+	Activity       repository.AddressActivity `json:"activity"`
+	Counterparties []repository.Counterparty  `json:"counterparties"`
+	Assets         []repository.AssetFlow     `json:"assets"`
+	Backfill       *ProfileBackfill           `json:"backfill,omitempty"`
+}
+
+const (
+	ProfileBackfillPending = "pending" // 已在后台开始回填，稍后再次请求即包含回填的交易
+	ProfileBackfillSkipped = "skipped" // 冷却期内已回填过，本次未请求
+)
+
+type ProfileBackfill struct {
+	Status string `json:"status"`
+}
+
+// Get 获取地址画像
+// @Summary      获取地址画像
+// @Description  基于已存储的交易统计地址的首末活动时间、交易数、主要对手方与各资产的流入流出，附带 ENS 名称与是否为合约；地址需被监控或出现在当前用户的 feed 中。backfill=true 时在后台从 Alchemy 回填该地址最新的 ETH 转账（每个地址每小时一次）并返回 202，本次结果不包含回填的交易
+// @Tags         交易
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "以太坊地址"
+// @Param        window query string false "统计区间" Enums(24h, 7d, 30d, 90d, 1y, all) default(30d)
+// @Param        backfill query bool false "在后台回填历史交易"
+// @Success      200 {object} AddressProfileResponse
+// @Success      202 {object} AddressProfileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /addresses/{address}/profile [get]
+func (h *AddressProfileHandler) Get(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+	address = common.HexToAddress(address).Hex()
+
+	window := c.DefaultQuery("window", defaultProfileWindow)
+	since, err := profileSince(window, time.Now())
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	backfill := false
+	if raw := c.Query("backfill"); raw != "" {
+		if backfill, err = strconv.ParseBool(raw); err != nil {
+			response.BadRequest(c, "invalid backfill")
+			return
+		}
+	}
+	if backfill && (h.alchemyService == nil || h.pipeline == nil) {
+		response.BadRequest(c, "backfill not configured")
+		return
+	}
+
+	// 只允许查看用户监控的地址（个人或所在团队），或出现在其 feed 中的对手方
+	ctx := c.Request.Context()
+	watchedAddr, err := h.watchedAddrRepo.GetAccessibleByAddress(ctx, userID, address)
+	if err != nil {
+		h.logger.Error("Failed to find watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if watchedAddr == nil {
+		inFeed, err := h.profileRepo.InFeed(ctx, userID, address)
+		if err != nil {
+			h.logger.Error("Failed to check feed counterparty", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+		if !inFeed {
+			response.NotFound(c, "address not found in feed")
+			return
+		}
+	}
+
+	resp := AddressProfileResponse{
+		Address:        address,
+		WatchedAddress: watchedAddr,
+		Window:         window,
+		Since:          since,
+	}
+	lifetime, err := h.profileRepo.Activity(ctx, address, nil)
+	if err != nil {
+		h.logger.Error("Failed to get address profile", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	activity := lifetime
+	if since != nil {
+		if activity, err = h.profileRepo.Activity(ctx, address, since); err != nil {
+			h.logger.Error("Failed to get address profile", zap.String("address", address), zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+	}
+	counterparties, err := h.profileRepo.TopCounterparties(ctx, address, since, maxProfileCounterparties)
+	if err != nil {
+		h.logger.Error("Failed to get address profile", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	assets, err := h.profileRepo.TopAssets(ctx, address, since, maxProfileAssets)
+	if err != nil {
+		h.logger.Error("Failed to get address profile", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	resp.Lifetime = *lifetime
+	resp.Activity = *activity
+	resp.Counterparties = counterparties
+	resp.Assets = assets
+	if resp.Counterparties == nil {
+		resp.Counterparties = []repository.Counterparty{}
+	}
+	if resp.Assets == nil {
+		resp.Assets = []repository.AssetFlow{}
+	}
+
+	resp.ENSName, resp.IsContract = h.chainInfo(ctx, address)
+	if resp.ENSName == "" && watchedAddr != nil {
+		resp.ENSName = watchedAddr.ENSName
+	}
+
+	if backfill {
+		resp.Backfill = h.backfill(ctx, address)
+		if resp.Backfill.Status == ProfileBackfillPending {
+			response.Accepted(c, resp)
+			return
+		}
+	}
+	response.Success(c, resp)
+}
+
+// profileSince 将统计区间转换为起始时间，all 返回 nil
+func profileSince(window string, now time.Time) (*time.Time, error) {
+	d, ok := profileWindows[window]
+	if !ok {
+		return nil, errors.New("invalid window: must be one of 24h, 7d, 30d, 90d, 1y, all")
+	}
+	if d == 0 {
+		return nil, nil
+	}
+	since := now.Add(-d)
+	return &since, nil
+}
+
+// backfill 在后台从 Alchemy 获取地址最新的 ETH 转账，经与 Webhook 相同的入库流程补全并保存（按交易哈希合并），
+// 不创建 feed 条目；同一地址冷却期内只回填一次
+func (h *AddressProfileHandler) backfill(ctx context.Context, address string) *ProfileBackfill {
+	key := profileBackfillKeyPrefix + address
+	acquired, err := h.redis.SetNX(ctx, key, time.Now().Unix(), profileBackfillCooldown).Result()
+	if err != nil {
+		h.logger.Warn("Failed to check backfill cooldown", zap.String("address", address), zap.Error(err))
+	} else if !acquired {
+		return &ProfileBackfill{Status: ProfileBackfillSkipped}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), profileBackfillTimeout)
+		defer cancel()
+
+		transactions, err := h.alchemyService.GetAddressTransfers(ctx, address)
+		if err != nil {
+			h.logger.Error("Failed to fetch transactions from Alchemy", zap.String("address", address), zap.Error(err))
+			// 失败时允许立即重试；获取超时时 ctx 已失效，使用新的 context
+			delCtx, delCancel := context.WithTimeout(context.Background(), profileCooldownTimeout)
+			defer delCancel()
+			if err := h.redis.Del(delCtx, key).Err(); err != nil {
+				h.logger.Warn("Failed to clear backfill cooldown", zap.String("address", address), zap.Error(err))
+			}
+			return
+		}
+		stored := h.pipeline.Ingest(ctx, transactions)
+		h.logger.Info("Profile backfill completed",
+			zap.String("address", address),
+			zap.Int("fetched", len(transactions)),
+			zap.Int("stored", stored))
+	}()
+	return &ProfileBackfill{Status: ProfileBackfillPending}
+}
+
+// chainInfo 查询地址的 ENS 反向解析名称与是否为合约；未配置 RPC 或查询失败时对应结果为空
+func (h *AddressProfileHandler) chainInfo(ctx context.Context, address string) (string, *bool) {
+	ctx, cancel := context.WithTimeout(ctx, profileChainTimeout)
+	defer cancel()
+
+	var ensName string
+	if h.ensService != nil {
+		ensName, _ = h.ensService.ReverseResolve(ctx, address)
+	}
+
+	var isContract *bool
+	if h.ethClient != nil {
+		code, err := h.ethClient.CodeAt(ctx, common.HexToAddress(address), nil)
+		if err != nil {
+			h.logger.Warn("Failed to get contract code", zap.String("address", address), zap.Error(err))
+		} else {
+			// EIP-7702 委托的 EOA 也有代码（0xef0100 + 委托地址），不视为合约
+			contract := len(code) > 0 && !(len(code) == 23 && code[0] == 0xef && code[1] == 0x01 && code[2] == 0x00)
+			isContract = &contract
+		}
+	}
+	return ensName, isContract
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileSince(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	since, err := profileSince("7d", now)
+	require.NoError(t, err)
+	require.NotNil(t, since)
+	assert.Equal(t, time.Date(2026, 10, 11, 12, 0, 0, 0, time.UTC), *since)
+
+	since, err = profileSince("all", now)
+	require.NoError(t, err)
+	assert.Nil(t, since, "all has no lower bound")
+
+	_, err = profileSince("2w", now)
+	assert.Error(t, err)
+}
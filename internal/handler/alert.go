@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/alert"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxAlertRuleNameLength = 100
+	maxAlertRulesPerUser   = 50
+)
+
+type AlertHandler struct {
+	alertRepo *repository.AlertRepository
+	logger    *zap.Logger
+}
+
+func NewAlertHandler(alertRepo *repository.AlertRepository, logger *zap.Logger) *AlertHandler {
+	return &AlertHandler{
+		alertRepo: alertRepo,
+		logger:    logger,
+	}
+}
+
+type CreateAlertRuleRequest struct {
+	Name       string                 `json:"name" binding:"required"`
+	Severity   string                 `json:"severity"`
+	Enabled    *bool                  `json:"enabled"`
+	Conditions models.AlertConditions `json:"conditions"`
+}
+
+type UpdateAlertRuleRequest struct {
+	Name       *string                 `json:"name"`
+	Severity   *string                 `json:"severity"`
+	Enabled    *bool                   `json:"enabled"`
+	Conditions *models.AlertConditions `json:"conditions"`
+}
+
+func validateAlertRuleName(name string) error {
+	if name == "" || len([]rune(name)) > maxAlertRuleNameLength {
+		return errors.New("rule name must be 1-100 characters")
+	}
+	return nil
+}
+
+// ListRules 获取告警规则
+// @Summary      获取告警规则
+// @Description  获取当前用户的所有告警规则
+// @Tags         告警
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.AlertRule
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /alerts/rules [get]
+func (h *AlertHandler) ListRules(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	rules, err := h.alertRepo.ListRules(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list alert rules", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, rules)
+}
+
+// CreateRule 创建告警规则
+// @Summary      创建告警规则
+// @Description  创建告警规则，条件包括方向、交易类型、代币、金额区间、对手方与时间窗口，所有条件需同时满足
+// @Tags         告警
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateAlertRuleRequest true "规则信息"
+// @Success      200 {object} models.AlertRule
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /alerts/rules [post]
+func (h *AlertHandler) CreateRule(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	rule := &models.AlertRule{
+		UserID:     userID,
+		Name:       strings.TrimSpace(req.Name),
+		Severity:   req.Severity,
+		Enabled:    true,
+		Conditions: req.Conditions,
+	}
+	if rule.Severity == "" {
+		rule.Severity = models.AlertSeverityInfo
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := validateAlertRuleName(rule.Name); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if !alert.ValidSeverity(rule.Severity) {
+		response.BadRequest(c, "severity must be info, warning or critical")
+		return
+	}
+	if err := alert.Normalize(&rule.Conditions); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	count, err := h.alertRepo.CountRules(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count alert rules", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if count >= maxAlertRulesPerUser {
+		response.Error(c, http.StatusConflict, 409, "too many alert rules")
+		return
+	}
+
+	if err := h.alertRepo.CreateRule(ctx, rule); err != nil {
+		h.logger.Error("Failed to create alert rule", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// GetRule 获取告警规则详情
+// @Summary      获取告警规则详情
+// @Tags         告警
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "规则 ID"
+// @Success      200 {object} models.AlertRule
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /alerts/rules/{id} [get]
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	rule, err := h.alertRepo.GetRule(context.Background(), id, userID)
+	if err != nil {
+		h.logger.Error("Failed to get alert rule", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if rule == nil {
+		response.NotFound(c, "rule not found")
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// UpdateRule 编辑告警规则
+// @Summary      编辑告警规则
+// @Description  修改规则名称、级别、启用状态或条件，未提供的字段保持不变；conditions 整体替换
+// @Tags         告警
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "规则 ID"
+// @Param        request body UpdateAlertRuleRequest true "可编辑字段"
+// @Success      200 {object} models.AlertRule
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /alerts/rules/{id} [patch]
+func (h *AlertHandler) UpdateRule(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req UpdateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Name == nil && req.Severity == nil && req.Enabled == nil && req.Conditions == nil {
+		response.BadRequest(c, "nothing to update")
+		return
+	}
+
+	update := repository.AlertRuleUpdate{
+		Severity:   req.Severity,
+		Enabled:    req.Enabled,
+		Conditions: req.Conditions,
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := validateAlertRuleName(name); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		update.Name = &name
+	}
+	if req.Severity != nil && !alert.ValidSeverity(*req.Severity) {
+		response.BadRequest(c, "severity must be info, warning or critical")
+		return
+	}
+	if req.Conditions != nil {
+		if err := alert.Normalize(req.Conditions); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	rule, err := h.alertRepo.UpdateRule(context.Background(), id, userID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "rule not found")
+			return
+		}
+		h.logger.Error("Failed to update alert rule", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// DeleteRule 删除告警规则
+// @Summary      删除告警规则
+// @Description  删除告警规则及其告警记录
+// @Tags         告警
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "规则 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /alerts/rules/{id} [delete]
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.alertRepo.DeleteRule(context.Background(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "rule not found")
+			return
+		}
+		h.logger.Error("Failed to delete alert rule", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "rule deleted", nil)
+}
+
+// ListAlerts 获取告警记录
+// @Summary      获取告警记录
+// @Description  获取规则命中产生的告警，按时间倒序
+// @Tags         告警
+// @Produce      json
+// @Security     BearerAuth
+// @Param        severity query string false "按级别过滤" Enums(info, warning, critical)
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Success      200 {array} repository.AlertDetail
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /alerts [get]
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	severity := c.Query("severity")
+	if severity != "" && !alert.ValidSeverity(severity) {
+		response.BadRequest(c, "invalid severity")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	alerts, err := h.alertRepo.ListAlerts(context.Background(), userID, severity, pageSize, (page-1)*pageSize)
+	if err != nil {
+		h.logger.Error("Failed to list alerts", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, alerts)
+}
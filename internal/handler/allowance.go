@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+type AllowanceHandler struct {
+	allowanceRepo   *repository.TokenAllowanceRepository
+	watchedAddrRepo *repository.WatchedAddressRepository
+	logger          *zap.Logger
+}
+
+func NewAllowanceHandler(
+	allowanceRepo *repository.TokenAllowanceRepository,
+	watchedAddrRepo *repository.WatchedAddressRepository,
+	logger *zap.Logger,
+) *AllowanceHandler {
+	return &AllowanceHandler{
+		allowanceRepo:   allowanceRepo,
+		watchedAddrRepo: watchedAddrRepo,
+		logger:          logger,
+	}
+}
+
+type AllowanceListResponse struct {
+	Allowances []models.TokenAllowance `json:"allowances"`
+}
+
+// List 获取地址当前的代币授权
+// @Summary      获取地址授权
+// @Description  获取监控地址当前有效的代币授权（由授权事件重建，不含已撤销的授权与 NFT 的单个授权），无限授权给未认证 spender 的授权标记为 risky 并排在前面
+// @Tags         交易
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "以太坊地址"
+// @Param        risky query bool false "只返回有风险的授权"
+// @Success      200 {object} AllowanceListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /addresses/{address}/allowances [get]
+func (h *AllowanceHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+	address = common.HexToAddress(address).Hex()
+
+	riskyOnly := false
+	if raw := c.Query("risky"); raw != "" {
+		var err error
+		if riskyOnly, err = strconv.ParseBool(raw); err != nil {
+			response.BadRequest(c, "invalid risky")
+			return
+		}
+	}
+
+	// 验证用户是否监控了该地址（个人或所在团队）
+	ctx := c.Request.Context()
+	watchedAddr, err := h.watchedAddrRepo.GetAccessibleByAddress(ctx, userID, address)
+	if err != nil {
+		h.logger.Error("Failed to find watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if watchedAddr == nil {
+		response.NotFound(c, "address not watched")
+		return
+	}
+
+	allowances, err := h.allowanceRepo.ListByOwner(ctx, strings.ToLower(address), riskyOnly)
+	if err != nil {
+		h.logger.Error("Failed to list token allowances", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, AllowanceListResponse{Allowances: allowances})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const (
+	backfillWorkers   = 3                      // 并发回填数
+	backfillQueueSize = 1000                   // 队列容量，满时丢弃并记录日志
+	backfillInterval  = 300 * time.Millisecond // 每个 worker 两次 Alchemy 调用的最小间隔
+)
+
+type backfillJob struct {
+	recipients  []int64
+	watchedAddr *models.WatchedAddress
+}
+
+// backfillQueue 限流的历史交易回填队列，避免批量导入时并发打满 Alchemy
+type backfillQueue struct {
+	jobs   chan backfillJob
+	logger *zap.Logger
+}
+
+func newBackfillQueue(logger *zap.Logger, run func(ctx context.Context, job backfillJob)) *backfillQueue {
+	q := &backfillQueue{
+		jobs:   make(chan backfillJob, backfillQueueSize),
+		logger: logger,
+	}
+
+	for i := 0; i < backfillWorkers; i++ {
+		go func() {
+			ticker := time.NewTicker(backfillInterval)
+			defer ticker.Stop()
+			for job := range q.jobs {
+				run(context.Background(), job)
+				<-ticker.C
+			}
+		}()
+	}
+
+	return q
+}
+
+// Enqueue 非阻塞入队，返回是否成功
+func (q *backfillQueue) Enqueue(recipients []int64, watchedAddr *models.WatchedAddress) bool {
+	select {
+	case q.jobs <- backfillJob{recipients: recipients, watchedAddr: watchedAddr}:
+		return true
+	default:
+		q.logger.Warn("Backfill queue full, skipping",
+			zap.String("address", watchedAddr.Address),
+			zap.Int64("watched_address_id", watchedAddr.ID))
+		return false
+	}
+}
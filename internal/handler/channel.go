@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxChannelsPerUser   = 20
+	maxChannelNameLength = 100
+	slackWebhookHost     = "hooks.slack.com"
+)
+
+type ChannelHandler struct {
+	channelRepo *repository.ChannelRepository
+	redis       *redis.Client
+	channels    map[string]notify.Channel
+	botUsername string
+	logger      *zap.Logger
+}
+
+// NewChannelHandler channels 为已配置的渠道实现，用于发送测试消息
+func NewChannelHandler(channelRepo *repository.ChannelRepository, redis *redis.Client, botUsername string, logger *zap.Logger, channels ...notify.Channel) *ChannelHandler {
+	byType := make(map[string]notify.Channel, len(channels))
+	for _, ch := range channels {
+		byType[ch.Type()] = ch
+	}
+	return &ChannelHandler{
+		channelRepo: channelRepo,
+		redis:       redis,
+		channels:    byType,
+		botUsername: botUsername,
+		logger:      logger,
+	}
+}
+
+type CreateSlackChannelRequest struct {
+	WebhookURL string   `json:"webhook_url" binding:"required"`
+	Name       string   `json:"name"`
+	EventTypes []string `json:"event_types"`
+}
+
+type UpdateChannelRequest struct {
+	Name       *string   `json:"name"`
+	EventTypes *[]string `json:"event_types"`
+	Enabled    *bool     `json:"enabled"`
+}
+
+type TelegramLinkResponse struct {
+	Code      string    `json:"code"`
+	DeepLink  string    `json:"deep_link,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func validateSlackWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host != slackWebhookHost || !strings.HasPrefix(u.Path, "/services/") {
+		return errors.New("webhook_url must be a Slack incoming webhook URL (https://hooks.slack.com/services/...)")
+	}
+	return nil
+}
+
+// checkChannelQuota 校验渠道数量上限，失败时直接写入响应
+func (h *ChannelHandler) checkChannelQuota(c *gin.Context, userID int64) bool {
+	count, err := h.channelRepo.Count(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to count channels", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return false
+	}
+	if count >= maxChannelsPerUser {
+		response.Error(c, http.StatusConflict, 409, "too many notification channels")
+		return false
+	}
+	return true
+}
+
+// List 获取通知渠道
+// @Summary      获取通知渠道
+// @Description  获取当前用户绑定的 Telegram / Slack 通知渠道
+// @Tags         通知渠道
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.NotificationChannel
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /channels [get]
+func (h *ChannelHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	channels, err := h.channelRepo.List(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list channels", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, channels)
+}
+
+// CreateSlack 添加 Slack 渠道
+// @Summary      添加 Slack 渠道
+// @Description  通过 Slack incoming webhook URL 添加通知渠道；event_types 为空表示 new_transaction 与 alert 全部推送
+// @Tags         通知渠道
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateSlackChannelRequest true "渠道信息"
+// @Success      200 {object} models.NotificationChannel
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /channels/slack [post]
+func (h *ChannelHandler) CreateSlack(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req CreateSlackChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	webhookURL := strings.TrimSpace(req.WebhookURL)
+	if err := validateSlackWebhookURL(webhookURL); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Slack"
+	}
+	if len([]rune(name)) > maxChannelNameLength {
+		response.BadRequest(c, "name must be at most 100 characters")
+		return
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if !h.checkChannelQuota(c, userID) {
+		return
+	}
+
+	channel := &models.NotificationChannel{
+		UserID:     userID,
+		Type:       models.ChannelTypeSlack,
+		Name:       name,
+		Target:     webhookURL,
+		EventTypes: eventTypes,
+	}
+	if err := h.channelRepo.Create(context.Background(), channel); err != nil {
+		h.logger.Error("Failed to create slack channel", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, channel)
+}
+
+// CreateTelegramLink 生成 Telegram 绑定码
+// @Summary      生成 Telegram 绑定码
+// @Description  生成一次性绑定码（10 分钟有效），在 Telegram 中向 bot 发送 /start <code> 或打开 deep_link 完成绑定
+// @Tags         通知渠道
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} TelegramLinkResponse
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      503 {object} map[string]string
+// @Router       /channels/telegram/link [post]
+func (h *ChannelHandler) CreateTelegramLink(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if _, ok := h.channels[models.ChannelTypeTelegram]; !ok {
+		response.Error(c, http.StatusServiceUnavailable, 503, "telegram is not configured")
+		return
+	}
+
+	if !h.checkChannelQuota(c, userID) {
+		return
+	}
+
+	code, err := notify.CreateTelegramLinkCode(context.Background(), h.redis, userID)
+	if err != nil {
+		h.logger.Error("Failed to create telegram link code", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, TelegramLinkResponse{
+		Code:      code,
+		DeepLink:  notify.TelegramDeepLink(h.botUsername, code),
+		ExpiresAt: time.Now().Add(notify.TelegramLinkTTL),
+	})
+}
+
+// Update 编辑通知渠道
+// @Summary      编辑通知渠道
+// @Description  修改渠道名称、订阅事件或启用状态
+// @Tags         通知渠道
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "渠道 ID"
+// @Param        request body UpdateChannelRequest true "可编辑字段"
+// @Success      200 {object} models.NotificationChannel
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /channels/{id} [patch]
+func (h *ChannelHandler) Update(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req UpdateChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Name == nil && req.EventTypes == nil && req.Enabled == nil {
+		response.BadRequest(c, "nothing to update")
+		return
+	}
+
+	update := repository.ChannelUpdate{Enabled: req.Enabled}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len([]rune(name)) > maxChannelNameLength {
+			response.BadRequest(c, "name must be 1-100 characters")
+			return
+		}
+		update.Name = &name
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(*req.EventTypes)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		update.EventTypes = eventTypes
+	}
+
+	channel, err := h.channelRepo.Update(context.Background(), id, userID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "channel not found")
+			return
+		}
+		h.logger.Error("Failed to update channel", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, channel)
+}
+
+// Delete 删除通知渠道
+// @Summary      删除通知渠道
+// @Tags         通知渠道
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "渠道 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /channels/{id} [delete]
+func (h *ChannelHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.channelRepo.Delete(context.Background(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "channel not found")
+			return
+		}
+		h.logger.Error("Failed to delete channel", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "channel deleted", nil)
+}
+
+// Test 发送测试消息
+// @Summary      发送测试消息
+// @Description  立即向渠道发送一条测试消息，返回发送结果
+// @Tags         通知渠道
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "渠道 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      502 {object} map[string]string
+// @Router       /channels/{id}/test [post]
+func (h *ChannelHandler) Test(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	ctx := context.Background()
+	channel, err := h.channelRepo.Get(ctx, id, userID)
+	if err != nil {
+		h.logger.Error("Failed to get channel", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if channel == nil {
+		response.NotFound(c, "channel not found")
+		return
+	}
+
+	sender, ok := h.channels[channel.Type]
+	if !ok {
+		response.Error(c, http.StatusServiceUnavailable, 503, channel.Type+" is not configured")
+		return
+	}
+
+	err = sender.Send(ctx, channel.Target, &notify.Notification{
+		Title: "ChainFeed test notification",
+		Lines: []string{"This channel is connected and will receive your ChainFeed notifications."},
+	})
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if err := h.channelRepo.RecordResult(ctx, channel.ID, errMsg); err != nil {
+		h.logger.Error("Failed to record channel result", zap.Error(err))
+	}
+
+	if errMsg != "" {
+		response.Error(c, http.StatusBadGateway, 502, errMsg)
+		return
+	}
+	response.SuccessWithMessage(c, "test notification sent", nil)
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/decoder"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxContractABIsPerUser = 100
+	maxContractABISize     = 512 << 10
+	// 以字符串形式提交的 ABI 含转义字符，请求体上限放宽一倍
+	maxContractABIBodySize = 2 * maxContractABISize
+)
+
+type ContractABIHandler struct {
+	abiRepo *repository.ContractABIRepository
+	logger  *zap.Logger
+}
+
+func NewContractABIHandler(abiRepo *repository.ContractABIRepository, logger *zap.Logger) *ContractABIHandler {
+	return &ContractABIHandler{
+		abiRepo: abiRepo,
+		logger:  logger,
+	}
+}
+
+type UploadContractABIRequest struct {
+	Name string          `json:"name"`                   // 合约名称，显示为 "方法 on 名称"
+	ABI  json.RawMessage `json:"abi" binding:"required"` // JSON ABI，数组或编码为字符串的数组
+}
+
+// List 获取当前用户上传的合约 ABI
+// @Summary      获取上传的合约 ABI
+// @Tags         合约 ABI
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.ContractABI
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /abis [get]
+func (h *ContractABIHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	contracts, err := h.abiRepo.ListByUploader(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list contract abis", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, contracts)
+}
+
+// Get 获取合约 ABI
+// @Summary      获取合约 ABI
+// @Description  返回内置（source=bundled）或当前用户及其团队成员上传（source=uploaded）的合约 ABI
+// @Tags         合约 ABI
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "合约地址"
+// @Success      200 {object} models.ContractABI
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /abis/{address} [get]
+func (h *ContractABIHandler) Get(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+	address = strings.ToLower(address)
+
+	if bundled := decoder.Bundled(address); bundled != nil {
+		response.Success(c, bundled)
+		return
+	}
+
+	contract, err := h.abiRepo.GetByAddress(c.Request.Context(), userID, address)
+	if err != nil {
+		h.logger.Error("Failed to get contract abi", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if contract == nil {
+		response.NotFound(c, "contract abi not found")
+		return
+	}
+
+	response.Success(c, contract)
+}
+
+// Upload 上传或替换合约 ABI
+// @Summary      上传合约 ABI
+// @Description  上传的 ABI 只对当前用户及其团队成员生效：查看 feed 与地址交易时按此 ABI 解码该合约的调用（包括已入库的交易），其他用户不受影响；内置合约不可替换
+// @Tags         合约 ABI
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "合约地址"
+// @Param        request body UploadContractABIRequest true "合约名称与 JSON ABI"
+// @Success      200 {object} models.ContractABI
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /abis/{address} [put]
+func (h *ContractABIHandler) Upload(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+	address = strings.ToLower(address)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxContractABIBodySize)
+	var req UploadContractABIRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, 413, "abi too large")
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) > 255 {
+		response.BadRequest(c, "name too long")
+		return
+	}
+	// Etherscan 等接口以字符串形式返回 ABI
+	var encoded string
+	if json.Unmarshal(req.ABI, &encoded) == nil {
+		req.ABI = json.RawMessage(encoded)
+	}
+	if len(req.ABI) > maxContractABISize {
+		response.BadRequest(c, "abi too large")
+		return
+	}
+	if _, err := decoder.ParseABI(req.ABI); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if decoder.Bundled(address) != nil {
+		response.Error(c, http.StatusConflict, 409, "bundled contract abi cannot be replaced")
+		return
+	}
+
+	ctx := c.Request.Context()
+	existing, err := h.abiRepo.GetByUploader(ctx, userID, address)
+	if err != nil {
+		h.logger.Error("Failed to get contract abi", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if existing == nil {
+		count, err := h.abiRepo.CountByUploader(ctx, userID)
+		if err != nil {
+			h.logger.Error("Failed to count contract abis", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+		if count >= maxContractABIsPerUser {
+			response.Error(c, http.StatusConflict, 409, "too many contract abis")
+			return
+		}
+	}
+
+	contract := &models.ContractABI{Address: address, Name: req.Name, ABI: req.ABI, UploadedBy: userID}
+	if err := h.abiRepo.Save(ctx, contract); err != nil {
+		h.logger.Error("Failed to save contract abi", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, contract)
+}
+
+// Delete 删除上传的合约 ABI
+// @Summary      删除合约 ABI
+// @Tags         合约 ABI
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "合约地址"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /abis/{address} [delete]
+func (h *ContractABIHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+
+	if err := h.abiRepo.Delete(c.Request.Context(), strings.ToLower(address), userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "contract abi not found")
+			return
+		}
+		h.logger.Error("Failed to delete contract abi", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "contract abi deleted", nil)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+func TestUploadContractABI_Rejected(t *testing.T) {
+	h := NewContractABIHandler(repository.NewContractABIRepository(nil), zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+	})
+	router.PUT("/abis/:address", h.Upload)
+
+	do := func(address, body string) int {
+		req := httptest.NewRequest(http.MethodPut, "/abis/"+address, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	const vault = "0x1111111111111111111111111111111111111111"
+	const abi = `{"abi": [{"type":"function","name":"stake","inputs":[{"name":"amount","type":"uint256"}]}]}`
+	huge := `{"abi": "` + strings.Repeat("a", maxContractABIBodySize) + `"}`
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(vault, huge))
+	assert.Equal(t, http.StatusBadRequest, do(vault, `{"abi": "[{\"type\":\"bogus\"}]"}`))
+	assert.Equal(t, http.StatusBadRequest, do("0x1234", abi))
+	// 内置合约不可替换
+	assert.Equal(t, http.StatusConflict, do("0x7a250d5630b4cf539739df2c5dacb4c659f2488d", abi))
+}
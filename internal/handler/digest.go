@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/digest"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const defaultDigestHour = 8
+
+type DigestHandler struct {
+	digestRepo  *repository.DigestRepository
+	mailEnabled bool
+	explorer    string
+	publicURL   string
+	logger      *zap.Logger
+}
+
+// NewDigestHandler mailEnabled 为 false（未配置 SMTP）时不允许开启订阅
+func NewDigestHandler(digestRepo *repository.DigestRepository, mailEnabled bool, explorer, publicURL string, logger *zap.Logger) *DigestHandler {
+	return &DigestHandler{
+		digestRepo:  digestRepo,
+		mailEnabled: mailEnabled,
+		explorer:    explorer,
+		publicURL:   publicURL,
+		logger:      logger,
+	}
+}
+
+type UpdateDigestRequest struct {
+	Email     string `json:"email"      binding:"required"`
+	Frequency string `json:"frequency"  binding:"required,oneof=daily weekly"`
+	SendHour  *int   `json:"send_hour"  binding:"omitempty,min=0,max=23"`
+	Weekday   *int   `json:"weekday"    binding:"omitempty,min=0,max=6"`
+	Timezone  string `json:"timezone"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+// Get 获取摘要订阅
+// @Summary      获取邮件摘要订阅
+// @Tags         邮件摘要
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.DigestSubscription
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /digest [get]
+func (h *DigestHandler) Get(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	sub, err := h.digestRepo.GetSubscription(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get digest subscription", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if sub == nil {
+		response.NotFound(c, "digest subscription not found")
+		return
+	}
+
+	response.Success(c, sub)
+}
+
+// Update 创建或更新摘要订阅
+// @Summary      创建或更新邮件摘要订阅
+// @Description  frequency 为 daily 或 weekly；send_hour 为订阅时区的发送整点（默认 8）；weekday 仅 weekly 使用（0 = 周日，默认周一）
+// @Tags         邮件摘要
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body UpdateDigestRequest true "订阅设置"
+// @Success      200 {object} models.DigestSubscription
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      503 {object} map[string]string
+// @Router       /digest [put]
+func (h *DigestHandler) Update(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req UpdateDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		response.BadRequest(c, "invalid email address")
+		return
+	}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := digest.LoadLocation(timezone); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	enabled := req.Enabled == nil || *req.Enabled
+	if enabled && !h.mailEnabled {
+		response.Error(c, http.StatusServiceUnavailable, 503, "email is not configured")
+		return
+	}
+
+	ctx := context.Background()
+	existing, err := h.digestRepo.GetSubscription(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to get digest subscription", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	sub := &models.DigestSubscription{
+		UserID:    userID,
+		Email:     addr.Address,
+		Frequency: req.Frequency,
+		SendHour:  defaultDigestHour,
+		Weekday:   int(time.Monday),
+		Timezone:  timezone,
+		Enabled:   enabled,
+	}
+	if req.SendHour != nil {
+		sub.SendHour = *req.SendHour
+	}
+	if req.Weekday != nil {
+		sub.Weekday = *req.Weekday
+	}
+	if existing != nil {
+		sub.UnsubscribeToken = existing.UnsubscribeToken
+	} else if sub.UnsubscribeToken, err = digest.GenerateUnsubscribeToken(); err != nil {
+		h.logger.Error("Failed to generate unsubscribe token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if sub.NextRunAt, err = digest.NextRun(sub, time.Now()); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.digestRepo.UpsertSubscription(ctx, sub); err != nil {
+		h.logger.Error("Failed to save digest subscription", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, sub)
+}
+
+// Delete 删除摘要订阅
+// @Summary      删除邮件摘要订阅
+// @Tags         邮件摘要
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /digest [delete]
+func (h *DigestHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := h.digestRepo.DeleteSubscription(context.Background(), userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "digest subscription not found")
+			return
+		}
+		h.logger.Error("Failed to delete digest subscription", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "digest subscription deleted", nil)
+}
+
+// Preview 预览摘要
+// @Summary      预览邮件摘要
+// @Description  按当前订阅设置渲染截至现在的一期摘要（不发送），format=text 返回纯文本
+// @Tags         邮件摘要
+// @Produce      html
+// @Security     BearerAuth
+// @Param        format query string false "html 或 text" default(html)
+// @Success      200 {string} string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /digest/preview [get]
+func (h *DigestHandler) Preview(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	ctx := context.Background()
+	sub, err := h.digestRepo.GetSubscription(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to get digest subscription", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if sub == nil {
+		response.NotFound(c, "digest subscription not found")
+		return
+	}
+
+	now := time.Now()
+	from, err := digest.PeriodStart(sub, now)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	summary, err := digest.Compose(ctx, h.digestRepo, userID, from, now)
+	if err != nil {
+		h.logger.Error("Failed to compose digest", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	email, err := digest.Render(summary, sub, h.explorer, digest.UnsubscribeURL(h.publicURL, sub.UnsubscribeToken))
+	if err != nil {
+		h.logger.Error("Failed to render digest", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if c.Query("format") == "text" {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(email.Text))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+}
+
+// Unsubscribe 退订摘要
+// @Summary      退订邮件摘要
+// @Description  邮件中的退订链接，无需登录；支持 RFC 8058 一键退订（POST）
+// @Tags         邮件摘要
+// @Produce      plain
+// @Param        token query string true "退订 token"
+// @Success      200 {string} string
+// @Failure      404 {string} string
+// @Router       /digest/unsubscribe [get]
+// @Router       /digest/unsubscribe [post]
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.String(http.StatusBadRequest, "Missing unsubscribe token.")
+		return
+	}
+
+	if err := h.digestRepo.Unsubscribe(context.Background(), token); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.String(http.StatusNotFound, "This unsubscribe link is invalid.")
+			return
+		}
+		h.logger.Error("Failed to unsubscribe digest", zap.Error(err))
+		c.String(http.StatusInternalServerError, "Something went wrong, please try again later.")
+		return
+	}
+
+	c.String(http.StatusOK, "You have been unsubscribed from ChainFeed digests.")
+}
@@ -0,0 +1,522 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/export"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxActiveExportJobs = 3
+	exportJobListLimit  = 50
+	syncExportTimeout   = 10 * time.Minute // 同步导出的写超时，覆盖服务器默认的 write_timeout
+)
+
+type ExportHandler struct {
+	feedRepo        *repository.FeedRepository
+	txRepo          *repository.TransactionRepository
+	exportRepo      *repository.ExportRepository
+	watchedAddrRepo *repository.WatchedAddressRepository
+	cfg             config.ExportConfig
+	publicURL       string
+	logger          *zap.Logger
+}
+
+func NewExportHandler(
+	feedRepo *repository.FeedRepository,
+	txRepo *repository.TransactionRepository,
+	exportRepo *repository.ExportRepository,
+	watchedAddrRepo *repository.WatchedAddressRepository,
+	cfg config.ExportConfig,
+	publicURL string,
+	logger *zap.Logger,
+) *ExportHandler {
+	return &ExportHandler{
+		feedRepo:        feedRepo,
+		txRepo:          txRepo,
+		exportRepo:      exportRepo,
+		watchedAddrRepo: watchedAddrRepo,
+		cfg:             export.WithDefaults(cfg),
+		publicURL:       publicURL,
+		logger:          logger,
+	}
+}
+
+// ExportJobResponse 导出任务，完成后 download_url 可用于下载（需携带 token）
+type ExportJobResponse struct {
+	models.ExportJob
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+type ExportJobListResponse struct {
+	Jobs []ExportJobResponse `json:"jobs"`
+}
+
+// ExportFeed 同步导出 feed
+// @Summary      导出 feed
+// @Description  以流式响应导出满足过滤条件的全部 feed 条目（按时间升序），过滤参数与 GET /feed 相同；
+// @Description  条目数超过 max_sync_rows（精确计数，最多数到 max_sync_rows+1）时返回 422，需改用 POST /feed/exports 创建异步任务
+// @Tags         feed
+// @Produce      text/csv,application/x-ndjson,application/vnd.apache.parquet
+// @Security     BearerAuth
+// @Param        format query string false "导出格式" Enums(csv, ndjson, parquet) default(csv)
+// @Param        tz query string false "时间列使用的 IANA 时区，如 Asia/Shanghai" default(UTC)
+// @Param        preset query int false "过滤预设 ID"
+// @Param        tags query string false "其他过滤参数同 GET /feed"
+// @Success      200 {file} file
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      422 {object} map[string]string
+// @Router       /feed/export [get]
+func (h *ExportHandler) ExportFeed(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	filter, ok := resolveFeedFilter(c, h.feedRepo, h.logger, userID)
+	if !ok {
+		return
+	}
+	h.stream(c, userID, filter, "chainfeed-feed")
+}
+
+// ExportAddress 同步导出单个地址的 feed
+// @Summary      导出地址的 feed
+// @Description  导出命中该地址（个人或所在团队的监控记录）的 feed 条目，参数与 GET /feed/export 相同，watched_address_ids 会被忽略
+// @Tags         feed
+// @Produce      text/csv,application/x-ndjson,application/vnd.apache.parquet
+// @Security     BearerAuth
+// @Param        address path string true "以太坊地址"
+// @Param        format query string false "导出格式" Enums(csv, ndjson, parquet) default(csv)
+// @Param        tz query string false "时间列使用的 IANA 时区" default(UTC)
+// @Success      200 {file} file
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      422 {object} map[string]string
+// @Router       /addresses/{address}/transactions/export [get]
+func (h *ExportHandler) ExportAddress(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+
+	ids, err := h.watchedAddrRepo.ListAccessibleIDsByAddress(c.Request.Context(), userID, address)
+	if err != nil {
+		h.logger.Error("Failed to find watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if len(ids) == 0 {
+		response.NotFound(c, "address not watched")
+		return
+	}
+
+	filter, ok := resolveFeedFilter(c, h.feedRepo, h.logger, userID)
+	if !ok {
+		return
+	}
+	filter.WatchedAddressIDs = ids
+	h.stream(c, userID, filter, "chainfeed-"+strings.ToLower(address))
+}
+
+func (h *ExportHandler) stream(c *gin.Context, userID int64, filter *models.FeedFilter, name string) {
+	format, loc, ok := parseExportFormat(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	// 精确计数，多数一条即可判断是否超限
+	count, err := h.feedRepo.CountUserFeedUpTo(ctx, userID, filter, h.cfg.MaxSyncRows+1)
+	if err != nil {
+		h.logger.Error("Failed to count export size", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if count > h.cfg.MaxSyncRows {
+		response.Error(c, http.StatusUnprocessableEntity, 422,
+			fmt.Sprintf("export exceeds %d items, create an async export with POST /api/v1/feed/exports", h.cfg.MaxSyncRows))
+		return
+	}
+
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(syncExportTimeout))
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// 响应头已发出，之后的错误只能中断响应
+	w, err := export.NewWriter(format, c.Writer)
+	if err == nil {
+		_, err = export.Write(ctx, h.feedRepo, userID, filter, loc, w)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		h.logger.Error("Failed to export feed", zap.Int64("user_id", userID), zap.Error(err))
+		c.Abort()
+	}
+}
+
+// ExportLedger 导出钱包账本
+// @Summary      导出钱包账本
+// @Description  将 transactions 中该钱包的转账整理为按资产记账的账本（含转入、转出与累计余额），CSV 布局可直接导入常见加密货币税务工具；
+// @Description  余额从第一笔已存储的交易开始累计，区间之前的交易计入余额但不输出；数据局限见 docs/ledger-export.md
+// @Tags         监控地址
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        address path string true "以太坊地址"
+// @Param        layout query string false "CSV 布局" Enums(generic, koinly, cointracker) default(generic)
+// @Param        tz query string false "generic 布局的时区，税务工具布局固定为 UTC" default(UTC)
+// @Param        from_time query string false "开始时间，RFC3339（含）"
+// @Param        to_time query string false "结束时间，RFC3339（不含）"
+// @Success      200 {file} file
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /addresses/{address}/ledger [get]
+func (h *ExportHandler) ExportLedger(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+	address = strings.ToLower(address)
+
+	layout := strings.ToLower(c.DefaultQuery("layout", export.LedgerGeneric))
+	if !export.ValidLedgerLayout(layout) {
+		response.BadRequest(c, "layout must be generic, koinly or cointracker")
+		return
+	}
+	loc, err := export.LoadLocation(c.Query("tz"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	from, err := parseTimeParam(c, "from_time")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	to, err := parseTimeParam(c, "to_time")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		response.BadRequest(c, "from_time must be before to_time")
+		return
+	}
+
+	ctx := c.Request.Context()
+	watchedAddr, err := h.watchedAddrRepo.GetAccessibleByAddress(ctx, userID, address)
+	if err != nil {
+		h.logger.Error("Failed to find watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if watchedAddr == nil {
+		response.NotFound(c, "address not watched")
+		return
+	}
+
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(syncExportTimeout))
+
+	filename := fmt.Sprintf("chainfeed-ledger-%s-%s-%s.csv", address, layout, time.Now().UTC().Format("20060102"))
+	c.Header("Content-Type", export.ContentType(export.FormatCSV))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// 响应头已发出，之后的错误只能中断响应
+	w, err := export.NewLedgerWriter(layout, address, c.Writer, loc)
+	if err == nil {
+		_, err = export.WriteLedger(ctx, h.txRepo, address, from, to, w)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		h.logger.Error("Failed to export ledger", zap.String("address", address), zap.Error(err))
+		c.Abort()
+	}
+}
+
+func parseTimeParam(c *gin.Context, key string) (*time.Time, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected RFC3339: %s", key, raw)
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// parseExportFormat 解析 format 与 tz 参数，失败时已写入错误响应
+func parseExportFormat(c *gin.Context) (string, *time.Location, bool) {
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatCSV))
+	if !export.ValidFormat(format) {
+		response.BadRequest(c, "format must be csv, ndjson or parquet")
+		return "", nil, false
+	}
+	loc, err := export.LoadLocation(c.Query("tz"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return "", nil, false
+	}
+	return format, loc, true
+}
+
+// CreateJob 创建异步导出任务
+// @Summary      创建异步导出任务
+// @Description  参数与 GET /feed/export 相同（通过查询参数传递），适用于大批量导出；完成后通过 download_url 下载，文件保留时间由服务端配置
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format query string false "导出格式" Enums(csv, ndjson, parquet) default(csv)
+// @Param        tz query string false "时间列使用的 IANA 时区" default(UTC)
+// @Param        preset query int false "过滤预设 ID"
+// @Param        tags query string false "其他过滤参数同 GET /feed"
+// @Success      200 {object} ExportJobResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /feed/exports [post]
+func (h *ExportHandler) CreateJob(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	format, _, ok := parseExportFormat(c)
+	if !ok {
+		return
+	}
+	filter, ok := resolveFeedFilter(c, h.feedRepo, h.logger, userID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	active, err := h.exportRepo.CountActive(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count export jobs", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if active >= maxActiveExportJobs {
+		response.Error(c, http.StatusConflict, 409, "too many export jobs in progress")
+		return
+	}
+
+	job := &models.ExportJob{
+		UserID:   userID,
+		Format:   format,
+		Timezone: c.Query("tz"),
+		Filter:   *filter,
+	}
+	if err := h.exportRepo.Create(ctx, job); err != nil {
+		h.logger.Error("Failed to create export job", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, h.jobResponse(job))
+}
+
+// ListJobs 获取导出任务
+// @Summary      获取导出任务列表
+// @Description  返回最近 50 个导出任务
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} ExportJobListResponse
+// @Failure      401 {object} map[string]string
+// @Router       /feed/exports [get]
+func (h *ExportHandler) ListJobs(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	jobs, err := h.exportRepo.ListByUser(c.Request.Context(), userID, exportJobListLimit)
+	if err != nil {
+		h.logger.Error("Failed to list export jobs", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	result := make([]ExportJobResponse, len(jobs))
+	for i := range jobs {
+		result[i] = h.jobResponse(&jobs[i])
+	}
+	response.Success(c, ExportJobListResponse{Jobs: result})
+}
+
+// GetJob 获取导出任务
+// @Summary      获取导出任务
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "任务 ID"
+// @Success      200 {object} ExportJobResponse
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/exports/{id} [get]
+func (h *ExportHandler) GetJob(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+	response.Success(c, h.jobResponse(job))
+}
+
+// Download 下载导出文件
+// @Summary      下载导出文件
+// @Tags         feed
+// @Produce      text/csv,application/x-ndjson,application/vnd.apache.parquet
+// @Security     BearerAuth
+// @Param        id path int true "任务 ID"
+// @Success      200 {file} file
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      410 {object} map[string]string
+// @Router       /feed/exports/{id}/download [get]
+func (h *ExportHandler) Download(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+
+	switch job.Status {
+	case models.ExportStatusCompleted:
+	case models.ExportStatusExpired:
+		response.Error(c, http.StatusGone, 410, "export file expired")
+		return
+	default:
+		response.Error(c, http.StatusConflict, 409, "export is "+job.Status)
+		return
+	}
+
+	if _, err := os.Stat(job.FilePath); err != nil {
+		h.logger.Error("Export file missing", zap.Int64("export_job_id", job.ID), zap.Error(err))
+		response.Error(c, http.StatusGone, 410, "export file expired")
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(job.Format))
+	c.FileAttachment(job.FilePath, export.FileName(job))
+}
+
+// DeleteJob 删除导出任务及文件
+// @Summary      删除导出任务
+// @Tags         feed
+// @Security     BearerAuth
+// @Param        id path int true "任务 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/exports/{id} [delete]
+func (h *ExportHandler) DeleteJob(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	job, err := h.exportRepo.Delete(c.Request.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "export job not found")
+			return
+		}
+		h.logger.Error("Failed to delete export job", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if job.FilePath != "" {
+		if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			h.logger.Warn("Failed to remove export file", zap.String("path", job.FilePath), zap.Error(err))
+		}
+	}
+
+	response.SuccessWithMessage(c, "export job deleted", nil)
+}
+
+func (h *ExportHandler) loadJob(c *gin.Context) (*models.ExportJob, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return nil, false
+	}
+
+	job, err := h.exportRepo.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		h.logger.Error("Failed to get export job", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return nil, false
+	}
+	if job == nil {
+		response.NotFound(c, "export job not found")
+		return nil, false
+	}
+	return job, true
+}
+
+func (h *ExportHandler) jobResponse(job *models.ExportJob) ExportJobResponse {
+	resp := ExportJobResponse{ExportJob: *job}
+	if job.Status == models.ExportStatusCompleted {
+		resp.DownloadURL = fmt.Sprintf("%s/api/v1/feed/exports/%d/download", strings.TrimRight(h.publicURL, "/"), job.ID)
+	}
+	return resp
+}
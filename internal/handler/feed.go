@@ -1,39 +1,170 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/bwmspring/chainfeed-go/internal/decoder"
+	"github.com/bwmspring/chainfeed-go/internal/feedfilter"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
 	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/response"
+	"github.com/bwmspring/chainfeed-go/internal/websocket"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxFeedPresetNameLength = 100
+	maxFeedPresetsPerUser   = 50
+	maxFeedNoteLength       = 2000
+	maxHiddenTokensPerUser  = 1000
+)
+
+// 条目状态变更事件，推送给用户的全部 WebSocket 连接用于多端同步
+const (
+	EventFeedItemUpdated = "feed_item_updated"
+	EventFeedRead        = "feed_read"
 )
 
 type FeedHandler struct {
-	feedRepo *repository.FeedRepository
+	feedRepo  *repository.FeedRepository
+	publisher notify.Publisher
+	abis      *decoder.Uploaded // 按用户上传的 ABI 解码合约调用
+	logger    *zap.Logger
+}
+
+func NewFeedHandler(feedRepo *repository.FeedRepository, publisher notify.Publisher, abis *decoder.Uploaded, logger *zap.Logger) *FeedHandler {
+	return &FeedHandler{feedRepo: feedRepo, publisher: publisher, abis: abis, logger: logger}
+}
+
+// UpdateFeedItemRequest 未提供的字段保持不变
+type UpdateFeedItemRequest struct {
+	Seen    *bool   `json:"seen"`
+	Starred *bool   `json:"starred"`
+	Note    *string `json:"note"`
+}
+
+type MarkFeedReadRequest struct {
+	Cursor string `json:"cursor"` // 标记该位置及更早的条目，为空时标记全部
+}
+
+type HideTokenRequest struct {
+	TokenAddress string `json:"token_address" binding:"required"`
+}
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+type MarkFeedReadResponse struct {
+	Marked int64 `json:"marked"`
+	Unread int64 `json:"unread"`
+}
+
+// FeedItemState 条目状态，作为 feed_item_updated 事件的 payload
+type FeedItemState struct {
+	ID        int64      `json:"id"`
+	SeenAt    *time.Time `json:"seen_at"`
+	StarredAt *time.Time `json:"starred_at"`
+	Note      string     `json:"note"`
+}
+
+type CreateFeedPresetRequest struct {
+	Name   string            `json:"name" binding:"required"`
+	Filter models.FeedFilter `json:"filter"`
 }
 
-func NewFeedHandler(feedRepo *repository.FeedRepository) *FeedHandler {
-	return &FeedHandler{feedRepo: feedRepo}
+type UpdateFeedPresetRequest struct {
+	Name   *string            `json:"name"`
+	Filter *models.FeedFilter `json:"filter"`
+}
+
+func validateFeedPresetName(name string) error {
+	if name == "" || len([]rune(name)) > maxFeedPresetNameLength {
+		return errors.New("preset name must be 1-100 characters")
+	}
+	return nil
+}
+
+// resolveFeedFilter 解析 preset 与过滤参数，查询参数覆盖预设中的同名条件；失败时已写入响应
+func resolveFeedFilter(c *gin.Context, feedRepo *repository.FeedRepository, logger *zap.Logger, userID int64) (*models.FeedFilter, bool) {
+	var base *models.FeedFilter
+	if raw := c.Query("preset"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "invalid preset")
+			return nil, false
+		}
+		preset, err := feedRepo.GetPreset(c.Request.Context(), id, userID)
+		if err != nil {
+			logger.Error("Failed to get feed preset", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return nil, false
+		}
+		if preset == nil {
+			response.NotFound(c, "preset not found")
+			return nil, false
+		}
+		base = &preset.Filter
+	}
+
+	filter, err := feedfilter.Parse(c.Request.URL.Query(), base)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return nil, false
+	}
+	return filter, true
 }
 
 type FeedResponse struct {
-	Items      []repository.FeedItemDetail `json:"items"`
-	TotalCount int                         `json:"total_count"`
-	Page       int                         `json:"page"`
-	PageSize   int                         `json:"page_size"`
+	Items []repository.FeedItemDetail `json:"items"`
+	pagination.Page
 }
 
 // GetFeed godoc
 // @Summary Get user feed
-// @Description Get paginated feed items for the authenticated user
+// @Description Get feed items for the authenticated user, newest first, using cursor pagination.
+// @Description Pass next_cursor as cursor to load older items; pass prev_cursor as since to poll for newer items.
 // @Tags feed
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
-// @Param page_size query int false "Page size" default(20)
+// @Param limit query int false "Page size (1-100)" default(20)
+// @Param cursor query string false "Return items older than this cursor (next_cursor)"
+// @Param since query string false "Return items newer than this cursor (prev_cursor)"
+// @Param count query string false "Include total_count: exact or estimate"
+// @Param preset query int false "Saved filter preset ID; filter parameters below override the preset"
+// @Param watched_address_ids query string false "Comma-separated watched address IDs"
+// @Param tags query string false "Comma-separated tags; the watched address must have all of them"
+// @Param tx_types query string false "Comma-separated tx types: ETH, ERC20, ERC721, SWAP, APPROVAL"
+// @Param tokens query string false "Comma-separated token contract addresses or symbols"
+// @Param direction query string false "Direction relative to the watched wallet" Enums(in, out)
+// @Param min_amount query string false "Minimum amount (inclusive)"
+// @Param max_amount query string false "Maximum amount (inclusive)"
+// @Param min_usd_value query string false "Minimum USD value at block time (inclusive); unpriced items never match"
+// @Param max_usd_value query string false "Maximum USD value at block time (inclusive); unpriced items never match"
+// @Param from_block query int false "First block (inclusive)"
+// @Param to_block query int false "Last block (inclusive)"
+// @Param from_time query string false "Block time lower bound, RFC3339 (inclusive)"
+// @Param to_time query string false "Block time upper bound, RFC3339 (exclusive)"
+// @Param counterparties query string false "Comma-separated counterparty addresses"
+// @Param unread query bool false "Only unread items"
+// @Param starred query bool false "Only starred items"
+// @Param include_spam query bool false "Include spam transfers and hidden tokens"
+// @Param exclude_reverted query bool false "Exclude reverted transactions"
 // @Success 200 {object} FeedResponse
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /api/v1/feed [get]
@@ -44,28 +175,513 @@ func (h *FeedHandler) GetFeed(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "5"))
+	q, err := pagination.Parse(c.Query("limit"), c.Query("cursor"), c.Query("since"), c.Query("count"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	filter, ok := resolveFeedFilter(c, h.feedRepo, h.logger, userID.(int64))
+	if !ok {
+		return
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 5
+
+	ctx := c.Request.Context()
+	items, hasMore, err := h.feedRepo.ListUserFeed(ctx, userID.(int64), filter, q)
+	if err != nil {
+		h.logger.Error("Failed to get feed", zap.Error(err))
+		response.InternalServerError(c, "failed to get feed")
+		return
 	}
 
-	offset := (page - 1) * pageSize
+	txs := make([]*models.Transaction, len(items))
+	for i := range items {
+		txs[i] = &items[i].Transaction
+	}
+	h.abis.Apply(ctx, userID.(int64), txs)
 
-	items, err := h.feedRepo.GetUserFeed(userID.(int64), pageSize, offset)
+	keys := make([]pagination.Cursor, len(items))
+	for i, item := range items {
+		keys[i] = pagination.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}
+	page := pagination.NewPage(q, keys, hasMore)
+
+	if q.Count != pagination.CountNone {
+		total, estimated, err := h.feedRepo.CountUserFeed(ctx, userID.(int64), filter, q.Count)
+		if err != nil {
+			h.logger.Error("Failed to count feed", zap.Error(err))
+			response.InternalServerError(c, "failed to count feed")
+			return
+		}
+		page.TotalCount = &total
+		page.CountEstimated = estimated
+	}
+
+	if items == nil {
+		items = []repository.FeedItemDetail{}
+	}
+	response.Success(c, FeedResponse{Items: items, Page: page})
+}
+
+// UnreadCount 获取未读数量
+// @Summary      获取 feed 未读数量
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} UnreadCountResponse
+// @Failure      401 {object} map[string]string
+// @Router       /feed/unread-count [get]
+func (h *FeedHandler) UnreadCount(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	count, err := h.feedRepo.CountUnread(c.Request.Context(), userID)
 	if err != nil {
-		response.InternalServerError(c, "failed to get feed")
+		h.logger.Error("Failed to count unread feed items", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, UnreadCountResponse{Unread: count})
+}
+
+// UpdateItem 修改 feed 条目状态
+// @Summary      修改 feed 条目状态
+// @Description  标记已读/未读、加星标/取消星标、编辑备注，未提供的字段保持不变；变更通过 feed_item_updated 事件同步到该用户的其他连接
+// @Tags         feed
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "feed 条目 ID"
+// @Param        request body UpdateFeedItemRequest true "条目状态"
+// @Success      200 {object} FeedItemState
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/items/{id} [patch]
+func (h *FeedHandler) UpdateItem(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req UpdateFeedItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Seen == nil && req.Starred == nil && req.Note == nil {
+		response.BadRequest(c, "nothing to update")
 		return
 	}
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		if len([]rune(note)) > maxFeedNoteLength {
+			response.BadRequest(c, "note must be at most 2000 characters")
+			return
+		}
+		req.Note = &note
+	}
 
-	response.Success(c, FeedResponse{
-		Items:      items,
-		TotalCount: len(items),
-		Page:       page,
-		PageSize:   pageSize,
+	ctx := c.Request.Context()
+	item, err := h.feedRepo.UpdateItemState(ctx, id, userID, repository.FeedItemStateUpdate{
+		Seen:    req.Seen,
+		Starred: req.Starred,
+		Note:    req.Note,
 	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "feed item not found")
+			return
+		}
+		h.logger.Error("Failed to update feed item", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	state := FeedItemState{ID: item.ID, SeenAt: item.SeenAt, StarredAt: item.StarredAt, Note: item.Note}
+	h.sync(ctx, userID, EventFeedItemUpdated, state)
+	response.Success(c, state)
+}
+
+// MarkRead 批量标记已读
+// @Summary      批量标记已读
+// @Description  将 cursor 位置及更早的未读条目标记为已读（通常传入列表的 prev_cursor），cursor 为空时标记全部；通过 feed_read 事件同步到该用户的其他连接
+// @Tags         feed
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body MarkFeedReadRequest false "截止位置"
+// @Success      200 {object} MarkFeedReadResponse
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /feed/read [post]
+func (h *FeedHandler) MarkRead(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req MarkFeedReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	var upTo *pagination.Cursor
+	if req.Cursor != "" {
+		cursor, err := pagination.Decode(req.Cursor)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		upTo = cursor
+	}
+
+	ctx := c.Request.Context()
+	marked, err := h.feedRepo.MarkReadUpTo(ctx, userID, upTo)
+	if err != nil {
+		h.logger.Error("Failed to mark feed read", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	unread, err := h.feedRepo.CountUnread(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count unread feed items", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if marked > 0 {
+		h.sync(ctx, userID, EventFeedRead, map[string]interface{}{
+			"cursor": req.Cursor,
+			"marked": marked,
+			"unread": unread,
+		})
+	}
+	response.Success(c, MarkFeedReadResponse{Marked: marked, Unread: unread})
+}
+
+// sync 经 Feed Stream 推送状态变更，由 StreamService 转发给用户的全部 WebSocket 连接；失败只记录日志
+func (h *FeedHandler) sync(ctx context.Context, userID int64, eventType string, payload interface{}) {
+	err := h.publisher.Publish(ctx, &websocket.Message{UserID: userID, Type: eventType, Payload: payload})
+	if err != nil {
+		h.logger.Warn("Failed to publish feed state change", zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// ListPresets 获取 feed 过滤预设
+// @Summary      获取 feed 过滤预设
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.FeedFilterPreset
+// @Failure      401 {object} map[string]string
+// @Router       /feed/presets [get]
+func (h *FeedHandler) ListPresets(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	presets, err := h.feedRepo.ListPresets(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list feed presets", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, presets)
+}
+
+// CreatePreset 保存 feed 过滤预设
+// @Summary      保存 feed 过滤预设
+// @Description  保存一组过滤条件，之后可通过 GET /feed?preset=ID 或 WebSocket /ws?preset=ID 使用
+// @Tags         feed
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateFeedPresetRequest true "预设名称与过滤条件"
+// @Success      200 {object} models.FeedFilterPreset
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /feed/presets [post]
+func (h *FeedHandler) CreatePreset(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req CreateFeedPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	preset := &models.FeedFilterPreset{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+		Filter: req.Filter,
+	}
+	if err := validateFeedPresetName(preset.Name); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err := feedfilter.Normalize(&preset.Filter); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := h.feedRepo.CountPresets(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count feed presets", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if count >= maxFeedPresetsPerUser {
+		response.Error(c, http.StatusConflict, 409, "too many feed presets")
+		return
+	}
+
+	if err := h.feedRepo.CreatePreset(ctx, preset); err != nil {
+		h.logger.Error("Failed to create feed preset", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, preset)
+}
+
+// UpdatePreset 编辑 feed 过滤预设
+// @Summary      编辑 feed 过滤预设
+// @Description  未提供的字段保持不变；filter 整体替换
+// @Tags         feed
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "预设 ID"
+// @Param        request body UpdateFeedPresetRequest true "可编辑字段"
+// @Success      200 {object} models.FeedFilterPreset
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/presets/{id} [patch]
+func (h *FeedHandler) UpdatePreset(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req UpdateFeedPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Name == nil && req.Filter == nil {
+		response.BadRequest(c, "nothing to update")
+		return
+	}
+
+	update := repository.FeedPresetUpdate{Filter: req.Filter}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := validateFeedPresetName(name); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		update.Name = &name
+	}
+	if req.Filter != nil {
+		if err := feedfilter.Normalize(req.Filter); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	preset, err := h.feedRepo.UpdatePreset(c.Request.Context(), id, userID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "preset not found")
+			return
+		}
+		h.logger.Error("Failed to update feed preset", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, preset)
+}
+
+// DeletePreset 删除 feed 过滤预设
+// @Summary      删除 feed 过滤预设
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "预设 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/presets/{id} [delete]
+func (h *FeedHandler) DeletePreset(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.feedRepo.DeletePreset(c.Request.Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "preset not found")
+			return
+		}
+		h.logger.Error("Failed to delete feed preset", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "preset deleted", nil)
+}
+
+// ListHiddenTokens 获取隐藏的代币
+// @Summary      获取隐藏的代币
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.HiddenToken
+// @Failure      401 {object} map[string]string
+// @Router       /feed/hidden-tokens [get]
+func (h *FeedHandler) ListHiddenTokens(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	tokens, err := h.feedRepo.ListHiddenTokens(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list hidden tokens", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// HideToken 隐藏代币
+// @Summary      隐藏代币
+// @Description  该代币的转账不再出现在 feed、未读数与摘要中，也不推送通知与告警；feed 查询传 include_spam=true 时仍可查看
+// @Tags         feed
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body HideTokenRequest true "代币合约地址"
+// @Success      200 {object} models.HiddenToken
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /feed/hidden-tokens [post]
+func (h *FeedHandler) HideToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req HideTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if !common.IsHexAddress(req.TokenAddress) {
+		response.BadRequest(c, "invalid token address")
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := h.feedRepo.CountHiddenTokens(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count hidden tokens", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if count >= maxHiddenTokensPerUser {
+		response.Error(c, http.StatusConflict, 409, "too many hidden tokens")
+		return
+	}
+
+	token := &models.HiddenToken{UserID: userID, TokenAddress: strings.ToLower(req.TokenAddress)}
+	if err := h.feedRepo.HideToken(ctx, token); err != nil {
+		h.logger.Error("Failed to hide token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, token)
+}
+
+// UnhideToken 取消隐藏代币
+// @Summary      取消隐藏代币
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "代币合约地址"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/hidden-tokens/{address} [delete]
+func (h *FeedHandler) UnhideToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid token address")
+		return
+	}
+
+	if err := h.feedRepo.UnhideToken(c.Request.Context(), userID, strings.ToLower(address)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "token not hidden")
+			return
+		}
+		h.logger.Error("Failed to unhide token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "token unhidden", nil)
 }
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/digest"
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxMuteDuration    = 90 * 24 * time.Hour
+	maxRateLimitCount  = 1000
+	minRateLimitWindow = 60
+	maxRateLimitWindow = 86400
+)
+
+type PreferenceHandler struct {
+	prefRepo        *repository.PreferenceRepository
+	watchedAddrRepo *repository.WatchedAddressRepository
+	teamRepo        *repository.TeamRepository
+	logger          *zap.Logger
+}
+
+func NewPreferenceHandler(
+	prefRepo *repository.PreferenceRepository,
+	watchedAddrRepo *repository.WatchedAddressRepository,
+	teamRepo *repository.TeamRepository,
+	logger *zap.Logger,
+) *PreferenceHandler {
+	return &PreferenceHandler{
+		prefRepo:        prefRepo,
+		watchedAddrRepo: watchedAddrRepo,
+		teamRepo:        teamRepo,
+		logger:          logger,
+	}
+}
+
+// UpdatePreferencesRequest 未提供的字段保持不变
+type UpdatePreferencesRequest struct {
+	Timezone          *string `json:"timezone"`
+	QuietHoursEnabled *bool   `json:"quiet_hours_enabled"`
+	QuietHoursStart   *string `json:"quiet_hours_start"`
+	QuietHoursEnd     *string `json:"quiet_hours_end"`
+	RateLimitCount    *int    `json:"rate_limit_count"`
+	RateLimitWindow   *int    `json:"rate_limit_window"`
+}
+
+type MuteAddressRequest struct {
+	Duration string `json:"duration" binding:"required"` // 如 30m、8h、168h
+}
+
+func defaultPreferences(userID int64) *models.NotificationPreference {
+	return &models.NotificationPreference{
+		UserID:          userID,
+		Timezone:        "UTC",
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "07:00",
+		RateLimitWindow: 3600,
+	}
+}
+
+func (h *PreferenceHandler) loadPreferences(ctx context.Context, userID int64) (*models.NotificationPreference, error) {
+	pref, err := h.prefRepo.GetPreferences(ctx, userID)
+	if err != nil || pref != nil {
+		return pref, err
+	}
+	return defaultPreferences(userID), nil
+}
+
+// GetPreferences 获取通知偏好
+// @Summary      获取通知偏好
+// @Description  获取免打扰时段与单地址限流设置，未设置时返回默认值
+// @Tags         通知偏好
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} models.NotificationPreference
+// @Failure      401 {object} map[string]string
+// @Router       /notifications/preferences [get]
+func (h *PreferenceHandler) GetPreferences(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	pref, err := h.loadPreferences(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, pref)
+}
+
+// UpdatePreferences 更新通知偏好
+// @Summary      更新通知偏好
+// @Description  免打扰时段（本地时间 HH:MM，可跨午夜）内不推送；rate_limit_count 为每个地址在 rate_limit_window 秒内最多推送的交易数，超出部分在窗口结束后汇总为一条 burst_summary，0 表示不限。只影响 WebSocket 与外部渠道推送，feed 历史不受影响
+// @Tags         通知偏好
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body UpdatePreferencesRequest true "偏好设置"
+// @Success      200 {object} models.NotificationPreference
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Router       /notifications/preferences [put]
+func (h *PreferenceHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	pref, err := h.loadPreferences(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if req.Timezone != nil {
+		if _, err := digest.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			response.BadRequest(c, "invalid timezone")
+			return
+		}
+		pref.Timezone = *req.Timezone
+	}
+	if req.QuietHoursEnabled != nil {
+		pref.QuietHoursEnabled = *req.QuietHoursEnabled
+	}
+	if req.QuietHoursStart != nil {
+		if _, err := notify.ParseClock(*req.QuietHoursStart); err != nil {
+			response.BadRequest(c, "quiet_hours_start: "+err.Error())
+			return
+		}
+		pref.QuietHoursStart = *req.QuietHoursStart
+	}
+	if req.QuietHoursEnd != nil {
+		if _, err := notify.ParseClock(*req.QuietHoursEnd); err != nil {
+			response.BadRequest(c, "quiet_hours_end: "+err.Error())
+			return
+		}
+		pref.QuietHoursEnd = *req.QuietHoursEnd
+	}
+	if pref.QuietHoursEnabled && pref.QuietHoursStart == pref.QuietHoursEnd {
+		response.BadRequest(c, "quiet_hours_start and quiet_hours_end must differ")
+		return
+	}
+	if req.RateLimitCount != nil {
+		if *req.RateLimitCount < 0 || *req.RateLimitCount > maxRateLimitCount {
+			response.BadRequest(c, "rate_limit_count must be between 0 and 1000")
+			return
+		}
+		pref.RateLimitCount = *req.RateLimitCount
+	}
+	if req.RateLimitWindow != nil {
+		if *req.RateLimitWindow < minRateLimitWindow || *req.RateLimitWindow > maxRateLimitWindow {
+			response.BadRequest(c, "rate_limit_window must be between 60 and 86400 seconds")
+			return
+		}
+		pref.RateLimitWindow = *req.RateLimitWindow
+	}
+
+	if err := h.prefRepo.UpsertPreferences(ctx, pref); err != nil {
+		h.logger.Error("Failed to save notification preferences", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, pref)
+}
+
+// ListMutes 获取静音中的地址
+// @Summary      获取静音中的地址
+// @Tags         通知偏好
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} repository.AddressMuteDetail
+// @Failure      401 {object} map[string]string
+// @Router       /notifications/mutes [get]
+func (h *PreferenceHandler) ListMutes(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	mutes, err := h.prefRepo.ListMutes(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list address mutes", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, mutes)
+}
+
+// canAccessAddress 个人地址需为本人所有，团队地址需为团队成员（任意角色）
+func (h *PreferenceHandler) canAccessAddress(ctx context.Context, userID, id int64) (bool, error) {
+	wa, err := h.watchedAddrRepo.GetByID(ctx, id)
+	if err != nil || wa == nil {
+		return false, err
+	}
+	if wa.TeamID == nil {
+		return wa.UserID == userID, nil
+	}
+	role, err := h.teamRepo.GetMemberRole(ctx, *wa.TeamID, userID)
+	return role != "", err
+}
+
+// Mute 静音监控地址
+// @Summary      静音监控地址
+// @Description  在指定时长内不推送该地址的交易与告警（仅对当前用户生效，最长 90 天），feed 历史不受影响
+// @Tags         通知偏好
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "地址 ID"
+// @Param        request body MuteAddressRequest true "静音时长"
+// @Success      200 {object} models.AddressMute
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /addresses/{id}/mute [post]
+func (h *PreferenceHandler) Mute(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req MuteAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 || duration > maxMuteDuration {
+		response.BadRequest(c, "duration must be a positive duration up to 2160h (e.g. 30m, 8h)")
+		return
+	}
+
+	ctx := context.Background()
+	allowed, err := h.canAccessAddress(ctx, userID, id)
+	if err != nil {
+		h.logger.Error("Failed to check address access", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if !allowed {
+		response.NotFound(c, "address not found")
+		return
+	}
+
+	mute, err := h.prefRepo.Mute(ctx, userID, id, time.Now().Add(duration))
+	if err != nil {
+		h.logger.Error("Failed to mute address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, mute)
+}
+
+// Unmute 取消静音
+// @Summary      取消静音
+// @Tags         通知偏好
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "地址 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /addresses/{id}/mute [delete]
+func (h *PreferenceHandler) Unmute(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.prefRepo.Unmute(context.Background(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "address is not muted")
+			return
+		}
+		h.logger.Error("Failed to unmute address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "address unmuted", nil)
+}
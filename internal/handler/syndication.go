@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+	"github.com/bwmspring/chainfeed-go/internal/syndication"
+)
+
+const (
+	maxFeedTokensPerUser    = 20
+	maxFeedTokenNameLength  = 100
+	syndicationItemLimit    = 50
+	syndicationCacheControl = "private, max-age=60"
+)
+
+type SyndicationHandler struct {
+	feedRepo        *repository.FeedRepository
+	watchedAddrRepo *repository.WatchedAddressRepository
+	explorer        string
+	publicURL       string
+	logger          *zap.Logger
+}
+
+func NewSyndicationHandler(
+	feedRepo *repository.FeedRepository,
+	watchedAddrRepo *repository.WatchedAddressRepository,
+	explorer, publicURL string,
+	logger *zap.Logger,
+) *SyndicationHandler {
+	return &SyndicationHandler{
+		feedRepo:        feedRepo,
+		watchedAddrRepo: watchedAddrRepo,
+		explorer:        explorer,
+		publicURL:       strings.TrimRight(publicURL, "/"),
+		logger:          logger,
+	}
+}
+
+type CreateFeedTokenRequest struct {
+	Name    string `json:"name"`
+	Address string `json:"address"` // 为空时包含全部 feed 条目
+}
+
+// FeedTokenWithURLs 新建的订阅令牌，token 与订阅地址只展示一次
+type FeedTokenWithURLs struct {
+	models.FeedToken
+	Token   string `json:"token"`
+	AtomURL string `json:"atom_url"`
+	RSSURL  string `json:"rss_url"`
+}
+
+// ListTokens 获取订阅令牌
+// @Summary      获取 Atom / RSS 订阅令牌
+// @Tags         feed
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.FeedToken
+// @Failure      401 {object} map[string]string
+// @Router       /feed/tokens [get]
+func (h *SyndicationHandler) ListTokens(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	tokens, err := h.feedRepo.ListTokens(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list feed tokens", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// CreateToken 创建订阅令牌
+// @Summary      创建 Atom / RSS 订阅令牌
+// @Description  令牌放在订阅地址中供阅读器使用，与登录 JWT 无关，可单独吊销；address 非空时只包含该地址（个人或所在团队的监控记录）的条目。
+// @Description  返回的 token、atom_url 与 rss_url 只展示一次
+// @Tags         feed
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateFeedTokenRequest true "令牌信息"
+// @Success      200 {object} FeedTokenWithURLs
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /feed/tokens [post]
+func (h *SyndicationHandler) CreateToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req CreateFeedTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if len([]rune(name)) > maxFeedTokenNameLength {
+		response.BadRequest(c, "name must be at most 100 characters")
+		return
+	}
+
+	ctx := c.Request.Context()
+	address := strings.ToLower(strings.TrimSpace(req.Address))
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			response.BadRequest(c, "invalid address")
+			return
+		}
+		ids, err := h.watchedAddrRepo.ListAccessibleIDsByAddress(ctx, userID, address)
+		if err != nil {
+			h.logger.Error("Failed to find watched address", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+		if len(ids) == 0 {
+			response.NotFound(c, "address not watched")
+			return
+		}
+	}
+
+	count, err := h.feedRepo.CountTokens(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count feed tokens", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if count >= maxFeedTokensPerUser {
+		response.Error(c, http.StatusConflict, 409, "too many feed tokens")
+		return
+	}
+
+	plain, hash, err := syndication.GenerateToken()
+	if err != nil {
+		h.logger.Error("Failed to generate feed token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	token := &models.FeedToken{UserID: userID, Name: name, Address: address, TokenHash: hash}
+	if err := h.feedRepo.CreateToken(ctx, token); err != nil {
+		h.logger.Error("Failed to create feed token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, FeedTokenWithURLs{
+		FeedToken: *token,
+		Token:     plain,
+		AtomURL:   h.feedURL("atom", plain),
+		RSSURL:    h.feedURL("rss", plain),
+	})
+}
+
+// DeleteToken 吊销订阅令牌
+// @Summary      吊销 Atom / RSS 订阅令牌
+// @Description  吊销后使用该令牌的订阅地址立即失效，不影响其他令牌与登录状态
+// @Tags         feed
+// @Security     BearerAuth
+// @Param        id path int true "令牌 ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /feed/tokens/{id} [delete]
+func (h *SyndicationHandler) DeleteToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.feedRepo.DeleteToken(c.Request.Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "feed token not found")
+			return
+		}
+		h.logger.Error("Failed to delete feed token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "feed token revoked", nil)
+}
+
+// Atom 以 Atom 1.0 输出最近的 feed 条目
+// @Summary      Atom 订阅
+// @Description  使用订阅令牌认证（无需 JWT），输出最近 50 条 feed 条目；支持 If-None-Match / If-Modified-Since 条件请求
+// @Tags         feed
+// @Produce      application/atom+xml
+// @Param        token query string true "订阅令牌"
+// @Success      200 {string} string
+// @Success      304 {string} string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /syndication/atom [get]
+func (h *SyndicationHandler) Atom(c *gin.Context) {
+	h.serve(c, "atom", syndication.ContentTypeAtom, syndication.RenderAtom)
+}
+
+// RSS 以 RSS 2.0 输出最近的 feed 条目
+// @Summary      RSS 订阅
+// @Description  与 GET /syndication/atom 相同，输出 RSS 2.0
+// @Tags         feed
+// @Produce      application/rss+xml
+// @Param        token query string true "订阅令牌"
+// @Success      200 {string} string
+// @Success      304 {string} string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /syndication/rss [get]
+func (h *SyndicationHandler) RSS(c *gin.Context) {
+	h.serve(c, "rss", syndication.ContentTypeRSS, syndication.RenderRSS)
+}
+
+func (h *SyndicationHandler) serve(c *gin.Context, kind, contentType string, render func(*syndication.Feed) ([]byte, error)) {
+	plain := c.Query("token")
+	if plain == "" {
+		response.Unauthorized(c, "missing feed token")
+		return
+	}
+
+	ctx := c.Request.Context()
+	token, err := h.feedRepo.GetTokenByHash(ctx, syndication.HashToken(plain))
+	if err != nil {
+		h.logger.Error("Failed to get feed token", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if token == nil {
+		response.Unauthorized(c, "invalid feed token")
+		return
+	}
+
+	filter := &models.FeedFilter{}
+	feedID := fmt.Sprintf("urn:chainfeed:feed:user:%d", token.UserID)
+	title := "ChainFeed"
+	if token.Address != "" {
+		ids, err := h.watchedAddrRepo.ListAccessibleIDsByAddress(ctx, token.UserID, token.Address)
+		if err != nil {
+			h.logger.Error("Failed to find watched address", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+		if len(ids) == 0 {
+			response.NotFound(c, "address not watched")
+			return
+		}
+		filter.WatchedAddressIDs = ids
+		feedID = fmt.Sprintf("urn:chainfeed:feed:user:%d:address:%s", token.UserID, token.Address)
+	}
+	if token.Name != "" {
+		title += ": " + token.Name
+	}
+
+	items, _, err := h.feedRepo.ListUserFeed(ctx, token.UserID, filter, &pagination.Query{Limit: syndicationItemLimit})
+	if err != nil {
+		h.logger.Error("Failed to get feed", zap.Int64("user_id", token.UserID), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if err := h.feedRepo.TouchToken(ctx, token.ID); err != nil {
+		h.logger.Warn("Failed to update feed token usage", zap.Int64("feed_token_id", token.ID), zap.Error(err))
+	}
+
+	lastModified := syndication.LastModified(items, token.CreatedAt)
+	body, err := render(&syndication.Feed{
+		ID:       feedID,
+		Title:    title,
+		SelfURL:  h.feedURL(kind, plain),
+		SiteURL:  h.publicURL,
+		Explorer: h.explorer,
+		Updated:  lastModified,
+		Items:    items,
+	})
+	if err != nil {
+		h.logger.Error("Failed to render feed", zap.String("kind", kind), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	etag := syndication.ETag(body)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", syndicationCacheControl)
+	if syndication.NotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func (h *SyndicationHandler) feedURL(kind, token string) string {
+	return h.publicURL + "/api/v1/syndication/" + kind + "?token=" + url.QueryEscape(token)
+}
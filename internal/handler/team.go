@@ -0,0 +1,624 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const maxTeamNameLength = 100
+
+type TeamHandler struct {
+	teamRepo *repository.TeamRepository
+	logger   *zap.Logger
+}
+
+func NewTeamHandler(teamRepo *repository.TeamRepository, logger *zap.Logger) *TeamHandler {
+	return &TeamHandler{
+		teamRepo: teamRepo,
+		logger:   logger,
+	}
+}
+
+// roleRank 角色权限等级，数值越大权限越高
+var roleRank = map[string]int{
+	models.TeamRoleViewer: 1,
+	models.TeamRoleEditor: 2,
+	models.TeamRoleOwner:  3,
+}
+
+func roleAtLeast(role, required string) bool {
+	return roleRank[role] >= roleRank[required]
+}
+
+// teamAccess 当前用户对路径中团队的访问信息
+type teamAccess struct {
+	UserID int64
+	TeamID int64
+	Role   string
+}
+
+// requireTeamRole 解析路径中的团队 ID 并校验当前用户角色，失败时直接写入响应
+func requireTeamRole(c *gin.Context, teamRepo *repository.TeamRepository, logger *zap.Logger, required string) (*teamAccess, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	teamID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid team id")
+		return nil, false
+	}
+
+	role, err := teamRepo.GetMemberRole(c.Request.Context(), teamID, userID)
+	if err != nil {
+		logger.Error("Failed to get team role", zap.Error(err), zap.Int64("team_id", teamID))
+		response.InternalServerError(c, "internal server error")
+		return nil, false
+	}
+
+	// 非成员统一返回 404，避免泄露团队是否存在
+	if role == "" {
+		response.NotFound(c, "team not found")
+		return nil, false
+	}
+
+	if !roleAtLeast(role, required) {
+		response.Forbidden(c, "insufficient team role")
+		return nil, false
+	}
+
+	return &teamAccess{UserID: userID, TeamID: teamID, Role: role}, true
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// Create 创建团队
+// @Summary      创建团队
+// @Description  创建团队，创建者自动成为 owner
+// @Tags         团队
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateTeamRequest true "团队信息"
+// @Success      200 {object} models.Team
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /teams [post]
+func (h *TeamHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxTeamNameLength {
+		response.BadRequest(c, "team name must be 1-100 characters")
+		return
+	}
+
+	team := &models.Team{
+		Name:    name,
+		OwnerID: userID,
+	}
+	if err := h.teamRepo.Create(context.Background(), team); err != nil {
+		h.logger.Error("Failed to create team", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, team)
+}
+
+// List 获取当前用户所在团队
+// @Summary      获取团队列表
+// @Description  获取当前用户所在的所有团队及其角色
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} repository.TeamWithRole
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /teams [get]
+func (h *TeamHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	teams, err := h.teamRepo.ListByUser(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list teams", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, teams)
+}
+
+type TeamDetailResponse struct {
+	Team    *models.Team                  `json:"team"`
+	Members []repository.TeamMemberDetail `json:"members"`
+}
+
+// Get 获取团队详情
+// @Summary      获取团队详情
+// @Description  获取团队信息及成员列表（成员可见）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Success      200 {object} TeamDetailResponse
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /teams/{id} [get]
+func (h *TeamHandler) Get(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleViewer)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	team, err := h.teamRepo.GetByID(ctx, access.TeamID)
+	if err != nil {
+		h.logger.Error("Failed to get team", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if team == nil {
+		response.NotFound(c, "team not found")
+		return
+	}
+
+	members, err := h.teamRepo.ListMembers(ctx, access.TeamID)
+	if err != nil {
+		h.logger.Error("Failed to list team members", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, TeamDetailResponse{
+		Team:    team,
+		Members: members,
+	})
+}
+
+// Update 重命名团队
+// @Summary      重命名团队
+// @Description  修改团队名称（仅 owner）
+// @Tags         团队
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        request body CreateTeamRequest true "团队信息"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id} [patch]
+func (h *TeamHandler) Update(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleOwner)
+	if !ok {
+		return
+	}
+
+	var req CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxTeamNameLength {
+		response.BadRequest(c, "team name must be 1-100 characters")
+		return
+	}
+
+	if err := h.teamRepo.Rename(context.Background(), access.TeamID, name); err != nil {
+		h.logger.Error("Failed to rename team", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "team updated", nil)
+}
+
+// Delete 删除团队
+// @Summary      删除团队
+// @Description  删除团队及其监控地址（仅 owner）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Success      200 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id} [delete]
+func (h *TeamHandler) Delete(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := h.teamRepo.Delete(context.Background(), access.TeamID); err != nil {
+		h.logger.Error("Failed to delete team", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "team deleted", nil)
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateMember 修改成员角色
+// @Summary      修改成员角色
+// @Description  将成员设置为 editor 或 viewer（仅 owner）
+// @Tags         团队
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        user_id path int true "成员用户 ID"
+// @Param        request body UpdateMemberRequest true "角色"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/members/{user_id} [patch]
+func (h *TeamHandler) UpdateMember(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleOwner)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user id")
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Role != models.TeamRoleEditor && req.Role != models.TeamRoleViewer {
+		response.BadRequest(c, "role must be editor or viewer")
+		return
+	}
+
+	if memberID == access.UserID {
+		response.BadRequest(c, "owner role cannot be changed")
+		return
+	}
+
+	if err := h.teamRepo.UpdateMemberRole(context.Background(), access.TeamID, memberID, req.Role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "member not found")
+			return
+		}
+		h.logger.Error("Failed to update member role", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "member updated", nil)
+}
+
+// RemoveMember 移除成员或退出团队
+// @Summary      移除成员
+// @Description  owner 可移除其他成员，成员可移除自己（退出团队）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        user_id path int true "成员用户 ID"
+// @Success      200 {object} map[string]string
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/members/{user_id} [delete]
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleViewer)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid user id")
+		return
+	}
+
+	if memberID != access.UserID && access.Role != models.TeamRoleOwner {
+		response.Forbidden(c, "insufficient team role")
+		return
+	}
+
+	// owner 不能退出，只能删除团队
+	if memberID == access.UserID && access.Role == models.TeamRoleOwner {
+		response.BadRequest(c, "owner cannot leave the team")
+		return
+	}
+
+	if err := h.teamRepo.RemoveMember(context.Background(), access.TeamID, memberID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "member not found")
+			return
+		}
+		h.logger.Error("Failed to remove team member", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "member removed", nil)
+}
+
+type CreateInviteRequest struct {
+	WalletAddress string `json:"wallet_address" binding:"required"`
+	Role          string `json:"role"`
+}
+
+// CreateInvite 按钱包地址邀请成员
+// @Summary      邀请成员
+// @Description  按钱包地址邀请成员加入团队（仅 owner），角色默认为 viewer
+// @Tags         团队
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        request body CreateInviteRequest true "邀请信息"
+// @Success      200 {object} models.TeamInvite
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/invites [post]
+func (h *TeamHandler) CreateInvite(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleOwner)
+	if !ok {
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if !common.IsHexAddress(req.WalletAddress) {
+		response.BadRequest(c, "invalid wallet address")
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.TeamRoleViewer
+	}
+	if role != models.TeamRoleEditor && role != models.TeamRoleViewer {
+		response.BadRequest(c, "role must be editor or viewer")
+		return
+	}
+
+	invite := &models.TeamInvite{
+		TeamID:        access.TeamID,
+		WalletAddress: common.HexToAddress(req.WalletAddress).Hex(),
+		Role:          role,
+		InvitedBy:     access.UserID,
+	}
+	if err := h.teamRepo.CreateInvite(context.Background(), invite); err != nil {
+		h.logger.Error("Failed to create team invite", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, invite)
+}
+
+// ListInvites 获取团队待处理邀请
+// @Summary      获取团队邀请
+// @Description  获取团队的待处理邀请（仅 owner）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Success      200 {array} models.TeamInvite
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/invites [get]
+func (h *TeamHandler) ListInvites(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleOwner)
+	if !ok {
+		return
+	}
+
+	invites, err := h.teamRepo.ListInvites(context.Background(), access.TeamID)
+	if err != nil {
+		h.logger.Error("Failed to list team invites", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, invites)
+}
+
+// RevokeInvite 撤回邀请
+// @Summary      撤回邀请
+// @Description  撤回待处理的邀请（仅 owner）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        invite_id path int true "邀请 ID"
+// @Success      200 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/invites/{invite_id} [delete]
+func (h *TeamHandler) RevokeInvite(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleOwner)
+	if !ok {
+		return
+	}
+
+	inviteID, err := strconv.ParseInt(c.Param("invite_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid invite id")
+		return
+	}
+
+	if err := h.teamRepo.DeleteInvite(context.Background(), access.TeamID, inviteID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "invite not found")
+			return
+		}
+		h.logger.Error("Failed to revoke team invite", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "invite revoked", nil)
+}
+
+// MyInvites 获取发给当前钱包的邀请
+// @Summary      我的邀请
+// @Description  获取发给当前登录钱包的待处理团队邀请
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} repository.TeamInviteDetail
+// @Failure      401 {object} map[string]string
+// @Router       /invites [get]
+func (h *TeamHandler) MyInvites(c *gin.Context) {
+	walletAddress, ok := middleware.GetWalletAddress(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	// 邀请按 checksum 地址存储，直接比较以使用索引
+	invites, err := h.teamRepo.ListPendingByWallet(context.Background(), common.HexToAddress(walletAddress).Hex())
+	if err != nil {
+		h.logger.Error("Failed to list invites", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, invites)
+}
+
+// AcceptInvite 接受邀请
+// @Summary      接受邀请
+// @Description  接受发给当前钱包的团队邀请
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        invite_id path int true "邀请 ID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /invites/{invite_id}/accept [post]
+func (h *TeamHandler) AcceptInvite(c *gin.Context) {
+	userID, invite, ok := h.loadOwnInvite(c)
+	if !ok {
+		return
+	}
+
+	if err := h.teamRepo.AcceptInvite(context.Background(), invite, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "invite not found")
+			return
+		}
+		h.logger.Error("Failed to accept invite", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "invite accepted", nil)
+}
+
+// DeclineInvite 拒绝邀请
+// @Summary      拒绝邀请
+// @Description  拒绝发给当前钱包的团队邀请
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        invite_id path int true "邀请 ID"
+// @Success      200 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /invites/{invite_id}/decline [post]
+func (h *TeamHandler) DeclineInvite(c *gin.Context) {
+	_, invite, ok := h.loadOwnInvite(c)
+	if !ok {
+		return
+	}
+
+	if err := h.teamRepo.DeclineInvite(context.Background(), invite.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "invite not found")
+			return
+		}
+		h.logger.Error("Failed to decline invite", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "invite declined", nil)
+}
+
+// loadOwnInvite 加载发给当前钱包的待处理邀请
+func (h *TeamHandler) loadOwnInvite(c *gin.Context) (int64, *models.TeamInvite, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return 0, nil, false
+	}
+	walletAddress, _ := middleware.GetWalletAddress(c)
+
+	inviteID, err := strconv.ParseInt(c.Param("invite_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid invite id")
+		return 0, nil, false
+	}
+
+	invite, err := h.teamRepo.GetInvite(context.Background(), inviteID)
+	if err != nil {
+		h.logger.Error("Failed to get invite", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return 0, nil, false
+	}
+
+	if invite == nil || invite.Status != models.InviteStatusPending ||
+		!strings.EqualFold(invite.WalletAddress, walletAddress) {
+		response.NotFound(c, "invite not found")
+		return 0, nil, false
+	}
+
+	return userID, invite, true
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+// ListTeam 获取团队监控地址
+// @Summary      获取团队监控地址
+// @Description  获取团队共享的监控地址（成员可见）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Success      200 {array} models.WatchedAddress
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/addresses [get]
+func (h *WatchedAddressHandler) ListTeam(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleViewer)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.repo.GetByTeamID(context.Background(), access.TeamID)
+	if err != nil {
+		h.logger.Error("Failed to get team watched addresses", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, addresses)
+}
+
+// AddTeam 添加团队监控地址
+// @Summary      添加团队监控地址
+// @Description  添加团队共享的监控地址（editor 及以上），新交易推送给所有成员
+// @Tags         团队
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        request body AddWatchedAddressRequest true "地址信息"
+// @Success      200 {object} models.WatchedAddress
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Router       /teams/{id}/addresses [post]
+func (h *WatchedAddressHandler) AddTeam(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleEditor)
+	if !ok {
+		return
+	}
+
+	var req AddWatchedAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	watchedAddr, err := h.prepareWatchedAddress(ctx, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	teamID := access.TeamID
+	watchedAddr.UserID = access.UserID
+	watchedAddr.TeamID = &teamID
+
+	exists, err := h.repo.ExistsInTeam(ctx, access.TeamID, watchedAddr.Kind, watchedAddr.Address)
+	if err != nil {
+		h.logger.Error("Failed to check address existence", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if exists {
+		response.Error(c, http.StatusConflict, 409, "address already watched")
+		return
+	}
+
+	if err := h.repo.Create(ctx, watchedAddr); err != nil {
+		h.logger.Error("Failed to create team watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	// 为所有成员回填历史交易
+	if watchedAddr.Kind == models.WatchKindWallet {
+		memberIDs, err := h.teamRepo.GetMemberUserIDs(ctx, access.TeamID)
+		if err != nil {
+			h.logger.Error("Failed to get team members", zap.Error(err))
+		} else {
+			h.backfill.Enqueue(memberIDs, watchedAddr)
+		}
+	}
+
+	response.Success(c, watchedAddr)
+}
+
+// RemoveTeam 删除团队监控地址
+// @Summary      删除团队监控地址
+// @Description  删除团队共享的监控地址（editor 及以上）
+// @Tags         团队
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "团队 ID"
+// @Param        address_id path int true "地址 ID"
+// @Success      200 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /teams/{id}/addresses/{address_id} [delete]
+func (h *WatchedAddressHandler) RemoveTeam(c *gin.Context) {
+	access, ok := requireTeamRole(c, h.teamRepo, h.logger, models.TeamRoleEditor)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("address_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.repo.DeleteFromTeam(context.Background(), id, access.TeamID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "address not found")
+			return
+		}
+		h.logger.Error("Failed to delete team watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "address removed", nil)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	teamOwnerID   int64 = 1
+	teamEditorID  int64 = 2
+	teamViewerID  int64 = 3
+	teamOutsideID int64 = 4
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	assert.True(t, roleAtLeast(models.TeamRoleOwner, models.TeamRoleEditor))
+	assert.True(t, roleAtLeast(models.TeamRoleEditor, models.TeamRoleEditor))
+	assert.False(t, roleAtLeast(models.TeamRoleViewer, models.TeamRoleEditor))
+	assert.False(t, roleAtLeast(models.TeamRoleEditor, models.TeamRoleOwner))
+	assert.False(t, roleAtLeast("", models.TeamRoleViewer))
+}
+
+func TestTeamRoleChecks(t *testing.T) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			wallet_address TEXT NOT NULL UNIQUE
+		);
+		CREATE TABLE teams (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			owner_id INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE team_members (
+			team_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (team_id, user_id)
+		);
+		CREATE TABLE team_invites (
+			id INTEGER PRIMARY KEY,
+			team_id INTEGER NOT NULL,
+			wallet_address TEXT NOT NULL,
+			role TEXT NOT NULL,
+			invited_by INTEGER NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE watched_addresses (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			team_id INTEGER,
+			kind TEXT NOT NULL DEFAULT 'wallet',
+			address TEXT NOT NULL,
+			min_amount TEXT,
+			label TEXT NOT NULL DEFAULT '',
+			ens_name TEXT NOT NULL DEFAULT '',
+			notes TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT '{}',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE feed_items (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			watched_address_id INTEGER
+		);
+		CREATE TABLE feed_item_addresses (
+			feed_item_id INTEGER NOT NULL,
+			watched_address_id INTEGER NOT NULL
+		);
+
+		INSERT INTO users (id, wallet_address) VALUES
+			(1, '0x0000000000000000000000000000000000000001'),
+			(2, '0x0000000000000000000000000000000000000002'),
+			(3, '0x0000000000000000000000000000000000000003'),
+			(4, '0x0000000000000000000000000000000000000004');
+		INSERT INTO teams (id, name, owner_id) VALUES (1, 'desk', 1);
+		INSERT INTO team_members (team_id, user_id, role) VALUES
+			(1, 1, 'owner'), (1, 2, 'editor'), (1, 3, 'viewer');
+		INSERT INTO team_invites (id, team_id, wallet_address, role, invited_by)
+			VALUES (1, 1, '0x0000000000000000000000000000000000000005', 'viewer', 1);
+		INSERT INTO watched_addresses (id, user_id, team_id, address) VALUES
+			(1, 2, 1, '0xd8da6bf26964af9d7eed9e03e53415d37aa96045'),
+			(2, 2, 1, '0x742d35cc6634c0532925a3b8d4c9db96c4b4d8b6');
+	`)
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	teamRepo := repository.NewTeamRepository(db)
+	teamHandler := NewTeamHandler(teamRepo, logger)
+	addrHandler := NewWatchedAddressHandler(repository.NewWatchedAddressRepository(db), teamRepo,
+		nil, nil, nil, nil, nil, nil, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		userID, _ := strconv.ParseInt(c.GetHeader("X-User-ID"), 10, 64)
+		c.Set("user_id", userID)
+	})
+	router.PATCH("/teams/:id/members/:user_id", teamHandler.UpdateMember)
+	router.DELETE("/teams/:id/members/:user_id", teamHandler.RemoveMember)
+	router.POST("/teams/:id/invites", teamHandler.CreateInvite)
+	router.GET("/teams/:id/invites", teamHandler.ListInvites)
+	router.DELETE("/teams/:id/invites/:invite_id", teamHandler.RevokeInvite)
+	router.POST("/teams/:id/addresses", addrHandler.AddTeam)
+	router.DELETE("/teams/:id/addresses/:address_id", addrHandler.RemoveTeam)
+	router.PATCH("/addresses/:id", addrHandler.Update)
+
+	do := func(userID int64, method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", strconv.FormatInt(userID, 10))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	const (
+		invite  = `{"wallet_address": "0x0000000000000000000000000000000000000006"}`
+		address = `{"address": "0x0000000000000000000000000000000000000007"}`
+	)
+
+	tests := []struct {
+		name   string
+		userID int64
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"Viewer cannot change member role", teamViewerID, http.MethodPatch, "/teams/1/members/2", `{"role": "viewer"}`, http.StatusForbidden},
+		{"Editor cannot change member role", teamEditorID, http.MethodPatch, "/teams/1/members/3", `{"role": "editor"}`, http.StatusForbidden},
+		{"Non-member cannot change member role", teamOutsideID, http.MethodPatch, "/teams/1/members/3", `{"role": "editor"}`, http.StatusNotFound},
+		{"Owner changes member role", teamOwnerID, http.MethodPatch, "/teams/1/members/3", `{"role": "viewer"}`, http.StatusOK},
+		{"Viewer cannot remove another member", teamViewerID, http.MethodDelete, "/teams/1/members/2", "", http.StatusForbidden},
+		{"Non-member cannot remove member", teamOutsideID, http.MethodDelete, "/teams/1/members/3", "", http.StatusNotFound},
+
+		{"Viewer cannot invite", teamViewerID, http.MethodPost, "/teams/1/invites", invite, http.StatusForbidden},
+		{"Editor cannot invite", teamEditorID, http.MethodPost, "/teams/1/invites", invite, http.StatusForbidden},
+		{"Non-member cannot invite", teamOutsideID, http.MethodPost, "/teams/1/invites", invite, http.StatusNotFound},
+		{"Viewer cannot list invites", teamViewerID, http.MethodGet, "/teams/1/invites", "", http.StatusForbidden},
+		{"Viewer cannot revoke invite", teamViewerID, http.MethodDelete, "/teams/1/invites/1", "", http.StatusForbidden},
+		{"Non-member cannot revoke invite", teamOutsideID, http.MethodDelete, "/teams/1/invites/1", "", http.StatusNotFound},
+
+		{"Viewer cannot add team address", teamViewerID, http.MethodPost, "/teams/1/addresses", address, http.StatusForbidden},
+		{"Non-member cannot add team address", teamOutsideID, http.MethodPost, "/teams/1/addresses", address, http.StatusNotFound},
+		{"Viewer cannot edit team address", teamViewerID, http.MethodPatch, "/addresses/1", `{"label": "x"}`, http.StatusForbidden},
+		{"Non-member cannot edit team address", teamOutsideID, http.MethodPatch, "/addresses/1", `{"label": "x"}`, http.StatusNotFound},
+		{"Viewer cannot remove team address", teamViewerID, http.MethodDelete, "/teams/1/addresses/1", "", http.StatusForbidden},
+		{"Non-member cannot remove team address", teamOutsideID, http.MethodDelete, "/teams/1/addresses/1", "", http.StatusNotFound},
+		{"Editor removes team address", teamEditorID, http.MethodDelete, "/teams/1/addresses/2", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, do(tt.userID, tt.method, tt.path, tt.body))
+		})
+	}
+
+	// 被拒绝的请求不应修改数据
+	var role string
+	require.NoError(t, db.Get(&role, `SELECT role FROM team_members WHERE team_id = 1 AND user_id = 2`))
+	assert.Equal(t, models.TeamRoleEditor, role)
+	var invites, addresses int
+	require.NoError(t, db.Get(&invites, `SELECT COUNT(*) FROM team_invites`))
+	require.NoError(t, db.Get(&addresses, `SELECT COUNT(*) FROM watched_addresses`))
+	assert.Equal(t, 1, invites)
+	assert.Equal(t, 1, addresses)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+type TokenHandler struct {
+	tokenRepo *repository.TokenRepository
+	registry  *tokens.Registry // 为 nil 时只返回已保存的代币
+	logger    *zap.Logger
+}
+
+func NewTokenHandler(tokenRepo *repository.TokenRepository, registry *tokens.Registry, logger *zap.Logger) *TokenHandler {
+	return &TokenHandler{
+		tokenRepo: tokenRepo,
+		registry:  registry,
+		logger:    logger,
+	}
+}
+
+// Get 获取代币元数据
+// @Summary      获取代币元数据
+// @Description  返回代币的 name、symbol、decimals（链上查询）以及 logo 与认证状态（代币列表）；未收录的代币会即时查询链上数据
+// @Tags         代币
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        address path string true "代币合约地址"
+// @Success      200 {object} models.Token
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /tokens/{address} [get]
+func (h *TokenHandler) Get(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		response.BadRequest(c, "invalid address")
+		return
+	}
+	address = strings.ToLower(address)
+
+	var token *models.Token
+	var err error
+	if h.registry != nil {
+		token, err = h.registry.Get(c.Request.Context(), address)
+	} else {
+		token, err = h.tokenRepo.GetByAddress(c.Request.Context(), address)
+	}
+	if err != nil {
+		h.logger.Error("Failed to get token", zap.String("address", address), zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if token == nil {
+		response.NotFound(c, "token not found")
+		return
+	}
+
+	response.Success(c, token)
+}
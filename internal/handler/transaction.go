@@ -7,8 +7,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/bwmspring/chainfeed-go/internal/decoder"
 	"github.com/bwmspring/chainfeed-go/internal/middleware"
 	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
 	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/response"
 )
@@ -16,41 +18,51 @@ import (
 type TransactionHandler struct {
 	txRepo          *repository.TransactionRepository
 	watchedAddrRepo *repository.WatchedAddressRepository
+	abis            *decoder.Uploaded // 按用户上传的 ABI 解码合约调用
 	logger          *zap.Logger
 }
 
 func NewTransactionHandler(
 	txRepo *repository.TransactionRepository,
 	watchedAddrRepo *repository.WatchedAddressRepository,
+	abis *decoder.Uploaded,
 	logger *zap.Logger,
 ) *TransactionHandler {
 	return &TransactionHandler{
 		txRepo:          txRepo,
 		watchedAddrRepo: watchedAddrRepo,
+		abis:            abis,
 		logger:          logger,
 	}
 }
 
 type TransactionListResponse struct {
 	Transactions []TransactionWithAddress `json:"transactions"`
-	TotalCount   int                      `json:"total_count"`
-	Page         int                      `json:"page"`
-	PageSize     int                      `json:"page_size"`
+	pagination.Page
 }
 
 type TransactionWithAddress struct {
-	ID             int64  `json:"id"`
-	TxHash         string `json:"tx_hash"`
-	BlockNumber    int64  `json:"block_number"`
-	BlockTimestamp string `json:"block_timestamp"`
-	FromAddress    string `json:"from_address"`
-	ToAddress      string `json:"to_address"`
-	Value          string `json:"value"`
-	TxType         string `json:"tx_type"`
-	TokenAddress   string `json:"token_address,omitempty"`
-	TokenID        string `json:"token_id,omitempty"`
-	TokenSymbol    string `json:"token_symbol,omitempty"`
-	TokenDecimals  int    `json:"token_decimals,omitempty"`
+	ID             int64            `json:"id"`
+	TxHash         string           `json:"tx_hash"`
+	BlockNumber    int64            `json:"block_number"`
+	BlockTimestamp string           `json:"block_timestamp"`
+	FromAddress    string           `json:"from_address"`
+	ToAddress      string           `json:"to_address"`
+	Value          string           `json:"value"`
+	TxType         string           `json:"tx_type"`
+	TokenAddress   string           `json:"token_address,omitempty"`
+	TokenID        string           `json:"token_id,omitempty"`
+	TokenSymbol    string           `json:"token_symbol,omitempty"`
+	TokenDecimals  int              `json:"token_decimals,omitempty"`
+	SpamReason     string           `json:"spam_reason,omitempty"`
+	Action         *models.Action   `json:"action,omitempty"`
+	Swap           *models.Swap     `json:"swap,omitempty"`
+	Approval       *models.Approval `json:"approval,omitempty"`
+	Status         string           `json:"status,omitempty"`
+	Nonce          *int64           `json:"nonce,omitempty"`
+	Fee            *string          `json:"fee,omitempty"`
+	Gas            *models.Gas      `json:"gas,omitempty"`
+	InputData      string           `json:"input_data,omitempty"`
 	WatchedAddress struct {
 		Address string `json:"address"`
 		Label   string `json:"label"`
@@ -60,14 +72,18 @@ type TransactionWithAddress struct {
 
 // GetByAddress 获取指定地址的交易列表
 // @Summary      获取地址交易
-// @Description  获取指定监控地址的交易列表
+// @Description  获取指定监控地址的交易列表，按区块时间倒序、游标分页：next_cursor 作为 cursor 加载更早的交易，prev_cursor 作为 since 轮询新交易
 // @Tags         交易
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        address path string true "以太坊地址"
-// @Param        page query int false "页码" default(1)
-// @Param        page_size query int false "每页数量" default(20)
+// @Param        limit query int false "每页数量（1-100）" default(20)
+// @Param        cursor query string false "返回早于该游标的交易（next_cursor）"
+// @Param        since query string false "返回晚于该游标的交易（prev_cursor）"
+// @Param        count query string false "返回 total_count：exact 精确计数或 estimate 估算"
+// @Param        include_spam query bool false "包含被判定为垃圾的交易"
+// @Param        exclude_reverted query bool false "排除执行失败的交易"
 // @Success      200 {object} TransactionListResponse
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
@@ -88,48 +104,55 @@ func (h *TransactionHandler) GetByAddress(c *gin.Context) {
 	}
 	address = common.HexToAddress(address).Hex()
 
-	// 验证用户是否监控了该地址
-	watchedAddrs, err := h.watchedAddrRepo.FindByAddress(address)
+	// 验证用户是否监控了该地址（个人或所在团队）
+	watchedAddr, err := h.watchedAddrRepo.GetAccessibleByAddress(c.Request.Context(), userID, address)
 	if err != nil {
 		h.logger.Error("Failed to find watched address", zap.Error(err))
 		response.InternalServerError(c, "internal server error")
 		return
 	}
 
-	var watchedAddr *models.WatchedAddress
-	for _, wa := range watchedAddrs {
-		if wa.UserID == userID {
-			watchedAddr = &wa
-			break
-		}
-	}
-
 	if watchedAddr == nil {
 		response.NotFound(c, "address not watched")
 		return
 	}
 
 	// 分页参数
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	if page < 1 {
-		page = 1
+	q, err := pagination.Parse(c.Query("limit"), c.Query("cursor"), c.Query("since"), c.Query("count"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	includeSpam := false
+	if raw := c.Query("include_spam"); raw != "" {
+		if includeSpam, err = strconv.ParseBool(raw); err != nil {
+			response.BadRequest(c, "invalid include_spam")
+			return
+		}
+	}
+	excludeReverted := false
+	if raw := c.Query("exclude_reverted"); raw != "" {
+		if excludeReverted, err = strconv.ParseBool(raw); err != nil {
+			response.BadRequest(c, "invalid exclude_reverted")
+			return
+		}
 	}
-
-	offset := (page - 1) * pageSize
 
 	// 查询交易
-	txs, err := h.txRepo.GetByAddress(address, pageSize, offset)
+	ctx := c.Request.Context()
+	txs, hasMore, err := h.txRepo.ListByAddress(ctx, address, includeSpam, excludeReverted, q)
 	if err != nil {
 		h.logger.Error("Failed to get transactions", zap.Error(err))
 		response.InternalServerError(c, "internal server error")
 		return
 	}
 
+	decoded := make([]*models.Transaction, len(txs))
+	for i := range txs {
+		decoded[i] = &txs[i]
+	}
+	h.abis.Apply(ctx, userID, decoded)
+
 	// 转换为响应格式
 	result := make([]TransactionWithAddress, len(txs))
 	for i, tx := range txs {
@@ -146,16 +169,44 @@ func (h *TransactionHandler) GetByAddress(c *gin.Context) {
 			TokenID:        tx.TokenID,
 			TokenSymbol:    tx.TokenSymbol,
 			TokenDecimals:  tx.TokenDecimals,
+			SpamReason:     tx.SpamReason,
+			Action:         tx.Action,
+			Swap:           tx.Swap,
+			Approval:       tx.Approval,
+			Status:         tx.Status,
+			Nonce:          tx.Nonce,
+			Fee:            tx.Fee,
+			Gas:            tx.Gas,
+			InputData:      tx.InputData,
 		}
 		result[i].WatchedAddress.Address = watchedAddr.Address
 		result[i].WatchedAddress.Label = watchedAddr.Label
 		result[i].WatchedAddress.ENSName = watchedAddr.ENSName
 	}
 
+	keys := make([]pagination.Cursor, len(txs))
+	stored := make([]pagination.Cursor, len(txs))
+	for i, tx := range txs {
+		keys[i] = pagination.Cursor{Time: tx.BlockTimestamp, ID: tx.ID}
+		stored[i] = pagination.Cursor{Time: tx.CreatedAt, ID: tx.ID}
+	}
+	page := pagination.NewPage(q, keys, hasMore)
+	// 轮询按入库顺序进行，prev_cursor 取本页最晚入库的交易
+	page.PrevCursor = pagination.PollCursor(q, stored)
+
+	if q.Count != pagination.CountNone {
+		total, estimated, err := h.txRepo.CountByAddress(ctx, address, includeSpam, excludeReverted, q.Count)
+		if err != nil {
+			h.logger.Error("Failed to count transactions", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+		page.TotalCount = &total
+		page.CountEstimated = estimated
+	}
+
 	response.Success(c, TransactionListResponse{
 		Transactions: result,
-		TotalCount:   len(result),
 		Page:         page,
-		PageSize:     pageSize,
 	})
 }
@@ -3,8 +3,14 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
@@ -13,6 +19,7 @@ import (
 
 	"github.com/bwmspring/chainfeed-go/internal/middleware"
 	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pricing"
 	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/response"
 	"github.com/bwmspring/chainfeed-go/internal/service"
@@ -21,41 +28,53 @@ import (
 
 type WatchedAddressHandler struct {
 	repo           *repository.WatchedAddressRepository
+	teamRepo       *repository.TeamRepository
 	ensService     *service.ENSService
 	alchemyService *service.AlchemyService
 	txRepo         *repository.TransactionRepository
 	feedRepo       *repository.FeedRepository
+	pricer         *pricing.Service
 	redis          *redis.Client
 	logger         *zap.Logger
+	backfill       *backfillQueue
 }
 
 func NewWatchedAddressHandler(
 	repo *repository.WatchedAddressRepository,
+	teamRepo *repository.TeamRepository,
 	ensService *service.ENSService,
 	alchemyService *service.AlchemyService,
 	txRepo *repository.TransactionRepository,
 	feedRepo *repository.FeedRepository,
+	pricer *pricing.Service,
 	redis *redis.Client,
 	logger *zap.Logger,
 ) *WatchedAddressHandler {
-	return &WatchedAddressHandler{
+	h := &WatchedAddressHandler{
 		repo:           repo,
+		teamRepo:       teamRepo,
 		ensService:     ensService,
 		alchemyService: alchemyService,
 		txRepo:         txRepo,
 		feedRepo:       feedRepo,
+		pricer:         pricer,
 		redis:          redis,
 		logger:         logger,
 	}
+	h.backfill = newBackfillQueue(logger, func(ctx context.Context, job backfillJob) {
+		h.fetchAndStoreTransactions(ctx, job.recipients, job.watchedAddr)
+	})
+	return h
 }
 
 // List 获取用户的监控地址列表
 // @Summary      获取监控地址列表
-// @Description  获取当前用户的所有监控地址
+// @Description  获取当前用户的所有监控地址，可按 tag 过滤（多个 tag 需同时命中）
 // @Tags         监控地址
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        tag query []string false "标签过滤" collectionFormat(multi)
 // @Success      200 {object} map[string][]models.WatchedAddress
 // @Failure      401 {object} map[string]string
 // @Failure      500 {object} map[string]string
@@ -67,8 +86,14 @@ func (h *WatchedAddressHandler) List(c *gin.Context) {
 		return
 	}
 
+	tags, err := normalizeTags(c.QueryArray("tag"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
 	ctx := context.Background()
-	addresses, err := h.repo.GetByUserID(ctx, userID)
+	addresses, err := h.repo.GetByUserIDAndTags(ctx, userID, tags)
 	if err != nil {
 		h.logger.Error("Failed to get watched addresses", zap.Error(err))
 		response.InternalServerError(c, "internal server error")
@@ -79,13 +104,17 @@ func (h *WatchedAddressHandler) List(c *gin.Context) {
 }
 
 type AddWatchedAddressRequest struct {
-	Address string `json:"address" binding:"required"`
-	Label   string `json:"label"`
+	Address   string   `json:"address" binding:"required"`
+	Kind      string   `json:"kind"       enums:"wallet,token" default:"wallet"`
+	MinAmount string   `json:"min_amount" example:"1000000"`
+	Label     string   `json:"label"`
+	Notes     string   `json:"notes"`
+	Tags      []string `json:"tags"`
 }
 
 // Add 添加监控地址
 // @Summary      添加监控地址
-// @Description  添加新的监控地址（支持以太坊地址或 ENS 域名）
+// @Description  添加新的监控地址（支持以太坊地址或 ENS 域名）；kind=token 时监控代币合约的所有转账，可用 min_amount 过滤小额转账
 // @Tags         监控地址
 // @Accept       json
 // @Produce      json
@@ -112,37 +141,16 @@ func (h *WatchedAddressHandler) Add(c *gin.Context) {
 
 	ctx := context.Background()
 
-	// 处理 ENS 或地址
-	var address, ensName string
-	if common.IsHexAddress(req.Address) {
-		// 是以太坊地址
-		address = common.HexToAddress(req.Address).Hex()
-
-		// 尝试反向解析 ENS
-		if h.ensService != nil {
-			if name, err := h.ensService.ReverseResolve(ctx, address); err == nil && name != "" {
-				ensName = name
-			}
-		}
-	} else {
-		// 可能是 ENS 域名
-		if h.ensService == nil {
-			response.BadRequest(c, "ENS resolution not available")
-			return
-		}
-
-		resolvedAddr, err := h.ensService.Resolve(ctx, req.Address)
-		if err != nil {
-			h.logger.Warn("Failed to resolve ENS", zap.Error(err), zap.String("ens", req.Address))
-			response.BadRequest(c, "invalid address or ENS name")
-			return
-		}
-		address = resolvedAddr
-		ensName = req.Address
+	// 校验字段并处理 ENS 或地址
+	watchedAddr, err := h.prepareWatchedAddress(ctx, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
 	}
+	watchedAddr.UserID = userID
 
 	// 检查是否已存在
-	exists, err := h.repo.Exists(ctx, userID, address)
+	exists, err := h.repo.ExistsOfKind(ctx, userID, watchedAddr.Kind, watchedAddr.Address)
 	if err != nil {
 		h.logger.Error("Failed to check address existence", zap.Error(err))
 		response.InternalServerError(c, "internal server error")
@@ -154,28 +162,106 @@ func (h *WatchedAddressHandler) Add(c *gin.Context) {
 		return
 	}
 
-	// 创建监控地址
-	watchedAddr := &models.WatchedAddress{
-		UserID:  userID,
-		Address: address,
-		Label:   req.Label,
-		ENSName: ensName,
-	}
-
 	if err := h.repo.Create(ctx, watchedAddr); err != nil {
 		h.logger.Error("Failed to create watched address", zap.Error(err))
 		response.InternalServerError(c, "internal server error")
 		return
 	}
 
-	// 立即查询该地址的交易记录
-	go h.fetchAndStoreTransactions(context.Background(), userID, watchedAddr)
+	// 排队查询该地址的交易记录（代币合约监控只接收新转账）
+	if watchedAddr.Kind == models.WatchKindWallet {
+		h.backfill.Enqueue([]int64{userID}, watchedAddr)
+	}
 
 	response.Success(c, watchedAddr)
 }
 
-// fetchAndStoreTransactions 查询并存储交易记录
-func (h *WatchedAddressHandler) fetchAndStoreTransactions(ctx context.Context, userID int64, watchedAddr *models.WatchedAddress) {
+// prepareWatchedAddress 校验请求并解析地址，返回待创建的监控记录（未设置 UserID/TeamID）
+func (h *WatchedAddressHandler) prepareWatchedAddress(ctx context.Context, req *AddWatchedAddressRequest) (*models.WatchedAddress, error) {
+	kind := req.Kind
+	if kind == "" {
+		kind = models.WatchKindWallet
+	}
+	if kind != models.WatchKindWallet && kind != models.WatchKindToken {
+		return nil, errors.New("kind must be wallet or token")
+	}
+
+	var minAmount *string
+	if req.MinAmount != "" {
+		if kind != models.WatchKindToken {
+			return nil, errors.New("min_amount is only supported for token watches")
+		}
+		normalized, err := normalizeMinAmount(req.MinAmount)
+		if err != nil {
+			return nil, err
+		}
+		minAmount = &normalized
+	}
+
+	if err := validateLabelAndNotes(req.Label, req.Notes); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	address, ensName, err := h.resolveAddress(ctx, req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WatchedAddress{
+		Kind:      kind,
+		Address:   address,
+		MinAmount: minAmount,
+		Label:     strings.TrimSpace(req.Label),
+		ENSName:   ensName,
+		Notes:     req.Notes,
+		Tags:      tags,
+	}, nil
+}
+
+// normalizeMinAmount 校验最小转账数量（代币单位的非负十进制数）
+func normalizeMinAmount(raw string) (string, error) {
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(raw))
+	if !ok || amount.Sign() < 0 {
+		return "", errors.New("min_amount must be a non-negative decimal number")
+	}
+	return amount.FloatString(18), nil
+}
+
+// resolveAddress 将以太坊地址或 ENS 域名解析为校验和地址及 ENS 名称
+func (h *WatchedAddressHandler) resolveAddress(ctx context.Context, input string) (string, string, error) {
+	if common.IsHexAddress(input) {
+		// 是以太坊地址
+		address := common.HexToAddress(input).Hex()
+
+		// 尝试反向解析 ENS
+		var ensName string
+		if h.ensService != nil {
+			if name, err := h.ensService.ReverseResolve(ctx, address); err == nil && name != "" {
+				ensName = name
+			}
+		}
+		return address, ensName, nil
+	}
+
+	// 可能是 ENS 域名
+	if h.ensService == nil {
+		return "", "", errors.New("ENS resolution not available")
+	}
+
+	resolvedAddr, err := h.ensService.Resolve(ctx, input)
+	if err != nil {
+		h.logger.Warn("Failed to resolve ENS", zap.Error(err), zap.String("ens", input))
+		return "", "", errors.New("invalid address or ENS name")
+	}
+	return resolvedAddr, input, nil
+}
+
+// fetchAndStoreTransactions 查询并存储交易记录，为 recipients 中的每个用户创建 feed
+func (h *WatchedAddressHandler) fetchAndStoreTransactions(ctx context.Context, recipients []int64, watchedAddr *models.WatchedAddress) {
 	if h.alchemyService == nil {
 		return
 	}
@@ -195,6 +281,10 @@ func (h *WatchedAddressHandler) fetchAndStoreTransactions(ctx context.Context, u
 
 	// 存储交易并创建 feed
 	for _, tx := range transactions {
+		if h.pricer != nil {
+			h.pricer.Enrich(ctx, tx)
+		}
+
 		// 存储交易（数据库唯一索引会自动去重）
 		if err := h.txRepo.Create(tx); err != nil {
 			// 如果是重复交易，跳过
@@ -204,40 +294,51 @@ func (h *WatchedAddressHandler) fetchAndStoreTransactions(ctx context.Context, u
 			continue
 		}
 
-		// 创建 feed item
-		feedItem := &models.FeedItem{
-			UserID:           userID,
-			TransactionID:    tx.ID,
-			WatchedAddressID: watchedAddr.ID,
-		}
+		for _, userID := range recipients {
+			// 创建 feed item
+			feedItem := &models.FeedItem{
+				UserID:           userID,
+				TransactionID:    tx.ID,
+				WatchedAddressID: watchedAddr.ID,
+			}
 
-		if err := h.feedRepo.Create(feedItem); err != nil {
-			h.logger.Error("Failed to create feed item",
-				zap.String("tx_hash", tx.TxHash),
-				zap.Error(err))
-			continue
-		}
+			created, err := h.feedRepo.Create(ctx, feedItem, []int64{watchedAddr.ID})
+			if err != nil {
+				h.logger.Error("Failed to create feed item",
+					zap.String("tx_hash", tx.TxHash),
+					zap.Error(err))
+				continue
+			}
+			// 已有条目（经由其他监控地址命中）只补充关联，不重复推送
+			if !created {
+				continue
+			}
 
-		// 推送到 Redis Stream（推送完整的 FeedItem）
-		h.publishFeedUpdate(ctx, feedItem, tx, watchedAddr)
+			// 推送到 Redis Stream（推送完整的 FeedItem）
+			h.publishFeedUpdate(ctx, feedItem, tx, watchedAddr)
+		}
 	}
 }
 
 func (h *WatchedAddressHandler) publishFeedUpdate(ctx context.Context, feedItem *models.FeedItem, tx *models.Transaction, wa *models.WatchedAddress) {
 	// 构造前端期望的数据格式
 	payload := map[string]interface{}{
-		"id":              feedItem.ID,
-		"created_at":      feedItem.CreatedAt,
-		"transaction":     tx,
-		"watched_address": wa,
+		"id":                feedItem.ID,
+		"created_at":        feedItem.CreatedAt,
+		"transaction":       tx,
+		"watched_address":   wa,
+		"watched_addresses": []*models.WatchedAddress{wa},
 	}
 
-	msg := &websocket.Message{
+	h.publish(ctx, &websocket.Message{
 		UserID:  feedItem.UserID,
 		Type:    "new_transaction",
 		Payload: payload,
-	}
+	})
+}
 
+// publish 推送消息到 Redis Stream，由 StreamService 转发给用户的 WebSocket 连接
+func (h *WatchedAddressHandler) publish(ctx context.Context, msg *websocket.Message) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		h.logger.Error("Failed to marshal message", zap.Error(err))
@@ -245,8 +346,8 @@ func (h *WatchedAddressHandler) publishFeedUpdate(ctx context.Context, feedItem
 	}
 
 	values := map[string]any{
-		"user_id": feedItem.UserID,
-		"type":    "new_transaction",
+		"user_id": msg.UserID,
+		"type":    msg.Type,
 		"payload": string(data),
 	}
 
@@ -269,6 +370,7 @@ func (h *WatchedAddressHandler) publishFeedUpdate(ctx context.Context, feedItem
 // @Success      200 {object} map[string]string
 // @Failure      400 {object} map[string]string
 // @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /addresses/{id} [delete]
 func (h *WatchedAddressHandler) Remove(c *gin.Context) {
@@ -287,6 +389,10 @@ func (h *WatchedAddressHandler) Remove(c *gin.Context) {
 
 	ctx := context.Background()
 	if err := h.repo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "address not found")
+			return
+		}
 		h.logger.Error("Failed to delete watched address", zap.Error(err))
 		response.InternalServerError(c, "internal server error")
 		return
@@ -294,3 +400,194 @@ func (h *WatchedAddressHandler) Remove(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "address removed", nil)
 }
+
+const (
+	maxLabelLength = 100
+	maxNotesLength = 2000
+	maxTags        = 20
+	maxTagLength   = 32
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-:.]*$`)
+
+// validateLabelAndNotes 校验标签与备注长度（按字符计）
+func validateLabelAndNotes(label, notes string) error {
+	if utf8.RuneCountInString(strings.TrimSpace(label)) > maxLabelLength {
+		return fmt.Errorf("label must be at most %d characters", maxLabelLength)
+	}
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return fmt.Errorf("notes must be at most %d characters", maxNotesLength)
+	}
+	return nil
+}
+
+// normalizeTags 小写、去空白、去重并校验 tags，保持原有顺序
+func normalizeTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, t := range raw {
+		tag := strings.ToLower(strings.TrimSpace(t))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength || !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: use up to %d characters of a-z, 0-9, '_', '-', ':' or '.'", t, maxTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", maxTags)
+	}
+	return tags, nil
+}
+
+type UpdateWatchedAddressRequest struct {
+	Label     *string   `json:"label"`
+	Notes     *string   `json:"notes"`
+	Tags      *[]string `json:"tags"`
+	MinAmount *string   `json:"min_amount"`
+}
+
+// Update 编辑监控地址
+// @Summary      编辑监控地址
+// @Description  修改监控地址的标签、备注与 tags，未提供的字段保持不变；团队地址需要 editor 及以上角色
+// @Tags         监控地址
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "地址 ID"
+// @Param        request body UpdateWatchedAddressRequest true "可编辑字段"
+// @Success      200 {object} models.WatchedAddress
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /addresses/{id} [patch]
+func (h *WatchedAddressHandler) Update(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req UpdateWatchedAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.Label == nil && req.Notes == nil && req.Tags == nil && req.MinAmount == nil {
+		response.BadRequest(c, "nothing to update")
+		return
+	}
+
+	var update repository.WatchedAddressUpdate
+	var label, notes string
+	if req.Label != nil {
+		label = strings.TrimSpace(*req.Label)
+		update.Label = &label
+	}
+	if req.Notes != nil {
+		notes = *req.Notes
+		update.Notes = &notes
+	}
+	if err := validateLabelAndNotes(label, notes); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		update.Tags = tags
+	}
+	if req.MinAmount != nil {
+		minAmount := ""
+		if strings.TrimSpace(*req.MinAmount) != "" {
+			normalized, err := normalizeMinAmount(*req.MinAmount)
+			if err != nil {
+				response.BadRequest(c, err.Error())
+				return
+			}
+			minAmount = normalized
+		}
+		update.MinAmount = &minAmount
+	}
+
+	ctx := context.Background()
+	existing, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		h.logger.Error("Failed to get watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if existing == nil {
+		response.NotFound(c, "address not found")
+		return
+	}
+
+	if update.MinAmount != nil && existing.Kind != models.WatchKindToken {
+		response.BadRequest(c, "min_amount is only supported for token watches")
+		return
+	}
+
+	// 权限：个人地址只能本人编辑，团队地址需要 editor 及以上
+	recipients := []int64{userID}
+	if existing.TeamID == nil {
+		if existing.UserID != userID {
+			response.NotFound(c, "address not found")
+			return
+		}
+	} else {
+		role, err := h.teamRepo.GetMemberRole(ctx, *existing.TeamID, userID)
+		if err != nil {
+			h.logger.Error("Failed to get team role", zap.Error(err))
+			response.InternalServerError(c, "internal server error")
+			return
+		}
+		if role == "" {
+			response.NotFound(c, "address not found")
+			return
+		}
+		if !roleAtLeast(role, models.TeamRoleEditor) {
+			response.Forbidden(c, "insufficient team role")
+			return
+		}
+		if recipients, err = h.teamRepo.GetMemberUserIDs(ctx, *existing.TeamID); err != nil {
+			h.logger.Error("Failed to get team members", zap.Error(err))
+			recipients = []int64{userID}
+		}
+	}
+
+	updated, err := h.repo.Update(ctx, id, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "address not found")
+			return
+		}
+		h.logger.Error("Failed to update watched address", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	// 同步到用户（或团队成员）已打开的 WebSocket 会话
+	for _, uid := range recipients {
+		h.publish(ctx, &websocket.Message{
+			UserID:  uid,
+			Type:    "address_updated",
+			Payload: updated,
+		})
+	}
+
+	response.Success(c, updated)
+}
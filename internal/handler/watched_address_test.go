@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestValidateLabelAndNotes(t *testing.T) {
+	assert.NoError(t, validateLabelAndNotes("Vitalik", "cold wallet"))
+	assert.NoError(t, validateLabelAndNotes(strings.Repeat("鲸", maxLabelLength), ""))
+	assert.Error(t, validateLabelAndNotes(strings.Repeat("a", maxLabelLength+1), ""))
+	assert.Error(t, validateLabelAndNotes("", strings.Repeat("a", maxNotesLength+1)))
+}
+
+func TestUpdateWatchedAddress(t *testing.T) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE watched_addresses (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			team_id INTEGER,
+			kind TEXT NOT NULL DEFAULT 'wallet',
+			address TEXT NOT NULL,
+			min_amount TEXT,
+			label TEXT NOT NULL DEFAULT '',
+			ens_name TEXT NOT NULL DEFAULT '',
+			notes TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT '{}',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO watched_addresses (id, user_id, address, label, tags)
+			VALUES (1, 1, '0xd8da6bf26964af9d7eed9e03e53415d37aa96045', 'Vitalik', '{kol}');
+	`)
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	h := NewWatchedAddressHandler(repository.NewWatchedAddressRepository(db), repository.NewTeamRepository(db),
+		nil, nil, nil, nil, nil, nil, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", int64(1))
+	})
+	router.GET("/addresses", h.List)
+	router.PATCH("/addresses/:id", h.Update)
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"Invalid id", "/addresses/abc", `{"label": "x"}`, http.StatusBadRequest},
+		{"Nothing to update", "/addresses/1", `{}`, http.StatusBadRequest},
+		{"Label too long", "/addresses/1", `{"label": "` + strings.Repeat("a", maxLabelLength+1) + `"}`, http.StatusBadRequest},
+		{"Notes too long", "/addresses/1", `{"notes": "` + strings.Repeat("a", maxNotesLength+1) + `"}`, http.StatusBadRequest},
+		{"Invalid tag", "/addresses/1", `{"tags": ["has space"]}`, http.StatusBadRequest},
+		{"Min amount on wallet watch", "/addresses/1", `{"min_amount": "100"}`, http.StatusBadRequest},
+		{"Unknown address", "/addresses/99", `{"label": "x"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, do(http.MethodPatch, tt.path, tt.body))
+		})
+	}
+
+	t.Run("Other user's address", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO watched_addresses (id, user_id, address)
+			VALUES (2, 2, '0x742d35cc6634c0532925a3b8d4c9db96c4b4d8b6')`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodPatch, "/addresses/2", `{"label": "x"}`))
+	})
+
+	t.Run("Invalid tag filter", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/addresses?tag=Bad%20Tag", ""))
+	})
+
+	// 校验失败的请求不应修改数据
+	var label, tags string
+	require.NoError(t, db.QueryRow(`SELECT label, tags FROM watched_addresses WHERE id = 1`).Scan(&label, &tags))
+	assert.Equal(t, "Vitalik", label)
+	assert.Equal(t, "{kol}", tags)
+}
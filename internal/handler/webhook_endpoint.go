@@ -0,0 +1,479 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/response"
+)
+
+const (
+	maxWebhookEndpointsPerUser = 10
+	maxWebhookURLLength        = 2048
+	maxWebhookDescLength       = 255
+	webhookResolveTimeout      = 5 * time.Second
+)
+
+type WebhookEndpointHandler struct {
+	webhookRepo *repository.WebhookRepository
+	logger      *zap.Logger
+}
+
+func NewWebhookEndpointHandler(webhookRepo *repository.WebhookRepository, logger *zap.Logger) *WebhookEndpointHandler {
+	return &WebhookEndpointHandler{
+		webhookRepo: webhookRepo,
+		logger:      logger,
+	}
+}
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+}
+
+type UpdateWebhookEndpointRequest struct {
+	URL         *string   `json:"url"`
+	Description *string   `json:"description"`
+	EventTypes  *[]string `json:"event_types"`
+	Enabled     *bool     `json:"enabled"`
+}
+
+// WebhookEndpointWithSecret 创建或轮换密钥时返回，密钥只展示这一次
+type WebhookEndpointWithSecret struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// validateWebhookURL 校验 URL 格式，并拒绝解析到内网、回环、链路本地等非公网地址的主机
+func validateWebhookURL(ctx context.Context, raw string) error {
+	if len(raw) > maxWebhookURLLength {
+		return errors.New("url is too long")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookResolveTimeout)
+	defer cancel()
+	if err := notify.CheckPublicHost(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, notify.ErrBlockedAddress) {
+			return errors.New("url must point to a public address")
+		}
+		return errors.New("url host cannot be resolved")
+	}
+	return nil
+}
+
+func normalizeEventTypes(types []string) ([]string, error) {
+	result := make([]string, 0, len(types))
+	seen := make(map[string]bool, len(types))
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		supported := false
+		for _, s := range notify.SupportedEvents {
+			if t == s {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported event type: %s", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// loadEndpoint 解析路径中的端点 ID 并确认属于当前用户，失败时直接写入响应
+func (h *WebhookEndpointHandler) loadEndpoint(c *gin.Context) (int64, *models.WebhookEndpoint, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return 0, nil, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return 0, nil, false
+	}
+
+	endpoint, err := h.webhookRepo.GetEndpoint(context.Background(), id, userID)
+	if err != nil {
+		h.logger.Error("Failed to get webhook endpoint", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return 0, nil, false
+	}
+	if endpoint == nil {
+		response.NotFound(c, "webhook not found")
+		return 0, nil, false
+	}
+
+	return userID, endpoint, true
+}
+
+// List 获取推送端点
+// @Summary      获取推送端点
+// @Description  获取当前用户注册的 Webhook 推送端点（不含密钥）
+// @Tags         Webhook
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {array} models.WebhookEndpoint
+// @Failure      401 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks [get]
+func (h *WebhookEndpointHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	endpoints, err := h.webhookRepo.ListEndpoints(context.Background(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list webhook endpoints", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, endpoints)
+}
+
+// Create 注册推送端点
+// @Summary      注册推送端点
+// @Description  注册 Webhook 推送端点，返回的 secret 用于校验 X-ChainFeed-Signature，只展示一次；event_types 为空表示订阅全部事件
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateWebhookEndpointRequest true "端点信息"
+// @Success      200 {object} WebhookEndpointWithSecret
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks [post]
+func (h *WebhookEndpointHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	endpointURL := strings.TrimSpace(req.URL)
+	if err := validateWebhookURL(c.Request.Context(), endpointURL); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	description := strings.TrimSpace(req.Description)
+	if len([]rune(description)) > maxWebhookDescLength {
+		response.BadRequest(c, "description must be at most 255 characters")
+		return
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	count, err := h.webhookRepo.CountEndpoints(ctx, userID)
+	if err != nil {
+		h.logger.Error("Failed to count webhook endpoints", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+	if count >= maxWebhookEndpointsPerUser {
+		response.Error(c, http.StatusConflict, 409, "too many webhook endpoints")
+		return
+	}
+
+	secret, err := notify.GenerateSecret()
+	if err != nil {
+		h.logger.Error("Failed to generate webhook secret", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		UserID:      userID,
+		URL:         endpointURL,
+		Secret:      secret,
+		Description: description,
+		EventTypes:  eventTypes,
+	}
+	if err := h.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+		h.logger.Error("Failed to create webhook endpoint", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, WebhookEndpointWithSecret{WebhookEndpoint: *endpoint, Secret: secret})
+}
+
+// Get 获取推送端点详情
+// @Summary      获取推送端点详情
+// @Tags         Webhook
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "端点 ID"
+// @Success      200 {object} models.WebhookEndpoint
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /webhooks/{id} [get]
+func (h *WebhookEndpointHandler) Get(c *gin.Context) {
+	_, endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, endpoint)
+}
+
+// Update 编辑推送端点
+// @Summary      编辑推送端点
+// @Description  修改 URL、描述、订阅事件或启用状态；重新启用会清零连续失败计数
+// @Tags         Webhook
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "端点 ID"
+// @Param        request body UpdateWebhookEndpointRequest true "可编辑字段"
+// @Success      200 {object} models.WebhookEndpoint
+// @Failure      400 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /webhooks/{id} [patch]
+func (h *WebhookEndpointHandler) Update(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	var req UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if req.URL == nil && req.Description == nil && req.EventTypes == nil && req.Enabled == nil {
+		response.BadRequest(c, "nothing to update")
+		return
+	}
+
+	update := repository.WebhookEndpointUpdate{Enabled: req.Enabled}
+	if req.URL != nil {
+		endpointURL := strings.TrimSpace(*req.URL)
+		if err := validateWebhookURL(c.Request.Context(), endpointURL); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		update.URL = &endpointURL
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len([]rune(description)) > maxWebhookDescLength {
+			response.BadRequest(c, "description must be at most 255 characters")
+			return
+		}
+		update.Description = &description
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(*req.EventTypes)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		update.EventTypes = eventTypes
+	}
+
+	endpoint, err := h.webhookRepo.UpdateEndpoint(context.Background(), id, userID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "webhook not found")
+			return
+		}
+		h.logger.Error("Failed to update webhook endpoint", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, endpoint)
+}
+
+// Delete 删除推送端点
+// @Summary      删除推送端点
+// @Description  删除推送端点及其投递记录
+// @Tags         Webhook
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "端点 ID"
+// @Success      200 {object} map[string]string
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookEndpointHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid id")
+		return
+	}
+
+	if err := h.webhookRepo.DeleteEndpoint(context.Background(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "webhook not found")
+			return
+		}
+		h.logger.Error("Failed to delete webhook endpoint", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.SuccessWithMessage(c, "webhook deleted", nil)
+}
+
+// RotateSecret 轮换签名密钥
+// @Summary      轮换签名密钥
+// @Description  生成新的签名密钥，旧密钥立即失效
+// @Tags         Webhook
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "端点 ID"
+// @Success      200 {object} WebhookEndpointWithSecret
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /webhooks/{id}/rotate-secret [post]
+func (h *WebhookEndpointHandler) RotateSecret(c *gin.Context) {
+	userID, endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	secret, err := notify.GenerateSecret()
+	if err != nil {
+		h.logger.Error("Failed to generate webhook secret", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	if err := h.webhookRepo.RotateSecret(context.Background(), endpoint.ID, userID, secret); err != nil {
+		h.logger.Error("Failed to rotate webhook secret", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, WebhookEndpointWithSecret{WebhookEndpoint: *endpoint, Secret: secret})
+}
+
+// ListDeliveries 获取投递记录
+// @Summary      获取投递记录
+// @Description  获取端点的投递记录，包括状态、尝试次数、最近一次响应码与错误
+// @Tags         Webhook
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "端点 ID"
+// @Param        page query int false "页码" default(1)
+// @Param        page_size query int false "每页数量" default(20)
+// @Success      200 {array} models.WebhookDelivery
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /webhooks/{id}/deliveries [get]
+func (h *WebhookEndpointHandler) ListDeliveries(c *gin.Context) {
+	_, endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	deliveries, err := h.webhookRepo.ListDeliveries(context.Background(), endpoint.ID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		h.logger.Error("Failed to list webhook deliveries", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, deliveries)
+}
+
+// Redeliver 重新投递
+// @Summary      重新投递
+// @Description  以相同内容创建一条新的投递记录，由后台立即发送
+// @Tags         Webhook
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "端点 ID"
+// @Param        delivery_id path int true "投递 ID"
+// @Success      200 {object} models.WebhookDelivery
+// @Failure      401 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Router       /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookEndpointHandler) Redeliver(c *gin.Context) {
+	_, endpoint, ok := h.loadEndpoint(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "invalid delivery id")
+		return
+	}
+
+	delivery, err := h.webhookRepo.Redeliver(context.Background(), deliveryID, endpoint.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "delivery not found")
+			return
+		}
+		h.logger.Error("Failed to redeliver webhook", zap.Error(err))
+		response.InternalServerError(c, "internal server error")
+		return
+	}
+
+	response.Success(c, delivery)
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"net/http"
 
+	"github.com/bwmspring/chainfeed-go/internal/feedfilter"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/response"
 	"github.com/bwmspring/chainfeed-go/internal/websocket"
 
@@ -20,24 +22,30 @@ var upgrader = ws.Upgrader{
 }
 
 type WebSocketHandler struct {
-	hub    *websocket.Hub
-	logger *zap.Logger
+	hub      *websocket.Hub
+	feedRepo *repository.FeedRepository
+	logger   *zap.Logger
 }
 
-func NewWebSocketHandler(hub *websocket.Hub, logger *zap.Logger) *WebSocketHandler {
+func NewWebSocketHandler(hub *websocket.Hub, feedRepo *repository.FeedRepository, logger *zap.Logger) *WebSocketHandler {
 	return &WebSocketHandler{
-		hub:    hub,
-		logger: logger,
+		hub:      hub,
+		feedRepo: feedRepo,
+		logger:   logger,
 	}
 }
 
 // HandleWebSocket godoc
 // @Summary WebSocket connection
-// @Description Establish WebSocket connection for real-time feed updates
+// @Description Establish WebSocket connection for real-time feed updates.
+// @Description Accepts the same preset and filter parameters as GET /api/v1/feed; new_transaction messages that do not match are not pushed.
 // @Tags websocket
 // @Param token query string true "JWT token"
+// @Param preset query int false "Saved filter preset ID"
 // @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /ws [get]
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
@@ -47,6 +55,12 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
+	// 过滤条件在握手前解析，参数错误时直接返回 HTTP 错误
+	filter, ok := resolveFeedFilter(c, h.feedRepo, h.logger, userID.(int64))
+	if !ok {
+		return
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		h.logger.Error("failed to upgrade websocket connection",
@@ -61,6 +75,7 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		Conn:   conn,
 		Send:   make(chan []byte, 256),
 		Hub:    h.hub,
+		Filter: feedfilter.MessageFilter(filter),
 	}
 
 	h.hub.Register <- client
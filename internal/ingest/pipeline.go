@@ -0,0 +1,154 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/approvals"
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/decoder"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pricing"
+	"github.com/bwmspring/chainfeed-go/internal/receipts"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/spam"
+	"github.com/bwmspring/chainfeed-go/internal/swaps"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+// Pipeline 交易入库流程：补全代币信息、识别垃圾交易、处理授权、解码、合并兑换、获取回执、计价并保存。
+// Webhook 推送与地址画像的按需回填共用，保证两者存储的交易一致
+type Pipeline struct {
+	txRepo     *repository.TransactionRepository
+	tokens     *tokens.Registry   // 为 nil 时使用 Alchemy 提供的代币信息
+	classifier *spam.Classifier   // 为 nil 时不识别垃圾交易
+	approvals  *approvals.Tracker // 授权的风险标记与钱包当前授权
+	decoder    *decoder.Decoder   // 为 nil 时不解码合约调用
+	swaps      *swaps.Detector    // 为 nil 时不合并兑换的转账
+	receipts   *receipts.Enricher // 为 nil 时不获取回执（状态、nonce 与手续费）
+	pricer     *pricing.Service   // 为 nil 时不计算 usd_value
+	logger     *zap.Logger
+}
+
+// New 按配置创建各环节，可选环节初始化失败时记录警告并跳过
+func New(cfg *config.Config, db *sqlx.DB, redis *redis.Client, logger *zap.Logger) *Pipeline {
+	pricer, err := pricing.New(cfg.Pricing, repository.NewPriceRepository(db), redis, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize price service, USD values disabled", zap.Error(err))
+	}
+	registry, err := tokens.New(cfg.Tokens, cfg.Ethereum, repository.NewTokenRepository(db), logger)
+	if err != nil {
+		logger.Warn("Failed to initialize token registry, using webhook token metadata", zap.Error(err))
+	}
+	if registry != nil {
+		go syncTokenList(registry, logger)
+	}
+	classifier, err := spam.New(cfg.Spam, cfg.Tokens, cfg.Ethereum, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize spam classifier, spam filtering disabled", zap.Error(err))
+	}
+	approvalTracker, err := approvals.New(cfg.Approvals, repository.NewTokenAllowanceRepository(db), logger)
+	if err != nil {
+		logger.Warn("Failed to load spender list, using bundled spenders", zap.Error(err))
+		approvalTracker, _ = approvals.NewTracker(repository.NewTokenAllowanceRepository(db), nil, logger)
+	}
+	abiDecoder, err := decoder.New(cfg.ABI, cfg.Ethereum, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize abi decoder, contract calls will not be decoded", zap.Error(err))
+	}
+	// 兑换识别依赖解码后的事件
+	var swapDetector *swaps.Detector
+	if abiDecoder != nil {
+		swapDetector = swaps.NewDetector(registry, logger)
+	}
+	receiptEnricher, err := receipts.New(cfg.Ethereum, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize receipt enricher, status and fees will not be recorded", zap.Error(err))
+	}
+
+	return &Pipeline{
+		txRepo:     repository.NewTransactionRepository(db),
+		tokens:     registry,
+		classifier: classifier,
+		approvals:  approvalTracker,
+		decoder:    abiDecoder,
+		swaps:      swapDetector,
+		receipts:   receiptEnricher,
+		pricer:     pricer,
+		logger:     logger,
+	}
+}
+
+// syncTokenList 启动时将代币列表写入 tokens 表
+func syncTokenList(registry *tokens.Registry, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := registry.SyncList(ctx); err != nil {
+		logger.Error("Failed to sync token list", zap.Error(err))
+	}
+}
+
+// Enrich 整批补全交易，返回待入库的交易（附带发出的授权被移除，兑换的各条转账合并为一条）
+func (p *Pipeline) Enrich(ctx context.Context, txs []*models.Transaction) []*models.Transaction {
+	// 整批查询代币元数据，需在计价之前完成（可能按链上 decimals 重新换算 Value）
+	if p.tokens != nil {
+		p.tokens.Enrich(ctx, txs)
+	}
+	// 垃圾交易识别依赖代币的认证状态与链上 symbol
+	if p.classifier != nil {
+		p.classifier.Classify(ctx, txs)
+	}
+	// 更新钱包的当前授权并标记有风险的授权，附带发出的授权不单独入库
+	txs = p.approvals.Apply(ctx, txs)
+	// 解码合约调用；垃圾交易不解码，避免消耗节点请求
+	if p.decoder != nil {
+		p.decoder.Decode(ctx, notSpam(txs))
+	}
+	// 兑换的各条转账合并为一条 SWAP，需在计价之前完成（按 token_out 计价）
+	if p.swaps != nil {
+		txs = p.swaps.Apply(ctx, txs)
+	}
+	// 补全执行状态、nonce 与手续费；垃圾交易同样跳过
+	if p.receipts != nil {
+		p.receipts.Enrich(ctx, notSpam(txs))
+	}
+	return txs
+}
+
+// Store 计价并保存一笔已补全的交易（按交易哈希合并已有记录）
+func (p *Pipeline) Store(ctx context.Context, tx *models.Transaction) error {
+	// 入库前按区块时间计价，feed 推送与告警评估使用同一 usd_value；垃圾交易不计价，避免消耗价格接口配额
+	if p.pricer != nil && tx.SpamReason == "" {
+		p.pricer.Enrich(ctx, tx)
+	}
+	return p.txRepo.Create(tx)
+}
+
+// Ingest 补全并保存交易，不创建 feed 条目，返回保存成功的交易数
+func (p *Pipeline) Ingest(ctx context.Context, txs []*models.Transaction) int {
+	stored := 0
+	for _, tx := range p.Enrich(ctx, txs) {
+		if err := p.Store(ctx, tx); err != nil {
+			p.logger.Error("Failed to store transaction",
+				zap.String("tx_hash", tx.TxHash),
+				zap.Error(err))
+			continue
+		}
+		stored++
+	}
+	return stored
+}
+
+func notSpam(txs []*models.Transaction) []*models.Transaction {
+	var result []*models.Transaction
+	for _, tx := range txs {
+		if tx.SpamReason == "" {
+			result = append(result, tx)
+		}
+	}
+	return result
+}
@@ -0,0 +1,335 @@
+package mempool
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpcbatch"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+// 待打包交易的状态
+const (
+	StatusPending  = "pending"
+	StatusMined    = "mined"
+	StatusReplaced = "replaced" // 发起方的同一 nonce 被另一笔交易使用（加速、取消或被覆盖）
+	StatusDropped  = "dropped"  // 节点已不再持有该交易，或待打包超过 drop_after
+)
+
+const (
+	// dropGrace 交易出现后至少经过该时长，节点查不到时才视为丢弃，避免负载均衡节点之间的同步延迟造成误判
+	dropGrace = time.Minute
+)
+
+// Transaction 待打包交易；状态变化时以相同 hash 再次推送，前端据此更新同一张卡片
+type Transaction struct {
+	Hash          string      `json:"hash"`
+	Status        string      `json:"status"`
+	From          string      `json:"from"`
+	To            string      `json:"to,omitempty"` // 合约创建交易为空
+	Nonce         uint64      `json:"nonce"`
+	Value         string      `json:"value"` // wei
+	Input         string      `json:"input"`
+	Gas           *models.Gas `json:"gas"`
+	FirstSeen     time.Time   `json:"first_seen"`
+	BlockNumber   uint64      `json:"block_number,omitempty"`   // mined
+	ReceiptStatus string      `json:"receipt_status,omitempty"` // mined：success / reverted
+	ReplacedBy    string      `json:"replaced_by,omitempty"`    // replaced：替换交易的哈希，未观察到替换交易时为空
+	ResolvedAt    *time.Time  `json:"resolved_at,omitempty"`
+}
+
+// Tracked 跟踪中的交易及其推送对象
+type Tracked struct {
+	Tx         *Transaction
+	Recipients map[int64][]*models.WatchedAddress // 用户 ID → 命中的监控地址
+}
+
+type senderNonce struct {
+	from  string
+	nonce uint64
+}
+
+// Tracker 在内存中维护待打包交易，并在新区块到来时判断其打包、替换或丢弃；
+// 并发安全：Watcher 在订阅循环中添加与替换交易，在单独的 goroutine 中调用 Resolve
+type Tracker struct {
+	dropAfter time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	byHash  map[string]*Tracked
+	byNonce map[senderNonce]*Tracked
+}
+
+func NewTracker(dropAfter time.Duration) *Tracker {
+	return &Tracker{
+		dropAfter: dropAfter,
+		now:       time.Now,
+		byHash:    make(map[string]*Tracked),
+		byNonce:   make(map[senderNonce]*Tracked),
+	}
+}
+
+// Len 返回跟踪中的交易数
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.byHash)
+}
+
+func (t *Tracker) Has(hash string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.byHash[hash] != nil
+}
+
+// Add 开始跟踪一笔交易，已跟踪的交易忽略
+func (t *Tracker) Add(tr *Tracked) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.byHash[tr.Tx.Hash] != nil {
+		return
+	}
+	t.byHash[tr.Tx.Hash] = tr
+	t.byNonce[senderNonce{tr.Tx.From, tr.Tx.Nonce}] = tr
+}
+
+// Supersede 在看到新的待打包交易时调用：跟踪中同一发起方、相同 nonce 的其他交易被替换，
+// 返回被替换的交易（已移出跟踪），没有时返回 nil。新交易本身不需要涉及监控地址
+func (t *Tracker) Supersede(tx *Transaction) *Tracked {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.byNonce[senderNonce{tx.From, tx.Nonce}]
+	if old == nil || old.Tx.Hash == tx.Hash {
+		return nil
+	}
+	old.Tx.ReplacedBy = tx.Hash
+	t.resolve(old, StatusReplaced)
+	return old
+}
+
+// Resolve 查询节点判断跟踪中交易的去向，返回状态已变化的交易（已移出跟踪）：
+// 有回执为 mined；没有回执但发起方的 nonce 已被使用为 replaced；
+// 节点查不到或待打包超过 dropAfter 为 dropped。任一请求失败时不做判断，等待下一个区块重试。
+// 查询节点期间不持有锁，期间被替换的交易不再重复返回
+func (t *Tracker) Resolve(ctx context.Context, caller tokens.Caller) ([]*Tracked, error) {
+	t.mu.Lock()
+	tracked := make([]*Tracked, 0, len(t.byHash))
+	for _, tr := range t.byHash {
+		tracked = append(tracked, tr)
+	}
+	t.mu.Unlock()
+	if len(tracked) == 0 {
+		return nil, nil
+	}
+	sort.Slice(tracked, func(i, j int) bool {
+		if !tracked[i].Tx.FirstSeen.Equal(tracked[j].Tx.FirstSeen) {
+			return tracked[i].Tx.FirstSeen.Before(tracked[j].Tx.FirstSeen)
+		}
+		return tracked[i].Tx.Hash < tracked[j].Tx.Hash
+	})
+
+	// 先查 nonce 再查回执：两次查询之间打包的交易会在回执中出现，不会被误判为替换
+	var senders []string
+	seen := make(map[string]bool)
+	for _, tr := range tracked {
+		if !seen[tr.Tx.From] {
+			seen[tr.Tx.From] = true
+			senders = append(senders, tr.Tx.From)
+		}
+	}
+	counts := make([]hexutil.Uint64, len(senders))
+	args := make([][]interface{}, len(senders))
+	for i, sender := range senders {
+		args[i] = []interface{}{sender, "latest"}
+	}
+	if err := rpcbatch.Call(ctx, caller, "eth_getTransactionCount", args, func(i int) interface{} { return &counts[i] }, nil); err != nil {
+		return nil, err
+	}
+	nonces := make(map[string]uint64, len(senders))
+	for i, sender := range senders {
+		nonces[sender] = uint64(counts[i])
+	}
+
+	receipts := make([]*rpcReceipt, len(tracked))
+	if err := rpcbatch.Call(ctx, caller, "eth_getTransactionReceipt", hashArgs(tracked), func(i int) interface{} { return &receipts[i] }, nil); err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	statuses := make([]string, len(tracked))
+	var unresolved []*Tracked
+	var unresolvedIdx []int
+	for i, tr := range tracked {
+		age := now.Sub(tr.Tx.FirstSeen)
+		switch {
+		case receipts[i] != nil:
+			statuses[i] = StatusMined
+		case nonces[tr.Tx.From] > tr.Tx.Nonce:
+			statuses[i] = StatusReplaced
+		case t.dropAfter > 0 && age > t.dropAfter:
+			statuses[i] = StatusDropped
+		case age > dropGrace:
+			unresolved = append(unresolved, tr)
+			unresolvedIdx = append(unresolvedIdx, i)
+		}
+	}
+
+	known := make([]*struct{}, len(unresolved))
+	if err := rpcbatch.Call(ctx, caller, "eth_getTransactionByHash", hashArgs(unresolved), func(i int) interface{} { return &known[i] }, nil); err != nil {
+		return nil, err
+	}
+	for i, idx := range unresolvedIdx {
+		if known[i] == nil {
+			statuses[idx] = StatusDropped
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var resolved []*Tracked
+	for i, tr := range tracked {
+		if statuses[i] == "" || t.byHash[tr.Tx.Hash] != tr {
+			continue
+		}
+		if r := receipts[i]; r != nil {
+			tr.Tx.BlockNumber = uint64(r.BlockNumber)
+			tr.Tx.ReceiptStatus = receiptStatus(r)
+			if tr.Tx.Gas != nil {
+				tr.Tx.Gas.Used = uint64(r.GasUsed)
+				if r.EffectiveGasPrice != nil {
+					tr.Tx.Gas.EffectiveGasPrice = r.EffectiveGasPrice.ToInt().String()
+				}
+			}
+		}
+		t.resolve(tr, statuses[i])
+		resolved = append(resolved, tr)
+	}
+	return resolved, nil
+}
+
+func (t *Tracker) resolve(tr *Tracked, status string) {
+	now := t.now()
+	tr.Tx.Status = status
+	tr.Tx.ResolvedAt = &now
+	delete(t.byHash, tr.Tx.Hash)
+	key := senderNonce{tr.Tx.From, tr.Tx.Nonce}
+	if t.byNonce[key] == tr {
+		delete(t.byNonce, key)
+	}
+}
+
+type rpcTransaction struct {
+	Hash                 common.Hash     `json:"hash"`
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Value                *hexutil.Big    `json:"value"`
+	Type                 hexutil.Uint64  `json:"type"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	MaxFeePerBlobGas     *hexutil.Big    `json:"maxFeePerBlobGas"`
+	BlobVersionedHashes  []common.Hash   `json:"blobVersionedHashes"`
+	Input                hexutil.Bytes   `json:"input"`
+}
+
+type rpcReceipt struct {
+	Status            *hexutil.Uint64 `json:"status"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+}
+
+// transaction 转换为待打包交易；legacy 交易的 gasPrice 即实际单价，EIP-1559 交易打包后才能确定
+func (r *rpcTransaction) transaction(firstSeen time.Time) *Transaction {
+	tx := &Transaction{
+		Hash:      strings.ToLower(r.Hash.Hex()),
+		Status:    StatusPending,
+		From:      strings.ToLower(r.From.Hex()),
+		Nonce:     uint64(r.Nonce),
+		Value:     bigString(r.Value),
+		Input:     hexutil.Encode(r.Input),
+		FirstSeen: firstSeen,
+		Gas: &models.Gas{
+			Sender:               strings.ToLower(r.From.Hex()),
+			Type:                 int(r.Type),
+			Limit:                uint64(r.Gas),
+			MaxFeePerGas:         bigString(r.MaxFeePerGas),
+			MaxPriorityFeePerGas: bigString(r.MaxPriorityFeePerGas),
+			MaxFeePerBlobGas:     bigString(r.MaxFeePerBlobGas),
+		},
+	}
+	if tx.Value == "" {
+		tx.Value = "0"
+	}
+	if r.To != nil {
+		tx.To = strings.ToLower(r.To.Hex())
+	}
+	if r.MaxFeePerGas == nil {
+		tx.Gas.EffectiveGasPrice = bigString(r.GasPrice)
+	}
+	for _, h := range r.BlobVersionedHashes {
+		tx.Gas.BlobVersionedHashes = append(tx.Gas.BlobVersionedHashes, h.Hex())
+	}
+	return tx
+}
+
+var (
+	transferSelector     = []byte{0xa9, 0x05, 0x9c, 0xbb} // transfer(address,uint256)
+	transferFromSelector = []byte{0x23, 0xb8, 0x72, 0xdd} // transferFrom(address,address,uint256)
+)
+
+// participants 返回交易涉及的地址（小写）：发起方、接收方，
+// 以及 ERC-20 transfer / transferFrom 调用参数中的代币发送方与接收方
+func (r *rpcTransaction) participants() []string {
+	addresses := []string{strings.ToLower(r.From.Hex())}
+	if r.To != nil {
+		addresses = append(addresses, strings.ToLower(r.To.Hex()))
+	}
+	input := []byte(r.Input)
+	switch {
+	case len(input) >= 68 && string(input[:4]) == string(transferSelector):
+		addresses = append(addresses, wordAddress(input[4:36]))
+	case len(input) >= 100 && string(input[:4]) == string(transferFromSelector):
+		addresses = append(addresses, wordAddress(input[4:36]), wordAddress(input[36:68]))
+	}
+	return addresses
+}
+
+func wordAddress(word []byte) string {
+	return strings.ToLower(common.BytesToAddress(word[12:]).Hex())
+}
+
+func hashArgs(tracked []*Tracked) [][]interface{} {
+	args := make([][]interface{}, len(tracked))
+	for i, tr := range tracked {
+		args[i] = []interface{}{tr.Tx.Hash}
+	}
+	return args
+}
+
+func receiptStatus(r *rpcReceipt) string {
+	switch {
+	case r.Status == nil:
+		return ""
+	case *r.Status == 1:
+		return models.TxStatusSuccess
+	default:
+		return models.TxStatusReverted
+	}
+}
+
+func bigString(v *hexutil.Big) string {
+	if v == nil {
+		return ""
+	}
+	return v.ToInt().String()
+}
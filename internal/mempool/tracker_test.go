@@ -0,0 +1,135 @@
+package mempool
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+const (
+	sender    = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+	other     = "0x2b5ad5c4795c026514f8317c7a215e218dccd6cf"
+	recipient = "0x6813eb9362372eef6200f3b1dbc3f819671cba69"
+	usdc      = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func track(tracker *Tracker, hash, from string, nonce uint64, firstSeen time.Time) *Tracked {
+	tracked := &Tracked{Tx: &Transaction{
+		Hash: hash, Status: StatusPending, From: from, Nonce: nonce, FirstSeen: firstSeen, Gas: &models.Gas{Sender: from},
+	}}
+	tracker.Add(tracked)
+	return tracked
+}
+
+func TestSupersede(t *testing.T) {
+	tracker := NewTracker(time.Hour)
+	old := track(tracker, "0xaaa", sender, 7, time.Now())
+
+	assert.Nil(t, tracker.Supersede(&Transaction{Hash: "0xaaa", From: sender, Nonce: 7}), "same transaction seen again")
+	assert.Nil(t, tracker.Supersede(&Transaction{Hash: "0xbbb", From: sender, Nonce: 8}))
+
+	replaced := tracker.Supersede(&Transaction{Hash: "0xccc", From: sender, Nonce: 7})
+	require.Same(t, old, replaced)
+	assert.Equal(t, StatusReplaced, replaced.Tx.Status)
+	assert.Equal(t, "0xccc", replaced.Tx.ReplacedBy)
+	assert.NotNil(t, replaced.Tx.ResolvedAt)
+	assert.False(t, tracker.Has("0xaaa"))
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(30 * time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	mined := track(tracker, "0x01", sender, 5, now.Add(-10*time.Second))
+	replaced := track(tracker, "0x02", other, 3, now.Add(-10*time.Second))
+	dropped := track(tracker, "0x03", sender, 6, now.Add(-5*time.Minute))
+	expired := track(tracker, "0x04", sender, 7, now.Add(-time.Hour))
+	fresh := track(tracker, "0x05", sender, 8, now.Add(-10*time.Second))
+	stuck := track(tracker, "0x06", sender, 9, now.Add(-5*time.Minute))
+
+	caller := &rpctest.Caller{Results: map[string]string{
+		"eth_getTransactionCount " + sender: `"0x6"`,
+		"eth_getTransactionCount " + other:  `"0x4"`,
+		"eth_getTransactionReceipt 0x01":    `{"status":"0x1","blockNumber":"0x121eac0","gasUsed":"0x5208","effectiveGasPrice":"0x4a817c800"}`,
+		"eth_getTransactionByHash 0x04":     `{"hash":"0x04"}`,
+		"eth_getTransactionByHash 0x06":     `{"hash":"0x06"}`,
+	}}
+	resolved, err := tracker.Resolve(context.Background(), caller)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*Tracked{mined, replaced, dropped, expired}, resolved)
+
+	assert.Equal(t, StatusMined, mined.Tx.Status)
+	assert.Equal(t, uint64(19000000), mined.Tx.BlockNumber)
+	assert.Equal(t, models.TxStatusSuccess, mined.Tx.ReceiptStatus)
+	assert.Equal(t, uint64(21000), mined.Tx.Gas.Used)
+	assert.Equal(t, "20000000000", mined.Tx.Gas.EffectiveGasPrice)
+
+	assert.Equal(t, StatusReplaced, replaced.Tx.Status, "nonce used without a receipt")
+	assert.Empty(t, replaced.Tx.ReplacedBy)
+	assert.Equal(t, StatusDropped, dropped.Tx.Status, "unknown to the node")
+	assert.Equal(t, StatusDropped, expired.Tx.Status, "pending longer than drop_after")
+
+	assert.Equal(t, StatusPending, fresh.Tx.Status, "too recent to be considered dropped")
+	assert.Equal(t, StatusPending, stuck.Tx.Status, "still known to the node")
+	assert.Equal(t, 2, tracker.Len())
+}
+
+// supersedingCaller 在 Resolve 查询节点期间替换一笔跟踪中的交易，模拟订阅循环并发处理交易池推送
+type supersedingCaller struct {
+	*rpctest.Caller
+	tracker *Tracker
+	tx      *Transaction
+}
+
+func (c *supersedingCaller) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	if c.tx != nil {
+		c.tracker.Supersede(c.tx)
+		c.tx = nil
+	}
+	return c.Caller.BatchCallContext(ctx, b)
+}
+
+func TestResolve_SkipsTransactionsReplacedDuringQuery(t *testing.T) {
+	tracker := NewTracker(30 * time.Minute)
+	speedUp := track(tracker, "0x01", sender, 5, time.Now())
+	caller := &supersedingCaller{
+		Caller: &rpctest.Caller{Results: map[string]string{
+			"eth_getTransactionCount " + sender: `"0x6"`,
+		}},
+		tracker: tracker,
+		tx:      &Transaction{Hash: "0x02", From: sender, Nonce: 5},
+	}
+
+	resolved, err := tracker.Resolve(context.Background(), caller)
+	require.NoError(t, err)
+	assert.Empty(t, resolved, "already reported by Supersede")
+	assert.Equal(t, "0x02", speedUp.Tx.ReplacedBy)
+	assert.Zero(t, tracker.Len())
+}
+
+func TestParticipants(t *testing.T) {
+	raw := `{"hash":"0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b",
+		"from":"` + sender + `","to":"` + usdc + `","nonce":"0x1","value":"0x0","type":"0x2","gas":"0x186a0",
+		"maxFeePerGas":"0x6fc23ac00","input":"0xa9059cbb` +
+		`0000000000000000000000006813eb9362372eef6200f3b1dbc3f819671cba69` +
+		`00000000000000000000000000000000000000000000000000000000000f4240"}`
+	var tx rpcTransaction
+	require.NoError(t, json.Unmarshal([]byte(raw), &tx))
+
+	assert.Equal(t, []string{sender, usdc, recipient}, tx.participants(), "token transfer recipient is matched")
+
+	pending := tx.transaction(time.Now())
+	assert.Equal(t, StatusPending, pending.Status)
+	assert.Equal(t, "0", pending.Value)
+	assert.Equal(t, "30000000000", pending.Gas.MaxFeePerGas)
+	assert.Empty(t, pending.Gas.EffectiveGasPrice, "EIP-1559 price is known only after mining")
+}
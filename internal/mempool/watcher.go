@@ -0,0 +1,267 @@
+package mempool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+	"github.com/bwmspring/chainfeed-go/internal/websocket"
+)
+
+// EventPendingTransaction 监控地址的待打包交易及其后续状态
+const EventPendingTransaction = "pending_transaction"
+
+const (
+	defaultDropAfter       = 30 * time.Minute
+	addressRefreshInterval = time.Minute
+	reconnectDelay         = 10 * time.Second
+	rpcTimeout             = 15 * time.Second
+)
+
+// errHashOnly 节点忽略了完整交易参数，只推送交易哈希。无法在获取详情前按监控地址过滤，
+// 为每个哈希调用 eth_getTransactionByHash 的请求量过大，因此不支持
+var errHashOnly = errors.New("node only sends pending transaction hashes, full transaction subscriptions are required")
+
+// Store 监控地址的查询，由 repository.WatchedAddressRepository 实现
+type Store interface {
+	ListWalletAddresses(ctx context.Context) ([]string, error)
+	FindWatchers(addresses []string) ([]repository.AddressWatcher, error)
+}
+
+// Watcher 通过 WebSocket RPC 订阅 newPendingTransactions，推送监控钱包发起或接收的待打包交易，
+// 并在每个新区块到来时判断其打包、替换或丢弃，以 pending_transaction 事件推送状态变化
+type Watcher struct {
+	wsURL     string
+	store     Store
+	gate      *notify.Gate
+	publisher notify.Publisher
+	tracker   *Tracker
+	logger    *zap.Logger
+
+	watched map[string]bool // 监控中的钱包地址（小写），定期从数据库刷新
+}
+
+func NewWatcher(cfg config.MempoolConfig, store Store, gate *notify.Gate, publisher notify.Publisher, logger *zap.Logger) *Watcher {
+	dropAfter := cfg.DropAfter
+	if dropAfter <= 0 {
+		dropAfter = defaultDropAfter
+	}
+	return &Watcher{
+		wsURL:     cfg.WSURL,
+		store:     store,
+		gate:      gate,
+		publisher: publisher,
+		tracker:   NewTracker(dropAfter),
+		logger:    logger,
+		watched:   make(map[string]bool),
+	}
+}
+
+// Run 订阅直到 ctx 取消，连接断开后自动重连；跟踪中的交易在重连后继续判断。
+// 节点只推送交易哈希时停止跟踪
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		err := w.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errHashOnly) {
+			w.logger.Error("Mempool tracking disabled", zap.Error(err))
+			return
+		}
+		w.logger.Warn("Mempool subscription lost, reconnecting",
+			zap.Error(err), zap.Duration("delay", reconnectDelay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (w *Watcher) watch(ctx context.Context) error {
+	client, err := rpc.DialContext(ctx, w.wsURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to websocket rpc: %w", err)
+	}
+	defer client.Close()
+
+	// 订阅完整交易，才能在不额外请求节点的情况下按监控地址过滤
+	pending := make(chan json.RawMessage, 1024)
+	sub, err := client.EthSubscribe(ctx, pending, "newPendingTransactions", true)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to full pending transactions: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	heads := make(chan json.RawMessage, 16)
+	headSub, err := client.EthSubscribe(ctx, heads, "newHeads")
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	defer headSub.Unsubscribe()
+
+	w.logger.Info("Subscribed to pending transactions", zap.Int("tracked", w.tracker.Len()))
+	w.refreshAddresses(ctx)
+
+	// 判断去向需要多次 RPC 请求，在单独的 goroutine 中进行，避免阻塞订阅循环导致交易池推送被丢弃；
+	// 判断期间到来的新区块合并为一次
+	resolveCtx, stopResolve := context.WithCancel(ctx)
+	resolveCh := make(chan struct{}, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-resolveCtx.Done():
+				return
+			case <-resolveCh:
+				w.resolve(resolveCtx, client)
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer stopResolve()
+
+	refresh := time.NewTicker(addressRefreshInterval)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return err
+		case err := <-headSub.Err():
+			return err
+		case raw := <-pending:
+			var hash string
+			if json.Unmarshal(raw, &hash) == nil {
+				return errHashOnly
+			}
+			var tx rpcTransaction
+			if err := json.Unmarshal(raw, &tx); err != nil {
+				w.logger.Debug("Failed to decode pending transaction", zap.Error(err))
+				continue
+			}
+			w.handle(ctx, &tx)
+		case <-heads:
+			select {
+			case resolveCh <- struct{}{}:
+			default:
+			}
+		case <-refresh.C:
+			w.refreshAddresses(ctx)
+		}
+	}
+}
+
+// handle 处理一笔待打包交易：替换跟踪中的同 nonce 交易，命中监控地址且通过推送偏好时推送并开始跟踪。
+// 先推送再跟踪，保证 pending 先于 resolve goroutine 推送的最终状态
+func (w *Watcher) handle(ctx context.Context, raw *rpcTransaction) {
+	tx := raw.transaction(time.Now())
+	if w.tracker.Has(tx.Hash) {
+		return
+	}
+	if old := w.tracker.Supersede(tx); old != nil {
+		w.publish(ctx, old)
+	}
+
+	recipients := w.recipients(ctx, raw.participants())
+	if len(recipients) == 0 {
+		return
+	}
+	tracked := &Tracked{Tx: tx, Recipients: recipients}
+	w.publish(ctx, tracked)
+	w.tracker.Add(tracked)
+}
+
+// recipients 查询监控命中地址的用户，经静音与免打扰过滤后返回用户 ID → 命中的监控地址
+func (w *Watcher) recipients(ctx context.Context, addresses []string) map[int64][]*models.WatchedAddress {
+	var matched []string
+	for _, addr := range addresses {
+		if w.watched[addr] {
+			matched = append(matched, addr)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	watchers, err := w.store.FindWatchers(matched)
+	if err != nil {
+		w.logger.Error("Failed to find address watchers", zap.Strings("addresses", matched), zap.Error(err))
+		return nil
+	}
+	recipients := make(map[int64][]*models.WatchedAddress)
+	for i := range watchers {
+		watcher := &watchers[i]
+		recipients[watcher.WatcherID] = append(recipients[watcher.WatcherID], &watcher.WatchedAddress)
+	}
+
+	// 任一命中地址放行即推送
+	for userID, was := range recipients {
+		if !w.gate.AllowAny(ctx, userID, was, EventPendingTransaction) {
+			delete(recipients, userID)
+		}
+	}
+	return recipients
+}
+
+// resolve 新区块到来时判断跟踪中交易的去向并推送状态变化
+func (w *Watcher) resolve(ctx context.Context, client *rpc.Client) {
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	resolved, err := w.tracker.Resolve(ctx, client)
+	if err != nil {
+		w.logger.Warn("Failed to resolve pending transactions", zap.Int("tracked", w.tracker.Len()), zap.Error(err))
+		return
+	}
+	for _, tracked := range resolved {
+		w.publish(ctx, tracked)
+	}
+}
+
+func (w *Watcher) refreshAddresses(ctx context.Context) {
+	addresses, err := w.store.ListWalletAddresses(ctx)
+	if err != nil {
+		w.logger.Error("Failed to list watched addresses", zap.Error(err))
+		return
+	}
+	watched := make(map[string]bool, len(addresses))
+	for _, addr := range addresses {
+		watched[addr] = true
+	}
+	w.watched = watched
+}
+
+// publish 向跟踪交易的每个用户推送当前状态，格式与 new_transaction 一致，watched_address 为首个命中的地址
+func (w *Watcher) publish(ctx context.Context, tracked *Tracked) {
+	for userID, addresses := range tracked.Recipients {
+		msg := &websocket.Message{
+			UserID: userID,
+			Type:   EventPendingTransaction,
+			Payload: map[string]interface{}{
+				"transaction":       tracked.Tx,
+				"watched_address":   addresses[0],
+				"watched_addresses": addresses,
+			},
+		}
+		if err := w.publisher.Publish(ctx, msg); err != nil {
+			w.logger.Error("Failed to publish pending transaction",
+				zap.Int64("user_id", userID), zap.String("tx_hash", tracked.Tx.Hash), zap.Error(err))
+		}
+	}
+}
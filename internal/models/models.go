@@ -1,7 +1,13 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type User struct {
@@ -13,17 +19,70 @@ type User struct {
 }
 
 type WatchedAddress struct {
+	ID        int64          `db:"id"         json:"id"`
+	UserID    int64          `db:"user_id"    json:"user_id"`
+	TeamID    *int64         `db:"team_id"    json:"team_id,omitempty"`
+	Kind      string         `db:"kind"       json:"kind"`
+	Address   string         `db:"address"    json:"address"`
+	MinAmount *string        `db:"min_amount" json:"min_amount,omitempty"`
+	Label     string         `db:"label"      json:"label"`
+	ENSName   string         `db:"ens_name"   json:"ens_name"`
+	Notes     string         `db:"notes"      json:"notes"`
+	Tags      pq.StringArray `db:"tags"       json:"tags"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// 监控类型
+const (
+	WatchKindWallet = "wallet" // 按 from/to 匹配钱包地址
+	WatchKindToken  = "token"  // 按 token_address 匹配代币合约
+)
+
+// 团队角色，权限从高到低
+const (
+	TeamRoleOwner  = "owner"
+	TeamRoleEditor = "editor"
+	TeamRoleViewer = "viewer"
+)
+
+// 团队邀请状态
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusDeclined = "declined"
+)
+
+type Team struct {
 	ID        int64     `db:"id"         json:"id"`
+	Name      string    `db:"name"       json:"name"`
+	OwnerID   int64     `db:"owner_id"   json:"owner_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type TeamMember struct {
+	TeamID    int64     `db:"team_id"    json:"team_id"`
 	UserID    int64     `db:"user_id"    json:"user_id"`
-	Address   string    `db:"address"    json:"address"`
-	Label     string    `db:"label"      json:"label"`
-	ENSName   string    `db:"ens_name"   json:"ens_name"`
+	Role      string    `db:"role"       json:"role"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
+type TeamInvite struct {
+	ID            int64     `db:"id"             json:"id"`
+	TeamID        int64     `db:"team_id"        json:"team_id"`
+	WalletAddress string    `db:"wallet_address" json:"wallet_address"`
+	Role          string    `db:"role"           json:"role"`
+	InvitedBy     int64     `db:"invited_by"     json:"invited_by"`
+	Status        string    `db:"status"         json:"status"`
+	CreatedAt     time.Time `db:"created_at"     json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"     json:"updated_at"`
+}
+
 type Transaction struct {
 	ID             int64     `db:"id"              json:"id"`
 	TxHash         string    `db:"tx_hash"         json:"tx_hash"`
+	LogIndex       int       `db:"log_index"       json:"log_index"` // 代币 Transfer 日志的序号，交易级记录为 TxLogIndex
 	BlockNumber    int64     `db:"block_number"    json:"block_number"`
 	BlockTimestamp time.Time `db:"block_timestamp" json:"block_timestamp"`
 	FromAddress    string    `db:"from_address"    json:"from_address"`
@@ -34,13 +93,575 @@ type Transaction struct {
 	TokenID        string    `db:"token_id"        json:"token_id"`
 	TokenSymbol    string    `db:"token_symbol"    json:"token_symbol"`
 	TokenDecimals  int       `db:"token_decimals"  json:"token_decimals"`
+	USDValue       *string   `db:"usd_value"       json:"usd_value"`            // 区块时间的美元价值，无法计价时为 null
+	SpamReason     string    `db:"spam_reason"     json:"spam_reason"`          // 垃圾交易分类，空表示正常交易
+	Action         *Action   `db:"action"          json:"action,omitempty"`     // 解码后的合约调用，非合约调用时为 null
+	Swap           *Swap     `db:"swap"            json:"swap,omitempty"`       // tx_type 为 SWAP 时的兑换明细
+	Approval       *Approval `db:"approval"        json:"approval,omitempty"`   // tx_type 为 APPROVAL 时的授权明细
+	Status         string    `db:"status"          json:"status"`               // success / reverted，未获取回执时为空
+	Nonce          *int64    `db:"nonce"           json:"nonce"`                // 发起方的 nonce
+	Fee            *string   `db:"fee"             json:"fee"`                  // 发起方支付的手续费（wei），含 blob 费用
+	Gas            *Gas      `db:"gas"             json:"gas,omitempty"`        // gas 与 EIP-1559 / EIP-4844 费用明细
+	InputData      string    `db:"input_data"      json:"input_data,omitempty"` // 交易 input（十六进制）
 	CreatedAt      time.Time `db:"created_at"      json:"created_at"`
+	Token          *Token    `db:"-"               json:"token,omitempty"` // 按 token_address 关联的代币元数据
 }
 
+// TxLogIndex 交易级记录（ETH 转账、授权、兑换与回填的交易）的 log_index；
+// 同一交易中的多条代币 Transfer 日志按各自的序号分行保存
+const TxLogIndex = -1
+
+// 垃圾交易分类
+const (
+	SpamReasonDenylist     = "denylist"      // 代币在拒绝列表中
+	SpamReasonZeroValue    = "zero_value"    // 零金额代币转账（地址投毒）
+	SpamReasonLookalike    = "lookalike"     // 仿冒已认证代币的符号
+	SpamReasonMassTransfer = "mass_transfer" // 未认证代币在一笔交易中发送给大量地址（空投）
+)
+
 type FeedItem struct {
+	ID               int64      `db:"id"                 json:"id"`
+	UserID           int64      `db:"user_id"            json:"user_id"`
+	TransactionID    int64      `db:"transaction_id"     json:"transaction_id"`
+	WatchedAddressID int64      `db:"watched_address_id" json:"watched_address_id"`
+	CreatedAt        time.Time  `db:"created_at"         json:"created_at"`
+	SeenAt           *time.Time `db:"seen_at"            json:"seen_at"`    // nil 表示未读
+	StarredAt        *time.Time `db:"starred_at"         json:"starred_at"` // nil 表示未加星标
+	Note             string     `db:"note"               json:"note"`
+}
+
+// 告警级别
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// 交易方向，相对于命中的钱包地址
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Direction 返回交易相对于监控钱包的方向和对手方（小写）；代币合约监控或未命中时返回空
+func (t *Transaction) Direction(wa *WatchedAddress) (string, string) {
+	if wa.Kind == WatchKindToken {
+		return "", ""
+	}
+	switch {
+	case strings.EqualFold(t.ToAddress, wa.Address):
+		return DirectionIn, strings.ToLower(t.FromAddress)
+	case strings.EqualFold(t.FromAddress, wa.Address):
+		return DirectionOut, strings.ToLower(t.ToAddress)
+	}
+	return "", ""
+}
+
+type AlertRule struct {
+	ID         int64           `db:"id"         json:"id"`
+	UserID     int64           `db:"user_id"    json:"user_id"`
+	Name       string          `db:"name"       json:"name"`
+	Severity   string          `db:"severity"   json:"severity"`
+	Enabled    bool            `db:"enabled"    json:"enabled"`
+	Conditions AlertConditions `db:"conditions" json:"conditions"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// AlertConditions 告警条件，所有非空条件需同时满足；以 JSONB 存储
+type AlertConditions struct {
+	Direction         string           `json:"direction,omitempty"`           // in / out，空表示不限
+	TxTypes           []string         `json:"tx_types,omitempty"`            // ETH / ERC20 / ERC721 / SWAP / APPROVAL
+	TokenAddresses    []string         `json:"token_addresses,omitempty"`     // 代币合约地址
+	MinAmount         *string          `json:"min_amount,omitempty"`          // 人类可读金额，含边界
+	MaxAmount         *string          `json:"max_amount,omitempty"`          // 人类可读金额，含边界
+	MinUSDValue       *string          `json:"min_usd_value,omitempty"`       // 美元价值，含边界；无法计价的交易不命中
+	MaxUSDValue       *string          `json:"max_usd_value,omitempty"`       // 美元价值，含边界；无法计价的交易不命中
+	Counterparties    []string         `json:"counterparties,omitempty"`      // 对手方地址
+	WatchedAddressIDs []int64          `json:"watched_address_ids,omitempty"` // 限定监控地址
+	TimeWindow        *AlertTimeWindow `json:"time_window,omitempty"`
+	RiskyApproval     bool             `json:"risky_approval,omitempty"` // 只匹配无限授权给未认证 spender 的 APPROVAL
+}
+
+// AlertTimeWindow 按区块时间匹配的时间窗口，Start > End 表示跨零点
+type AlertTimeWindow struct {
+	Start    string `json:"start"`              // HH:MM
+	End      string `json:"end"`                // HH:MM
+	Days     []int  `json:"days,omitempty"`     // 0=周日 ... 6=周六，空表示每天
+	Timezone string `json:"timezone,omitempty"` // IANA 时区，默认 UTC
+}
+
+func (c AlertConditions) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *AlertConditions) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case nil:
+		*c = AlertConditions{}
+		return nil
+	default:
+		return errors.New("unsupported type for AlertConditions")
+	}
+}
+
+type Alert struct {
 	ID               int64     `db:"id"                 json:"id"`
+	RuleID           int64     `db:"rule_id"            json:"rule_id"`
 	UserID           int64     `db:"user_id"            json:"user_id"`
 	TransactionID    int64     `db:"transaction_id"     json:"transaction_id"`
 	WatchedAddressID int64     `db:"watched_address_id" json:"watched_address_id"`
+	Severity         string    `db:"severity"           json:"severity"`
+	CreatedAt        time.Time `db:"created_at"         json:"created_at"`
+}
+
+// 推送状态
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookEndpoint 用户注册的推送端点，Secret 仅在创建和轮换时返回
+type WebhookEndpoint struct {
+	ID                  int64          `db:"id"                   json:"id"`
+	UserID              int64          `db:"user_id"              json:"user_id"`
+	URL                 string         `db:"url"                  json:"url"`
+	Secret              string         `db:"secret"               json:"-"`
+	Description         string         `db:"description"          json:"description"`
+	EventTypes          pq.StringArray `db:"event_types"          json:"event_types"`
+	Enabled             bool           `db:"enabled"              json:"enabled"`
+	ConsecutiveFailures int            `db:"consecutive_failures" json:"consecutive_failures"`
+	DisabledReason      string         `db:"disabled_reason"      json:"disabled_reason"`
+	CreatedAt           time.Time      `db:"created_at"           json:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at"           json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `db:"id"               json:"id"`
+	EndpointID     int64           `db:"endpoint_id"      json:"endpoint_id"`
+	EventType      string          `db:"event_type"       json:"event_type"`
+	Payload        json.RawMessage `db:"payload"          json:"payload"`
+	Status         string          `db:"status"           json:"status"`
+	Attempts       int             `db:"attempts"         json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at"  json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `db:"last_attempt_at"  json:"last_attempt_at,omitempty"`
+	LastStatusCode *int            `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      string          `db:"last_error"       json:"last_error"`
+	LastDurationMs *int            `db:"last_duration_ms" json:"last_duration_ms,omitempty"`
+	DeliveredAt    *time.Time      `db:"delivered_at"     json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `db:"created_at"       json:"created_at"`
+}
+
+// 通知渠道类型
+const (
+	ChannelTypeTelegram = "telegram"
+	ChannelTypeSlack    = "slack"
+)
+
+// NotificationChannel 用户的通知渠道，Target 为 Telegram chat_id 或 Slack webhook URL，不对外返回
+type NotificationChannel struct {
+	ID         int64          `db:"id"           json:"id"`
+	UserID     int64          `db:"user_id"      json:"user_id"`
+	Type       string         `db:"type"         json:"type"`
+	Name       string         `db:"name"         json:"name"`
+	Target     string         `db:"target"       json:"-"`
+	EventTypes pq.StringArray `db:"event_types"  json:"event_types"`
+	Enabled    bool           `db:"enabled"      json:"enabled"`
+	LastError  string         `db:"last_error"   json:"last_error"`
+	LastSentAt *time.Time     `db:"last_sent_at" json:"last_sent_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at"   json:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"   json:"updated_at"`
+}
+
+// 摘要频率
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSubscription 邮件摘要订阅，时间均以 UTC 存储
+type DigestSubscription struct {
+	ID               int64      `db:"id"                json:"id"`
+	UserID           int64      `db:"user_id"           json:"user_id"`
+	Email            string     `db:"email"             json:"email"`
+	Frequency        string     `db:"frequency"         json:"frequency"`
+	SendHour         int        `db:"send_hour"         json:"send_hour"`
+	Weekday          int        `db:"weekday"           json:"weekday"`
+	Timezone         string     `db:"timezone"          json:"timezone"`
+	Enabled          bool       `db:"enabled"           json:"enabled"`
+	UnsubscribeToken string     `db:"unsubscribe_token" json:"-"`
+	NextRunAt        time.Time  `db:"next_run_at"       json:"next_run_at"`
+	LastSentAt       *time.Time `db:"last_sent_at"      json:"last_sent_at,omitempty"`
+	CreatedAt        time.Time  `db:"created_at"        json:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"        json:"updated_at"`
+}
+
+// NotificationPreference 用户通知偏好，QuietHoursStart/End 为本地时间 HH:MM
+type NotificationPreference struct {
+	UserID            int64     `db:"user_id"             json:"user_id"`
+	Timezone          string    `db:"timezone"            json:"timezone"`
+	QuietHoursEnabled bool      `db:"quiet_hours_enabled" json:"quiet_hours_enabled"`
+	QuietHoursStart   string    `db:"quiet_hours_start"   json:"quiet_hours_start"`
+	QuietHoursEnd     string    `db:"quiet_hours_end"     json:"quiet_hours_end"`
+	RateLimitCount    int       `db:"rate_limit_count"    json:"rate_limit_count"`
+	RateLimitWindow   int       `db:"rate_limit_window"   json:"rate_limit_window"`
+	CreatedAt         time.Time `db:"created_at"          json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"          json:"updated_at"`
+}
+
+type AddressMute struct {
+	UserID           int64     `db:"user_id"            json:"user_id"`
+	WatchedAddressID int64     `db:"watched_address_id" json:"watched_address_id"`
+	MutedUntil       time.Time `db:"muted_until"        json:"muted_until"`
 	CreatedAt        time.Time `db:"created_at"         json:"created_at"`
 }
+
+// FeedFilter feed 过滤条件，所有非空条件需同时满足；以 JSONB 存储于过滤预设
+type FeedFilter struct {
+	WatchedAddressIDs []int64    `json:"watched_address_ids,omitempty"`
+	Tags              []string   `json:"tags,omitempty"`             // 监控地址需包含全部 tags
+	TxTypes           []string   `json:"tx_types,omitempty"`         // ETH / ERC20 / ERC721 / SWAP / APPROVAL
+	Tokens            []string   `json:"tokens,omitempty"`           // 代币合约地址或符号
+	Direction         string     `json:"direction,omitempty"`        // in / out，相对于监控钱包
+	MinAmount         *string    `json:"min_amount,omitempty"`       // 人类可读金额，含边界
+	MaxAmount         *string    `json:"max_amount,omitempty"`       // 人类可读金额，含边界
+	MinUSDValue       *string    `json:"min_usd_value,omitempty"`    // 美元价值，含边界；无法计价的交易不命中
+	MaxUSDValue       *string    `json:"max_usd_value,omitempty"`    // 美元价值，含边界；无法计价的交易不命中
+	FromBlock         *int64     `json:"from_block,omitempty"`       // 含边界
+	ToBlock           *int64     `json:"to_block,omitempty"`         // 含边界
+	FromTime          *time.Time `json:"from_time,omitempty"`        // 区块时间，含边界
+	ToTime            *time.Time `json:"to_time,omitempty"`          // 区块时间，不含边界
+	Counterparties    []string   `json:"counterparties,omitempty"`   // 对手方地址
+	Unread            bool       `json:"unread,omitempty"`           // 只返回未读条目
+	Starred           bool       `json:"starred,omitempty"`          // 只返回加星标的条目
+	IncludeSpam       bool       `json:"include_spam,omitempty"`     // 包含垃圾交易与用户隐藏的代币
+	ExcludeReverted   bool       `json:"exclude_reverted,omitempty"` // 排除执行失败的交易
+}
+
+func (f FeedFilter) Value() (driver.Value, error) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (f *FeedFilter) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case nil:
+		*f = FeedFilter{}
+		return nil
+	default:
+		return errors.New("unsupported type for FeedFilter")
+	}
+}
+
+// FeedFilterPreset 用户保存的 feed 过滤预设，可用于 GET /feed 与 WebSocket 订阅
+type FeedFilterPreset struct {
+	ID        int64      `db:"id"         json:"id"`
+	UserID    int64      `db:"user_id"    json:"user_id"`
+	Name      string     `db:"name"       json:"name"`
+	Filter    FeedFilter `db:"filter"     json:"filter"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// 导出任务状态
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusExpired   = "expired" // 文件已过期删除
+)
+
+// ExportJob 异步 feed 导出任务，完成后文件保留至 ExpiresAt
+type ExportJob struct {
+	ID          int64      `db:"id"           json:"id"`
+	UserID      int64      `db:"user_id"      json:"user_id"`
+	Format      string     `db:"format"       json:"format"`
+	Timezone    string     `db:"timezone"     json:"timezone"`
+	Filter      FeedFilter `db:"filter"       json:"filter"`
+	Status      string     `db:"status"       json:"status"`
+	RowCount    int64      `db:"row_count"    json:"row_count"`
+	FileSize    int64      `db:"file_size"    json:"file_size"`
+	FilePath    string     `db:"file_path"    json:"-"`
+	Error       string     `db:"error"        json:"error,omitempty"`
+	CreatedAt   time.Time  `db:"created_at"   json:"created_at"`
+	StartedAt   *time.Time `db:"started_at"   json:"started_at,omitempty"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `db:"expires_at"   json:"expires_at,omitempty"`
+}
+
+// FeedToken Atom / RSS 订阅令牌，可独立于 JWT 吊销；Address 非空时只包含该地址的条目
+type FeedToken struct {
+	ID         int64      `db:"id"           json:"id"`
+	UserID     int64      `db:"user_id"      json:"user_id"`
+	Name       string     `db:"name"         json:"name"`
+	Address    string     `db:"address"      json:"address,omitempty"`
+	TokenHash  string     `db:"token_hash"   json:"-"`
+	CreatedAt  time.Time  `db:"created_at"   json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+}
+
+// AssetPrice 按小时缓存的历史美元价格，Asset 为 ETH 或小写代币合约地址
+type AssetPrice struct {
+	Asset     string    `db:"asset"      json:"asset"`
+	Bucket    time.Time `db:"bucket"     json:"bucket"`
+	USD       string    `db:"usd"        json:"usd"`
+	Source    string    `db:"source"     json:"source"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Token 代币元数据，以小写合约地址为主键
+type Token struct {
+	Address   string     `db:"address"    json:"address"`
+	Name      string     `db:"name"       json:"name"`
+	Symbol    string     `db:"symbol"     json:"symbol"`
+	Decimals  *int       `db:"decimals"   json:"decimals"` // 合约未实现 decimals() 时为 null
+	LogoURL   string     `db:"logo_url"   json:"logo_url"`
+	Verified  bool       `db:"verified"   json:"verified"` // 是否在代币列表文件中
+	FetchedAt *time.Time `db:"fetched_at" json:"-"`        // 最近一次链上查询时间，nil 表示仅来自代币列表
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// HiddenToken 用户隐藏的代币，其转账不出现在 feed 中
+type HiddenToken struct {
+	UserID       int64     `db:"user_id"       json:"-"`
+	TokenAddress string    `db:"token_address" json:"token_address"`
+	CreatedAt    time.Time `db:"created_at"    json:"created_at"`
+}
+
+// Action 解码后的合约调用：交易 input 与回执中的事件日志
+type Action struct {
+	From         string        `json:"from"`                    // 交易发起地址
+	Contract     string        `json:"contract"`                // 被调用的合约地址
+	ContractName string        `json:"contract_name,omitempty"` // 合约名称，如 "Uniswap V2"，未收录时为空
+	Selector     string        `json:"selector"`                // input 前 4 字节
+	Method       string        `json:"method,omitempty"`        // 无法识别时为空
+	Signature    string        `json:"signature,omitempty"`     // 如 "transfer(address,uint256)"
+	Args         []ActionArg   `json:"args,omitempty"`
+	Events       []ActionEvent `json:"events,omitempty"` // 只包含可识别的事件
+}
+
+type ActionArg struct {
+	Name  string      `json:"name,omitempty"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"` // 整数为十进制字符串，地址小写，bytes 为十六进制
+}
+
+type ActionEvent struct {
+	Address      string      `json:"address"`
+	ContractName string      `json:"contract_name,omitempty"`
+	LogIndex     int         `json:"log_index"`
+	Event        string      `json:"event"`
+	Signature    string      `json:"signature"`
+	Args         []ActionArg `json:"args,omitempty"`
+}
+
+// String 可读的调用描述，如 "swapExactETHForTokens on Uniswap V2"；无法识别方法时为空
+func (a *Action) String() string {
+	if a == nil || a.Method == "" {
+		return ""
+	}
+	if a.ContractName == "" {
+		return a.Method
+	}
+	return a.Method + " on " + a.ContractName
+}
+
+func (a Action) Value() (driver.Value, error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Action) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("unsupported type for Action")
+	}
+}
+
+// ContractABI 用户上传的合约 ABI，用于为上传者及其团队成员解码该合约的调用
+type ContractABI struct {
+	Address    string          `db:"address"     json:"address"`
+	Name       string          `db:"name"        json:"name"`
+	ABI        json.RawMessage `db:"abi"         json:"abi"`
+	UploadedBy int64           `db:"uploaded_by" json:"-"`
+	Source     string          `db:"-"           json:"source"` // bundled 或 uploaded
+	CreatedAt  time.Time       `db:"created_at"  json:"created_at"`
+	UpdatedAt  time.Time       `db:"updated_at"  json:"updated_at"`
+}
+
+const (
+	ContractABISourceBundled  = "bundled"
+	ContractABISourceUploaded = "uploaded"
+)
+
+// Swap DEX 兑换：同一交易中钱包卖出的代币与买入的代币，由 Uniswap V2/V3 风格的 Swap 事件识别
+type Swap struct {
+	Protocol   string    `json:"protocol"`              // uniswap_v2 / uniswap_v3，跨协议路由时为 mixed
+	Pools      []string  `json:"pools"`                 // 按执行顺序
+	Router     string    `json:"router,omitempty"`      // 被调用的合约，直接与池交互时为空
+	RouterName string    `json:"router_name,omitempty"` // 如 "Uniswap V2"
+	Trader     string    `json:"trader"`                // 交易发起地址
+	Recipient  string    `json:"recipient"`             // 收到 token_out 的地址
+	TokenIn    SwapAsset `json:"token_in"`
+	TokenOut   SwapAsset `json:"token_out"`
+}
+
+type SwapAsset struct {
+	Address  string `json:"address"` // 代币合约地址，原生 ETH 为空
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"` // 未知时按 18 位
+	Amount   string `json:"amount"`   // 最小单位的整数
+}
+
+const (
+	SwapProtocolUniswapV2 = "uniswap_v2"
+	SwapProtocolUniswapV3 = "uniswap_v3"
+	SwapProtocolMixed     = "mixed"
+)
+
+func (s Swap) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (s *Swap) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return errors.New("unsupported type for Swap")
+	}
+}
+
+// Approval 代币授权（Approval / ApprovalForAll 事件），交易的 from 为 owner、to 为 spender
+type Approval struct {
+	Standard    string `json:"standard"`               // erc20 / nft（ERC721 与 ERC1155）
+	Spender     string `json:"spender"`                // 被授权地址，ApprovalForAll 为 operator
+	SpenderName string `json:"spender_name,omitempty"` // 已认证 spender 的名称
+	Amount      string `json:"amount,omitempty"`       // ERC20 授权额度（最小单位）
+	All         bool   `json:"all"`                    // ApprovalForAll，授权该合约下的全部 NFT
+	Unlimited   bool   `json:"unlimited"`              // ERC20 额度不低于 2^96-1，或 ApprovalForAll
+	Revoked     bool   `json:"revoked"`                // 额度为 0、取消 ApprovalForAll 或 NFT 授权给零地址
+	Risky       bool   `json:"risky"`                  // 无限授权给未认证的 spender
+	LogIndex    int    `json:"log_index"`
+
+	// Implicit 由 transferFrom 等调用附带发出的 Approval 事件，只更新授权状态，不生成交易
+	Implicit bool `json:"-"`
+}
+
+const (
+	ApprovalStandardERC20 = "erc20"
+	ApprovalStandardNFT   = "nft"
+)
+
+func (a Approval) Value() (driver.Value, error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Approval) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return errors.New("unsupported type for Approval")
+	}
+}
+
+// 交易回执状态
+const (
+	TxStatusSuccess  = "success"
+	TxStatusReverted = "reverted"
+)
+
+// Gas 交易的 gas 与手续费明细，来自交易、回执与区块头；金额均为 wei 的整数字符串
+type Gas struct {
+	Sender               string   `json:"sender"`                             // 交易发起方，即手续费支付方
+	Type                 int      `json:"type"`                               // 0 legacy / 1 access list / 2 EIP-1559 / 3 blob / 4 set code
+	Limit                uint64   `json:"limit"`                              // gas limit
+	Used                 uint64   `json:"used"`                               // gas used
+	EffectiveGasPrice    string   `json:"effective_gas_price"`                // 实际单价
+	BaseFeePerGas        string   `json:"base_fee_per_gas,omitempty"`         // 区块 base fee，London 之前为空
+	PriorityFeePerGas    string   `json:"priority_fee_per_gas,omitempty"`     // 实际支付的小费单价：effective_gas_price - base_fee_per_gas
+	MaxFeePerGas         string   `json:"max_fee_per_gas,omitempty"`          // EIP-1559 交易的上限
+	MaxPriorityFeePerGas string   `json:"max_priority_fee_per_gas,omitempty"` // EIP-1559 交易的小费上限
+	BlobGasUsed          uint64   `json:"blob_gas_used,omitempty"`            // EIP-4844 blob gas
+	BlobGasPrice         string   `json:"blob_gas_price,omitempty"`
+	MaxFeePerBlobGas     string   `json:"max_fee_per_blob_gas,omitempty"`
+	BlobVersionedHashes  []string `json:"blob_versioned_hashes,omitempty"`
+}
+
+func (g Gas) Value() (driver.Value, error) {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (g *Gas) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, g)
+	case string:
+		return json.Unmarshal([]byte(v), g)
+	default:
+		return errors.New("unsupported type for Gas")
+	}
+}
+
+// TokenAllowance 钱包当前的代币授权，由 Approval / ApprovalForAll 事件按区块顺序重建；
+// ERC20 每个 (owner, token, spender) 一条，NFT 只记录 ApprovalForAll
+type TokenAllowance struct {
+	Owner         string    `db:"owner"          json:"owner"`
+	TokenAddress  string    `db:"token_address"  json:"token_address"`
+	TokenSymbol   string    `db:"token_symbol"   json:"token_symbol"`
+	TokenDecimals int       `db:"token_decimals" json:"token_decimals"`
+	Spender       string    `db:"spender"        json:"spender"`
+	SpenderName   string    `db:"spender_name"   json:"spender_name,omitempty"`
+	Standard      string    `db:"standard"       json:"standard"`
+	Amount        string    `db:"amount"         json:"amount"` // ERC20 最近一次授权的额度，ApprovalForAll 为空
+	Unlimited     bool      `db:"unlimited"      json:"unlimited"`
+	Risky         bool      `db:"risky"          json:"risky"`
+	Revoked       bool      `db:"revoked"        json:"-"`
+	TxHash        string    `db:"tx_hash"        json:"tx_hash"`
+	BlockNumber   int64     `db:"block_number"   json:"block_number"`
+	LogIndex      int       `db:"log_index"      json:"log_index"`
+	ApprovedAt    time.Time `db:"approved_at"    json:"approved_at"` // 区块时间
+	UpdatedAt     time.Time `db:"updated_at"     json:"updated_at"`
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const telegramAPIBase = "https://api.telegram.org"
+
+// ErrChannelGone 目标已不可用（如 bot 被屏蔽、Slack webhook 被删除），应停用渠道
+var ErrChannelGone = errors.New("channel target is gone")
+
+// Channel 通知渠道，target 为 Telegram chat_id 或 Slack webhook URL
+type Channel interface {
+	Type() string
+	Send(ctx context.Context, target string, n *Notification) error
+}
+
+// Notification 渠道无关的通知内容，由各渠道渲染为自己的格式
+type Notification struct {
+	Title    string
+	Lines    []string // 纯文本，渠道负责转义
+	Link     string
+	LinkText string
+}
+
+// TelegramChannel 通过 Bot API 发送消息
+type TelegramChannel struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+func NewTelegramChannel(token string, client *http.Client) *TelegramChannel {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &TelegramChannel{token: token, baseURL: telegramAPIBase, client: client}
+}
+
+func (t *TelegramChannel) Type() string { return models.ChannelTypeTelegram }
+
+func (t *TelegramChannel) Send(ctx context.Context, chatID string, n *Notification) error {
+	var b strings.Builder
+	b.WriteString("<b>" + html.EscapeString(n.Title) + "</b>")
+	for _, line := range n.Lines {
+		b.WriteString("\n" + html.EscapeString(line))
+	}
+	if n.Link != "" {
+		b.WriteString(fmt.Sprintf("\n<a href=\"%s\">%s</a>", html.EscapeString(n.Link), html.EscapeString(n.LinkText)))
+	}
+
+	return t.SendText(ctx, chatID, b.String())
+}
+
+// SendText 发送 HTML 格式文本，供绑定流程回复使用
+func (t *TelegramChannel) SendText(ctx context.Context, chatID, text string) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.baseURL, t.token)
+	status, respBody, err := postJSON(ctx, t.client, url, body)
+	if err != nil {
+		// 请求 URL 中含 bot token，避免写入日志
+		return errors.New(strings.ReplaceAll(err.Error(), t.token, "<token>"))
+	}
+	// 403: bot 被屏蔽或踢出群组
+	if status == http.StatusForbidden {
+		return fmt.Errorf("%w: telegram %d: %s", ErrChannelGone, status, respBody)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("telegram %d: %s", status, respBody)
+	}
+	return nil
+}
+
+// SlackChannel 通过 incoming webhook 发送消息
+type SlackChannel struct {
+	client *http.Client
+}
+
+func NewSlackChannel(client *http.Client) *SlackChannel {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &SlackChannel{client: client}
+}
+
+func (s *SlackChannel) Type() string { return models.ChannelTypeSlack }
+
+func (s *SlackChannel) Send(ctx context.Context, webhookURL string, n *Notification) error {
+	var b strings.Builder
+	b.WriteString("*" + slackEscape(n.Title) + "*")
+	for _, line := range n.Lines {
+		b.WriteString("\n" + slackEscape(line))
+	}
+	if n.Link != "" {
+		b.WriteString(fmt.Sprintf("\n<%s|%s>", n.Link, slackEscape(n.LinkText)))
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"text":         b.String(),
+		"unfurl_links": false,
+	})
+
+	status, respBody, err := postJSON(ctx, s.client, webhookURL, body)
+	if err != nil {
+		// webhook URL 本身即凭证，避免写入日志
+		return errors.New(strings.ReplaceAll(err.Error(), webhookURL, "<webhook>"))
+	}
+	// 404/410: webhook 已被删除或应用已卸载
+	if status == http.StatusNotFound || status == http.StatusGone {
+		return fmt.Errorf("%w: slack %d: %s", ErrChannelGone, status, respBody)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("slack %d: %s", status, respBody)
+	}
+	return nil
+}
+
+// EnabledChannels 返回已配置的渠道实现，未配置 bot token 时不启用 Telegram
+func EnabledChannels(telegramToken string) []Channel {
+	channels := []Channel{NewSlackChannel(nil)}
+	if telegramToken != "" {
+		channels = append(channels, NewTelegramChannel(telegramToken, nil))
+	}
+	return channels
+}
+
+// slackEscape 转义 Slack mrkdwn 控制字符
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+	return resp.StatusCode, string(respBody), nil
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const (
+	ChannelConsumerGrp    = "channel:consumers"
+	ChannelConsumerPrefix = "channel-dispatcher" // 消费者名称前缀，见 consumerName
+
+	channelRateInterval = time.Second // 同一渠道两条消息的最小间隔（Telegram 单聊约 1 条/秒）
+	channelRateBurst    = 5
+	channelQueueSize    = 100 // 单渠道待发送上限，超出丢弃
+	channelIdleTimeout  = 5 * time.Minute
+)
+
+// ChannelStore 通知渠道的持久化，由 repository.ChannelRepository 实现
+type ChannelStore interface {
+	ListActive(ctx context.Context, userID int64, eventType string) ([]models.NotificationChannel, error)
+	RecordResult(ctx context.Context, id int64, errMsg string) error
+	DisableByTarget(ctx context.Context, channelType, target, reason string) error
+}
+
+type channelJob struct {
+	channel      models.NotificationChannel
+	notification *Notification
+}
+
+// channelQueue 单个渠道的发送队列，按令牌桶限速
+type channelQueue struct {
+	jobs chan channelJob
+}
+
+// ChannelDispatcher 读取 Feed Stream，渲染后发送到用户的 Telegram / Slack 渠道
+type ChannelDispatcher struct {
+	store    ChannelStore
+	redis    *redis.Client
+	channels map[string]Channel
+	explorer string
+	logger   *zap.Logger
+
+	interval time.Duration
+	burst    int
+
+	mu     sync.Mutex
+	queues map[int64]*channelQueue
+}
+
+func NewChannelDispatcher(store ChannelStore, redis *redis.Client, explorer string, logger *zap.Logger, channels ...Channel) *ChannelDispatcher {
+	byType := make(map[string]Channel, len(channels))
+	for _, ch := range channels {
+		byType[ch.Type()] = ch
+	}
+	return &ChannelDispatcher{
+		store:    store,
+		redis:    redis,
+		channels: byType,
+		explorer: explorer,
+		logger:   logger,
+		interval: channelRateInterval,
+		burst:    channelRateBurst,
+		queues:   make(map[int64]*channelQueue),
+	}
+}
+
+// Consume 读取 Feed Stream 并分发到渠道队列
+func (d *ChannelDispatcher) Consume(ctx context.Context) error {
+	return consumeStream(ctx, d.redis, ChannelConsumerGrp, consumerName(ChannelConsumerPrefix), d.logger, d.Dispatch)
+}
+
+// Dispatch 渲染事件并放入用户各渠道的发送队列
+func (d *ChannelDispatcher) Dispatch(ctx context.Context, event *streamEvent) error {
+	n, err := Render(event.Type, event.Payload, d.explorer)
+	if err != nil {
+		d.logger.Warn("Failed to render notification", zap.String("type", event.Type), zap.Error(err))
+		return nil
+	}
+	if n == nil {
+		return nil
+	}
+
+	channels, err := d.store.ListActive(ctx, event.UserID, event.Type)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range channels {
+		if _, ok := d.channels[ch.Type]; !ok {
+			continue
+		}
+		d.enqueue(ctx, channelJob{channel: ch, notification: n})
+	}
+	return nil
+}
+
+func (d *ChannelDispatcher) enqueue(ctx context.Context, job channelJob) {
+	// 入队与队列回收都在锁内进行，避免消息进入已退出的队列
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	q, ok := d.queues[job.channel.ID]
+	if !ok {
+		q = &channelQueue{jobs: make(chan channelJob, channelQueueSize)}
+		d.queues[job.channel.ID] = q
+		go d.runQueue(ctx, job.channel.ID, q)
+	}
+
+	select {
+	case q.jobs <- job:
+	default:
+		d.logger.Warn("Channel queue full, dropping notification",
+			zap.Int64("channel_id", job.channel.ID),
+			zap.String("type", job.channel.Type))
+	}
+}
+
+// runQueue 按令牌桶限速发送，空闲一段时间后退出以释放资源
+func (d *ChannelDispatcher) runQueue(ctx context.Context, channelID int64, q *channelQueue) {
+	tokens := d.burst
+	last := time.Now()
+	idle := time.NewTimer(channelIdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-idle.C:
+			d.mu.Lock()
+			if len(q.jobs) == 0 {
+				delete(d.queues, channelID)
+				d.mu.Unlock()
+				return
+			}
+			d.mu.Unlock()
+			idle.Reset(channelIdleTimeout)
+		case job := <-q.jobs:
+			// 补充令牌
+			now := time.Now()
+			tokens += int(now.Sub(last) / d.interval)
+			if tokens > d.burst {
+				tokens = d.burst
+			}
+			last = now
+
+			if tokens == 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(d.interval):
+				}
+				last = time.Now()
+			} else {
+				tokens--
+			}
+
+			d.send(ctx, job)
+			idle.Reset(channelIdleTimeout)
+		}
+	}
+}
+
+func (d *ChannelDispatcher) send(ctx context.Context, job channelJob) {
+	ch := d.channels[job.channel.Type]
+	err := ch.Send(ctx, job.channel.Target, job.notification)
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+		d.logger.Warn("Failed to send notification",
+			zap.Int64("channel_id", job.channel.ID),
+			zap.String("type", job.channel.Type),
+			zap.Error(err))
+	}
+
+	if errors.Is(err, ErrChannelGone) {
+		if err := d.store.DisableByTarget(ctx, job.channel.Type, job.channel.Target, errMsg); err != nil {
+			d.logger.Error("Failed to disable channel", zap.Int64("channel_id", job.channel.ID), zap.Error(err))
+		}
+		return
+	}
+
+	if err := d.store.RecordResult(ctx, job.channel.ID, errMsg); err != nil {
+		d.logger.Error("Failed to record channel result", zap.Int64("channel_id", job.channel.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+type fakeChannel struct {
+	mu    sync.Mutex
+	sent  []time.Time
+	err   error
+	calls chan struct{}
+}
+
+func (c *fakeChannel) Type() string { return models.ChannelTypeSlack }
+
+func (c *fakeChannel) Send(ctx context.Context, target string, n *Notification) error {
+	c.mu.Lock()
+	c.sent = append(c.sent, time.Now())
+	c.mu.Unlock()
+	c.calls <- struct{}{}
+	return c.err
+}
+
+type fakeChannelStore struct {
+	mu       sync.Mutex
+	channels []models.NotificationChannel
+	results  []string
+	disabled []string
+}
+
+func (s *fakeChannelStore) ListActive(ctx context.Context, userID int64, eventType string) ([]models.NotificationChannel, error) {
+	return s.channels, nil
+}
+
+func (s *fakeChannelStore) RecordResult(ctx context.Context, id int64, errMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, errMsg)
+	return nil
+}
+
+func (s *fakeChannelStore) DisableByTarget(ctx context.Context, channelType, target, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disabled = append(s.disabled, target)
+	return nil
+}
+
+func transactionEvent(t *testing.T) *streamEvent {
+	payload, err := json.Marshal(map[string]interface{}{
+		"transaction": models.Transaction{
+			TxHash:      "0xabc",
+			FromAddress: "0x1111111111111111111111111111111111111111",
+			ToAddress:   "0x2222222222222222222222222222222222222222",
+			Value:       "1234500000000000000000",
+			TxType:      "ETH",
+			Action:      &models.Action{Method: "swapExactETHForTokens", ContractName: "Uniswap V2"},
+		},
+		"watched_address": models.WatchedAddress{
+			Address: "0x2222222222222222222222222222222222222222",
+			Label:   "Treasury",
+		},
+	})
+	require.NoError(t, err)
+	return &streamEvent{UserID: 1, Type: "new_transaction", Payload: payload}
+}
+
+func TestRender(t *testing.T) {
+	n, err := Render("new_transaction", transactionEvent(t).Payload, ExplorerURL("mainnet"))
+	require.NoError(t, err)
+	require.NotNil(t, n)
+
+	assert.Equal(t, "Treasury received 1,234.5 ETH", n.Title)
+	assert.Equal(t, "https://etherscan.io/tx/0xabc", n.Link)
+	assert.Equal(t, "From: 0x1111…1111", n.Lines[0])
+	assert.Equal(t, "Action: swapExactETHForTokens on Uniswap V2", n.Lines[2])
+
+	n, err = Render("address_updated", json.RawMessage(`{}`), ExplorerURL("mainnet"))
+	require.NoError(t, err)
+	assert.Nil(t, n)
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		tx   models.Transaction
+		want string
+	}{
+		{models.Transaction{TxType: "ETH", Value: "1000000000000000000"}, "1 ETH"},
+		{models.Transaction{TxType: "ERC20", TokenSymbol: "USDC", Value: "1234567890000000000000000"}, "1,234,567.89 USDC"},
+		{models.Transaction{TxType: "ETH", Value: "1"}, "<0.000001 ETH"},
+		{models.Transaction{TxType: "ERC721", TokenSymbol: "BAYC", TokenID: "42"}, "BAYC #42"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatAmount(&tt.tx))
+	}
+
+	usd := "2000"
+	swap := &models.Transaction{TxType: "SWAP", USDValue: &usd, Swap: &models.Swap{
+		TokenIn:  models.SwapAsset{Symbol: "ETH", Decimals: 18, Amount: "1000000000000000000"},
+		TokenOut: models.SwapAsset{Address: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Decimals: 6, Amount: "2000500000"},
+	}}
+	assert.Equal(t, "1 ETH for 2,000.5 0xa0b8…eb48 ($2,000.00)", FormatSwap(swap))
+
+	approval := &models.Transaction{TxType: "APPROVAL", TokenSymbol: "USDC", TokenDecimals: 6, Approval: &models.Approval{
+		Standard: models.ApprovalStandardERC20, Spender: "0x2222222222222222222222222222222222222222", Amount: "1500000",
+	}}
+	assert.Equal(t, "approved 1.5 USDC to 0x2222…2222", FormatApproval(approval))
+	approval.Approval.Unlimited, approval.Approval.SpenderName = true, "Uniswap Permit2"
+	assert.Equal(t, "approved unlimited USDC to Uniswap Permit2", FormatApproval(approval))
+	approval.Approval.Revoked = true
+	assert.Equal(t, "revoked USDC approval for Uniswap Permit2", FormatApproval(approval))
+}
+
+func TestChannelDispatcher_RateLimit(t *testing.T) {
+	ch := &fakeChannel{calls: make(chan struct{}, 10)}
+	store := &fakeChannelStore{channels: []models.NotificationChannel{{ID: 1, Type: models.ChannelTypeSlack, Target: "https://hooks.slack.com/services/x"}}}
+	d := NewChannelDispatcher(store, nil, ExplorerURL("mainnet"), zap.NewNop(), ch)
+	d.interval = 50 * time.Millisecond
+	d.burst = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, d.Dispatch(ctx, transactionEvent(t)))
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-ch.calls:
+		case <-time.After(time.Second):
+			t.Fatal("notification not sent")
+		}
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	// 前 2 条立即发送，之后按间隔发送
+	assert.Less(t, ch.sent[1].Sub(ch.sent[0]), 50*time.Millisecond)
+	assert.GreaterOrEqual(t, ch.sent[3].Sub(ch.sent[0]), 100*time.Millisecond)
+}
+
+func TestChannelDispatcher_DisablesGoneChannel(t *testing.T) {
+	ch := &fakeChannel{calls: make(chan struct{}, 1), err: errors.Join(ErrChannelGone, errors.New("slack 404"))}
+	store := &fakeChannelStore{channels: []models.NotificationChannel{{ID: 1, Type: models.ChannelTypeSlack, Target: "https://hooks.slack.com/services/x"}}}
+	d := NewChannelDispatcher(store, nil, ExplorerURL("mainnet"), zap.NewNop(), ch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, d.Dispatch(ctx, transactionEvent(t)))
+	<-ch.calls
+
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.disabled) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTelegramChannel_Send(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/botsecret-token/sendMessage", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"ok":false,"description":"bot was blocked by the user"}`))
+	}))
+	defer server.Close()
+
+	tg := NewTelegramChannel("secret-token", nil)
+	tg.baseURL = server.URL
+
+	err := tg.Send(context.Background(), "42", &Notification{Title: "A <b>", Lines: []string{"x & y"}})
+	assert.ErrorIs(t, err, ErrChannelGone)
+	assert.Equal(t, "42", body["chat_id"])
+	assert.Equal(t, "<b>A &lt;b&gt;</b>\nx &amp; y", body["text"])
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	WebhookConsumerGrp    = "webhook:consumers"
+	WebhookConsumerPrefix = "webhook-dispatcher" // 消费者名称前缀，见 consumerName
+
+	MaxDeliveryAttempts  = 8                // 单条投递最大尝试次数
+	DisableAfterFailures = 20               // 端点连续失败次数达到后自动停用
+	baseBackoff          = 30 * time.Second // 首次重试间隔，之后每次翻倍
+	maxBackoff           = time.Hour
+	pollInterval         = 2 * time.Second
+	claimBatchSize       = 20
+	deliveryConcurrency  = 5
+)
+
+// WebhookStore 投递队列的持久化，由 repository.WebhookRepository 实现
+type WebhookStore interface {
+	EnqueueForUser(ctx context.Context, userID int64, eventType string, payload json.RawMessage) (int64, error)
+	ClaimDueDeliveries(ctx context.Context, limit int) ([]repository.DeliveryJob, error)
+	RecordDeliveryAttempt(ctx context.Context, attempt repository.DeliveryAttempt, disableAfter int) (bool, error)
+}
+
+// WebhookDispatcher 从 Feed Stream 读取事件写入投递队列，并按退避策略投递到用户端点
+type WebhookDispatcher struct {
+	store  WebhookStore
+	redis  *redis.Client
+	sender *WebhookSender
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewWebhookDispatcher(store WebhookStore, redis *redis.Client, sender *WebhookSender, logger *zap.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		store:  store,
+		redis:  redis,
+		sender: sender,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Backoff 第 attempt 次失败后的重试间隔
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// Consume 读取 Feed Stream，为订阅该事件的端点写入投递队列
+func (d *WebhookDispatcher) Consume(ctx context.Context) error {
+	return consumeStream(ctx, d.redis, WebhookConsumerGrp, consumerName(WebhookConsumerPrefix), d.logger,
+		func(ctx context.Context, event *streamEvent) error {
+			if !IsSupportedEvent(event.Type) {
+				return nil
+			}
+			_, err := d.store.EnqueueForUser(ctx, event.UserID, event.Type, event.Payload)
+			return err
+		})
+}
+
+// Run 定时领取到期的投递并发送
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 队列积压时连续处理，直到领取不满一批
+			for d.ProcessDue(ctx) == claimBatchSize && ctx.Err() == nil {
+			}
+		}
+	}
+}
+
+// ProcessDue 处理一批到期的投递，返回处理数量
+func (d *WebhookDispatcher) ProcessDue(ctx context.Context) int {
+	jobs, err := d.store.ClaimDueDeliveries(ctx, claimBatchSize)
+	if err != nil {
+		d.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, deliveryConcurrency)
+	for i := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(job *repository.DeliveryJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(ctx, job)
+		}(&jobs[i])
+	}
+	wg.Wait()
+
+	return len(jobs)
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, job *repository.DeliveryJob) {
+	event := &WebhookEvent{
+		ID:        job.ID,
+		Type:      job.EventType,
+		CreatedAt: job.CreatedAt,
+		Data:      job.Payload,
+	}
+
+	start := d.now()
+	statusCode, err := d.sender.Send(ctx, job.URL, job.Secret, event)
+
+	attempt := repository.DeliveryAttempt{
+		DeliveryID: job.ID,
+		EndpointID: job.EndpointID,
+		Success:    err == nil,
+		StatusCode: statusCode,
+		Duration:   d.now().Sub(start),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		if attempts := job.Attempts + 1; attempts < MaxDeliveryAttempts {
+			next := d.now().Add(Backoff(attempts))
+			attempt.NextAttemptAt = &next
+		}
+	}
+
+	disabled, err := d.store.RecordDeliveryAttempt(ctx, attempt, DisableAfterFailures)
+	if err != nil {
+		d.logger.Error("Failed to record webhook delivery",
+			zap.Int64("delivery_id", job.ID),
+			zap.Error(err))
+		return
+	}
+
+	if disabled {
+		d.logger.Warn("Webhook endpoint disabled after consecutive failures",
+			zap.Int64("endpoint_id", job.EndpointID),
+			zap.Int64("delivery_id", job.ID))
+	}
+}
@@ -0,0 +1,278 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/websocket"
+)
+
+const (
+	// EventBurstSummary 限流结束后汇总被折叠的推送
+	EventBurstSummary = "burst_summary"
+
+	rateKeyPrefix  = "notify:rate:"
+	burstKeyPrefix = "notify:burst:"
+	burstQueueKey  = "notify:bursts" // ZSET，score 为限流窗口结束时间（毫秒）
+
+	burstFlushInterval = 5 * time.Second
+	burstFlushBatch    = 100
+)
+
+// PreferenceStore 通知偏好的持久化，由 repository.PreferenceRepository 实现
+type PreferenceStore interface {
+	GetPreferences(ctx context.Context, userID int64) (*models.NotificationPreference, error)
+	IsMuted(ctx context.Context, userID, watchedAddressID int64) (bool, error)
+}
+
+// Publisher 发布消息到 Feed Stream，由 service.StreamService 实现
+type Publisher interface {
+	Publish(ctx context.Context, msg *websocket.Message) error
+}
+
+// Gate 在事件推送前应用静音、免打扰时段与单地址限流，只影响推送，不影响 feed 记录
+type Gate struct {
+	store  PreferenceStore
+	redis  *redis.Client
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewGate(store PreferenceStore, redis *redis.Client, logger *zap.Logger) *Gate {
+	return &Gate{store: store, redis: redis, logger: logger, now: time.Now}
+}
+
+// Allow 判断是否向用户推送该监控地址的事件；查询失败时放行
+// 限流只统计 new_transaction，告警只受静音与免打扰影响
+func (g *Gate) Allow(ctx context.Context, userID int64, wa *models.WatchedAddress, eventType string) bool {
+	return g.AllowAny(ctx, userID, []*models.WatchedAddress{wa}, eventType)
+}
+
+// AllowAny 判断是否向用户推送命中多个监控地址的同一事件：任一地址未静音即可推送，
+// 限流只计一次，计入首个未静音的地址
+func (g *Gate) AllowAny(ctx context.Context, userID int64, addresses []*models.WatchedAddress, eventType string) bool {
+	logger := g.logger.With(zap.Int64("user_id", userID))
+
+	var wa *models.WatchedAddress
+	for _, candidate := range addresses {
+		muted, err := g.store.IsMuted(ctx, userID, candidate.ID)
+		if err != nil {
+			logger.Warn("Failed to check address mute", zap.Int64("watched_address_id", candidate.ID), zap.Error(err))
+		} else if muted {
+			continue
+		}
+		wa = candidate
+		break
+	}
+	if wa == nil {
+		return false
+	}
+	logger = logger.With(zap.Int64("watched_address_id", wa.ID))
+
+	pref, err := g.store.GetPreferences(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to load notification preferences", zap.Error(err))
+		return true
+	}
+	if pref == nil {
+		return true
+	}
+
+	if InQuietHours(pref, g.now()) {
+		return false
+	}
+
+	if eventType != "new_transaction" || pref.RateLimitCount <= 0 {
+		return true
+	}
+	allowed, err := g.takeRate(ctx, userID, wa, pref)
+	if err != nil {
+		logger.Warn("Failed to apply rate limit", zap.Error(err))
+		return true
+	}
+	return allowed
+}
+
+// takeRate 固定窗口计数，超出上限的事件记入折叠汇总，窗口结束后由 BurstFlusher 推送
+func (g *Gate) takeRate(ctx context.Context, userID int64, wa *models.WatchedAddress, pref *models.NotificationPreference) (bool, error) {
+	member := fmt.Sprintf("%d:%d", userID, wa.ID)
+	rateKey := rateKeyPrefix + member
+	window := time.Duration(pref.RateLimitWindow) * time.Second
+
+	pipe := g.redis.TxPipeline()
+	pipe.SetNX(ctx, rateKey, 0, window)
+	incr := pipe.Incr(ctx, rateKey)
+	ttl := pipe.PTTL(ctx, rateKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	if incr.Val() <= int64(pref.RateLimitCount) {
+		return true, nil
+	}
+
+	waJSON, err := json.Marshal(wa)
+	if err != nil {
+		return false, err
+	}
+	now := g.now()
+	windowEnd := now.Add(ttl.Val())
+
+	burstKey := burstKeyPrefix + member
+	pipe = g.redis.TxPipeline()
+	pipe.HIncrBy(ctx, burstKey, "count", 1)
+	pipe.HSetNX(ctx, burstKey, "watched_address", waJSON)
+	pipe.HSetNX(ctx, burstKey, "since", now.UTC().Format(time.RFC3339))
+	pipe.HSet(ctx, burstKey, "until", windowEnd.UTC().Format(time.RFC3339))
+	pipe.ZAddNX(ctx, burstQueueKey, redis.Z{Score: float64(windowEnd.UnixMilli()), Member: member})
+	_, err = pipe.Exec(ctx)
+	return false, err
+}
+
+// InQuietHours 判断当前是否处于用户的免打扰时段，时段可跨午夜
+func InQuietHours(pref *models.NotificationPreference, now time.Time) bool {
+	if !pref.QuietHoursEnabled {
+		return false
+	}
+	start, err := ParseClock(pref.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(pref.QuietHoursEnd)
+	if err != nil || start == end {
+		return false
+	}
+
+	loc, err := time.LoadLocation(pref.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// ParseClock 解析 HH:MM，返回当天的分钟数
+func ParseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok || len(h) != 2 || len(m) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// BurstFlusher 在限流窗口结束后推送 burst_summary 事件（"还有 N 笔来自 X 的转账"）；
+// 推送前经 Gate 应用静音与免打扰，被拦截的汇总直接丢弃
+type BurstFlusher struct {
+	redis     *redis.Client
+	gate      *Gate
+	publisher Publisher
+	logger    *zap.Logger
+}
+
+func NewBurstFlusher(redis *redis.Client, gate *Gate, publisher Publisher, logger *zap.Logger) *BurstFlusher {
+	return &BurstFlusher{redis: redis, gate: gate, publisher: publisher, logger: logger}
+}
+
+func (f *BurstFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(burstFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Flush(ctx, time.Now()); err != nil {
+				f.logger.Error("Failed to flush notification bursts", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Flush 推送窗口已结束的折叠汇总；ZREM 成功者负责推送，多实例下不会重复
+func (f *BurstFlusher) Flush(ctx context.Context, now time.Time) error {
+	members, err := f.redis.ZRangeByScore(ctx, burstQueueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: burstFlushBatch,
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		removed, err := f.redis.ZRem(ctx, burstQueueKey, member).Result()
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			continue
+		}
+
+		burstKey := burstKeyPrefix + member
+		pipe := f.redis.TxPipeline()
+		fields := pipe.HGetAll(ctx, burstKey)
+		pipe.Del(ctx, burstKey)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+
+		msg, wa, err := burstMessage(member, fields.Val())
+		if err != nil {
+			f.logger.Warn("Invalid burst record", zap.String("member", member), zap.Error(err))
+			continue
+		}
+		if !f.gate.Allow(ctx, msg.UserID, wa, EventBurstSummary) {
+			continue
+		}
+		if err := f.publisher.Publish(ctx, msg); err != nil {
+			f.logger.Error("Failed to publish burst summary", zap.String("member", member), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// burstMessage 由折叠记录构造 burst_summary 消息，同时返回其监控地址
+func burstMessage(member string, fields map[string]string) (*websocket.Message, *models.WatchedAddress, error) {
+	userPart, _, _ := strings.Cut(member, ":")
+	userID, err := strconv.ParseInt(userPart, 10, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	count, err := strconv.Atoi(fields["count"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid count: %w", err)
+	}
+	var wa models.WatchedAddress
+	if err := json.Unmarshal([]byte(fields["watched_address"]), &wa); err != nil {
+		return nil, nil, fmt.Errorf("invalid watched address: %w", err)
+	}
+
+	return &websocket.Message{
+		UserID: userID,
+		Type:   EventBurstSummary,
+		Payload: map[string]interface{}{
+			"watched_address": &wa,
+			"suppressed":      count,
+			"since":           fields["since"],
+			"until":           fields["until"],
+		},
+	}, &wa, nil
+}
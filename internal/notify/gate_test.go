@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/websocket"
+)
+
+type fakePreferenceStore struct {
+	pref  *models.NotificationPreference
+	muted bool
+}
+
+func (s *fakePreferenceStore) GetPreferences(ctx context.Context, userID int64) (*models.NotificationPreference, error) {
+	return s.pref, nil
+}
+
+func (s *fakePreferenceStore) IsMuted(ctx context.Context, userID, watchedAddressID int64) (bool, error) {
+	return s.muted, nil
+}
+
+func TestParseClock(t *testing.T) {
+	minute, err := ParseClock("07:30")
+	require.NoError(t, err)
+	assert.Equal(t, 450, minute)
+
+	for _, invalid := range []string{"7:30", "24:00", "12:60", "noon", ""} {
+		_, err := ParseClock(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	pref := &models.NotificationPreference{
+		Timezone:          "Asia/Shanghai",
+		QuietHoursEnabled: true,
+		QuietHoursStart:   "22:00",
+		QuietHoursEnd:     "07:00",
+	}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	assert.True(t, InQuietHours(pref, time.Date(2026, 5, 1, 23, 0, 0, 0, shanghai)))
+	assert.True(t, InQuietHours(pref, time.Date(2026, 5, 1, 6, 59, 0, 0, shanghai)))
+	assert.False(t, InQuietHours(pref, time.Date(2026, 5, 1, 7, 0, 0, 0, shanghai)))
+	// 14:30 UTC = 22:30 上海
+	assert.True(t, InQuietHours(pref, time.Date(2026, 5, 1, 14, 30, 0, 0, time.UTC)))
+
+	pref.QuietHoursStart, pref.QuietHoursEnd = "12:00", "13:00"
+	assert.True(t, InQuietHours(pref, time.Date(2026, 5, 1, 12, 30, 0, 0, shanghai)))
+	assert.False(t, InQuietHours(pref, time.Date(2026, 5, 1, 23, 0, 0, 0, shanghai)))
+
+	pref.QuietHoursEnabled = false
+	assert.False(t, InQuietHours(pref, time.Date(2026, 5, 1, 12, 30, 0, 0, shanghai)))
+}
+
+func TestGate_Allow(t *testing.T) {
+	wa := &models.WatchedAddress{ID: 7, Address: "0x2222222222222222222222222222222222222222"}
+	now := time.Date(2026, 5, 1, 23, 0, 0, 0, time.UTC)
+
+	store := &fakePreferenceStore{}
+	gate := NewGate(store, nil, zap.NewNop())
+	gate.now = func() time.Time { return now }
+
+	assert.True(t, gate.Allow(context.Background(), 1, wa, "new_transaction"))
+
+	store.muted = true
+	assert.False(t, gate.Allow(context.Background(), 1, wa, "alert"))
+
+	store.muted = false
+	store.pref = &models.NotificationPreference{
+		Timezone: "UTC", QuietHoursEnabled: true, QuietHoursStart: "22:00", QuietHoursEnd: "07:00",
+	}
+	assert.False(t, gate.Allow(context.Background(), 1, wa, "new_transaction"))
+
+	// 非免打扰时段且未设置限流，不访问 Redis
+	now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.True(t, gate.Allow(context.Background(), 1, wa, "new_transaction"))
+}
+
+func TestBurstSummary(t *testing.T) {
+	waJSON, err := json.Marshal(models.WatchedAddress{ID: 7, Address: "0x2222222222222222222222222222222222222222", Label: "Hot wallet"})
+	require.NoError(t, err)
+
+	msg, wa, err := burstMessage("42:7", map[string]string{
+		"count":           "37",
+		"watched_address": string(waJSON),
+		"since":           "2026-05-01T14:05:00Z",
+		"until":           "2026-05-01T15:00:00Z",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), msg.UserID)
+	assert.Equal(t, EventBurstSummary, msg.Type)
+	assert.Equal(t, int64(7), wa.ID)
+
+	payload, err := json.Marshal(msg.Payload)
+	require.NoError(t, err)
+	n, err := Render(EventBurstSummary, payload, ExplorerURL("mainnet"))
+	require.NoError(t, err)
+	require.NotNil(t, n)
+	assert.Equal(t, "37 more transfers from Hot wallet", n.Title)
+	assert.Contains(t, n.Lines[0], "14:05 to 15:00 UTC")
+}
+
+type recordingPublisher struct {
+	messages []*websocket.Message
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, msg *websocket.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func TestGate_AllowAny_RateLimitsOncePerItem(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	ctx := context.Background()
+
+	hot := &models.WatchedAddress{ID: 7, Address: "0x2222222222222222222222222222222222222222"}
+	cold := &models.WatchedAddress{ID: 8, Address: "0x3333333333333333333333333333333333333333"}
+	store := &fakePreferenceStore{pref: &models.NotificationPreference{Timezone: "UTC", RateLimitCount: 1, RateLimitWindow: 60}}
+	gate := NewGate(store, rdb, zap.NewNop())
+
+	// 同时命中两个地址的条目只计一次，第二个条目超出限流
+	assert.True(t, gate.AllowAny(ctx, 1, []*models.WatchedAddress{hot, cold}, "new_transaction"))
+	assert.False(t, gate.AllowAny(ctx, 1, []*models.WatchedAddress{hot, cold}, "new_transaction"))
+	count, err := rdb.Get(ctx, rateKeyPrefix+"1:7").Int()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.False(t, mr.Exists(rateKeyPrefix+"1:8"))
+	burst, err := rdb.HGet(ctx, burstKeyPrefix+"1:7", "count").Int()
+	require.NoError(t, err)
+	assert.Equal(t, 1, burst)
+}
+
+func TestBurstFlusher_AppliesQuietHours(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	ctx := context.Background()
+
+	wa := &models.WatchedAddress{ID: 7, Address: "0x2222222222222222222222222222222222222222"}
+	store := &fakePreferenceStore{pref: &models.NotificationPreference{Timezone: "UTC", RateLimitCount: 1, RateLimitWindow: 60}}
+	gate := NewGate(store, rdb, zap.NewNop())
+	now := time.Date(2026, 5, 1, 21, 59, 30, 0, time.UTC)
+	gate.now = func() time.Time { return now }
+	publisher := &recordingPublisher{}
+	flusher := NewBurstFlusher(rdb, gate, publisher, zap.NewNop())
+
+	for i := 0; i < 3; i++ {
+		gate.Allow(ctx, 1, wa, "new_transaction")
+	}
+
+	// 窗口在 22:00 后结束，此时已进入免打扰时段，汇总被丢弃
+	store.pref.QuietHoursEnabled, store.pref.QuietHoursStart, store.pref.QuietHoursEnd = true, "22:00", "07:00"
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, flusher.Flush(ctx, now))
+	assert.Empty(t, publisher.messages)
+	assert.False(t, mr.Exists(burstKeyPrefix+"1:7"))
+
+	store.pref.QuietHoursEnabled = false
+	mr.FlushAll()
+	for i := 0; i < 3; i++ {
+		gate.Allow(ctx, 1, wa, "new_transaction")
+	}
+	require.NoError(t, flusher.Flush(ctx, now.Add(2*time.Minute)))
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, EventBurstSummary, publisher.messages[0].Type)
+}
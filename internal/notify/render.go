@@ -0,0 +1,305 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+const amountDisplayDecimals = 6
+
+// feedPayload new_transaction / alert 消息的公共字段
+type feedPayload struct {
+	Transaction    *models.Transaction    `json:"transaction"`
+	WatchedAddress *models.WatchedAddress `json:"watched_address"`
+	RuleName       string                 `json:"rule_name"`
+	Severity       string                 `json:"severity"`
+}
+
+// ExplorerURL 根据网络返回区块浏览器地址
+func ExplorerURL(network string) string {
+	switch strings.ToLower(network) {
+	case "", "mainnet":
+		return "https://etherscan.io"
+	default:
+		return fmt.Sprintf("https://%s.etherscan.io", strings.ToLower(network))
+	}
+}
+
+// burstPayload burst_summary 消息
+type burstPayload struct {
+	WatchedAddress *models.WatchedAddress `json:"watched_address"`
+	Suppressed     int                    `json:"suppressed"`
+	Since          time.Time              `json:"since"`
+	Until          time.Time              `json:"until"`
+}
+
+// Render 将 Feed Stream 事件渲染为通知，不支持的事件返回 nil
+func Render(eventType string, payload json.RawMessage, explorer string) (*Notification, error) {
+	if eventType == EventBurstSummary {
+		return renderBurst(payload)
+	}
+	if eventType != "new_transaction" && eventType != "alert" {
+		return nil, nil
+	}
+
+	var p feedPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if p.Transaction == nil || p.WatchedAddress == nil {
+		return nil, nil
+	}
+	tx, wa := p.Transaction, p.WatchedAddress
+
+	name := DisplayName(wa)
+	amount := FormatAmountWithUSD(tx)
+
+	var title string
+	switch {
+	case wa.Kind == models.WatchKindToken:
+		title = fmt.Sprintf("%s transfer: %s", name, amount)
+	case tx.Approval != nil && strings.EqualFold(tx.FromAddress, wa.Address):
+		title = fmt.Sprintf("%s %s", name, FormatApproval(tx))
+	case tx.Swap != nil && strings.EqualFold(tx.FromAddress, wa.Address):
+		title = fmt.Sprintf("%s swapped %s", name, FormatSwap(tx))
+	case strings.EqualFold(tx.ToAddress, wa.Address):
+		title = fmt.Sprintf("%s received %s", name, amount)
+	case strings.EqualFold(tx.FromAddress, wa.Address):
+		title = fmt.Sprintf("%s sent %s", name, amount)
+	default:
+		title = fmt.Sprintf("%s: %s", name, amount)
+	}
+
+	n := &Notification{
+		Title: title,
+		Lines: []string{
+			fmt.Sprintf("From: %s", ShortAddress(tx.FromAddress)),
+			fmt.Sprintf("To: %s", ShortAddress(tx.ToAddress)),
+		},
+		Link:     fmt.Sprintf("%s/tx/%s", strings.TrimRight(explorer, "/"), tx.TxHash),
+		LinkText: "View on Etherscan",
+	}
+	if action := tx.Action.String(); action != "" {
+		n.Lines = append(n.Lines, fmt.Sprintf("Action: %s", action))
+	}
+	if tx.Approval != nil && tx.Approval.Risky {
+		n.Lines = append(n.Lines, "Risk: unlimited approval to an unverified spender")
+	}
+	if tx.Status == models.TxStatusReverted {
+		n.Lines = append(n.Lines, "Status: reverted")
+	}
+	// 手续费只对发起方有意义
+	if tx.Fee != nil && tx.Gas != nil && strings.EqualFold(tx.Gas.Sender, wa.Address) {
+		n.Lines = append(n.Lines, fmt.Sprintf("Fee: %s", FormatValue(*tx.Fee, "ETH")))
+	}
+
+	if eventType == "alert" {
+		n.Title = fmt.Sprintf("[%s] %s", strings.ToUpper(p.Severity), p.RuleName)
+		n.Lines = append([]string{title}, n.Lines...)
+	}
+
+	return n, nil
+}
+
+func renderBurst(payload json.RawMessage) (*Notification, error) {
+	var p burstPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if p.WatchedAddress == nil || p.Suppressed <= 0 {
+		return nil, nil
+	}
+
+	noun := "transfers"
+	if p.Suppressed == 1 {
+		noun = "transfer"
+	}
+	return &Notification{
+		Title: fmt.Sprintf("%d more %s from %s", p.Suppressed, noun, DisplayName(p.WatchedAddress)),
+		Lines: []string{
+			fmt.Sprintf("Notifications were throttled from %s to %s UTC. All transfers are in your feed.",
+				p.Since.UTC().Format("15:04"), p.Until.UTC().Format("15:04")),
+		},
+	}, nil
+}
+
+// DisplayName 监控地址的展示名称：标签 > ENS > 缩写地址
+func DisplayName(wa *models.WatchedAddress) string {
+	if wa.Label != "" {
+		return wa.Label
+	}
+	if wa.ENSName != "" {
+		return wa.ENSName
+	}
+	return ShortAddress(wa.Address)
+}
+
+// ShortAddress 0x1234…abcd
+func ShortAddress(addr string) string {
+	if len(addr) < 12 {
+		return addr
+	}
+	return addr[:6] + "…" + addr[len(addr)-4:]
+}
+
+// FormatAmount 格式化交易金额，Value 为放大 1e18 的整数；授权为授权的额度
+func FormatAmount(tx *models.Transaction) string {
+	if tx.Approval != nil {
+		return formatAllowance(tx)
+	}
+	if tx.TxType == "ERC721" {
+		symbol := tx.TokenSymbol
+		if symbol == "" {
+			symbol = "NFT"
+		}
+		return fmt.Sprintf("%s #%s", symbol, tx.TokenID)
+	}
+
+	return FormatValue(tx.Value, AssetSymbol(tx))
+}
+
+// AssetSymbol 交易资产的展示符号，缺少符号时使用缩写合约地址
+func AssetSymbol(tx *models.Transaction) string {
+	if tx.TokenSymbol != "" {
+		return tx.TokenSymbol
+	}
+	if tx.TxType == "ETH" {
+		return "ETH"
+	}
+	return ShortAddress(tx.TokenAddress)
+}
+
+// FormatSwap 兑换的卖出与买入金额，如 "1 ETH for 2,000 USDC ($2,000.00)"
+func FormatSwap(tx *models.Transaction) string {
+	s := FormatSwapAsset(tx.Swap.TokenIn) + " for " + FormatSwapAsset(tx.Swap.TokenOut)
+	if usd := FormatUSD(tx); usd != "" {
+		s += " (" + usd + ")"
+	}
+	return s
+}
+
+// FormatSwapAsset 格式化兑换一端的最小单位数量
+func FormatSwapAsset(a models.SwapAsset) string {
+	symbol := a.Symbol
+	if symbol == "" {
+		symbol = ShortAddress(a.Address)
+	}
+	r, ok := new(big.Rat).SetString(a.Amount)
+	if !ok {
+		return "? " + symbol
+	}
+	units.ToDecimal(r, a.Decimals)
+	return formatDecimal(r) + " " + symbol
+}
+
+// FormatApproval 授权的描述，如 "approved unlimited USDC to Uniswap Permit2"、"revoked USDC approval for 0x1234…abcd"
+func FormatApproval(tx *models.Transaction) string {
+	spender := tx.Approval.SpenderName
+	if spender == "" {
+		spender = ShortAddress(tx.Approval.Spender)
+	}
+	if tx.Approval.Revoked {
+		// NFT 的单个授权以授权给零地址表示取消，spender 没有意义
+		if tx.Approval.Standard == models.ApprovalStandardNFT && !tx.Approval.All {
+			return fmt.Sprintf("revoked approval for %s", formatAllowance(tx))
+		}
+		return fmt.Sprintf("revoked %s approval for %s", AssetSymbol(tx), spender)
+	}
+	return fmt.Sprintf("approved %s to %s", formatAllowance(tx), spender)
+}
+
+// formatAllowance 授权额度：无限、ERC20 数量、全部 NFT 或单个 NFT
+func formatAllowance(tx *models.Transaction) string {
+	a := tx.Approval
+	symbol := AssetSymbol(tx)
+	switch {
+	case a.All:
+		return "all " + symbol
+	case a.Standard == models.ApprovalStandardNFT:
+		return fmt.Sprintf("%s #%s", symbol, tx.TokenID)
+	case a.Unlimited:
+		return "unlimited " + symbol
+	}
+	r, ok := new(big.Rat).SetString(a.Amount)
+	if !ok {
+		return "? " + symbol
+	}
+	units.ToDecimal(r, tx.TokenDecimals)
+	return formatDecimal(r) + " " + symbol
+}
+
+// FormatValue 格式化放大 1e18 的整数金额
+func FormatValue(value, symbol string) string {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return "? " + symbol
+	}
+	units.FromScaled(r)
+
+	return formatDecimal(r) + " " + symbol
+}
+
+// formatDecimal 保留最多 6 位小数并添加千位分隔符
+func formatDecimal(r *big.Rat) string {
+	s := r.FloatString(amountDisplayDecimals)
+	intPart, frac, _ := strings.Cut(s, ".")
+	frac = strings.TrimRight(frac, "0")
+
+	neg := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	result := groupThousands(intPart)
+	if frac != "" {
+		result += "." + frac
+	}
+	if neg {
+		result = "-" + result
+	}
+	if result == "0" && r.Sign() > 0 {
+		return "<0.000001"
+	}
+	return result
+}
+
+// FormatUSD 格式化交易的美元价值，如 "$1,234.56"；无法计价时返回空
+func FormatUSD(tx *models.Transaction) string {
+	if tx.USDValue == nil {
+		return ""
+	}
+	r, ok := new(big.Rat).SetString(*tx.USDValue)
+	if !ok {
+		return ""
+	}
+	intPart, frac, _ := strings.Cut(r.FloatString(2), ".")
+	if strings.HasPrefix(intPart, "-") {
+		return "-$" + groupThousands(strings.TrimPrefix(intPart, "-")) + "." + frac
+	}
+	return "$" + groupThousands(intPart) + "." + frac
+}
+
+// FormatAmountWithUSD 金额后附美元价值，如 "1.5 ETH ($4,500.00)"；无法计价时与 FormatAmount 相同
+func FormatAmountWithUSD(tx *models.Transaction) string {
+	amount := FormatAmount(tx)
+	if usd := FormatUSD(tx); usd != "" {
+		amount += " (" + usd + ")"
+	}
+	return amount
+}
+
+// groupThousands 为非负整数字符串添加千位分隔符
+func groupThousands(digits string) string {
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress 目标解析到内网、回环、链路本地或云元数据等非公网地址
+var ErrBlockedAddress = errors.New("destination address is not allowed")
+
+// blockedNets 除 net.IP 自带判断外需要拒绝的网段；
+// 云元数据地址 169.254.169.254 属于链路本地地址，AWS 的 fd00:ec2::254 属于 ULA 私有地址
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",      // 本网络
+	"100.64.0.0/10",  // 运营商级 NAT
+	"192.0.0.0/24",   // IETF 协议分配
+	"198.18.0.0/15",  // 基准测试
+	"240.0.0.0/4",    // 保留
+	"64:ff9b::/96",   // NAT64，可映射到任意 IPv4
+	"64:ff9b:1::/48", // 本地 NAT64
+	"2001:db8::/32",  // 文档
+	"2002::/16",      // 6to4，可映射到任意 IPv4
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
+
+// IsPublicIP 判断是否为可推送的公网单播地址
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.Equal(net.IPv4bcast) {
+		return false
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPublicHost 解析主机名并确认所有地址均为公网地址，用于保存端点时提前校验；
+// 实际推送时由 NewSafeHTTPClient 在建立连接时再次校验，防止 DNS 重绑定
+func CheckPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return ErrBlockedAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve host: %w", err)
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
+
+// NewSafeHTTPClient 创建只连接公网地址、不跟随重定向的 HTTP 客户端，用于向用户提供的 URL 推送。
+// 地址在解析后、连接前校验（net.Dialer.Control），不使用环境变量中的代理
+func NewSafeHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: timeout,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// 重定向可指向内网地址，3xx 按失败处理
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/service"
+)
+
+// streamEvent Feed Stream 中的消息，Payload 保留原始 JSON
+type streamEvent struct {
+	UserID  int64           `json:"user_id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+const (
+	streamReadCount = 50
+	// streamClaimMinIdle 处理失败（未确认）的消息至少空闲该时长后才重新认领，作为重试间隔
+	streamClaimMinIdle  = time.Minute
+	streamClaimInterval = 10 * time.Second
+	// streamMaxDeliveries 最多处理次数，超过后移入死信 Stream 并确认
+	streamMaxDeliveries    = 5
+	streamDeadLetterMaxLen = 10000
+)
+
+// DeadLetterStream 消费者组的死信 Stream，保存多次处理失败的原始消息
+func DeadLetterStream(group string) string {
+	return service.FeedStream + ":dead:" + group
+}
+
+// consumerName 消费者组内的消费者名称，由前缀、主机名与进程号组成；
+// 多实例部署时各实例使用不同的名称，不会读取或确认彼此的待确认消息
+func consumerName(prefix string) string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%s-%d", prefix, host, os.Getpid())
+}
+
+// consumeStream 以独立消费者组读取 Feed Stream，与 WebSocket 推送互不影响
+// handle 返回错误时不确认，消息空闲 streamClaimMinIdle 后通过 XAUTOCLAIM 重新认领处理（包括其他实例或重启前遗留的消息）；
+// 处理次数超过 streamMaxDeliveries 的消息移入死信 Stream
+func consumeStream(ctx context.Context, rdb *redis.Client, group, consumer string, logger *zap.Logger,
+	handle func(ctx context.Context, event *streamEvent) error) error {
+	// 从当前位置开始消费，不回放历史事件
+	err := rdb.XGroupCreateMkStream(ctx, service.FeedStream, group, "$").Err()
+	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	logger.Info("started consuming from stream",
+		zap.String("stream", service.FeedStream),
+		zap.String("group", group),
+		zap.String("consumer", consumer))
+
+	c := &streamConsumer{rdb: rdb, group: group, consumer: consumer, logger: logger, handle: handle}
+	var nextClaim time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if now := time.Now(); !now.Before(nextClaim) {
+			c.claimStale(ctx)
+			nextClaim = now.Add(streamClaimInterval)
+		}
+
+		streams, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{service.FeedStream, ">"},
+			Count:    streamReadCount,
+			Block:    time.Second,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.Error("failed to read from stream", zap.String("group", group), zap.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				c.process(ctx, message)
+			}
+		}
+	}
+}
+
+type streamConsumer struct {
+	rdb      *redis.Client
+	group    string
+	consumer string
+	logger   *zap.Logger
+	handle   func(ctx context.Context, event *streamEvent) error
+}
+
+// process 处理一条消息，成功后确认；失败时保留在待确认列表中等待重新认领
+func (c *streamConsumer) process(ctx context.Context, message redis.XMessage) {
+	if err := handleMessage(ctx, message, c.handle); err != nil {
+		c.logger.Error("failed to handle stream message",
+			zap.String("group", c.group),
+			zap.String("message_id", message.ID),
+			zap.Error(err))
+		return
+	}
+	c.rdb.XAck(ctx, service.FeedStream, c.group, message.ID)
+}
+
+// claimStale 认领空闲超过 streamClaimMinIdle 的待确认消息并重新处理，超过最大处理次数的移入死信
+func (c *streamConsumer) claimStale(ctx context.Context) {
+	start := "0-0"
+	for {
+		messages, next, err := c.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   service.FeedStream,
+			Group:    c.group,
+			Consumer: c.consumer,
+			MinIdle:  streamClaimMinIdle,
+			Start:    start,
+			Count:    streamReadCount,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				c.logger.Error("failed to claim pending messages", zap.String("group", c.group), zap.Error(err))
+			}
+			return
+		}
+
+		deliveries := c.deliveryCounts(ctx, messages)
+		for _, message := range messages {
+			// 认领会增加投递次数，此时的次数包含本次处理
+			if deliveries[message.ID] > streamMaxDeliveries {
+				c.deadLetter(ctx, message, deliveries[message.ID])
+				continue
+			}
+			c.process(ctx, message)
+		}
+
+		if next == "" || next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// deliveryCounts 查询认领到的消息的投递次数；查询失败时返回空，本轮照常处理
+func (c *streamConsumer) deliveryCounts(ctx context.Context, messages []redis.XMessage) map[string]int64 {
+	if len(messages) == 0 {
+		return nil
+	}
+	pending, err := c.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   service.FeedStream,
+		Group:    c.group,
+		Start:    messages[0].ID,
+		End:      messages[len(messages)-1].ID,
+		Count:    int64(len(messages)),
+		Consumer: c.consumer,
+	}).Result()
+	if err != nil {
+		c.logger.Warn("failed to get delivery counts", zap.String("group", c.group), zap.Error(err))
+		return nil
+	}
+	counts := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		counts[p.ID] = p.RetryCount
+	}
+	return counts
+}
+
+// deadLetter 将消息写入死信 Stream 后确认；写入失败时保留，下次认领时重试
+func (c *streamConsumer) deadLetter(ctx context.Context, message redis.XMessage, deliveries int64) {
+	values := map[string]interface{}{
+		"message_id": message.ID,
+		"deliveries": deliveries,
+	}
+	if payload, ok := message.Values["payload"].(string); ok {
+		values["payload"] = payload
+	}
+	if err := c.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: DeadLetterStream(c.group),
+		MaxLen: streamDeadLetterMaxLen,
+		Approx: true,
+		Values: values,
+	}).Err(); err != nil {
+		c.logger.Error("failed to move message to dead letter stream",
+			zap.String("group", c.group),
+			zap.String("message_id", message.ID),
+			zap.Error(err))
+		return
+	}
+	c.rdb.XAck(ctx, service.FeedStream, c.group, message.ID)
+	c.logger.Warn("stream message exceeded max deliveries, moved to dead letter stream",
+		zap.String("group", c.group),
+		zap.String("message_id", message.ID),
+		zap.Int64("deliveries", deliveries))
+}
+
+func handleMessage(ctx context.Context, msg redis.XMessage,
+	handle func(ctx context.Context, event *streamEvent) error) error {
+	payload, ok := msg.Values["payload"].(string)
+	if !ok {
+		return nil
+	}
+
+	var event streamEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil || event.UserID == 0 {
+		// 无法解析的消息直接丢弃
+		return nil
+	}
+
+	return handle(ctx, &event)
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/service"
+)
+
+func TestStreamConsumer_RetriesThenDeadLetters(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	const group = "test:consumers"
+	now := time.Now()
+	mr.SetTime(now)
+	require.NoError(t, rdb.XGroupCreateMkStream(ctx, service.FeedStream, group, "$").Err())
+	require.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: service.FeedStream,
+		Values: map[string]interface{}{"payload": `{"user_id":1,"type":"alert","payload":{}}`},
+	}).Err())
+
+	var calls int
+	c := &streamConsumer{rdb: rdb, group: group, consumer: "c1", logger: zap.NewNop(),
+		handle: func(ctx context.Context, event *streamEvent) error {
+			calls++
+			return errors.New("endpoint store unavailable")
+		}}
+
+	streams, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: group, Consumer: "c1", Streams: []string{service.FeedStream, ">"}, Count: 10,
+	}).Result()
+	require.NoError(t, err)
+	require.Len(t, streams[0].Messages, 1)
+	c.process(ctx, streams[0].Messages[0])
+	assert.Equal(t, 1, calls)
+
+	// 未达到最小空闲时间不重新认领
+	c.claimStale(ctx)
+	assert.Equal(t, 1, calls)
+
+	for i := 2; i <= streamMaxDeliveries; i++ {
+		now = now.Add(streamClaimMinIdle + time.Second)
+		mr.SetTime(now)
+		c.claimStale(ctx)
+		assert.Equal(t, i, calls)
+	}
+
+	// 超过最大处理次数后移入死信 Stream 并确认
+	now = now.Add(streamClaimMinIdle + time.Second)
+	mr.SetTime(now)
+	c.claimStale(ctx)
+	assert.Equal(t, streamMaxDeliveries, calls)
+
+	pending, err := rdb.XPending(ctx, service.FeedStream, group).Result()
+	require.NoError(t, err)
+	assert.Zero(t, pending.Count)
+
+	dead, err := rdb.XRange(ctx, DeadLetterStream(group), "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, streams[0].Messages[0].ID, dead[0].Values["message_id"])
+	assert.Equal(t, `{"user_id":1,"type":"alert","payload":{}}`, dead[0].Values["payload"])
+}
+
+func TestStreamConsumer_AcksAfterRetrySucceeds(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	const group = "test:consumers"
+	now := time.Now()
+	mr.SetTime(now)
+	require.NoError(t, rdb.XGroupCreateMkStream(ctx, service.FeedStream, group, "$").Err())
+	require.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: service.FeedStream,
+		Values: map[string]interface{}{"payload": `{"user_id":1,"type":"alert","payload":{}}`},
+	}).Err())
+
+	fail := true
+	c := &streamConsumer{rdb: rdb, group: group, consumer: "c1", logger: zap.NewNop(),
+		handle: func(ctx context.Context, event *streamEvent) error {
+			if fail {
+				return errors.New("temporary failure")
+			}
+			return nil
+		}}
+
+	// 消息由另一个已下线的消费者读取后未确认
+	streams, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: group, Consumer: "c0", Streams: []string{service.FeedStream, ">"}, Count: 10,
+	}).Result()
+	require.NoError(t, err)
+	require.Len(t, streams[0].Messages, 1)
+
+	fail = false
+	mr.SetTime(now.Add(streamClaimMinIdle + time.Second))
+	c.claimStale(ctx)
+
+	pending, err := rdb.XPending(ctx, service.FeedStream, group).Result()
+	require.NoError(t, err)
+	assert.Zero(t, pending.Count)
+	n, err := rdb.XLen(ctx, DeadLetterStream(group)).Result()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
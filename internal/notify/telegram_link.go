@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	telegramLinkPrefix = "telegram:link:"
+	TelegramLinkTTL    = 10 * time.Minute
+)
+
+// CreateTelegramLinkCode 生成一次性绑定码，用户在 Telegram 中向 bot 发送 /start <code> 完成绑定
+func CreateTelegramLinkCode(ctx context.Context, rdb *redis.Client, userID int64) (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+
+	if err := rdb.Set(ctx, telegramLinkPrefix+code, userID, TelegramLinkTTL).Err(); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ConsumeTelegramLinkCode 校验并销毁绑定码，返回对应用户；码无效或已过期时返回 0
+func ConsumeTelegramLinkCode(ctx context.Context, rdb *redis.Client, code string) (int64, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return 0, nil
+	}
+
+	value, err := rdb.GetDel(ctx, telegramLinkPrefix+code).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// TelegramDeepLink 打开 bot 并自动发送 /start <code>
+func TelegramDeepLink(botUsername, code string) string {
+	if botUsername == "" {
+		return ""
+	}
+	return "https://t.me/" + strings.TrimPrefix(botUsername, "@") + "?start=" + code
+}
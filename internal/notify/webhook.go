@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 推送请求头
+const (
+	SignatureHeader = "X-ChainFeed-Signature" // hex(HMAC-SHA256(secret, body))
+	EventHeader     = "X-ChainFeed-Event"
+	DeliveryHeader  = "X-ChainFeed-Delivery"
+)
+
+// SupportedEvents 可订阅的事件类型，与 Feed Stream 中的消息类型一致
+// Feed Stream 中的其他消息（如已读状态同步）只用于 WebSocket 多端同步，不推送给外部端点
+var SupportedEvents = []string{"new_transaction", "alert", "address_updated", EventBurstSummary}
+
+// IsSupportedEvent 判断事件类型是否可订阅
+func IsSupportedEvent(eventType string) bool {
+	for _, e := range SupportedEvents {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	webhookTimeout     = 10 * time.Second
+	maxErrorBodyLength = 512
+)
+
+// WebhookEvent 推送给用户端点的请求体
+type WebhookEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Sign 计算请求体签名，与 Alchemy 推送的 X-Alchemy-Signature 算法一致
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret 生成端点签名密钥
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// WebhookSender 向用户端点发送签名后的 HTTP 请求
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender client 为 nil 时使用只连接公网地址、不跟随重定向的客户端
+func NewWebhookSender(client *http.Client) *WebhookSender {
+	if client == nil {
+		client = NewSafeHTTPClient(webhookTimeout)
+	}
+	return &WebhookSender{client: client}
+}
+
+// Send 发送事件，2xx 视为成功；返回响应状态码（未收到响应时为 0）
+func (s *WebhookSender) Send(ctx context.Context, url, secret string, event *WebhookEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ChainFeed-Webhook/1.0")
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(event.ID, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 响应体可能包含内网服务的内容，错误中只记录状态码
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodyLength))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	jobs     []repository.DeliveryJob
+	attempts []repository.DeliveryAttempt
+}
+
+func (s *fakeStore) EnqueueForUser(ctx context.Context, userID int64, eventType string, payload json.RawMessage) (int64, error) {
+	return 0, nil
+}
+
+func (s *fakeStore) ClaimDueDeliveries(ctx context.Context, limit int) ([]repository.DeliveryJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := s.jobs
+	s.jobs = nil
+	return jobs, nil
+}
+
+func (s *fakeStore) RecordDeliveryAttempt(ctx context.Context, attempt repository.DeliveryAttempt, disableAfter int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, attempt)
+	return false, nil
+}
+
+func newJob(id int64, url string, attempts int) repository.DeliveryJob {
+	return repository.DeliveryJob{
+		WebhookDelivery: models.WebhookDelivery{
+			ID:         id,
+			EndpointID: 1,
+			EventType:  "new_transaction",
+			Payload:    json.RawMessage(`{"tx_hash":"0xabc"}`),
+			Attempts:   attempts,
+		},
+		URL:    url,
+		Secret: "whsec_test",
+	}
+}
+
+func TestWebhookSender_SignsRequest(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	event := &WebhookEvent{ID: 42, Type: "alert", Data: json.RawMessage(`{"rule_id":1}`)}
+	status, err := NewWebhookSender(server.Client()).Send(context.Background(), server.URL, "whsec_test", event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	assert.Equal(t, Sign("whsec_test", body), received.Header.Get(SignatureHeader))
+	assert.Equal(t, "alert", received.Header.Get(EventHeader))
+	assert.Equal(t, "42", received.Header.Get(DeliveryHeader))
+
+	var decoded WebhookEvent
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.JSONEq(t, `{"rule_id":1}`, string(decoded.Data))
+}
+
+func TestWebhookDispatcher_ProcessDue(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{}
+	d := NewWebhookDispatcher(store, nil, NewWebhookSender(server.Client()), zap.NewNop())
+	d.now = func() time.Time { return now }
+
+	t.Run("success", func(t *testing.T) {
+		store.jobs = []repository.DeliveryJob{newJob(1, server.URL, 0)}
+		assert.Equal(t, 1, d.ProcessDue(context.Background()))
+		require.Len(t, store.attempts, 1)
+		assert.True(t, store.attempts[0].Success)
+		assert.Equal(t, http.StatusOK, store.attempts[0].StatusCode)
+		assert.Nil(t, store.attempts[0].NextAttemptAt)
+	})
+
+	t.Run("retry with backoff", func(t *testing.T) {
+		failing.Store(true)
+		store.attempts = nil
+		store.jobs = []repository.DeliveryJob{newJob(2, server.URL, 2)}
+		d.ProcessDue(context.Background())
+		require.Len(t, store.attempts, 1)
+		attempt := store.attempts[0]
+		assert.False(t, attempt.Success)
+		assert.Equal(t, http.StatusInternalServerError, attempt.StatusCode)
+		assert.Equal(t, "unexpected status 500", attempt.Error) // 不记录响应体
+		require.NotNil(t, attempt.NextAttemptAt)
+		assert.Equal(t, now.Add(2*time.Minute), *attempt.NextAttemptAt)
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		store.attempts = nil
+		store.jobs = []repository.DeliveryJob{newJob(3, server.URL, MaxDeliveryAttempts-1)}
+		d.ProcessDue(context.Background())
+		require.Len(t, store.attempts, 1)
+		assert.False(t, store.attempts[0].Success)
+		assert.Nil(t, store.attempts[0].NextAttemptAt)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.0.0.1", "172.16.5.4", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "100.64.0.1", "::1", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1", "64:ff9b::7f00:1"} {
+		assert.False(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.True(t, IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestWebhookSender_BlocksPrivateTargets(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	// 默认客户端在连接时拒绝回环地址（即使 DNS 解析结果在保存后发生变化）
+	event := &WebhookEvent{ID: 1, Type: "alert", Data: json.RawMessage(`{}`)}
+	status, err := NewWebhookSender(nil).Send(context.Background(), server.URL, "whsec_test", event)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.Zero(t, status)
+	assert.Zero(t, hits.Load())
+}
+
+func TestWebhookSender_DoesNotFollowRedirects(t *testing.T) {
+	var internalHits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalHits.Add(1)
+	}))
+	defer internal.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusTemporaryRedirect)
+	}))
+	defer redirect.Close()
+
+	// 测试服务器位于回环地址，只替换拨号以验证重定向策略
+	client := NewSafeHTTPClient(webhookTimeout)
+	client.Transport = redirect.Client().Transport
+	event := &WebhookEvent{ID: 1, Type: "alert", Data: json.RawMessage(`{}`)}
+	status, err := NewWebhookSender(client).Send(context.Background(), redirect.URL, "whsec_test", event)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+	assert.Zero(t, internalHits.Load())
+}
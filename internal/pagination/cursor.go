@@ -0,0 +1,153 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// 计数方式
+const (
+	CountNone     = ""
+	CountExact    = "exact"
+	CountEstimate = "estimate" // 使用查询计划器的行数估算，适合大表
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor 键集分页位置：按 (Time, ID) 降序排列的某一行
+type Cursor struct {
+	Time time.Time
+	ID   int64
+}
+
+// Encode 编码为不透明的 URL 安全字符串
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.Time.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func Decode(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	micros, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{Time: time.UnixMicro(micros).UTC(), ID: rowID}, nil
+}
+
+// newerThan 判断在 (Time, ID) 顺序上是否晚于 other
+func (c Cursor) newerThan(other Cursor) bool {
+	if !c.Time.Equal(other.Time) {
+		return c.Time.After(other.Time)
+	}
+	return c.ID > other.ID
+}
+
+// Query 分页请求：Before 取更早的数据（翻页），After 取更新的数据（轮询新条目），二者互斥。
+// 仅设置 After 时为普通的升序键集，导出等按顺序遍历全部数据时使用
+type Query struct {
+	Limit  int
+	Before *Cursor
+	After  *Cursor
+	Count  string
+	// Overlap 轮询时补查游标之前一段时间内晚提交的行，结果可能与已返回的数据重复（客户端按 id 去重）；
+	// 仅供 HTTP 轮询接口使用，需同时设置 After
+	Overlap bool
+}
+
+// Parse 解析 limit / cursor / since / count 参数
+func Parse(limit, cursor, since, count string) (*Query, error) {
+	q := &Query{Limit: DefaultLimit, Count: count}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+		}
+		q.Limit = n
+	}
+	if cursor != "" && since != "" {
+		return nil, errors.New("cursor and since cannot be used together")
+	}
+
+	var err error
+	if cursor != "" {
+		if q.Before, err = Decode(cursor); err != nil {
+			return nil, err
+		}
+	}
+	if since != "" {
+		if q.After, err = Decode(since); err != nil {
+			return nil, err
+		}
+		q.Overlap = true
+	}
+
+	switch count {
+	case CountNone, CountExact, CountEstimate:
+	default:
+		return nil, errors.New("count must be exact or estimate")
+	}
+	return q, nil
+}
+
+// Page 分页结果的游标部分
+type Page struct {
+	NextCursor     string `json:"next_cursor,omitempty"` // 传给 cursor 获取更早的数据
+	PrevCursor     string `json:"prev_cursor,omitempty"` // 传给 since 轮询更新的数据
+	HasMore        bool   `json:"has_more"`
+	Limit          int    `json:"limit"`
+	TotalCount     *int64 `json:"total_count,omitempty"`
+	CountEstimated bool   `json:"count_estimated,omitempty"`
+}
+
+// NewPage 根据查询结果构造游标；keys 为按降序排列的每行位置，
+// hasMore 表示查询方向上还有更多数据（调用方多取一行判断）
+func NewPage(q *Query, keys []Cursor, hasMore bool) Page {
+	p := Page{Limit: q.Limit, HasMore: hasMore}
+
+	p.PrevCursor = PollCursor(q, keys)
+	// 轮询模式下更早的数据客户端已有，无需 next_cursor
+	if len(keys) > 0 && q.After == nil && hasMore {
+		p.NextCursor = keys[len(keys)-1].Encode()
+	}
+	return p
+}
+
+// PollCursor 返回传给 since 的轮询位置：keys 中最新的位置（不要求有序）；
+// 轮询模式下结果可能只有游标之前补回的数据或没有数据，此时保持原位置，游标不后退
+func PollCursor(q *Query, keys []Cursor) string {
+	var newest *Cursor
+	if q.After != nil {
+		newest = q.After
+	}
+	for i := range keys {
+		if newest == nil || keys[i].newerThan(*newest) {
+			newest = &keys[i]
+		}
+	}
+	if newest == nil {
+		return ""
+	}
+	return newest.Encode()
+}
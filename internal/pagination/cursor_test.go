@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := Cursor{Time: time.Date(2026, 5, 1, 14, 5, 6, 123456000, time.UTC), ID: 987}
+	decoded, err := Decode(c.Encode())
+	require.NoError(t, err)
+	assert.True(t, c.Time.Equal(decoded.Time))
+	assert.Equal(t, c.ID, decoded.ID)
+
+	for _, invalid := range []string{"!!!", "MTIz", Cursor{}.Encode()[:3]} {
+		_, err := Decode(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+}
+
+func TestParse(t *testing.T) {
+	q, err := Parse("", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLimit, q.Limit)
+	assert.Nil(t, q.Before)
+	assert.Nil(t, q.After)
+
+	cursor := Cursor{Time: time.Now(), ID: 1}.Encode()
+	q, err = Parse("50", cursor, "", CountEstimate)
+	require.NoError(t, err)
+	assert.Equal(t, 50, q.Limit)
+	assert.NotNil(t, q.Before)
+	assert.Equal(t, CountEstimate, q.Count)
+	assert.False(t, q.Overlap)
+
+	// 接口的 since 轮询补查游标之前晚提交的数据
+	q, err = Parse("", "", cursor, "")
+	require.NoError(t, err)
+	assert.NotNil(t, q.After)
+	assert.True(t, q.Overlap)
+
+	for _, args := range [][4]string{
+		{"0", "", "", ""},
+		{"101", "", "", ""},
+		{"", cursor, cursor, ""},
+		{"", "bogus", "", ""},
+		{"", "", "", "approx"},
+	} {
+		_, err := Parse(args[0], args[1], args[2], args[3])
+		assert.Error(t, err, args)
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	base := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	keys := []Cursor{{Time: base.Add(2 * time.Minute), ID: 3}, {Time: base.Add(time.Minute), ID: 2}}
+
+	p := NewPage(&Query{Limit: 2}, keys, true)
+	assert.Equal(t, keys[0].Encode(), p.PrevCursor)
+	assert.Equal(t, keys[1].Encode(), p.NextCursor)
+
+	p = NewPage(&Query{Limit: 2}, keys, false)
+	assert.Empty(t, p.NextCursor)
+
+	// 轮询模式没有新数据时保持原位置
+	after := &Cursor{Time: base, ID: 1}
+	p = NewPage(&Query{Limit: 2, After: after}, nil, false)
+	assert.Equal(t, after.Encode(), p.PrevCursor)
+	assert.Empty(t, p.NextCursor)
+
+	p = NewPage(&Query{Limit: 2, After: after}, keys, true)
+	assert.Equal(t, keys[0].Encode(), p.PrevCursor)
+	assert.Empty(t, p.NextCursor)
+
+	// 只有游标之前补回的数据时游标不后退
+	p = NewPage(&Query{Limit: 2, After: &keys[0]}, keys[1:], false)
+	assert.Equal(t, keys[0].Encode(), p.PrevCursor)
+}
+
+func TestPollCursor(t *testing.T) {
+	base := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	older := Cursor{Time: base, ID: 9}
+	newer := Cursor{Time: base.Add(time.Minute), ID: 2}
+
+	assert.Empty(t, PollCursor(&Query{}, nil))
+	// 按其他列排序的结果取其中最新的位置
+	assert.Equal(t, newer.Encode(), PollCursor(&Query{}, []Cursor{older, newer}))
+	assert.Equal(t, newer.Encode(), PollCursor(&Query{After: &older}, []Cursor{older, newer}))
+	assert.Equal(t, newer.Encode(), PollCursor(&Query{After: &newer}, []Cursor{older}))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/units"
 )
 
 type AlchemyWebhook struct {
@@ -21,6 +22,37 @@ type AlchemyWebhook struct {
 type AlchemyWebhookEvent struct {
 	Network  string                 `json:"network"`
 	Activity []AlchemyActivityEvent `json:"activity"`
+	Data     *AlchemyGraphQLData    `json:"data,omitempty"` // Custom Webhook (GRAPHQL) 数据
+}
+
+// AlchemyGraphQLData Custom Webhook 推送的区块日志，用于监控代币合约的所有转账
+type AlchemyGraphQLData struct {
+	Block AlchemyGraphQLBlock `json:"block"`
+}
+
+type AlchemyGraphQLBlock struct {
+	Hash      string              `json:"hash"`
+	Number    int64               `json:"number"`
+	Timestamp int64               `json:"timestamp"`
+	Logs      []AlchemyGraphQLLog `json:"logs"`
+}
+
+type AlchemyGraphQLLog struct {
+	Data        string                    `json:"data"`
+	Topics      []string                  `json:"topics"`
+	Index       int                       `json:"index"`
+	Account     AlchemyGraphQLAccount     `json:"account"`
+	Transaction AlchemyGraphQLTransaction `json:"transaction"`
+}
+
+type AlchemyGraphQLAccount struct {
+	Address string `json:"address"`
+}
+
+type AlchemyGraphQLTransaction struct {
+	Hash string                `json:"hash"`
+	From AlchemyGraphQLAccount `json:"from"`
+	To   AlchemyGraphQLAccount `json:"to"`
 }
 
 type AlchemyActivityEvent struct {
@@ -38,6 +70,7 @@ type AlchemyActivityEvent struct {
 
 type AlchemyRawContract struct {
 	Value    string `json:"value"`
+	RawValue string `json:"rawValue"` // 未按精度换算的原始数量（十六进制）
 	Address  string `json:"address"`
 	Decimals int    `json:"decimals"`
 }
@@ -54,6 +87,28 @@ type AlchemyLog struct {
 	Removed          bool     `json:"removed"`
 }
 
+const (
+	WebhookTypeAddressActivity = "ADDRESS_ACTIVITY"
+	WebhookTypeGraphQL         = "GRAPHQL"
+
+	// TransferEventTopic keccak256("Transfer(address,address,uint256)")，ERC20 与 ERC721 共用
+	TransferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// ApprovalEventTopic keccak256("Approval(address,address,uint256)")，ERC20 与 ERC721 共用
+	ApprovalEventTopic = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+	// ApprovalForAllEventTopic keccak256("ApprovalForAll(address,address,bool)")，ERC721 与 ERC1155
+	ApprovalForAllEventTopic = "0x17307eab39ab6107e8899845ad3d59bd9653f200f220920489ca2b5937696c31"
+
+	zeroAddress = "0x0000000000000000000000000000000000000000"
+)
+
+// unlimitedAllowance 不低于 2^96-1 的授权额度视为无限授权：除 2^256-1 外，
+// UNI / COMP 等 uint96 代币与 Permit2（uint160）以各自的最大值表示无限
+var unlimitedAllowance = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1))
+
+// defaultTokenDecimals 区块日志中不含 decimals，ERC20 数量先按 18 位换算，
+// 再由代币注册表按链上 decimals 重新换算（见 tokens.Registry.Enrich）
+const defaultTokenDecimals = 18
+
 type TransactionParser struct{}
 
 func NewTransactionParser() *TransactionParser {
@@ -61,6 +116,10 @@ func NewTransactionParser() *TransactionParser {
 }
 
 func (p *TransactionParser) ParseAlchemyWebhook(webhook *AlchemyWebhook) ([]*models.Transaction, error) {
+	if webhook.Type == WebhookTypeGraphQL {
+		return p.parseGraphQLLogs(webhook.Event.Data)
+	}
+
 	var transactions []*models.Transaction
 
 	for _, activity := range webhook.Event.Activity {
@@ -84,6 +143,7 @@ func (p *TransactionParser) parseActivity(activity *AlchemyActivityEvent) (*mode
 
 	tx := &models.Transaction{
 		TxHash:         activity.Hash,
+		LogIndex:       models.TxLogIndex,
 		BlockNumber:    blockNum,
 		BlockTimestamp: time.Now(), // TODO: Get actual block timestamp
 		FromAddress:    strings.ToLower(activity.FromAddress),
@@ -97,6 +157,17 @@ func (p *TransactionParser) parseActivity(activity *AlchemyActivityEvent) (*mode
 		tx.TokenAddress = strings.ToLower(activity.RawContract.Address)
 		tx.TokenDecimals = activity.RawContract.Decimals
 		tx.TokenSymbol = activity.Asset
+		// 同一交易的多条代币转账按日志序号分行保存
+		if index, err := strconv.ParseInt(strings.TrimPrefix(activity.Log.LogIndex, "0x"), 16, 32); err == nil {
+			tx.LogIndex = int(index)
+		}
+
+		// value 为浮点数会丢失精度，优先按 rawContract 的原始数量与 decimals 换算
+		if activity.Category == "erc20" && tx.TokenDecimals > 0 {
+			if raw, ok := new(big.Int).SetString(strings.TrimPrefix(activity.RawContract.RawValue, "0x"), 16); ok {
+				tx.Value = units.ScaleTo18(raw, tx.TokenDecimals).String()
+			}
+		}
 
 		if activity.Category == "erc721" {
 			tx.TokenID = activity.RawContract.Value
@@ -106,6 +177,132 @@ func (p *TransactionParser) parseActivity(activity *AlchemyActivityEvent) (*mode
 	return tx, nil
 }
 
+// parseGraphQLLogs 从 Custom Webhook 的区块日志中解析 ERC20/ERC721 Transfer 与 Approval / ApprovalForAll 事件
+func (p *TransactionParser) parseGraphQLLogs(data *AlchemyGraphQLData) ([]*models.Transaction, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	block := data.Block
+	timestamp := time.Now()
+	if block.Timestamp > 0 {
+		timestamp = time.Unix(block.Timestamp, 0).UTC()
+	}
+
+	var transactions []*models.Transaction
+	for _, log := range block.Logs {
+		if len(log.Topics) < 3 {
+			continue
+		}
+
+		tokenAddress := strings.ToLower(log.Account.Address)
+		tx := &models.Transaction{
+			TxHash:         log.Transaction.Hash,
+			LogIndex:       models.TxLogIndex,
+			BlockNumber:    block.Number,
+			BlockTimestamp: timestamp,
+			FromAddress:    topicToAddress(log.Topics[1]),
+			ToAddress:      topicToAddress(log.Topics[2]),
+			TokenAddress:   tokenAddress,
+		}
+
+		var err error
+		switch strings.ToLower(log.Topics[0]) {
+		case TransferEventTopic:
+			err = parseTransferLog(tx, &log)
+		case ApprovalEventTopic, ApprovalForAllEventTopic:
+			err = parseApprovalLog(tx, &log)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+// parseTransferLog 解析 Transfer 事件；同一交易可有多条 Transfer，按日志序号分行保存
+func parseTransferLog(tx *models.Transaction, log *AlchemyGraphQLLog) error {
+	tx.LogIndex = log.Index
+	if len(log.Topics) == 4 {
+		// ERC721: tokenId 位于第 4 个 topic
+		tokenID, ok := new(big.Int).SetString(strings.TrimPrefix(log.Topics[3], "0x"), 16)
+		if !ok {
+			return fmt.Errorf("invalid token id in log %d of %s", log.Index, log.Transaction.Hash)
+		}
+		tx.TxType = "ERC721"
+		tx.TokenID = tokenID.String()
+		tx.Value = "0"
+		return nil
+	}
+
+	tx.TxType = "ERC20"
+	tx.TokenDecimals = defaultTokenDecimals
+	tx.Value = units.ScaleTo18(dataToInt(log.Data), tx.TokenDecimals).String()
+	return nil
+}
+
+// parseApprovalLog 解析授权事件：from 为 owner，to 为 spender（ApprovalForAll 为 operator）。
+// 授权不转移资产，Value 为 0，额度记录在 Approval.Amount；授权为交易级记录，日志序号记录在 Approval.LogIndex
+func parseApprovalLog(tx *models.Transaction, log *AlchemyGraphQLLog) error {
+	tx.TxType = "APPROVAL"
+	tx.Value = "0"
+	approval := &models.Approval{
+		Spender:  tx.ToAddress,
+		LogIndex: log.Index,
+		// 交易不是发给代币合约的：多为 transferFrom 等调用附带发出的 Approval，只更新授权状态
+		Implicit: log.Transaction.To.Address != "" && !strings.EqualFold(log.Transaction.To.Address, tx.TokenAddress),
+	}
+	tx.Approval = approval
+
+	switch {
+	case strings.EqualFold(log.Topics[0], ApprovalForAllEventTopic):
+		approval.Standard = models.ApprovalStandardNFT
+		approval.All = true
+		approval.Revoked = dataToInt(log.Data).Sign() == 0
+		approval.Unlimited = !approval.Revoked
+	case len(log.Topics) == 4:
+		// ERC721 approve：tokenId 位于第 4 个 topic，spender 为零地址表示取消
+		tokenID, ok := new(big.Int).SetString(strings.TrimPrefix(log.Topics[3], "0x"), 16)
+		if !ok {
+			return fmt.Errorf("invalid token id in log %d of %s", log.Index, log.Transaction.Hash)
+		}
+		approval.Standard = models.ApprovalStandardNFT
+		tx.TokenID = tokenID.String()
+		approval.Revoked = tx.ToAddress == zeroAddress
+	default:
+		amount := dataToInt(log.Data)
+		approval.Standard = models.ApprovalStandardERC20
+		approval.Amount = amount.String()
+		approval.Revoked = amount.Sign() == 0
+		approval.Unlimited = amount.Cmp(unlimitedAllowance) >= 0
+		tx.TokenDecimals = defaultTokenDecimals
+	}
+	return nil
+}
+
+// dataToInt 将日志 data 解析为整数，无法解析时为 0
+func dataToInt(data string) *big.Int {
+	v, ok := new(big.Int).SetString(strings.TrimPrefix(data, "0x"), 16)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// topicToAddress 取 32 字节 topic 的后 20 字节作为地址
+func topicToAddress(topic string) string {
+	hex := strings.TrimPrefix(strings.ToLower(topic), "0x")
+	if len(hex) < 40 {
+		return ""
+	}
+	return "0x" + hex[len(hex)-40:]
+}
+
 func (p *TransactionParser) formatValue(value float64) string {
 	// Convert to wei (18 decimals)
 	wei := big.NewFloat(value)
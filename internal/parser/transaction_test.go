@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+func TestParseAlchemyWebhook_GraphQLTransferLogs(t *testing.T) {
+	webhook := &AlchemyWebhook{
+		Type: WebhookTypeGraphQL,
+		Event: AlchemyWebhookEvent{
+			Data: &AlchemyGraphQLData{
+				Block: AlchemyGraphQLBlock{
+					Number:    19000000,
+					Timestamp: 1700000000,
+					Logs: []AlchemyGraphQLLog{
+						{
+							// USDC 转账 1.5 (6 位精度)
+							Data: "0x000000000000000000000000000000000000000000000000000000000016e360",
+							Topics: []string{
+								TransferEventTopic,
+								"0x0000000000000000000000001111111111111111111111111111111111111111",
+								"0x0000000000000000000000002222222222222222222222222222222222222222",
+							},
+							Account:     AlchemyGraphQLAccount{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+							Transaction: AlchemyGraphQLTransaction{Hash: "0xaaa"},
+						},
+						{
+							// ERC721 转账 tokenId = 42
+							Topics: []string{
+								TransferEventTopic,
+								"0x0000000000000000000000001111111111111111111111111111111111111111",
+								"0x0000000000000000000000002222222222222222222222222222222222222222",
+								"0x000000000000000000000000000000000000000000000000000000000000002a",
+							},
+							Account:     AlchemyGraphQLAccount{Address: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"},
+							Transaction: AlchemyGraphQLTransaction{Hash: "0xbbb"},
+						},
+						{
+							// 非 Transfer 事件被忽略
+							Topics: []string{"0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	txs, err := NewTransactionParser().ParseAlchemyWebhook(webhook)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	assert.Equal(t, "ERC20", txs[0].TxType)
+	assert.Equal(t, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", txs[0].TokenAddress)
+	assert.Equal(t, "0x1111111111111111111111111111111111111111", txs[0].FromAddress)
+	assert.Equal(t, "0x2222222222222222222222222222222222222222", txs[0].ToAddress)
+	// 日志不含 decimals，先按 18 位换算，由代币注册表修正
+	assert.Equal(t, defaultTokenDecimals, txs[0].TokenDecimals)
+	assert.Equal(t, "1500000", txs[0].Value)
+	assert.Equal(t, int64(1700000000), txs[0].BlockTimestamp.Unix())
+
+	assert.Equal(t, "ERC721", txs[1].TxType)
+	assert.Equal(t, "42", txs[1].TokenID)
+}
+
+func TestParseAlchemyWebhook_GraphQLTransfersInOneTx(t *testing.T) {
+	transfer := func(index int, to string) AlchemyGraphQLLog {
+		return AlchemyGraphQLLog{
+			Index: index,
+			Data:  "0x000000000000000000000000000000000000000000000000000000000016e360",
+			Topics: []string{
+				TransferEventTopic,
+				"0x0000000000000000000000001111111111111111111111111111111111111111",
+				"0x000000000000000000000000" + to,
+			},
+			Account:     AlchemyGraphQLAccount{Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+			Transaction: AlchemyGraphQLTransaction{Hash: "0xaaa"},
+		}
+	}
+	webhook := &AlchemyWebhook{
+		Type: WebhookTypeGraphQL,
+		Event: AlchemyWebhookEvent{
+			Data: &AlchemyGraphQLData{Block: AlchemyGraphQLBlock{Number: 19000000, Logs: []AlchemyGraphQLLog{
+				transfer(3, "2222222222222222222222222222222222222222"),
+				transfer(7, "3333333333333333333333333333333333333333"),
+			}}},
+		},
+	}
+
+	// 同一交易的两条 Transfer 各自保留日志序号，按 (tx_hash, log_index) 分行保存
+	txs, err := NewTransactionParser().ParseAlchemyWebhook(webhook)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	assert.Equal(t, 3, txs[0].LogIndex)
+	assert.Equal(t, "0x2222222222222222222222222222222222222222", txs[0].ToAddress)
+	assert.Equal(t, 7, txs[1].LogIndex)
+	assert.Equal(t, "0x3333333333333333333333333333333333333333", txs[1].ToAddress)
+}
+
+func TestParseAlchemyWebhook_ActivityRawContract(t *testing.T) {
+	activity := AlchemyActivityEvent{
+		BlockNum:    "0x1234567",
+		Hash:        "0xaaa",
+		FromAddress: "0x1111111111111111111111111111111111111111",
+		ToAddress:   "0x2222222222222222222222222222222222222222",
+		Value:       1234567.890123, // 浮点数已丢失精度
+		Asset:       "USDC",
+		Category:    "erc20",
+		RawContract: AlchemyRawContract{
+			RawValue: "0x0000000000000000000000000000000000000000000000000000011f71fb04cb",
+			Address:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			Decimals: 6,
+		},
+		Log: AlchemyLog{LogIndex: "0x1a"},
+	}
+
+	txs, err := NewTransactionParser().ParseAlchemyWebhook(&AlchemyWebhook{
+		Type:  WebhookTypeAddressActivity,
+		Event: AlchemyWebhookEvent{Activity: []AlchemyActivityEvent{activity}},
+	})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, 26, txs[0].LogIndex)
+	assert.Equal(t, 6, txs[0].TokenDecimals)
+	assert.Equal(t, "1234567890123000000000000", txs[0].Value)
+
+	// 缺少 decimals 时保留 value 换算的结果
+	activity.RawContract.Decimals = 0
+	txs, err = NewTransactionParser().ParseAlchemyWebhook(&AlchemyWebhook{
+		Type:  WebhookTypeAddressActivity,
+		Event: AlchemyWebhookEvent{Activity: []AlchemyActivityEvent{activity}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, txs[0].TokenDecimals)
+	assert.Equal(t, NewTransactionParser().formatValue(activity.Value), txs[0].Value)
+}
+
+func TestParseAlchemyWebhook_GraphQLApprovalLogs(t *testing.T) {
+	const (
+		owner   = "0x0000000000000000000000001111111111111111111111111111111111111111"
+		spender = "0x0000000000000000000000002222222222222222222222222222222222222222"
+		usdc    = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+		bayc    = "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"
+	)
+	unlimited := "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+	webhook := &AlchemyWebhook{
+		Type: WebhookTypeGraphQL,
+		Event: AlchemyWebhookEvent{
+			Data: &AlchemyGraphQLData{
+				Block: AlchemyGraphQLBlock{
+					Number:    19000000,
+					Timestamp: 1700000000,
+					Logs: []AlchemyGraphQLLog{
+						{
+							// approve(spender, type(uint256).max)
+							Data:        unlimited,
+							Topics:      []string{ApprovalEventTopic, owner, spender},
+							Account:     AlchemyGraphQLAccount{Address: usdc},
+							Transaction: AlchemyGraphQLTransaction{Hash: "0xaaa", To: AlchemyGraphQLAccount{Address: usdc}},
+							Index:       3,
+						},
+						{
+							// setApprovalForAll(operator, false)
+							Data:        "0x0000000000000000000000000000000000000000000000000000000000000000",
+							Topics:      []string{ApprovalForAllEventTopic, owner, spender},
+							Account:     AlchemyGraphQLAccount{Address: bayc},
+							Transaction: AlchemyGraphQLTransaction{Hash: "0xbbb", To: AlchemyGraphQLAccount{Address: bayc}},
+						},
+						{
+							// transferFrom 扣减额度时附带发出的 Approval
+							Data:        "0x00000000000000000000000000000000000000000000000000000000000f4240",
+							Topics:      []string{ApprovalEventTopic, owner, spender},
+							Account:     AlchemyGraphQLAccount{Address: usdc},
+							Transaction: AlchemyGraphQLTransaction{Hash: "0xccc", To: AlchemyGraphQLAccount{Address: "0x3333333333333333333333333333333333333333"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	txs, err := NewTransactionParser().ParseAlchemyWebhook(webhook)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+
+	tx := txs[0]
+	assert.Equal(t, "APPROVAL", tx.TxType)
+	assert.Equal(t, "0", tx.Value)
+	assert.Equal(t, "0x1111111111111111111111111111111111111111", tx.FromAddress)
+	assert.Equal(t, "0x2222222222222222222222222222222222222222", tx.ToAddress)
+	assert.Equal(t, defaultTokenDecimals, tx.TokenDecimals) // 由代币注册表修正
+	require.NotNil(t, tx.Approval)
+	assert.Equal(t, models.ApprovalStandardERC20, tx.Approval.Standard)
+	assert.Equal(t, "0x2222222222222222222222222222222222222222", tx.Approval.Spender)
+	assert.True(t, tx.Approval.Unlimited)
+	assert.False(t, tx.Approval.Revoked)
+	assert.False(t, tx.Approval.Implicit)
+	assert.Equal(t, 3, tx.Approval.LogIndex)
+
+	tx = txs[1]
+	require.NotNil(t, tx.Approval)
+	assert.Equal(t, models.ApprovalStandardNFT, tx.Approval.Standard)
+	assert.True(t, tx.Approval.All)
+	assert.True(t, tx.Approval.Revoked)
+	assert.False(t, tx.Approval.Unlimited)
+
+	tx = txs[2]
+	require.NotNil(t, tx.Approval)
+	assert.Equal(t, "1000000", tx.Approval.Amount)
+	assert.False(t, tx.Approval.Unlimited)
+	assert.True(t, tx.Approval.Implicit)
+}
@@ -0,0 +1,140 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCoinGeckoURL = "https://api.coingecko.com/api/v3"
+	defaultPlatform     = "ethereum"
+	defaultNativeCoinID = "ethereum"
+	coinGeckoTimeout    = 10 * time.Second
+
+	// 查询窗口为 at 前后各 24 小时：range 不超过 90 天时 CoinGecko 返回小时粒度数据
+	coinGeckoWindow = 24 * time.Hour
+)
+
+// CoinGeckoSource CoinGecko 风格的 HTTP 价格接口（/coins/{id}/market_chart/range）
+type CoinGeckoSource struct {
+	client       *http.Client
+	baseURL      string
+	apiKey       string
+	apiKeyHeader string
+	platform     string
+	nativeCoinID string
+}
+
+// CoinGeckoOptions 未设置的字段使用公共 API 的默认值
+type CoinGeckoOptions struct {
+	BaseURL      string
+	APIKey       string
+	APIKeyHeader string // 如 x-cg-demo-api-key / x-cg-pro-api-key
+	Platform     string // 代币合约所在的资产平台 ID
+	NativeCoinID string // 原生 ETH 的币种 ID
+}
+
+func NewCoinGeckoSource(client *http.Client, opts CoinGeckoOptions) *CoinGeckoSource {
+	if client == nil {
+		client = &http.Client{Timeout: coinGeckoTimeout}
+	}
+	s := &CoinGeckoSource{
+		client:       client,
+		baseURL:      strings.TrimRight(opts.BaseURL, "/"),
+		apiKey:       opts.APIKey,
+		apiKeyHeader: opts.APIKeyHeader,
+		platform:     opts.Platform,
+		nativeCoinID: opts.NativeCoinID,
+	}
+	if s.baseURL == "" {
+		s.baseURL = defaultCoinGeckoURL
+	}
+	if s.apiKeyHeader == "" {
+		s.apiKeyHeader = "x-cg-demo-api-key"
+	}
+	if s.platform == "" {
+		s.platform = defaultPlatform
+	}
+	if s.nativeCoinID == "" {
+		s.nativeCoinID = defaultNativeCoinID
+	}
+	return s
+}
+
+func (s *CoinGeckoSource) Name() string {
+	return "coingecko"
+}
+
+// Price 取 at 前后窗口内最接近 at 的价格点
+func (s *CoinGeckoSource) Price(ctx context.Context, asset string, at time.Time) (*big.Rat, error) {
+	path := "/coins/" + url.PathEscape(s.nativeCoinID) + "/market_chart/range"
+	if asset != AssetETH {
+		path = "/coins/" + url.PathEscape(s.platform) + "/contract/" + url.PathEscape(asset) + "/market_chart/range"
+	}
+	query := url.Values{
+		"vs_currency": {"usd"},
+		"from":        {strconv.FormatInt(at.Add(-coinGeckoWindow).Unix(), 10)},
+		"to":          {strconv.FormatInt(at.Add(coinGeckoWindow).Unix(), 10)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set(s.apiKeyHeader, s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request price: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNoPrice
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("price request failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Prices [][2]json.Number `json:"prices"` // [毫秒时间戳, 价格]
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode price response: %w", err)
+	}
+
+	target := at.UnixMilli()
+	var best *big.Rat
+	var bestDist int64 = -1
+	for _, p := range body.Prices {
+		ms, err := p[0].Float64()
+		if err != nil {
+			continue
+		}
+		price, ok := new(big.Rat).SetString(p[1].String())
+		if !ok {
+			continue
+		}
+		dist := int64(ms) - target
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = price, dist
+		}
+	}
+	if best == nil {
+		return nil, ErrNoPrice
+	}
+	return best, nil
+}
@@ -0,0 +1,105 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+type memoryStore struct {
+	prices map[string]*models.AssetPrice
+}
+
+func (s *memoryStore) GetPrice(_ context.Context, asset string, bucket time.Time) (*models.AssetPrice, error) {
+	return s.prices[fmt.Sprintf("%s:%d", asset, bucket.Unix())], nil
+}
+
+func (s *memoryStore) SavePrice(_ context.Context, p *models.AssetPrice) error {
+	s.prices[fmt.Sprintf("%s:%d", p.Asset, p.Bucket.Unix())] = p
+	return nil
+}
+
+type countingSource struct {
+	Source
+	calls int
+}
+
+func (s *countingSource) Price(ctx context.Context, asset string, at time.Time) (*big.Rat, error) {
+	s.calls++
+	return s.Source.Price(ctx, asset, at)
+}
+
+func TestUSDValue(t *testing.T) {
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	static, err := NewStaticSource(map[string][]StaticPoint{
+		"eth": {{Time: day, USD: "3000"}, {Time: day.Add(12 * time.Hour), USD: "3100.5"}},
+		"0xA0b86991c6218b36c1d19d4a2e9eB0cE3606eB48": {{Time: day, USD: "0.9998"}},
+	})
+	require.NoError(t, err)
+	source := &countingSource{Source: static}
+	store := &memoryStore{prices: map[string]*models.AssetPrice{}}
+	svc := NewService(source, store, nil, zap.NewNop())
+	ctx := context.Background()
+
+	tx := &models.Transaction{TxType: "ETH", Value: "1500000000000000000", BlockTimestamp: day.Add(13 * time.Hour)}
+	require.NotNil(t, svc.USDValue(ctx, tx))
+	assert.Equal(t, "4650.75", *svc.USDValue(ctx, tx))
+	assert.Equal(t, 1, source.calls, "second lookup in the same hour is served from the store")
+
+	token := &models.Transaction{TxType: "ERC20", TokenAddress: usdc, Value: "2500000000000000000000", BlockTimestamp: day.Add(time.Hour)}
+	require.NotNil(t, svc.USDValue(ctx, token))
+	assert.Equal(t, "2499.50", *svc.USDValue(ctx, token))
+
+	// 价格点之前的时间、NFT 与未知代币无法计价
+	early := &models.Transaction{TxType: "ETH", Value: "1", BlockTimestamp: day.Add(-time.Hour)}
+	assert.Nil(t, svc.USDValue(ctx, early))
+	nft := &models.Transaction{TxType: "ERC721", TokenAddress: usdc, TokenID: "1", BlockTimestamp: day.Add(time.Hour)}
+	assert.Nil(t, svc.USDValue(ctx, nft))
+	unknown := &models.Transaction{TxType: "ERC20", TokenAddress: "0x1111111111111111111111111111111111111111", Value: "1", BlockTimestamp: day}
+	assert.Nil(t, svc.USDValue(ctx, unknown))
+}
+
+func TestCoinGeckoSource(t *testing.T) {
+	at := time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC)
+	var path, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey = r.URL.Path, r.Header.Get("x-cg-pro-api-key")
+		if r.URL.Path == "/coins/ethereum/contract/0xdead/market_chart/range" {
+			http.NotFound(w, r)
+			return
+		}
+		hour := at.Truncate(time.Hour).UnixMilli()
+		fmt.Fprintf(w, `{"prices": [[%d, 3000.1], [%d, 3050.25], [%d, 3100]]}`,
+			hour-3600000, hour+1800000, hour+7200000)
+	}))
+	defer server.Close()
+
+	source := NewCoinGeckoSource(server.Client(), CoinGeckoOptions{
+		BaseURL: server.URL, APIKey: "key", APIKeyHeader: "x-cg-pro-api-key",
+	})
+
+	price, err := source.Price(context.Background(), AssetETH, at)
+	require.NoError(t, err)
+	assert.Equal(t, "3050.25", price.FloatString(2))
+	assert.Equal(t, "/coins/ethereum/market_chart/range", path)
+	assert.Equal(t, "key", apiKey)
+
+	_, err = source.Price(context.Background(), usdc, at)
+	require.NoError(t, err)
+	assert.Equal(t, "/coins/ethereum/contract/"+usdc+"/market_chart/range", path)
+
+	_, err = source.Price(context.Background(), "0xdead", at)
+	assert.ErrorIs(t, err, ErrNoPrice)
+}
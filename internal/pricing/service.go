@@ -0,0 +1,183 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+const (
+	// bucketSize 价格按小时缓存，同一小时内的交易使用相同单价
+	bucketSize    = time.Hour
+	lookupTimeout = 5 * time.Second
+
+	cacheKeyPrefix = "price:usd:"
+	cacheTTL       = 24 * time.Hour
+	missTTL        = 6 * time.Hour // 来源没有该资产的价格
+	errorTTL       = time.Minute   // 来源出错（如限流）时短暂跳过，避免每笔交易都请求
+	missMarker     = "-"
+
+	usdDecimals = 2
+)
+
+// Store 历史价格的持久化缓存，由 repository.PriceRepository 实现
+type Store interface {
+	GetPrice(ctx context.Context, asset string, bucket time.Time) (*models.AssetPrice, error)
+	SavePrice(ctx context.Context, price *models.AssetPrice) error
+}
+
+// Service 计算交易的美元价值；价格依次从 Redis、Postgres 与价格来源获取
+type Service struct {
+	source Source
+	store  Store
+	redis  *redis.Client
+	logger *zap.Logger
+}
+
+// NewService store 与 redis 可为 nil，此时不缓存
+func NewService(source Source, store Store, redis *redis.Client, logger *zap.Logger) *Service {
+	return &Service{source: source, store: store, redis: redis, logger: logger}
+}
+
+// New 按配置创建价格服务，未配置 provider 时返回 nil
+func New(cfg config.PricingConfig, store Store, redis *redis.Client, logger *zap.Logger) (*Service, error) {
+	var source Source
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, nil
+	case "coingecko":
+		source = NewCoinGeckoSource(nil, CoinGeckoOptions{
+			BaseURL:      cfg.BaseURL,
+			APIKey:       cfg.APIKey,
+			APIKeyHeader: cfg.APIKeyHeader,
+			Platform:     cfg.Platform,
+			NativeCoinID: cfg.NativeCoinID,
+		})
+	case "static":
+		s, err := LoadStaticSource(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		source = s
+	default:
+		return nil, fmt.Errorf("unsupported price provider: %s", cfg.Provider)
+	}
+	return NewService(source, store, redis, logger), nil
+}
+
+// Price 资产在 at 所在小时的美元价格，来源没有价格时返回 ErrNoPrice
+func (s *Service) Price(ctx context.Context, asset string, at time.Time) (*big.Rat, error) {
+	bucket := at.UTC().Truncate(bucketSize)
+	key := fmt.Sprintf("%s%s:%d", cacheKeyPrefix, asset, bucket.Unix())
+
+	if s.redis != nil {
+		v, err := s.redis.Get(ctx, key).Result()
+		switch {
+		case err == nil && v == missMarker:
+			return nil, ErrNoPrice
+		case err == nil:
+			if price, ok := new(big.Rat).SetString(v); ok {
+				return price, nil
+			}
+		case !errors.Is(err, redis.Nil):
+			s.logger.Warn("Failed to read price cache", zap.String("asset", asset), zap.Error(err))
+		}
+	}
+
+	if s.store != nil {
+		cached, err := s.store.GetPrice(ctx, asset, bucket)
+		if err != nil {
+			s.logger.Warn("Failed to read stored price", zap.String("asset", asset), zap.Error(err))
+		} else if cached != nil {
+			if price, ok := new(big.Rat).SetString(cached.USD); ok {
+				s.remember(ctx, key, cached.USD, cacheTTL)
+				return price, nil
+			}
+		}
+	}
+
+	price, err := s.source.Price(ctx, asset, bucket.Add(bucketSize/2))
+	if err != nil {
+		ttl := errorTTL
+		if errors.Is(err, ErrNoPrice) {
+			ttl = missTTL
+		}
+		s.remember(ctx, key, missMarker, ttl)
+		return nil, err
+	}
+
+	usd := formatPrice(price)
+	if s.store != nil {
+		err := s.store.SavePrice(ctx, &models.AssetPrice{Asset: asset, Bucket: bucket, USD: usd, Source: s.source.Name()})
+		if err != nil {
+			s.logger.Warn("Failed to store price", zap.String("asset", asset), zap.Error(err))
+		}
+	}
+	s.remember(ctx, key, usd, cacheTTL)
+	return price, nil
+}
+
+func (s *Service) remember(ctx context.Context, key, value string, ttl time.Duration) {
+	if s.redis == nil {
+		return
+	}
+	if err := s.redis.Set(ctx, key, value, ttl).Err(); err != nil {
+		s.logger.Warn("Failed to write price cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// USDValue 交易在区块时间的美元价值（保留 2 位小数），NFT、未知类型或没有价格时返回 nil
+func (s *Service) USDValue(ctx context.Context, tx *models.Transaction) *string {
+	asset := AssetOf(tx)
+	if asset == "" {
+		return nil
+	}
+	amount, ok := new(big.Rat).SetString(tx.Value)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	price, err := s.Price(ctx, asset, tx.BlockTimestamp)
+	if err != nil {
+		if !errors.Is(err, ErrNoPrice) {
+			s.logger.Warn("Failed to get price",
+				zap.String("asset", asset),
+				zap.String("tx_hash", tx.TxHash),
+				zap.Error(err))
+		}
+		return nil
+	}
+
+	units.FromScaled(amount).Mul(amount, price)
+	value := amount.FloatString(usdDecimals)
+	return &value
+}
+
+// Enrich 为尚未计价的交易填充 USDValue
+func (s *Service) Enrich(ctx context.Context, tx *models.Transaction) {
+	if tx.USDValue == nil {
+		tx.USDValue = s.USDValue(ctx, tx)
+	}
+}
+
+// formatPrice 单价保留 18 位小数并去除末尾的 0，与 asset_prices.usd 的精度一致
+func formatPrice(r *big.Rat) string {
+	s := r.FloatString(18)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// AssetETH 原生 ETH 的资产标识，代币使用小写合约地址
+const AssetETH = "ETH"
+
+// ErrNoPrice 价格来源没有该资产（或该时间点）的价格
+var ErrNoPrice = errors.New("price not available")
+
+// Source 历史美元价格来源
+type Source interface {
+	// Name 来源名称，记录在价格缓存中
+	Name() string
+	// Price 返回资产在 at 时刻附近的美元价格，未知资产返回 ErrNoPrice
+	Price(ctx context.Context, asset string, at time.Time) (*big.Rat, error)
+}
+
+// AssetOf 交易的计价资产；NFT 与未知类型没有可用的单价，返回空。SWAP 按 token_out 计价，原生 ETH 没有代币地址
+func AssetOf(tx *models.Transaction) string {
+	switch tx.TxType {
+	case "ETH":
+		return AssetETH
+	case "ERC20":
+		return strings.ToLower(tx.TokenAddress)
+	case "SWAP":
+		if tx.TokenAddress == "" {
+			return AssetETH
+		}
+		return strings.ToLower(tx.TokenAddress)
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StaticPoint 静态价格文件中的一个价格点
+type StaticPoint struct {
+	Time time.Time `json:"time"`
+	USD  string    `json:"usd"`
+}
+
+type staticPoint struct {
+	time time.Time
+	usd  *big.Rat
+}
+
+// StaticSource 从 JSON 文件读取价格，用于测试与离线环境；
+// 文件格式为 {"ETH": [{"time": "2026-01-01T00:00:00Z", "usd": "3000"}], "0x代币地址": [...]}，
+// 取不晚于 at 的最后一个价格点
+type StaticSource struct {
+	prices map[string][]staticPoint
+}
+
+// LoadStaticSource 读取静态价格文件
+func LoadStaticSource(path string) (*StaticSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file: %w", err)
+	}
+	var raw map[string][]StaticPoint
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse price file: %w", err)
+	}
+	return NewStaticSource(raw)
+}
+
+func NewStaticSource(raw map[string][]StaticPoint) (*StaticSource, error) {
+	s := &StaticSource{prices: make(map[string][]staticPoint, len(raw))}
+	for asset, points := range raw {
+		key := strings.ToLower(asset)
+		if strings.EqualFold(asset, AssetETH) {
+			key = AssetETH
+		}
+		for _, p := range points {
+			usd, ok := new(big.Rat).SetString(p.USD)
+			if !ok || usd.Sign() < 0 {
+				return nil, fmt.Errorf("invalid price for %s: %s", asset, p.USD)
+			}
+			s.prices[key] = append(s.prices[key], staticPoint{time: p.Time, usd: usd})
+		}
+		sort.Slice(s.prices[key], func(i, j int) bool {
+			return s.prices[key][i].time.Before(s.prices[key][j].time)
+		})
+	}
+	return s, nil
+}
+
+func (s *StaticSource) Name() string {
+	return "static"
+}
+
+func (s *StaticSource) Price(_ context.Context, asset string, at time.Time) (*big.Rat, error) {
+	points := s.prices[asset]
+	i := sort.Search(len(points), func(i int) bool { return points[i].time.After(at) })
+	if i == 0 {
+		return nil, ErrNoPrice
+	}
+	return new(big.Rat).Set(points[i-1].usd), nil
+}
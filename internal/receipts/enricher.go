@@ -0,0 +1,243 @@
+package receipts
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpcbatch"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+const enrichTimeout = 15 * time.Second
+
+// Enricher 通过交易、回执与区块头补全交易的执行状态、nonce、input 与手续费
+type Enricher struct {
+	caller tokens.Caller
+	logger *zap.Logger
+}
+
+func NewEnricher(caller tokens.Caller, logger *zap.Logger) *Enricher {
+	return &Enricher{caller: caller, logger: logger}
+}
+
+// New 按配置创建，未配置 RPC 地址时返回 nil
+func New(eth config.EthereumConfig, logger *zap.Logger) (*Enricher, error) {
+	if eth.RPCURL == "" {
+		return nil, nil
+	}
+	client, err := ethclient.Dial(eth.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ethereum: %w", err)
+	}
+	return NewEnricher(client.Client(), logger), nil
+}
+
+type rpcTransaction struct {
+	From                 common.Address `json:"from"`
+	Type                 hexutil.Uint64 `json:"type"`
+	Nonce                hexutil.Uint64 `json:"nonce"`
+	Gas                  hexutil.Uint64 `json:"gas"`
+	GasPrice             *hexutil.Big   `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	MaxFeePerBlobGas     *hexutil.Big   `json:"maxFeePerBlobGas"`
+	BlobVersionedHashes  []common.Hash  `json:"blobVersionedHashes"`
+	Input                hexutil.Bytes  `json:"input"`
+}
+
+type rpcReceipt struct {
+	Status            *hexutil.Uint64 `json:"status"` // 拜占庭分叉之前的回执没有 status
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	BlobGasUsed       hexutil.Uint64  `json:"blobGasUsed"`
+	BlobGasPrice      *hexutil.Big    `json:"blobGasPrice"`
+}
+
+type rpcHeader struct {
+	BaseFeePerGas *hexutil.Big `json:"baseFeePerGas"`
+}
+
+// Enrich 为一批交易设置 Status、Nonce、Fee、Gas 与 InputData，同一交易哈希的多条记录共享同一结果；
+// 尚未上链（没有回执）的交易保持不变。节点请求失败时记录日志并跳过，不影响入库
+func (e *Enricher) Enrich(ctx context.Context, txs []*models.Transaction) {
+	var hashes []string
+	seen := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		hash := strings.ToLower(tx.TxHash)
+		if hash != "" && !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, enrichTimeout)
+	defer cancel()
+
+	calls := make([]*rpcTransaction, len(hashes))
+	receipts := make([]*rpcReceipt, len(hashes))
+	if err := e.batch(ctx, "eth_getTransactionByHash", rpcbatch.HashArgs(hashes), func(i int) interface{} { return &calls[i] }); err != nil {
+		e.logger.Warn("Failed to get transactions", zap.Int("count", len(hashes)), zap.Error(err))
+		return
+	}
+	if err := e.batch(ctx, "eth_getTransactionReceipt", rpcbatch.HashArgs(hashes), func(i int) interface{} { return &receipts[i] }); err != nil {
+		e.logger.Warn("Failed to get transaction receipts", zap.Int("count", len(hashes)), zap.Error(err))
+		return
+	}
+
+	baseFees := e.baseFees(ctx, receipts)
+
+	type result struct {
+		status string
+		nonce  int64
+		fee    string
+		gas    *models.Gas
+		input  string
+	}
+	results := make(map[string]*result, len(hashes))
+	for i, hash := range hashes {
+		call, receipt := calls[i], receipts[i]
+		if call == nil || receipt == nil {
+			continue
+		}
+		gas, fee := gasOf(call, receipt, baseFees[uint64(receipt.BlockNumber)])
+		results[hash] = &result{
+			status: statusOf(receipt),
+			nonce:  int64(call.Nonce),
+			fee:    fee.String(),
+			gas:    gas,
+			input:  hexutil.Encode(call.Input),
+		}
+	}
+
+	for _, tx := range txs {
+		r := results[strings.ToLower(tx.TxHash)]
+		if r == nil {
+			continue
+		}
+		nonce, fee := r.nonce, r.fee
+		tx.Status = r.status
+		tx.Nonce = &nonce
+		tx.Fee = &fee
+		tx.Gas = r.gas
+		tx.InputData = r.input
+	}
+}
+
+// baseFees 按回执中的区块号批量获取区块的 base fee，London 之前的区块不出现在结果中
+func (e *Enricher) baseFees(ctx context.Context, receipts []*rpcReceipt) map[uint64]*big.Int {
+	var blocks []uint64
+	seen := make(map[uint64]bool)
+	for _, r := range receipts {
+		if r != nil && !seen[uint64(r.BlockNumber)] {
+			seen[uint64(r.BlockNumber)] = true
+			blocks = append(blocks, uint64(r.BlockNumber))
+		}
+	}
+
+	result := make(map[uint64]*big.Int, len(blocks))
+	if len(blocks) == 0 {
+		return result
+	}
+	args := make([][]interface{}, len(blocks))
+	for i, n := range blocks {
+		args[i] = []interface{}{hexutil.EncodeUint64(n), false}
+	}
+	headers := make([]*rpcHeader, len(blocks))
+	if err := e.batch(ctx, "eth_getBlockByNumber", args, func(i int) interface{} { return &headers[i] }); err != nil {
+		e.logger.Warn("Failed to get block base fees", zap.Int("count", len(blocks)), zap.Error(err))
+		return result
+	}
+	for i, h := range headers {
+		if h != nil && h.BaseFeePerGas != nil {
+			result[blocks[i]] = h.BaseFeePerGas.ToInt()
+		}
+	}
+	return result
+}
+
+// batch 分批调用 RPC 方法，单个调用失败时对应结果保持为空
+func (e *Enricher) batch(ctx context.Context, method string, args [][]interface{}, result func(i int) interface{}) error {
+	return rpcbatch.Call(ctx, e.caller, method, args, result, func(i int, err error) {
+		e.logger.Debug("RPC call failed", zap.String("method", method), zap.Any("args", args[i]), zap.Error(err))
+	})
+}
+
+func statusOf(r *rpcReceipt) string {
+	switch {
+	case r.Status == nil:
+		return ""
+	case *r.Status == 1:
+		return models.TxStatusSuccess
+	default:
+		return models.TxStatusReverted
+	}
+}
+
+// gasOf 汇总 gas 明细并计算手续费：gas_used × effective_gas_price + blob_gas_used × blob_gas_price；
+// baseFee 为 nil 表示区块没有 base fee（London 之前）或获取失败
+func gasOf(call *rpcTransaction, r *rpcReceipt, baseFee *big.Int) (*models.Gas, *big.Int) {
+	price := bigOf(r.EffectiveGasPrice)
+	if price == nil {
+		// 早期节点的回执不含 effectiveGasPrice，legacy 交易的实际单价即 gasPrice
+		price = bigOf(call.GasPrice)
+	}
+	if price == nil {
+		price = new(big.Int)
+	}
+
+	gas := &models.Gas{
+		Sender:               strings.ToLower(call.From.Hex()),
+		Type:                 int(call.Type),
+		Limit:                uint64(call.Gas),
+		Used:                 uint64(r.GasUsed),
+		EffectiveGasPrice:    price.String(),
+		MaxFeePerGas:         bigString(call.MaxFeePerGas),
+		MaxPriorityFeePerGas: bigString(call.MaxPriorityFeePerGas),
+		BlobGasUsed:          uint64(r.BlobGasUsed),
+		BlobGasPrice:         bigString(r.BlobGasPrice),
+		MaxFeePerBlobGas:     bigString(call.MaxFeePerBlobGas),
+	}
+	for _, h := range call.BlobVersionedHashes {
+		gas.BlobVersionedHashes = append(gas.BlobVersionedHashes, h.Hex())
+	}
+	if baseFee != nil {
+		gas.BaseFeePerGas = baseFee.String()
+		if tip := new(big.Int).Sub(price, baseFee); tip.Sign() >= 0 {
+			gas.PriorityFeePerGas = tip.String()
+		}
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gas.Used), price)
+	if blobPrice := bigOf(r.BlobGasPrice); blobPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(gas.BlobGasUsed), blobPrice))
+	}
+	return gas, fee
+}
+
+func bigOf(v *hexutil.Big) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return v.ToInt()
+}
+
+func bigString(v *hexutil.Big) string {
+	if v == nil {
+		return ""
+	}
+	return v.ToInt().String()
+}
@@ -0,0 +1,71 @@
+package receipts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+const (
+	sender = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+	usdc   = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func TestEnrich(t *testing.T) {
+	caller := &rpctest.Caller{Results: map[string]string{
+		// EIP-1559 的 USDC transfer，成功
+		"eth_getTransactionByHash 0xaaa": `{"from":"` + sender + `","type":"0x2","nonce":"0x2a","gas":"0x186a0",
+			"maxFeePerGas":"0x6fc23ac00","maxPriorityFeePerGas":"0x3b9aca00","input":"0xa9059cbb"}`,
+		"eth_getTransactionReceipt 0xaaa": `{"status":"0x1","blockNumber":"0x121eac0","gasUsed":"0xfde8","effectiveGasPrice":"0x4a817c800"}`,
+		// blob 交易，执行失败
+		"eth_getTransactionByHash 0xbbb": `{"from":"` + sender + `","type":"0x3","nonce":"0x2b","gas":"0x5208",
+			"maxFeePerGas":"0x6fc23ac00","maxPriorityFeePerGas":"0x3b9aca00","maxFeePerBlobGas":"0x3b9aca00",
+			"blobVersionedHashes":["0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8"],"input":"0x"}`,
+		"eth_getTransactionReceipt 0xbbb": `{"status":"0x0","blockNumber":"0x121eac0","gasUsed":"0x5208","effectiveGasPrice":"0x4a817c800",
+			"blobGasUsed":"0x20000","blobGasPrice":"0x1"}`,
+		// 尚未上链
+		"eth_getTransactionByHash 0xccc": `{"from":"` + sender + `","type":"0x2","nonce":"0x2c","gas":"0x5208","input":"0x"}`,
+		"eth_getBlockByNumber 0x121eac0": `{"baseFeePerGas":"0x3b9aca00"}`,
+	}}
+
+	transfer := &models.Transaction{TxHash: "0xAAA", TxType: "ERC20", TokenAddress: usdc, FromAddress: sender}
+	log := &models.Transaction{TxHash: "0xaaa", TxType: "APPROVAL", TokenAddress: usdc, FromAddress: sender}
+	blob := &models.Transaction{TxHash: "0xbbb", TxType: "ETH", FromAddress: sender}
+	pending := &models.Transaction{TxHash: "0xccc", TxType: "ETH", FromAddress: sender}
+	NewEnricher(caller, zap.NewNop()).Enrich(context.Background(), []*models.Transaction{transfer, log, blob, pending})
+
+	assert.Equal(t, models.TxStatusSuccess, transfer.Status)
+	require.NotNil(t, transfer.Nonce)
+	assert.Equal(t, int64(42), *transfer.Nonce)
+	require.NotNil(t, transfer.Fee)
+	assert.Equal(t, "1300000000000000", *transfer.Fee, "65000 gas at 20 gwei")
+	assert.Equal(t, "0xa9059cbb", transfer.InputData)
+	assert.Equal(t, &models.Gas{
+		Sender:               sender,
+		Type:                 2,
+		Limit:                100000,
+		Used:                 65000,
+		EffectiveGasPrice:    "20000000000",
+		BaseFeePerGas:        "1000000000",
+		PriorityFeePerGas:    "19000000000",
+		MaxFeePerGas:         "30000000000",
+		MaxPriorityFeePerGas: "1000000000",
+	}, transfer.Gas)
+	assert.Same(t, transfer.Gas, log.Gas, "rows with the same hash share the result")
+
+	assert.Equal(t, models.TxStatusReverted, blob.Status)
+	require.NotNil(t, blob.Fee)
+	assert.Equal(t, "420000000131072", *blob.Fee, "execution fee plus blob fee")
+	assert.Equal(t, uint64(131072), blob.Gas.BlobGasUsed)
+	assert.Len(t, blob.Gas.BlobVersionedHashes, 1)
+
+	assert.Empty(t, pending.Status)
+	assert.Nil(t, pending.Fee)
+	assert.Nil(t, pending.Gas)
+}
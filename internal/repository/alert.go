@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const alertRuleColumns = `id, user_id, name, severity, enabled, conditions, created_at, updated_at`
+
+type AlertRepository struct {
+	db *sqlx.DB
+}
+
+func NewAlertRepository(db *sqlx.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// AlertDetail 告警记录及其规则名称、交易
+type AlertDetail struct {
+	models.Alert
+	RuleName    string             `db:"rule_name"   json:"rule_name"`
+	Transaction models.Transaction `db:"transaction" json:"transaction"`
+}
+
+// AlertRuleUpdate 可编辑字段，nil 表示不修改
+type AlertRuleUpdate struct {
+	Name       *string
+	Severity   *string
+	Enabled    *bool
+	Conditions *models.AlertConditions
+}
+
+func (r *AlertRepository) ListRules(ctx context.Context, userID int64) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	query := `
+		SELECT ` + alertRuleColumns + `
+		FROM alert_rules
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &rules, query, userID)
+	return rules, err
+}
+
+// ListEnabledRulesByUsers 批量获取多个用户已启用的规则，供入库流程评估
+func (r *AlertRepository) ListEnabledRulesByUsers(ctx context.Context, userIDs []int64) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	query := `
+		SELECT ` + alertRuleColumns + `
+		FROM alert_rules
+		WHERE user_id = ANY($1) AND enabled
+	`
+	err := r.db.SelectContext(ctx, &rules, query, pq.Array(userIDs))
+	return rules, err
+}
+
+func (r *AlertRepository) CountRules(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM alert_rules WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// GetRule 获取用户的规则，不存在时返回 nil
+func (r *AlertRepository) GetRule(ctx context.Context, id, userID int64) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id = $1 AND user_id = $2`
+	err := r.db.GetContext(ctx, &rule, query, id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *AlertRepository) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (user_id, name, severity, enabled, conditions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, rule.UserID, rule.Name, rule.Severity, rule.Enabled, rule.Conditions).
+		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule 更新规则，返回更新后的记录
+func (r *AlertRepository) UpdateRule(ctx context.Context, id, userID int64, update AlertRuleUpdate) (*models.AlertRule, error) {
+	var conditions interface{}
+	if update.Conditions != nil {
+		conditions = *update.Conditions
+	}
+
+	var rule models.AlertRule
+	query := `
+		UPDATE alert_rules SET
+			name = COALESCE($3, name),
+			severity = COALESCE($4, severity),
+			enabled = COALESCE($5, enabled),
+			conditions = COALESCE($6::JSONB, conditions),
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + alertRuleColumns
+	err := r.db.GetContext(ctx, &rule, query, id, userID, update.Name, update.Severity, update.Enabled, conditions)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *AlertRepository) DeleteRule(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateAlert 记录一次规则命中，同一规则对同一交易已触发过时返回 false
+func (r *AlertRepository) CreateAlert(ctx context.Context, alert *models.Alert) (bool, error) {
+	query := `
+		INSERT INTO alerts (rule_id, user_id, transaction_id, watched_address_id, severity)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rule_id, transaction_id) DO NOTHING
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		alert.RuleID, alert.UserID, alert.TransactionID, alert.WatchedAddressID, alert.Severity).
+		Scan(&alert.ID, &alert.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create alert: %w", err)
+	}
+	return true, nil
+}
+
+// ListAlerts 获取用户的告警记录，severity 非空时按级别过滤
+func (r *AlertRepository) ListAlerts(ctx context.Context, userID int64, severity string, limit, offset int) ([]AlertDetail, error) {
+	query := `
+		SELECT
+			a.id, a.rule_id, a.user_id, a.transaction_id, a.watched_address_id, a.severity, a.created_at,
+			ar.name as rule_name,
+			t.id as "transaction.id", t.tx_hash as "transaction.tx_hash",
+			t.block_number as "transaction.block_number", t.block_timestamp as "transaction.block_timestamp",
+			t.from_address as "transaction.from_address", t.to_address as "transaction.to_address",
+			t.value as "transaction.value", t.tx_type as "transaction.tx_type",
+			t.token_address as "transaction.token_address", t.token_id as "transaction.token_id",
+			t.token_symbol as "transaction.token_symbol", t.token_decimals as "transaction.token_decimals",
+			t.usd_value as "transaction.usd_value", t.spam_reason as "transaction.spam_reason",
+			t.action as "transaction.action", t.swap as "transaction.swap", t.approval as "transaction.approval",
+			t.status as "transaction.status", t.nonce as "transaction.nonce", t.fee as "transaction.fee",
+			t.gas as "transaction.gas", t.input_data as "transaction.input_data"
+		FROM alerts a
+		JOIN alert_rules ar ON a.rule_id = ar.id
+		JOIN transactions t ON a.transaction_id = t.id
+		WHERE a.user_id = $1 AND ($2 = '' OR a.severity = $2)
+		ORDER BY a.created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	var alerts []AlertDetail
+	if err := r.db.SelectContext(ctx, &alerts, query, userID, severity, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const channelColumns = `id, user_id, type, name, target, event_types, enabled, last_error, last_sent_at,
+	created_at, updated_at`
+
+type ChannelRepository struct {
+	db *sqlx.DB
+}
+
+func NewChannelRepository(db *sqlx.DB) *ChannelRepository {
+	return &ChannelRepository{db: db}
+}
+
+// ChannelUpdate 可编辑字段，nil 表示不修改
+type ChannelUpdate struct {
+	Name       *string
+	EventTypes []string
+	Enabled    *bool
+}
+
+func (r *ChannelRepository) List(ctx context.Context, userID int64) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	query := `
+		SELECT ` + channelColumns + `
+		FROM notification_channels
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &channels, query, userID)
+	return channels, err
+}
+
+func (r *ChannelRepository) Count(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM notification_channels WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// Get 获取用户的渠道，不存在时返回 nil
+func (r *ChannelRepository) Get(ctx context.Context, id, userID int64) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE id = $1 AND user_id = $2`
+	err := r.db.GetContext(ctx, &channel, query, id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// Create 创建渠道；同一用户重复绑定同一目标时重新启用原渠道
+func (r *ChannelRepository) Create(ctx context.Context, channel *models.NotificationChannel) error {
+	query := `
+		INSERT INTO notification_channels (user_id, type, name, target, event_types, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::TEXT[]), NOW(), NOW())
+		ON CONFLICT (user_id, type, target) DO UPDATE SET
+			name = EXCLUDED.name,
+			enabled = TRUE,
+			last_error = '',
+			updated_at = NOW()
+		RETURNING ` + channelColumns
+	err := r.db.GetContext(ctx, channel, query,
+		channel.UserID, channel.Type, channel.Name, channel.Target, channel.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to create channel: %w", err)
+	}
+	return nil
+}
+
+func (r *ChannelRepository) Update(ctx context.Context, id, userID int64, update ChannelUpdate) (*models.NotificationChannel, error) {
+	var eventTypes interface{}
+	if update.EventTypes != nil {
+		eventTypes = pq.Array(update.EventTypes)
+	}
+
+	var channel models.NotificationChannel
+	query := `
+		UPDATE notification_channels SET
+			name = COALESCE($3, name),
+			event_types = COALESCE($4, event_types),
+			enabled = COALESCE($5, enabled),
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + channelColumns
+	err := r.db.GetContext(ctx, &channel, query, id, userID, update.Name, eventTypes, update.Enabled)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *ChannelRepository) Delete(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// DisableByTarget 停用指向某目标的所有渠道（如用户在 Telegram 中屏蔽了 bot）
+func (r *ChannelRepository) DisableByTarget(ctx context.Context, channelType, target, reason string) error {
+	query := `
+		UPDATE notification_channels SET enabled = FALSE, last_error = $3, updated_at = NOW()
+		WHERE type = $1 AND target = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, channelType, target, reason)
+	return err
+}
+
+// ListActive 获取用户订阅该事件的已启用渠道
+func (r *ChannelRepository) ListActive(ctx context.Context, userID int64, eventType string) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	query := `
+		SELECT ` + channelColumns + `
+		FROM notification_channels
+		WHERE user_id = $1 AND enabled
+		  AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+	`
+	err := r.db.SelectContext(ctx, &channels, query, userID, eventType)
+	return channels, err
+}
+
+// RecordResult 记录最近一次发送结果，errMsg 为空表示成功
+func (r *ChannelRepository) RecordResult(ctx context.Context, id int64, errMsg string) error {
+	query := `
+		UPDATE notification_channels SET
+			last_error = $2,
+			last_sent_at = CASE WHEN $2 = '' THEN NOW() ELSE last_sent_at END
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, errMsg)
+	return err
+}
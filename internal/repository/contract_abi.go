@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ContractABIRepository struct {
+	db *sqlx.DB
+}
+
+func NewContractABIRepository(db *sqlx.DB) *ContractABIRepository {
+	return &ContractABIRepository{db: db}
+}
+
+const contractABIColumns = `address, name, abi, uploaded_by, created_at, updated_at`
+
+// visibleUploaders 用户本人及其所在团队的成员
+const visibleUploaders = `(
+	uploaded_by = $1 OR uploaded_by IN (
+		SELECT other.user_id FROM team_members me
+		JOIN team_members other ON other.team_id = me.team_id
+		WHERE me.user_id = $1))`
+
+// GetByAddress 获取用户可见的合约 ABI（本人或团队成员上传，本人上传的优先），不存在时返回 nil
+func (r *ContractABIRepository) GetByAddress(ctx context.Context, userID int64, address string) (*models.ContractABI, error) {
+	var contract models.ContractABI
+	query := `SELECT ` + contractABIColumns + ` FROM contract_abis
+		WHERE ` + visibleUploaders + ` AND address = $2
+		ORDER BY uploaded_by = $1 DESC, updated_at DESC
+		LIMIT 1`
+	err := r.db.GetContext(ctx, &contract, query, userID, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	contract.Source = models.ContractABISourceUploaded
+	return &contract, nil
+}
+
+// GetByUploader 获取用户本人上传的合约 ABI，不存在时返回 nil
+func (r *ContractABIRepository) GetByUploader(ctx context.Context, userID int64, address string) (*models.ContractABI, error) {
+	var contract models.ContractABI
+	query := `SELECT ` + contractABIColumns + ` FROM contract_abis WHERE uploaded_by = $1 AND address = $2`
+	err := r.db.GetContext(ctx, &contract, query, userID, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	contract.Source = models.ContractABISourceUploaded
+	return &contract, nil
+}
+
+// ListVisible 批量获取用户可见的合约 ABI，每个合约一个（本人上传的优先），用于读取交易时解码；
+// 不存在的地址不出现在结果中
+func (r *ContractABIRepository) ListVisible(ctx context.Context, userID int64, addresses []string) ([]models.ContractABI, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	var contracts []models.ContractABI
+	query := `
+		SELECT DISTINCT ON (address) ` + contractABIColumns + `
+		FROM contract_abis
+		WHERE ` + visibleUploaders + ` AND address = ANY($2)
+		ORDER BY address, uploaded_by = $1 DESC, updated_at DESC`
+	if err := r.db.SelectContext(ctx, &contracts, query, userID, pq.Array(addresses)); err != nil {
+		return nil, fmt.Errorf("failed to get contract abis: %w", err)
+	}
+	for i := range contracts {
+		contracts[i].Source = models.ContractABISourceUploaded
+	}
+	return contracts, nil
+}
+
+// ListByUploader 获取用户上传的合约 ABI
+func (r *ContractABIRepository) ListByUploader(ctx context.Context, userID int64) ([]models.ContractABI, error) {
+	contracts := []models.ContractABI{}
+	query := `SELECT ` + contractABIColumns + ` FROM contract_abis WHERE uploaded_by = $1 ORDER BY updated_at DESC`
+	if err := r.db.SelectContext(ctx, &contracts, query, userID); err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		contracts[i].Source = models.ContractABISourceUploaded
+	}
+	return contracts, nil
+}
+
+// CountByUploader 统计用户上传的合约 ABI 数
+func (r *ContractABIRepository) CountByUploader(ctx context.Context, userID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM contract_abis WHERE uploaded_by = $1`
+	err := r.db.GetContext(ctx, &count, query, userID)
+	return count, err
+}
+
+// Save 创建或替换用户上传的合约 ABI，不影响其他用户上传的同一合约
+func (r *ContractABIRepository) Save(ctx context.Context, contract *models.ContractABI) error {
+	query := `
+		INSERT INTO contract_abis (address, name, abi, uploaded_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (uploaded_by, address) DO UPDATE SET
+			name = EXCLUDED.name,
+			abi = EXCLUDED.abi,
+			updated_at = NOW()
+		RETURNING ` + contractABIColumns
+	err := r.db.GetContext(ctx, contract, query, contract.Address, contract.Name, string(contract.ABI), contract.UploadedBy)
+	if err != nil {
+		return err
+	}
+	contract.Source = models.ContractABISourceUploaded
+	return nil
+}
+
+// Delete 删除用户上传的合约 ABI
+func (r *ContractABIRepository) Delete(ctx context.Context, address string, userID int64) error {
+	query := `DELETE FROM contract_abis WHERE address = $1 AND uploaded_by = $2`
+	result, err := r.db.ExecContext(ctx, query, address, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const digestColumns = `id, user_id, email, frequency, send_hour, weekday, timezone, enabled, unsubscribe_token,
+	next_run_at, last_sent_at, created_at, updated_at`
+
+// maxDigestItems 单次摘要最多统计的 feed 条目
+const maxDigestItems = 5000
+
+type DigestRepository struct {
+	db *sqlx.DB
+}
+
+func NewDigestRepository(db *sqlx.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// DigestJob 被领取的摘要任务，RunAt 为本次应发送的时间（即统计周期的结束时间）
+type DigestJob struct {
+	models.DigestSubscription
+	RunAt time.Time `db:"run_at"`
+}
+
+// GetSubscription 获取用户的摘要订阅，不存在时返回 nil
+func (r *DigestRepository) GetSubscription(ctx context.Context, userID int64) (*models.DigestSubscription, error) {
+	var sub models.DigestSubscription
+	query := `SELECT ` + digestColumns + ` FROM digest_subscriptions WHERE user_id = $1`
+	err := r.db.GetContext(ctx, &sub, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// UpsertSubscription 创建或更新订阅，已有订阅保留原退订 token
+func (r *DigestRepository) UpsertSubscription(ctx context.Context, sub *models.DigestSubscription) error {
+	query := `
+		INSERT INTO digest_subscriptions
+			(user_id, email, frequency, send_hour, weekday, timezone, enabled, unsubscribe_token, next_run_at,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			frequency = EXCLUDED.frequency,
+			send_hour = EXCLUDED.send_hour,
+			weekday = EXCLUDED.weekday,
+			timezone = EXCLUDED.timezone,
+			enabled = EXCLUDED.enabled,
+			next_run_at = EXCLUDED.next_run_at,
+			updated_at = NOW()
+		RETURNING ` + digestColumns
+	err := r.db.GetContext(ctx, sub, query,
+		sub.UserID, sub.Email, sub.Frequency, sub.SendHour, sub.Weekday, sub.Timezone, sub.Enabled,
+		sub.UnsubscribeToken, sub.NextRunAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to upsert digest subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *DigestRepository) DeleteSubscription(ctx context.Context, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM digest_subscriptions WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// Unsubscribe 通过退订 token 停用订阅
+func (r *DigestRepository) Unsubscribe(ctx context.Context, token string) error {
+	query := `UPDATE digest_subscriptions SET enabled = FALSE, updated_at = NOW() WHERE unsubscribe_token = $1`
+	result, err := r.db.ExecContext(ctx, query, token)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ClaimDue 领取到期的订阅，领取后在租约期内不会被其他 worker 重复领取
+func (r *DigestRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]DigestJob, error) {
+	query := `
+		WITH due AS (
+			SELECT id, next_run_at
+			FROM digest_subscriptions
+			WHERE enabled AND next_run_at <= $3
+			ORDER BY next_run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE digest_subscriptions s
+		SET next_run_at = $3::TIMESTAMP + $2::INTERVAL
+		FROM due
+		WHERE s.id = due.id
+		RETURNING s.id, s.user_id, s.email, s.frequency, s.send_hour, s.weekday, s.timezone, s.enabled,
+			s.unsubscribe_token, s.next_run_at, s.last_sent_at, s.created_at, s.updated_at, due.next_run_at AS run_at
+	`
+	var jobs []DigestJob
+	err := r.db.SelectContext(ctx, &jobs, query, limit, fmt.Sprintf("%d seconds", int(lease.Seconds())), time.Now().UTC())
+	return jobs, err
+}
+
+// MarkRun 记录本次执行结果并设置下次执行时间
+func (r *DigestRepository) MarkRun(ctx context.Context, id int64, nextRunAt time.Time, sent bool) error {
+	query := `
+		UPDATE digest_subscriptions SET
+			next_run_at = $2,
+			last_sent_at = CASE WHEN $3 THEN NOW() ELSE last_sent_at END
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, nextRunAt.UTC(), sent)
+	return err
+}
+
+// ListItems 获取用户在 [from, to) 区间内（按区块时间）的 feed 条目，不含垃圾交易与隐藏的代币；
+// 命中多个监控地址的条目按地址各返回一行
+func (r *DigestRepository) ListItems(ctx context.Context, userID int64, from, to time.Time) ([]FeedItemDetail, error) {
+	query := `
+		SELECT
+			fi.id, fi.user_id, fi.transaction_id, fi.watched_address_id, fi.created_at,
+			t.id as "transaction.id", t.tx_hash as "transaction.tx_hash",
+			t.block_number as "transaction.block_number", t.block_timestamp as "transaction.block_timestamp",
+			t.from_address as "transaction.from_address", COALESCE(t.to_address, '') as "transaction.to_address",
+			t.value as "transaction.value", t.tx_type as "transaction.tx_type",
+			COALESCE(t.token_address, '') as "transaction.token_address",
+			COALESCE(t.token_id, '') as "transaction.token_id",
+			COALESCE(t.token_symbol, '') as "transaction.token_symbol",
+			COALESCE(t.token_decimals, 0) as "transaction.token_decimals",
+			t.usd_value as "transaction.usd_value", t.spam_reason as "transaction.spam_reason",
+			t.action as "transaction.action", t.swap as "transaction.swap", t.approval as "transaction.approval",
+			t.status as "transaction.status", t.nonce as "transaction.nonce", t.fee as "transaction.fee",
+			t.gas as "transaction.gas", t.input_data as "transaction.input_data",
+			wa.id as "watched_address.id", wa.kind as "watched_address.kind", wa.address as "watched_address.address",
+			COALESCE(wa.label, '') as "watched_address.label", COALESCE(wa.ens_name, '') as "watched_address.ens_name"
+		FROM feed_items fi
+		JOIN transactions t ON fi.transaction_id = t.id
+		JOIN feed_item_addresses fia ON fia.feed_item_id = fi.id
+		JOIN watched_addresses wa ON fia.watched_address_id = wa.id
+		WHERE fi.user_id = $1 AND t.block_timestamp >= $2 AND t.block_timestamp < $3` + spamWhere + `
+		ORDER BY t.block_timestamp, fi.id, wa.id
+		LIMIT $4`
+
+	var items []FeedItemDetail
+	err := r.db.SelectContext(ctx, &items, query, userID, from.UTC(), to.UTC(), maxDigestItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest items: %w", err)
+	}
+	return items, nil
+}
+
+// NewCounterparties 从候选地址（小写）中筛选出 before 之前从未出现在用户 feed 中的地址
+func (r *DigestRepository) NewCounterparties(ctx context.Context, userID int64, before time.Time, candidates []string) ([]string, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT c.address
+		FROM unnest($3::TEXT[]) AS c(address)
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM feed_items fi
+			JOIN transactions t ON fi.transaction_id = t.id
+			WHERE fi.user_id = $1 AND t.block_timestamp < $2
+			  AND (t.from_address = c.address OR t.to_address = c.address)
+		)`
+	var addresses []string
+	err := r.db.SelectContext(ctx, &addresses, query, userID, before.UTC(), pq.Array(candidates))
+	return addresses, err
+}
@@ -0,0 +1,6 @@
+package repository
+
+import "errors"
+
+// ErrNotFound 更新或删除时目标记录不存在（或不属于当前用户）
+var ErrNotFound = errors.New("record not found")
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const exportJobColumns = `id, user_id, format, timezone, filter, status, row_count, file_size, file_path, error,
+	created_at, started_at, completed_at, expires_at`
+
+type ExportRepository struct {
+	db *sqlx.DB
+}
+
+func NewExportRepository(db *sqlx.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) Create(ctx context.Context, job *models.ExportJob) error {
+	query := `
+		INSERT INTO export_jobs (user_id, format, timezone, filter, status, created_at)
+		VALUES ($1, $2, $3, $4, 'pending', NOW())
+		RETURNING ` + exportJobColumns
+	err := r.db.GetContext(ctx, job, query, job.UserID, job.Format, job.Timezone, job.Filter)
+	if err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+	return nil
+}
+
+// Get 获取用户的导出任务，不存在时返回 nil
+func (r *ExportRepository) Get(ctx context.Context, id, userID int64) (*models.ExportJob, error) {
+	var job models.ExportJob
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE id = $1 AND user_id = $2`
+	err := r.db.GetContext(ctx, &job, query, id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListByUser 获取用户最近的导出任务
+func (r *ExportRepository) ListByUser(ctx context.Context, userID int64, limit int) ([]models.ExportJob, error) {
+	jobs := []models.ExportJob{}
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+	err := r.db.SelectContext(ctx, &jobs, query, userID, limit)
+	return jobs, err
+}
+
+// CountActive 统计用户未完成的导出任务
+func (r *ExportRepository) CountActive(ctx context.Context, userID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM export_jobs WHERE user_id = $1 AND status IN ('pending', 'running')`
+	err := r.db.GetContext(ctx, &count, query, userID)
+	return count, err
+}
+
+// Delete 删除用户的导出任务，返回被删除的任务以便清理文件
+func (r *ExportRepository) Delete(ctx context.Context, id, userID int64) (*models.ExportJob, error) {
+	var job models.ExportJob
+	query := `DELETE FROM export_jobs WHERE id = $1 AND user_id = $2 RETURNING ` + exportJobColumns
+	err := r.db.GetContext(ctx, &job, query, id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext 领取一个待处理的任务；执行中但超过租约的任务（如实例崩溃）会被重新领取
+func (r *ExportRepository) ClaimNext(ctx context.Context, lease time.Duration) (*models.ExportJob, error) {
+	query := `
+		WITH next AS (
+			SELECT id
+			FROM export_jobs
+			WHERE status = 'pending' OR (status = 'running' AND started_at < $1)
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE export_jobs j
+		SET status = 'running', started_at = $2, error = ''
+		FROM next
+		WHERE j.id = next.id
+		RETURNING j.id, j.user_id, j.format, j.timezone, j.filter, j.status, j.row_count, j.file_size, j.file_path,
+			j.error, j.created_at, j.started_at, j.completed_at, j.expires_at
+	`
+	now := time.Now().UTC()
+	var job models.ExportJob
+	err := r.db.GetContext(ctx, &job, query, now.Add(-lease), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim export job: %w", err)
+	}
+	return &job, nil
+}
+
+// Complete 记录导出结果
+func (r *ExportRepository) Complete(ctx context.Context, id int64, filePath string, rows, size int64, expiresAt time.Time) error {
+	query := `
+		UPDATE export_jobs SET
+			status = 'completed', file_path = $2, row_count = $3, file_size = $4,
+			completed_at = NOW(), expires_at = $5
+		WHERE id = $1
+	`
+	result, err := r.db.ExecContext(ctx, query, id, filePath, rows, size, expiresAt.UTC())
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *ExportRepository) Fail(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE export_jobs SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, reason)
+	return err
+}
+
+// ListExpired 获取文件已过期的已完成任务
+func (r *ExportRepository) ListExpired(ctx context.Context, limit int) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	query := `
+		SELECT ` + exportJobColumns + ` FROM export_jobs
+		WHERE status = 'completed' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2`
+	err := r.db.SelectContext(ctx, &jobs, query, time.Now().UTC(), limit)
+	return jobs, err
+}
+
+// MarkExpired 文件删除后将任务标记为过期，保留任务记录
+func (r *ExportRepository) MarkExpired(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE export_jobs SET status = 'expired', file_path = '' WHERE id = $1`, id)
+	return err
+}
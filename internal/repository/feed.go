@@ -1,11 +1,18 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/bwmspring/chainfeed-go/internal/feedfilter"
 	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type FeedRepository struct {
@@ -20,54 +27,544 @@ type FeedItemDetail struct {
 	models.FeedItem
 	Transaction    models.Transaction    `db:"transaction"`
 	WatchedAddress models.WatchedAddress `db:"watched_address"`
+	// WatchedAddresses 命中该交易的全部监控地址（如同时监控了发送方与接收方），WatchedAddress 为首个命中的地址
+	WatchedAddresses []models.WatchedAddress `db:"-" json:"watched_addresses"`
 }
 
-func (r *FeedRepository) GetUserFeed(userID int64, limit, offset int) ([]FeedItemDetail, error) {
-	query := `
+const feedItemSelect = `
 		SELECT 
 			fi.id, fi.user_id, fi.transaction_id, fi.watched_address_id, fi.created_at,
+			fi.seen_at, fi.starred_at, fi.note,
 			t.id as "transaction.id", t.tx_hash as "transaction.tx_hash", 
 			t.block_number as "transaction.block_number", t.block_timestamp as "transaction.block_timestamp",
 			t.from_address as "transaction.from_address", t.to_address as "transaction.to_address",
 			t.value as "transaction.value", t.tx_type as "transaction.tx_type",
 			t.token_address as "transaction.token_address", t.token_id as "transaction.token_id",
 			t.token_symbol as "transaction.token_symbol", t.token_decimals as "transaction.token_decimals",
+			t.usd_value as "transaction.usd_value", t.spam_reason as "transaction.spam_reason",
+			t.action as "transaction.action", t.swap as "transaction.swap", t.approval as "transaction.approval",
+			t.status as "transaction.status", t.nonce as "transaction.nonce", t.fee as "transaction.fee",
+			t.gas as "transaction.gas", t.input_data as "transaction.input_data",
 			wa.id as "watched_address.id", wa.address as "watched_address.address",
 			wa.label as "watched_address.label", wa.ens_name as "watched_address.ens_name"
 		FROM feed_items fi
 		JOIN transactions t ON fi.transaction_id = t.id
-		JOIN watched_addresses wa ON fi.watched_address_id = wa.id
-		WHERE fi.user_id = $1
-		ORDER BY fi.created_at DESC
-		LIMIT $2 OFFSET $3`
+		JOIN watched_addresses wa ON fi.watched_address_id = wa.id`
+
+// ListUserFeed 按 (created_at, id) 键集分页获取用户 feed，结果按时间降序；hasMore 表示查询方向上还有数据。
+// 接口轮询（q.Overlap）时新数据之后附带游标前 pollOverlap 内的条目（见 lateWindow），导出不带 Overlap 按升序遍历
+func (r *FeedRepository) ListUserFeed(ctx context.Context, userID int64, filter *models.FeedFilter, q *pagination.Query) ([]FeedItemDetail, bool, error) {
+	filterWhere, filterArgs := feedFilterWhere(filter, 2)
+	where, order, keysetArgs := keyset(q, "fi.created_at", "fi.id", 2+len(filterArgs))
+	query := feedItemSelect + `
+		WHERE fi.user_id = $1` + filterWhere + where + order + fmt.Sprintf(" LIMIT %d", q.Limit+1)
 
+	args := append([]interface{}{userID}, filterArgs...)
 	var items []FeedItemDetail
-	err := r.db.Select(&items, query, userID, limit, offset)
+	err := r.db.SelectContext(ctx, &items, query, append(args, keysetArgs...)...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get user feed: %w", err)
+	}
+
+	items, hasMore := trimPage(items, q)
+	if lateWhere, lateArgs := lateWindow(q, "fi.created_at", "fi.id", 2+len(filterArgs)); lateWhere != "" {
+		var late []FeedItemDetail
+		query := feedItemSelect + `
+		WHERE fi.user_id = $1` + filterWhere + lateWhere + lateOrder("fi.created_at", "fi.id")
+		if err := r.db.SelectContext(ctx, &late, query, append(args, lateArgs...)...); err != nil {
+			return nil, false, fmt.Errorf("failed to get late feed items: %w", err)
+		}
+		items = append(items, late...)
+	}
+	if err := r.attachWatchedAddresses(ctx, items); err != nil {
+		return nil, false, err
+	}
+	if err := r.attachTokens(ctx, items); err != nil {
+		return nil, false, err
+	}
+	return items, hasMore, nil
+}
+
+// attachTokens 按 token_address 批量加载代币元数据
+func (r *FeedRepository) attachTokens(ctx context.Context, items []FeedItemDetail) error {
+	var addresses []string
+	for i := range items {
+		if addr := items[i].Transaction.TokenAddress; addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	var tokens []models.Token
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE address = ANY($1)`
+	if err := r.db.SelectContext(ctx, &tokens, query, pq.Array(addresses)); err != nil {
+		return fmt.Errorf("failed to get feed item tokens: %w", err)
+	}
+
+	byAddress := make(map[string]*models.Token, len(tokens))
+	for i := range tokens {
+		byAddress[tokens[i].Address] = &tokens[i]
+	}
+	for i := range items {
+		items[i].Transaction.Token = byAddress[items[i].Transaction.TokenAddress]
+	}
+	return nil
+}
+
+// attachWatchedAddresses 批量加载每个条目命中的全部监控地址
+func (r *FeedRepository) attachWatchedAddresses(ctx context.Context, items []FeedItemDetail) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+
+	var rows []struct {
+		FeedItemID int64 `db:"feed_item_id"`
+		models.WatchedAddress
+	}
+	query := `
+		SELECT fia.feed_item_id, ` + watchedAddressColumnsWA + `
+		FROM feed_item_addresses fia
+		JOIN watched_addresses wa ON fia.watched_address_id = wa.id
+		WHERE fia.feed_item_id = ANY($1)
+		ORDER BY fia.feed_item_id, wa.id`
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to get feed item addresses: %w", err)
+	}
+
+	byItem := make(map[int64][]models.WatchedAddress, len(items))
+	for _, row := range rows {
+		byItem[row.FeedItemID] = append(byItem[row.FeedItemID], row.WatchedAddress)
+	}
+	for i := range items {
+		addresses := byItem[items[i].ID]
+		if len(addresses) == 0 {
+			addresses = []models.WatchedAddress{items[i].WatchedAddress}
+		}
+		items[i].WatchedAddresses = addresses
+	}
+	return nil
+}
+
+// CountUserFeed 统计满足过滤条件的 feed 条目数，estimated 表示结果为估算值
+func (r *FeedRepository) CountUserFeed(ctx context.Context, userID int64, filter *models.FeedFilter, mode string) (int64, bool, error) {
+	query, args := userFeedCountQuery(userID, filter)
+	return countRows(ctx, r.db, mode, query, args...)
+}
+
+// CountUserFeedUpTo 精确统计满足过滤条件的 feed 条目数，最多数到 limit 条即停止
+func (r *FeedRepository) CountUserFeedUpTo(ctx context.Context, userID int64, filter *models.FeedFilter, limit int64) (int64, error) {
+	query, args := userFeedCountQuery(userID, filter)
+	query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+	total, _, err := countRows(ctx, r.db, pagination.CountExact, query, append(args, limit)...)
+	return total, err
+}
+
+func userFeedCountQuery(userID int64, filter *models.FeedFilter) (string, []interface{}) {
+	filterWhere, args := feedFilterWhere(filter, 2)
+	if filterWhere == "" {
+		return `SELECT 1 FROM feed_items WHERE user_id = $1`, []interface{}{userID}
+	}
+	query := `
+		SELECT 1 FROM feed_items fi
+		JOIN transactions t ON fi.transaction_id = t.id
+		WHERE fi.user_id = $1` + filterWhere
+	return query, append([]interface{}{userID}, args...)
+}
+
+// CountUnread 统计用户未读的 feed 条目数，不含垃圾交易与隐藏的代币
+func (r *FeedRepository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	query := `
+		SELECT COUNT(*) FROM feed_items fi
+		JOIN transactions t ON fi.transaction_id = t.id
+		WHERE fi.user_id = $1 AND fi.seen_at IS NULL` + spamWhere
+	err := r.db.GetContext(ctx, &count, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user feed: %w", err)
+		return 0, fmt.Errorf("failed to count unread feed items: %w", err)
 	}
+	return count, nil
+}
 
-	return items, nil
+// FeedItemStateUpdate 条目状态的修改，nil 表示不修改
+type FeedItemStateUpdate struct {
+	Seen    *bool
+	Starred *bool
+	Note    *string
 }
 
-func (r *FeedRepository) Create(item *models.FeedItem) error {
+const feedItemColumns = `id, user_id, transaction_id, watched_address_id, created_at, seen_at, starred_at, note`
+
+// UpdateItemState 修改条目的已读、星标与备注，重复标记时保留原时间戳；返回更新后的条目
+func (r *FeedRepository) UpdateItemState(ctx context.Context, id, userID int64, update FeedItemStateUpdate) (*models.FeedItem, error) {
+	var item models.FeedItem
+	query := `
+		UPDATE feed_items SET
+			seen_at = CASE WHEN $3::BOOLEAN IS NULL THEN seen_at WHEN $3 THEN COALESCE(seen_at, NOW()) END,
+			starred_at = CASE WHEN $4::BOOLEAN IS NULL THEN starred_at WHEN $4 THEN COALESCE(starred_at, NOW()) END,
+			note = COALESCE($5, note)
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + feedItemColumns
+	err := r.db.GetContext(ctx, &item, query, id, userID, update.Seen, update.Starred, update.Note)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update feed item: %w", err)
+	}
+	return &item, nil
+}
+
+// MarkReadUpTo 将不晚于 upTo（含）的未读条目标记为已读，upTo 为 nil 时标记全部；返回标记数量
+func (r *FeedRepository) MarkReadUpTo(ctx context.Context, userID int64, upTo *pagination.Cursor) (int64, error) {
+	query := `UPDATE feed_items SET seen_at = NOW() WHERE user_id = $1 AND seen_at IS NULL`
+	args := []interface{}{userID}
+	if upTo != nil {
+		query += ` AND (created_at, id) <= ($2, $3)`
+		args = append(args, upTo.Time, upTo.ID)
+	}
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark feed read: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// spamWhere 排除垃圾交易与用户隐藏的代币（fi 为 feed_items，t 为 transactions）
+const spamWhere = " AND t.spam_reason = ''" +
+	" AND NOT EXISTS (SELECT 1 FROM hidden_tokens ht" +
+	" WHERE ht.user_id = fi.user_id AND ht.token_address = t.token_address)"
+
+// feedFilterWhere 将过滤条件转换为 SQL 条件（fi 为 feed_items，t 为 transactions），argIndex 为起始占位符序号；
+// 监控地址相关条件对条目命中的任一监控地址成立即可，语义与 feedfilter.Match 保持一致
+func feedFilterWhere(f *models.FeedFilter, argIndex int) (string, []interface{}) {
+	var b, wa strings.Builder
+	// 垃圾交易默认排除，与是否设置其他条件无关
+	if f == nil || !f.IncludeSpam {
+		b.WriteString(spamWhere)
+	}
+	if feedfilter.IsEmpty(f) {
+		return b.String(), nil
+	}
+
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", argIndex+len(args)-1)
+	}
+
+	if f.Unread {
+		b.WriteString(" AND fi.seen_at IS NULL")
+	}
+	if f.Starred {
+		b.WriteString(" AND fi.starred_at IS NOT NULL")
+	}
+	if f.ExcludeReverted {
+		b.WriteString(" AND t.status <> '" + models.TxStatusReverted + "'")
+	}
+	if len(f.TxTypes) > 0 {
+		b.WriteString(" AND t.tx_type = ANY(" + arg(pq.Array(f.TxTypes)) + ")")
+	}
+	if len(f.Tokens) > 0 {
+		addresses, symbols := feedfilter.SplitTokens(f.Tokens)
+		b.WriteString(" AND (t.token_address = ANY(" + arg(pq.Array(addresses)) + ")" +
+			" OR UPPER(t.token_symbol) = ANY(" + arg(pq.Array(symbols)) + "))")
+	}
+	if f.MinAmount != nil {
+		b.WriteString(" AND t.value >= " + arg(feedfilter.ToWei(*f.MinAmount)) + "::NUMERIC")
+	}
+	if f.MaxAmount != nil {
+		b.WriteString(" AND t.value <= " + arg(feedfilter.ToWei(*f.MaxAmount)) + "::NUMERIC")
+	}
+	// 无法计价的交易 usd_value 为 NULL，不满足美元价值条件
+	if f.MinUSDValue != nil {
+		b.WriteString(" AND t.usd_value >= " + arg(*f.MinUSDValue) + "::NUMERIC")
+	}
+	if f.MaxUSDValue != nil {
+		b.WriteString(" AND t.usd_value <= " + arg(*f.MaxUSDValue) + "::NUMERIC")
+	}
+	if f.FromBlock != nil {
+		b.WriteString(" AND t.block_number >= " + arg(*f.FromBlock))
+	}
+	if f.ToBlock != nil {
+		b.WriteString(" AND t.block_number <= " + arg(*f.ToBlock))
+	}
+	if f.FromTime != nil {
+		b.WriteString(" AND t.block_timestamp >= " + arg(f.FromTime.UTC()))
+	}
+	if f.ToTime != nil {
+		b.WriteString(" AND t.block_timestamp < " + arg(f.ToTime.UTC()))
+	}
+
+	// 以下条件作用于命中的监控地址 mwa
+	if len(f.WatchedAddressIDs) > 0 {
+		wa.WriteString(" AND mwa.id = ANY(" + arg(pq.Array(f.WatchedAddressIDs)) + ")")
+	}
+	if len(f.Tags) > 0 {
+		wa.WriteString(" AND mwa.tags @> " + arg(pq.Array(f.Tags)) + "::TEXT[]")
+	}
+	switch f.Direction {
+	case models.DirectionIn:
+		wa.WriteString(" AND mwa.kind = 'wallet' AND t.to_address = LOWER(mwa.address)")
+	case models.DirectionOut:
+		wa.WriteString(" AND mwa.kind = 'wallet' AND t.from_address = LOWER(mwa.address)" +
+			" AND t.to_address IS DISTINCT FROM LOWER(mwa.address)")
+	}
+	if len(f.Counterparties) > 0 {
+		// 钱包监控匹配交易另一方，代币合约监控任一方命中即可
+		p := arg(pq.Array(f.Counterparties))
+		wa.WriteString(" AND CASE" +
+			" WHEN mwa.kind = 'token' THEN (t.from_address = ANY(" + p + ") OR t.to_address = ANY(" + p + "))" +
+			" WHEN t.to_address = LOWER(mwa.address) THEN t.from_address = ANY(" + p + ")" +
+			" WHEN t.from_address = LOWER(mwa.address) THEN t.to_address = ANY(" + p + ")" +
+			" ELSE FALSE END")
+	}
+	if wa.Len() > 0 {
+		b.WriteString(" AND EXISTS (SELECT 1 FROM feed_item_addresses fia" +
+			" JOIN watched_addresses mwa ON fia.watched_address_id = mwa.id" +
+			" WHERE fia.feed_item_id = fi.id" + wa.String() + ")")
+	}
+
+	return b.String(), args
+}
+
+// Create 创建 feed 条目并关联命中的全部监控地址，item.WatchedAddressID 为首个命中的地址；
+// 用户已有该交易的条目时只补充关联并回填 item，返回值表示是否新建
+func (r *FeedRepository) Create(ctx context.Context, item *models.FeedItem, watchedAddressIDs []int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// DO NOTHING 不产生新的行版本，已存在时再查询原条目
+	created := true
 	query := `
 		INSERT INTO feed_items (user_id, transaction_id, watched_address_id)
-		VALUES (:user_id, :transaction_id, :watched_address_id)
+		VALUES ($1, $2, $3)
 		ON CONFLICT (user_id, transaction_id) DO NOTHING
-		RETURNING id, created_at`
+		RETURNING id, watched_address_id, created_at`
+	err = tx.QueryRowContext(ctx, query, item.UserID, item.TransactionID, item.WatchedAddressID).
+		Scan(&item.ID, &item.WatchedAddressID, &item.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		created = false
+		query = `SELECT id, watched_address_id, created_at FROM feed_items WHERE user_id = $1 AND transaction_id = $2`
+		err = tx.QueryRowContext(ctx, query, item.UserID, item.TransactionID).
+			Scan(&item.ID, &item.WatchedAddressID, &item.CreatedAt)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create feed item: %w", err)
+	}
+
+	query = `
+		INSERT INTO feed_item_addresses (feed_item_id, watched_address_id)
+		SELECT $1, unnest($2::BIGINT[])
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, item.ID, pq.Array(watchedAddressIDs)); err != nil {
+		return false, fmt.Errorf("failed to link feed item addresses: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+const feedPresetColumns = `id, user_id, name, filter, created_at, updated_at`
+
+// FeedPresetUpdate 可编辑字段，nil 表示不修改
+type FeedPresetUpdate struct {
+	Name   *string
+	Filter *models.FeedFilter
+}
+
+func (r *FeedRepository) ListPresets(ctx context.Context, userID int64) ([]models.FeedFilterPreset, error) {
+	var presets []models.FeedFilterPreset
+	query := `SELECT ` + feedPresetColumns + ` FROM feed_filter_presets WHERE user_id = $1 ORDER BY name`
+	err := r.db.SelectContext(ctx, &presets, query, userID)
+	return presets, err
+}
+
+func (r *FeedRepository) CountPresets(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM feed_filter_presets WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// GetPreset 获取用户的过滤预设，不存在时返回 nil
+func (r *FeedRepository) GetPreset(ctx context.Context, id, userID int64) (*models.FeedFilterPreset, error) {
+	var preset models.FeedFilterPreset
+	query := `SELECT ` + feedPresetColumns + ` FROM feed_filter_presets WHERE id = $1 AND user_id = $2`
+	err := r.db.GetContext(ctx, &preset, query, id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &preset, nil
+}
 
-	rows, err := r.db.NamedQuery(query, item)
+func (r *FeedRepository) CreatePreset(ctx context.Context, preset *models.FeedFilterPreset) error {
+	query := `
+		INSERT INTO feed_filter_presets (user_id, name, filter, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, preset.UserID, preset.Name, preset.Filter).
+		Scan(&preset.ID, &preset.CreatedAt, &preset.UpdatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to create feed item: %w", err)
+		return fmt.Errorf("failed to create feed preset: %w", err)
+	}
+	return nil
+}
+
+// UpdatePreset 更新过滤预设，返回更新后的记录
+func (r *FeedRepository) UpdatePreset(ctx context.Context, id, userID int64, update FeedPresetUpdate) (*models.FeedFilterPreset, error) {
+	var filter interface{}
+	if update.Filter != nil {
+		filter = *update.Filter
 	}
-	defer rows.Close()
 
-	if rows.Next() {
-		if err := rows.Scan(&item.ID, &item.CreatedAt); err != nil {
-			return fmt.Errorf("failed to scan feed item: %w", err)
+	var preset models.FeedFilterPreset
+	query := `
+		UPDATE feed_filter_presets SET
+			name = COALESCE($3, name),
+			filter = COALESCE($4::JSONB, filter),
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + feedPresetColumns
+	err := r.db.GetContext(ctx, &preset, query, id, userID, update.Name, filter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
 		}
+		return nil, err
 	}
+	return &preset, nil
+}
 
+func (r *FeedRepository) DeletePreset(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM feed_filter_presets WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+const feedTokenColumns = `id, user_id, name, address, token_hash, created_at, last_used_at`
+
+func (r *FeedRepository) ListTokens(ctx context.Context, userID int64) ([]models.FeedToken, error) {
+	tokens := []models.FeedToken{}
+	query := `SELECT ` + feedTokenColumns + ` FROM feed_tokens WHERE user_id = $1 ORDER BY created_at, id`
+	err := r.db.SelectContext(ctx, &tokens, query, userID)
+	return tokens, err
+}
+
+func (r *FeedRepository) CountTokens(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM feed_tokens WHERE user_id = $1`, userID)
+	return count, err
+}
+
+func (r *FeedRepository) CreateToken(ctx context.Context, token *models.FeedToken) error {
+	query := `
+		INSERT INTO feed_tokens (user_id, name, address, token_hash, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.Name, token.Address, token.TokenHash).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create feed token: %w", err)
+	}
 	return nil
 }
+
+// GetTokenByHash 按令牌摘要查找，不存在（或已吊销）时返回 nil
+func (r *FeedRepository) GetTokenByHash(ctx context.Context, hash string) (*models.FeedToken, error) {
+	var token models.FeedToken
+	query := `SELECT ` + feedTokenColumns + ` FROM feed_tokens WHERE token_hash = $1`
+	err := r.db.GetContext(ctx, &token, query, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// TouchToken 记录令牌的使用时间；阅读器轮询频繁，每 5 分钟最多写一次
+func (r *FeedRepository) TouchToken(ctx context.Context, id int64) error {
+	query := `
+		UPDATE feed_tokens SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '5 minutes')
+	`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// DeleteToken 吊销令牌
+func (r *FeedRepository) DeleteToken(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM feed_tokens WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *FeedRepository) ListHiddenTokens(ctx context.Context, userID int64) ([]models.HiddenToken, error) {
+	tokens := []models.HiddenToken{}
+	query := `SELECT user_id, token_address, created_at FROM hidden_tokens WHERE user_id = $1 ORDER BY created_at, token_address`
+	err := r.db.SelectContext(ctx, &tokens, query, userID)
+	return tokens, err
+}
+
+func (r *FeedRepository) CountHiddenTokens(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM hidden_tokens WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// HideToken 隐藏代币，已隐藏时返回原记录
+func (r *FeedRepository) HideToken(ctx context.Context, token *models.HiddenToken) error {
+	query := `
+		INSERT INTO hidden_tokens (user_id, token_address, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, token_address) DO UPDATE SET created_at = hidden_tokens.created_at
+		RETURNING created_at
+	`
+	if err := r.db.QueryRowContext(ctx, query, token.UserID, token.TokenAddress).Scan(&token.CreatedAt); err != nil {
+		return fmt.Errorf("failed to hide token: %w", err)
+	}
+	return nil
+}
+
+func (r *FeedRepository) UnhideToken(ctx context.Context, userID int64, tokenAddress string) error {
+	query := `DELETE FROM hidden_tokens WHERE user_id = $1 AND token_address = $2`
+	result, err := r.db.ExecContext(ctx, query, userID, tokenAddress)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// UsersHidingToken 从 userIDs 中筛选隐藏了该代币的用户
+func (r *FeedRepository) UsersHidingToken(ctx context.Context, tokenAddress string, userIDs []int64) (map[int64]bool, error) {
+	var ids []int64
+	query := `SELECT user_id FROM hidden_tokens WHERE token_address = $1 AND user_id = ANY($2)`
+	if err := r.db.SelectContext(ctx, &ids, query, tokenAddress, pq.Array(userIDs)); err != nil {
+		return nil, fmt.Errorf("failed to get users hiding token: %w", err)
+	}
+	hidden := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
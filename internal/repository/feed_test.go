@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountUserFeedUpTo(t *testing.T) {
+	db := newTestDB(t)
+	_, err := db.Exec(`
+		CREATE TABLE feed_items (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			transaction_id INTEGER NOT NULL,
+			watched_address_id INTEGER
+		);
+		CREATE TABLE hidden_tokens (
+			user_id INTEGER NOT NULL,
+			token_address TEXT NOT NULL
+		);
+	`)
+	require.NoError(t, err)
+
+	const addr = "0x1111111111111111111111111111111111111111"
+	const other = "0x2222222222222222222222222222222222222222"
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 5; i++ {
+		insertTx(t, db, i, fmt.Sprintf("0x%02d", i), addr, other, base.Add(time.Duration(i)*time.Minute))
+		_, err := db.Exec(`INSERT INTO feed_items (id, user_id, transaction_id) VALUES ($1, 1, $1)`, i)
+		require.NoError(t, err)
+	}
+	_, err = db.Exec(`UPDATE transactions SET spam_reason = 'dust' WHERE id = 5`)
+	require.NoError(t, err)
+
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	count, err := repo.CountUserFeedUpTo(ctx, 1, nil, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// 垃圾交易不计入
+	count, err = repo.CountUserFeedUpTo(ctx, 1, nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
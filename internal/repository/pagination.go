@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// keyset 根据分页参数生成键集条件与排序，argIndex 为游标参数的起始占位符序号
+// 轮询模式（After）按升序查询，调用方需将结果反转为降序
+func keyset(q *pagination.Query, timeCol, idCol string, argIndex int) (where, order string, args []interface{}) {
+	switch {
+	case q.Before != nil:
+		where = fmt.Sprintf(" AND (%s, %s) < ($%d, $%d)", timeCol, idCol, argIndex, argIndex+1)
+		args = []interface{}{q.Before.Time, q.Before.ID}
+	case q.After != nil:
+		where = fmt.Sprintf(" AND (%s, %s) > ($%d, $%d)", timeCol, idCol, argIndex, argIndex+1)
+		args = []interface{}{q.After.Time, q.After.ID}
+		return where, fmt.Sprintf(" ORDER BY %s ASC, %s ASC", timeCol, idCol), args
+	}
+	return where, fmt.Sprintf(" ORDER BY %s DESC, %s DESC", timeCol, idCol), args
+}
+
+// pollOverlap 轮询模式补查游标之前的时间窗口：时间列与 id 在提交前生成，
+// 晚提交的行可能排在客户端已取得的游标之前，仅按游标轮询会永久遗漏
+const pollOverlap = 2 * time.Minute
+
+// lateWindow 轮询模式下游标之前 pollOverlap 内的条件，用于补回游标生成后才提交的行；
+// 未设置 q.Overlap（如导出按升序遍历）时返回空。补回的行大多客户端已有，需按 id 去重
+func lateWindow(q *pagination.Query, timeCol, idCol string, argIndex int) (where string, args []interface{}) {
+	if q.After == nil || !q.Overlap {
+		return "", nil
+	}
+	where = fmt.Sprintf(" AND (%s, %s) <= ($%d, $%d) AND %s > $%d", timeCol, idCol, argIndex, argIndex+1, timeCol, argIndex+2)
+	return where, []interface{}{q.After.Time, q.After.ID, q.After.Time.Add(-pollOverlap)}
+}
+
+// lateOrder 补查结果的排序与数量上限，接在 lateWindow 之后，结果按降序排在新数据之后
+func lateOrder(timeCol, idCol string) string {
+	return fmt.Sprintf(" ORDER BY %s DESC, %s DESC LIMIT %d", timeCol, idCol, pagination.MaxLimit)
+}
+
+// trimPage 去掉为判断 hasMore 多取的一行，并将轮询模式的结果恢复为降序
+func trimPage[T any](items []T, q *pagination.Query) ([]T, bool) {
+	hasMore := len(items) > q.Limit
+	if hasMore {
+		items = items[:q.Limit]
+	}
+	if q.After != nil {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items, hasMore
+}
+
+// countRows 精确计数或使用查询计划器估算，query 为 SELECT 1 ... 形式的查询
+func countRows(ctx context.Context, db *sqlx.DB, mode, query string, args ...interface{}) (int64, bool, error) {
+	if mode == pagination.CountEstimate {
+		var raw []byte
+		if err := db.GetContext(ctx, &raw, "EXPLAIN (FORMAT JSON) "+query, args...); err != nil {
+			return 0, false, fmt.Errorf("failed to estimate count: %w", err)
+		}
+		var plan []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+		if err := json.Unmarshal(raw, &plan); err != nil {
+			return 0, false, fmt.Errorf("failed to parse query plan: %w", err)
+		}
+		if len(plan) == 0 {
+			return 0, false, fmt.Errorf("failed to parse query plan: empty plan")
+		}
+		return int64(plan[0].Plan.Rows), true, nil
+	}
+
+	var total int64
+	if err := db.GetContext(ctx, &total, "SELECT COUNT(*) FROM ("+query+") t", args...); err != nil {
+		return 0, false, fmt.Errorf("failed to count: %w", err)
+	}
+	return total, false, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const preferenceColumns = `user_id, timezone, quiet_hours_enabled, quiet_hours_start, quiet_hours_end,
+	rate_limit_count, rate_limit_window, created_at, updated_at`
+
+type PreferenceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPreferenceRepository(db *sqlx.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+// AddressMuteDetail 静音记录及对应的监控地址
+type AddressMuteDetail struct {
+	models.AddressMute
+	Address string `db:"address" json:"address"`
+	Label   string `db:"label"   json:"label"`
+}
+
+// GetPreferences 获取用户通知偏好，未设置时返回 nil
+func (r *PreferenceRepository) GetPreferences(ctx context.Context, userID int64) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	query := `SELECT ` + preferenceColumns + ` FROM notification_preferences WHERE user_id = $1`
+	err := r.db.GetContext(ctx, &pref, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *PreferenceRepository) UpsertPreferences(ctx context.Context, pref *models.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences
+			(user_id, timezone, quiet_hours_enabled, quiet_hours_start, quiet_hours_end,
+			rate_limit_count, rate_limit_window, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			timezone = EXCLUDED.timezone,
+			quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			rate_limit_count = EXCLUDED.rate_limit_count,
+			rate_limit_window = EXCLUDED.rate_limit_window,
+			updated_at = NOW()
+		RETURNING ` + preferenceColumns
+	err := r.db.GetContext(ctx, pref, query,
+		pref.UserID, pref.Timezone, pref.QuietHoursEnabled, pref.QuietHoursStart, pref.QuietHoursEnd,
+		pref.RateLimitCount, pref.RateLimitWindow)
+	if err != nil {
+		return fmt.Errorf("failed to upsert notification preferences: %w", err)
+	}
+	return nil
+}
+
+// ListMutes 获取用户仍在生效的静音
+func (r *PreferenceRepository) ListMutes(ctx context.Context, userID int64) ([]AddressMuteDetail, error) {
+	var mutes []AddressMuteDetail
+	query := `
+		SELECT m.user_id, m.watched_address_id, m.muted_until, m.created_at, wa.address, COALESCE(wa.label, '') AS label
+		FROM address_mutes m
+		JOIN watched_addresses wa ON wa.id = m.watched_address_id
+		WHERE m.user_id = $1 AND m.muted_until > NOW()
+		ORDER BY m.muted_until
+	`
+	err := r.db.SelectContext(ctx, &mutes, query, userID)
+	return mutes, err
+}
+
+// Mute 静音监控地址至 until，重复静音时覆盖原截止时间
+func (r *PreferenceRepository) Mute(ctx context.Context, userID, watchedAddressID int64, until time.Time) (*models.AddressMute, error) {
+	var mute models.AddressMute
+	query := `
+		INSERT INTO address_mutes (user_id, watched_address_id, muted_until, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, watched_address_id) DO UPDATE SET
+			muted_until = EXCLUDED.muted_until,
+			created_at = NOW()
+		RETURNING user_id, watched_address_id, muted_until, created_at
+	`
+	err := r.db.GetContext(ctx, &mute, query, userID, watchedAddressID, until.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mute address: %w", err)
+	}
+	return &mute, nil
+}
+
+func (r *PreferenceRepository) Unmute(ctx context.Context, userID, watchedAddressID int64) error {
+	query := `DELETE FROM address_mutes WHERE user_id = $1 AND watched_address_id = $2 AND muted_until > NOW()`
+	result, err := r.db.ExecContext(ctx, query, userID, watchedAddressID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// IsMuted 判断用户是否静音了该监控地址
+func (r *PreferenceRepository) IsMuted(ctx context.Context, userID, watchedAddressID int64) (bool, error) {
+	var muted bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM address_mutes
+			WHERE user_id = $1 AND watched_address_id = $2 AND muted_until > NOW()
+		)
+	`
+	err := r.db.GetContext(ctx, &muted, query, userID, watchedAddressID)
+	return muted, err
+}
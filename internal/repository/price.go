@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PriceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPriceRepository(db *sqlx.DB) *PriceRepository {
+	return &PriceRepository{db: db}
+}
+
+// GetPrice 获取资产在某小时的缓存价格，不存在时返回 nil
+func (r *PriceRepository) GetPrice(ctx context.Context, asset string, bucket time.Time) (*models.AssetPrice, error) {
+	var price models.AssetPrice
+	query := `SELECT asset, bucket, usd, source, created_at FROM asset_prices WHERE asset = $1 AND bucket = $2`
+	err := r.db.GetContext(ctx, &price, query, asset, bucket.UTC())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// SavePrice 保存价格，已存在时保留原值，历史价格不随来源更新
+func (r *PriceRepository) SavePrice(ctx context.Context, price *models.AssetPrice) error {
+	query := `
+		INSERT INTO asset_prices (asset, bucket, usd, source, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (asset, bucket) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, price.Asset, price.Bucket.UTC(), price.USD, price.Source)
+	return err
+}
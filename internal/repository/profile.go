@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// ProfileRepository 基于已存储交易统计任意地址的活动，不区分是否被监控；垃圾交易不计入
+type ProfileRepository struct {
+	db *sqlx.DB
+}
+
+func NewProfileRepository(db *sqlx.DB) *ProfileRepository {
+	return &ProfileRepository{db: db}
+}
+
+// AddressActivity 地址在统计区间内的首末交易时间与交易数，Incoming / Outgoing 按 to / from 计
+type AddressActivity struct {
+	FirstSeen    *time.Time `db:"first_seen" json:"first_seen"`
+	LastSeen     *time.Time `db:"last_seen" json:"last_seen"`
+	Transactions int64      `db:"transactions" json:"transactions"`
+	Incoming     int64      `db:"incoming" json:"incoming"`
+	Outgoing     int64      `db:"outgoing" json:"outgoing"`
+}
+
+// Counterparty 与地址发生过交易的对手方
+type Counterparty struct {
+	Address      string    `db:"address" json:"address"`
+	Transactions int64     `db:"transactions" json:"transactions"`
+	Incoming     int64     `db:"incoming" json:"incoming"` // 对手方转入该地址
+	Outgoing     int64     `db:"outgoing" json:"outgoing"` // 该地址转给对手方
+	LastSeen     time.Time `db:"last_seen" json:"last_seen"`
+}
+
+// AssetFlow 某资产的流入流出，金额为放大 1e18 的整数；SWAP 按 token_out 计
+type AssetFlow struct {
+	TokenAddress string `db:"token_address" json:"token_address"` // 原生 ETH 为空
+	Symbol       string `db:"symbol" json:"symbol"`
+	Transactions int64  `db:"transactions" json:"transactions"`
+	Inflow       string `db:"inflow" json:"inflow"`
+	Outflow      string `db:"outflow" json:"outflow"`
+	Net          string `db:"-" json:"net"`
+}
+
+// profileWhere 地址相关、非垃圾且晚于 since 的交易条件，$1 为小写地址；since 为 nil 时不限时间
+func profileWhere(since *time.Time) (string, []interface{}) {
+	if since == nil {
+		return ` WHERE (from_address = $1 OR to_address = $1) AND spam_reason = ''`, nil
+	}
+	return ` WHERE (from_address = $1 OR to_address = $1) AND spam_reason = '' AND block_timestamp >= $2`,
+		[]interface{}{since.UTC()}
+}
+
+// Activity 统计地址在 since 之后的活动
+func (r *ProfileRepository) Activity(ctx context.Context, address string, since *time.Time) (*AddressActivity, error) {
+	where, args := profileWhere(since)
+	query := `
+		SELECT MIN(block_timestamp) AS first_seen, MAX(block_timestamp) AS last_seen,
+			COUNT(*) AS transactions,
+			COUNT(*) FILTER (WHERE to_address = $1) AS incoming,
+			COUNT(*) FILTER (WHERE from_address = $1) AS outgoing
+		FROM transactions` + where
+
+	var activity AddressActivity
+	if err := r.db.GetContext(ctx, &activity, query, append([]interface{}{strings.ToLower(address)}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to get address activity: %w", err)
+	}
+	return &activity, nil
+}
+
+// TopCounterparties 按交易数返回 since 之后的前 limit 个对手方
+func (r *ProfileRepository) TopCounterparties(ctx context.Context, address string, since *time.Time, limit int) ([]Counterparty, error) {
+	where, args := profileWhere(since)
+	query := `
+		SELECT counterparty AS address, COUNT(*) AS transactions,
+			COUNT(*) FILTER (WHERE to_address = $1) AS incoming,
+			COUNT(*) FILTER (WHERE from_address = $1) AS outgoing,
+			MAX(block_timestamp) AS last_seen
+		FROM (
+			SELECT from_address, to_address, block_timestamp,
+				CASE WHEN from_address = $1 THEN to_address ELSE from_address END AS counterparty
+			FROM transactions` + where + `
+		) t
+		WHERE counterparty <> '' AND counterparty <> $1
+		GROUP BY counterparty
+		ORDER BY transactions DESC, last_seen DESC` + fmt.Sprintf(" LIMIT %d", limit)
+
+	var counterparties []Counterparty
+	if err := r.db.SelectContext(ctx, &counterparties, query, append([]interface{}{strings.ToLower(address)}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to get top counterparties: %w", err)
+	}
+	return counterparties, nil
+}
+
+// TopAssets 按交易数返回 since 之后的前 limit 种资产及其流入流出；
+// 与摘要一致，NFT、授权与执行失败的交易不计入，自转账只计流入
+func (r *ProfileRepository) TopAssets(ctx context.Context, address string, since *time.Time, limit int) ([]AssetFlow, error) {
+	where, args := profileWhere(since)
+	query := `
+		SELECT asset AS token_address, MAX(symbol) AS symbol, COUNT(*) AS transactions,
+			COALESCE(SUM(value) FILTER (WHERE to_address = $1), 0) AS inflow,
+			COALESCE(SUM(value) FILTER (WHERE from_address = $1 AND COALESCE(to_address, '') <> $1), 0) AS outflow
+		FROM (
+			SELECT from_address, to_address, value,
+				CASE WHEN tx_type = 'ETH' OR COALESCE(token_address, '') = '' THEN '' ELSE token_address END AS asset,
+				CASE WHEN tx_type = 'ETH' OR COALESCE(token_address, '') = '' THEN 'ETH' ELSE COALESCE(token_symbol, '') END AS symbol
+			FROM transactions` + where + `
+				AND tx_type NOT IN ('ERC721', 'APPROVAL') AND status <> '` + models.TxStatusReverted + `'
+		) t
+		GROUP BY asset
+		ORDER BY transactions DESC, asset` + fmt.Sprintf(" LIMIT %d", limit)
+
+	var flows []AssetFlow
+	if err := r.db.SelectContext(ctx, &flows, query, append([]interface{}{strings.ToLower(address)}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to get asset flows: %w", err)
+	}
+	for i := range flows {
+		flows[i].Net = netFlow(flows[i].Inflow, flows[i].Outflow)
+	}
+	return flows, nil
+}
+
+// InFeed 判断地址是否出现在用户 feed 的交易中（作为发送方或接收方）
+func (r *ProfileRepository) InFeed(ctx context.Context, userID int64, address string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM feed_items fi
+			JOIN transactions t ON fi.transaction_id = t.id
+			WHERE fi.user_id = $1 AND (t.from_address = $2 OR t.to_address = $2)
+		)`
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, userID, strings.ToLower(address))
+	return exists, err
+}
+
+func netFlow(inflow, outflow string) string {
+	in, ok := new(big.Int).SetString(inflow, 10)
+	if !ok {
+		in = new(big.Int)
+	}
+	out, ok := new(big.Int).SetString(outflow, 10)
+	if !ok {
+		out = new(big.Int)
+	}
+	return in.Sub(in, out).String()
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// profileTestDriver SQLite 中 MIN / MAX(block_timestamp) 等表达式没有声明类型，go-sqlite3 按字符串返回；
+// 该驱动将可解析为时间的字符串转换为 time.Time，与 PostgreSQL 的 timestamptz 一致
+const profileTestDriver = "sqlite3_profile"
+
+func init() {
+	sql.Register(profileTestDriver, timeDriver{})
+}
+
+type timeDriver struct{ sqlite3.SQLiteDriver }
+
+func (d timeDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return timeConn{conn}, nil
+}
+
+type timeConn struct{ driver.Conn }
+
+func (c timeConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return timeStmt{stmt}, nil
+}
+
+type timeStmt struct{ driver.Stmt }
+
+func (s timeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.Stmt.Query(args)
+	if err != nil {
+		return nil, err
+	}
+	return timeRows{rows}, nil
+}
+
+type timeRows struct{ driver.Rows }
+
+func (r timeRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		for _, format := range sqlite3.SQLiteTimestampFormats {
+			if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+				dest[i] = t
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func insertProfileTx(t *testing.T, db *sqlx.DB, id int64, from, to, txType, token, value, spam, status string, at time.Time) {
+	t.Helper()
+	symbol := ""
+	if token != "" {
+		symbol = "USDC"
+	}
+	_, err := db.Exec(`INSERT INTO transactions (id, tx_hash, block_timestamp, from_address, to_address, value, tx_type,
+			token_address, token_symbol, spam_reason, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		id, fmt.Sprintf("0x%02d", id), at.UTC(), from, to, value, txType, token, symbol, spam, status)
+	require.NoError(t, err)
+}
+
+func TestProfileRepository(t *testing.T) {
+	db := newTestDBWithDriver(t, profileTestDriver)
+	repo := NewProfileRepository(db)
+	ctx := context.Background()
+
+	const addr = "0xabcdef1111111111111111111111111111111111"
+	const alice = "0x2222222222222222222222222222222222222222"
+	const bob = "0x3333333333333333333333333333333333333333"
+	const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	const oneETH = "1000000000000000000"
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	insertProfileTx(t, db, 1, addr, alice, "ETH", "", oneETH, "", "", now.Add(-40*24*time.Hour))
+	insertProfileTx(t, db, 2, alice, addr, "ETH", "", oneETH, "", "", now.Add(-48*time.Hour))
+	insertProfileTx(t, db, 3, addr, bob, "ERC20", usdc, "5000000000000000000", "", models.TxStatusSuccess, now.Add(-24*time.Hour))
+	insertProfileTx(t, db, 4, bob, addr, "ERC20", usdc, oneETH, models.SpamReasonLookalike, "", now.Add(-time.Hour))
+	insertProfileTx(t, db, 5, addr, addr, "ETH", "", oneETH, "", "", now.Add(-3*time.Hour))
+	insertProfileTx(t, db, 6, addr, alice, "APPROVAL", usdc, "0", "", "", now.Add(-2*time.Hour))
+	insertProfileTx(t, db, 7, addr, bob, "ERC20", usdc, oneETH, "", models.TxStatusReverted, now.Add(-30*time.Minute))
+	since := now.Add(-7 * 24 * time.Hour)
+
+	t.Run("Activity", func(t *testing.T) {
+		// 大小写不同的地址按小写匹配，垃圾交易不计入
+		lifetime, err := repo.Activity(ctx, "0xABCDEF1111111111111111111111111111111111", nil)
+		require.NoError(t, err)
+		require.NotNil(t, lifetime.FirstSeen)
+		require.NotNil(t, lifetime.LastSeen)
+		assert.True(t, now.Add(-40*24*time.Hour).Equal(*lifetime.FirstSeen))
+		assert.True(t, now.Add(-30*time.Minute).Equal(*lifetime.LastSeen))
+		assert.Equal(t, int64(6), lifetime.Transactions)
+		assert.Equal(t, int64(2), lifetime.Incoming)
+		assert.Equal(t, int64(5), lifetime.Outgoing)
+
+		window, err := repo.Activity(ctx, addr, &since)
+		require.NoError(t, err)
+		require.NotNil(t, window.FirstSeen)
+		assert.True(t, now.Add(-48*time.Hour).Equal(*window.FirstSeen))
+		assert.Equal(t, int64(5), window.Transactions)
+		assert.Equal(t, int64(2), window.Incoming)
+		assert.Equal(t, int64(4), window.Outgoing)
+
+		empty, err := repo.Activity(ctx, "0x4444444444444444444444444444444444444444", nil)
+		require.NoError(t, err)
+		assert.Nil(t, empty.FirstSeen)
+		assert.Zero(t, empty.Transactions)
+	})
+
+	t.Run("TopCounterparties", func(t *testing.T) {
+		// 自转账不算对手方；交易数相同时最近活跃的在前
+		counterparties, err := repo.TopCounterparties(ctx, addr, &since, 10)
+		require.NoError(t, err)
+		require.Len(t, counterparties, 2)
+		assert.Equal(t, bob, counterparties[0].Address)
+		assert.Equal(t, int64(2), counterparties[0].Transactions)
+		assert.Equal(t, int64(0), counterparties[0].Incoming)
+		assert.Equal(t, int64(2), counterparties[0].Outgoing)
+		assert.True(t, now.Add(-30*time.Minute).Equal(counterparties[0].LastSeen))
+		assert.Equal(t, alice, counterparties[1].Address)
+		assert.Equal(t, int64(1), counterparties[1].Incoming)
+		assert.Equal(t, int64(1), counterparties[1].Outgoing)
+
+		limited, err := repo.TopCounterparties(ctx, addr, nil, 1)
+		require.NoError(t, err)
+		require.Len(t, limited, 1)
+		assert.Equal(t, alice, limited[0].Address)
+		assert.Equal(t, int64(3), limited[0].Transactions)
+	})
+
+	t.Run("TopAssets", func(t *testing.T) {
+		// 授权与执行失败的交易不计入，自转账只计流入
+		assets, err := repo.TopAssets(ctx, addr, &since, 20)
+		require.NoError(t, err)
+		require.Len(t, assets, 2)
+		assert.Equal(t, AssetFlow{TokenAddress: "", Symbol: "ETH", Transactions: 2,
+			Inflow: "2000000000000000000", Outflow: "0", Net: "2000000000000000000"}, assets[0])
+		assert.Equal(t, AssetFlow{TokenAddress: usdc, Symbol: "USDC", Transactions: 1,
+			Inflow: "0", Outflow: "5000000000000000000", Net: "-5000000000000000000"}, assets[1])
+	})
+}
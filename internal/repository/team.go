@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TeamRepository struct {
+	db *sqlx.DB
+}
+
+func NewTeamRepository(db *sqlx.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// TeamWithRole 团队及当前用户在团队中的角色
+type TeamWithRole struct {
+	models.Team
+	Role        string `db:"role"         json:"role"`
+	MemberCount int    `db:"member_count" json:"member_count"`
+}
+
+// TeamMemberDetail 团队成员及其钱包地址
+type TeamMemberDetail struct {
+	models.TeamMember
+	WalletAddress string `db:"wallet_address" json:"wallet_address"`
+}
+
+// TeamInviteDetail 邀请及团队名称（被邀请人视角）
+type TeamInviteDetail struct {
+	models.TeamInvite
+	TeamName string `db:"team_name" json:"team_name"`
+}
+
+// Create 创建团队，并将创建者加入为 owner
+func (r *TeamRepository) Create(ctx context.Context, team *models.Team) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO teams (name, owner_id, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	if err := tx.QueryRowContext(ctx, query, team.Name, team.OwnerID).
+		Scan(&team.ID, &team.CreatedAt, &team.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+
+	memberQuery := `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, memberQuery, team.ID, team.OwnerID, models.TeamRoleOwner); err != nil {
+		return fmt.Errorf("failed to add team owner: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id int64) (*models.Team, error) {
+	var team models.Team
+	query := `SELECT id, name, owner_id, created_at, updated_at FROM teams WHERE id = $1`
+	err := r.db.GetContext(ctx, &team, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+// ListByUser 获取用户所在的所有团队
+func (r *TeamRepository) ListByUser(ctx context.Context, userID int64) ([]TeamWithRole, error) {
+	var teams []TeamWithRole
+	query := `
+		SELECT t.id, t.name, t.owner_id, t.created_at, t.updated_at, tm.role,
+			(SELECT COUNT(*) FROM team_members m WHERE m.team_id = t.id) AS member_count
+		FROM teams t
+		JOIN team_members tm ON tm.team_id = t.id
+		WHERE tm.user_id = $1
+		ORDER BY t.created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &teams, query, userID)
+	return teams, err
+}
+
+func (r *TeamRepository) Rename(ctx context.Context, id int64, name string) error {
+	query := `UPDATE teams SET name = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, name, id)
+	return err
+}
+
+// Delete 删除团队，团队地址及其 feed 通过外键级联删除
+func (r *TeamRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM teams WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// GetMemberRole 获取用户在团队中的角色，非成员返回空字符串
+func (r *TeamRepository) GetMemberRole(ctx context.Context, teamID, userID int64) (string, error) {
+	var role string
+	query := `SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`
+	err := r.db.GetContext(ctx, &role, query, teamID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+func (r *TeamRepository) ListMembers(ctx context.Context, teamID int64) ([]TeamMemberDetail, error) {
+	var members []TeamMemberDetail
+	query := `
+		SELECT tm.team_id, tm.user_id, tm.role, tm.created_at, u.wallet_address
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1
+		ORDER BY tm.created_at ASC
+	`
+	err := r.db.SelectContext(ctx, &members, query, teamID)
+	return members, err
+}
+
+// GetMemberUserIDs 获取团队所有成员的用户 ID（用于 feed 扇出）
+func (r *TeamRepository) GetMemberUserIDs(ctx context.Context, teamID int64) ([]int64, error) {
+	var ids []int64
+	query := `SELECT user_id FROM team_members WHERE team_id = $1`
+	err := r.db.SelectContext(ctx, &ids, query, teamID)
+	return ids, err
+}
+
+func (r *TeamRepository) UpdateMemberRole(ctx context.Context, teamID, userID int64, role string) error {
+	query := `UPDATE team_members SET role = $1 WHERE team_id = $2 AND user_id = $3`
+	result, err := r.db.ExecContext(ctx, query, role, teamID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RemoveMember 移除成员，同时清理该成员来自团队地址的 feed
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	// 只移除该成员 feed 条目中来自团队地址的命中；仍命中其他监控地址的条目改指向其余地址并保留
+	teamAddresses := `SELECT id FROM watched_addresses WHERE team_id = $2`
+	queries := []string{`
+		DELETE FROM feed_item_addresses
+		WHERE feed_item_id IN (SELECT id FROM feed_items WHERE user_id = $1)
+		  AND watched_address_id IN (` + teamAddresses + `)`, `
+		UPDATE feed_items
+		SET watched_address_id = (
+			SELECT MIN(fia.watched_address_id) FROM feed_item_addresses fia WHERE fia.feed_item_id = feed_items.id
+		)
+		WHERE user_id = $1
+		  AND watched_address_id IN (` + teamAddresses + `)
+		  AND EXISTS (SELECT 1 FROM feed_item_addresses fia WHERE fia.feed_item_id = feed_items.id)`, `
+		DELETE FROM feed_items
+		WHERE user_id = $1
+		  AND watched_address_id IN (` + teamAddresses + `)`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, userID, teamID); err != nil {
+			return fmt.Errorf("failed to remove team feed items: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateInvite 创建邀请，同一钱包在同一团队只能有一个待处理邀请
+func (r *TeamRepository) CreateInvite(ctx context.Context, invite *models.TeamInvite) error {
+	query := `
+		INSERT INTO team_invites (team_id, wallet_address, role, invited_by, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (team_id, wallet_address) WHERE status = 'pending'
+		DO UPDATE SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by, updated_at = NOW()
+		RETURNING id, status, created_at, updated_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		invite.TeamID, invite.WalletAddress, invite.Role, invite.InvitedBy, models.InviteStatusPending).
+		Scan(&invite.ID, &invite.Status, &invite.CreatedAt, &invite.UpdatedAt)
+}
+
+func (r *TeamRepository) GetInvite(ctx context.Context, id int64) (*models.TeamInvite, error) {
+	var invite models.TeamInvite
+	query := `
+		SELECT id, team_id, wallet_address, role, invited_by, status, created_at, updated_at
+		FROM team_invites WHERE id = $1
+	`
+	err := r.db.GetContext(ctx, &invite, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ListInvites 获取团队的待处理邀请
+func (r *TeamRepository) ListInvites(ctx context.Context, teamID int64) ([]models.TeamInvite, error) {
+	var invites []models.TeamInvite
+	query := `
+		SELECT id, team_id, wallet_address, role, invited_by, status, created_at, updated_at
+		FROM team_invites
+		WHERE team_id = $1 AND status = 'pending'
+		ORDER BY created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &invites, query, teamID)
+	return invites, err
+}
+
+// ListPendingByWallet 获取发给某个钱包的待处理邀请，钱包地址需为 checksum 格式（与存储一致）
+func (r *TeamRepository) ListPendingByWallet(ctx context.Context, walletAddress string) ([]TeamInviteDetail, error) {
+	var invites []TeamInviteDetail
+	query := `
+		SELECT i.id, i.team_id, i.wallet_address, i.role, i.invited_by, i.status,
+			i.created_at, i.updated_at, t.name AS team_name
+		FROM team_invites i
+		JOIN teams t ON t.id = i.team_id
+		WHERE i.wallet_address = $1 AND i.status = 'pending'
+		ORDER BY i.created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &invites, query, walletAddress)
+	return invites, err
+}
+
+// AcceptInvite 接受邀请并加入团队（已是成员则保留原角色）
+func (r *TeamRepository) AcceptInvite(ctx context.Context, invite *models.TeamInvite, userID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE team_invites SET status = $1, updated_at = NOW() WHERE id = $2 AND status = 'pending'`,
+		models.InviteStatusAccepted, invite.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update invite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	memberQuery := `
+		INSERT INTO team_members (team_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_id, user_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, memberQuery, invite.TeamID, userID, invite.Role); err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *TeamRepository) DeclineInvite(ctx context.Context, id int64) error {
+	query := `UPDATE team_invites SET status = $1, updated_at = NOW() WHERE id = $2 AND status = 'pending'`
+	result, err := r.db.ExecContext(ctx, query, models.InviteStatusDeclined, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteInvite 撤回待处理邀请
+func (r *TeamRepository) DeleteInvite(ctx context.Context, teamID, id int64) error {
+	query := `DELETE FROM team_invites WHERE id = $1 AND team_id = $2 AND status = 'pending'`
+	result, err := r.db.ExecContext(ctx, query, id, teamID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type TokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewTokenRepository(db *sqlx.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+const tokenColumns = `address, name, symbol, decimals, logo_url, verified, fetched_at, created_at, updated_at`
+
+// GetByAddress 获取代币元数据，不存在时返回 nil
+func (r *TokenRepository) GetByAddress(ctx context.Context, address string) (*models.Token, error) {
+	var token models.Token
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE address = $1`
+	err := r.db.GetContext(ctx, &token, query, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByAddresses 批量获取代币元数据，不存在的地址不出现在结果中
+func (r *TokenRepository) GetByAddresses(ctx context.Context, addresses []string) ([]models.Token, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	var tokens []models.Token
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE address = ANY($1)`
+	if err := r.db.SelectContext(ctx, &tokens, query, pq.Array(addresses)); err != nil {
+		return nil, fmt.Errorf("failed to get tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// SaveFetched 保存链上查询结果；链上返回空值时保留代币列表中的 name/symbol，logo 与认证状态不变
+func (r *TokenRepository) SaveFetched(ctx context.Context, token *models.Token) error {
+	query := `
+		INSERT INTO tokens (address, name, symbol, decimals, fetched_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW(), NOW())
+		ON CONFLICT (address) DO UPDATE SET
+			name = COALESCE(NULLIF(EXCLUDED.name, ''), tokens.name),
+			symbol = COALESCE(NULLIF(EXCLUDED.symbol, ''), tokens.symbol),
+			decimals = COALESCE(EXCLUDED.decimals, tokens.decimals),
+			fetched_at = EXCLUDED.fetched_at,
+			updated_at = NOW()
+		RETURNING ` + tokenColumns
+	return r.db.GetContext(ctx, token, query, token.Address, token.Name, token.Symbol, token.Decimals)
+}
+
+// SyncList 用代币列表更新 logo 与认证状态：列表中的代币标记为已认证，其余代币取消认证；
+// 新代币先写入列表中的 name/symbol/decimals，等待链上查询确认
+func (r *TokenRepository) SyncList(ctx context.Context, tokens []models.Token) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	addresses := make([]string, len(tokens))
+	query := `
+		INSERT INTO tokens (address, name, symbol, decimals, logo_url, verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, TRUE, NOW(), NOW())
+		ON CONFLICT (address) DO UPDATE SET
+			logo_url = EXCLUDED.logo_url,
+			verified = TRUE,
+			updated_at = NOW()
+		WHERE tokens.logo_url <> EXCLUDED.logo_url OR NOT tokens.verified
+	`
+	for i, t := range tokens {
+		addresses[i] = t.Address
+		if _, err := tx.ExecContext(ctx, query, t.Address, t.Name, t.Symbol, t.Decimals, t.LogoURL); err != nil {
+			return fmt.Errorf("failed to save listed token %s: %w", t.Address, err)
+		}
+	}
+
+	unverify := `UPDATE tokens SET verified = FALSE, updated_at = NOW() WHERE verified AND address <> ALL($1)`
+	if _, err := tx.ExecContext(ctx, unverify, pq.Array(addresses)); err != nil {
+		return fmt.Errorf("failed to reset token verification: %w", err)
+	}
+
+	return tx.Commit()
+}
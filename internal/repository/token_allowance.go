@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TokenAllowanceRepository struct {
+	db *sqlx.DB
+}
+
+func NewTokenAllowanceRepository(db *sqlx.DB) *TokenAllowanceRepository {
+	return &TokenAllowanceRepository{db: db}
+}
+
+// Save 记录一次授权事件；已有更晚（按区块号与日志序号）的事件时保持不变，避免乱序推送覆盖最新状态
+func (r *TokenAllowanceRepository) Save(ctx context.Context, a *models.TokenAllowance) error {
+	query := `
+		INSERT INTO token_allowances (owner, token_address, spender, token_symbol, token_decimals, spender_name,
+			standard, amount, unlimited, risky, revoked, tx_hash, block_number, log_index, approved_at, updated_at)
+		VALUES (:owner, :token_address, :spender, :token_symbol, :token_decimals, :spender_name,
+			:standard, :amount, :unlimited, :risky, :revoked, :tx_hash, :block_number, :log_index, :approved_at, NOW())
+		ON CONFLICT (owner, token_address, spender) DO UPDATE SET
+			token_symbol = EXCLUDED.token_symbol,
+			token_decimals = EXCLUDED.token_decimals,
+			spender_name = EXCLUDED.spender_name,
+			standard = EXCLUDED.standard,
+			amount = EXCLUDED.amount,
+			unlimited = EXCLUDED.unlimited,
+			risky = EXCLUDED.risky,
+			revoked = EXCLUDED.revoked,
+			tx_hash = EXCLUDED.tx_hash,
+			block_number = EXCLUDED.block_number,
+			log_index = EXCLUDED.log_index,
+			approved_at = EXCLUDED.approved_at,
+			updated_at = NOW()
+		WHERE (EXCLUDED.block_number, EXCLUDED.log_index) > (token_allowances.block_number, token_allowances.log_index)`
+	_, err := r.db.NamedExecContext(ctx, query, a)
+	return err
+}
+
+// ListByOwner 获取钱包当前有效的授权（不含已撤销），有风险的授权在前；riskyOnly 为 true 时只返回有风险的授权
+func (r *TokenAllowanceRepository) ListByOwner(ctx context.Context, owner string, riskyOnly bool) ([]models.TokenAllowance, error) {
+	allowances := []models.TokenAllowance{}
+	query := `
+		SELECT owner, token_address, token_symbol, token_decimals, spender, spender_name, standard, amount,
+			unlimited, risky, revoked, tx_hash, block_number, log_index, approved_at, updated_at
+		FROM token_allowances
+		WHERE owner = $1 AND NOT revoked`
+	if riskyOnly {
+		query += ` AND risky`
+	}
+	query += ` ORDER BY risky DESC, approved_at DESC, token_address, spender`
+	if err := r.db.SelectContext(ctx, &allowances, query, owner); err != nil {
+		return nil, err
+	}
+	return allowances, nil
+}
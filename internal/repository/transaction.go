@@ -1,10 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -17,33 +20,101 @@ func NewTransactionRepository(db *sqlx.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
+// swapDetected 已入库的交易再次推送时才识别出兑换（如首次推送时解码失败），此时以合并后的 SWAP 记录为准
+const swapDetected = `transactions.swap IS NULL AND EXCLUDED.swap IS NOT NULL`
+
+// onSwapDetected 识别出兑换时取新值，否则保留已入库的值
+func onSwapDetected(column string) string {
+	return column + ` = CASE WHEN ` + swapDetected + ` THEN EXCLUDED.` + column + ` ELSE transactions.` + column + ` END`
+}
+
+// Create 写入交易并回填 ID，按 (tx_hash, log_index) 合并已有记录：已有的解码、价格与回执信息保留，后续识别出的兑换、
+// 垃圾交易分类与授权明细写入（授权只更新已是 APPROVAL 的交易，同一哈希已有转账时不改写），RETURNING 的字段为合并后的结果。
+// 写入兑换时删除同一哈希此前按转账单独入库的其余 leg（授权保留）；兑换入库后再次推送的单条 leg 合并到 SWAP，
+// 回填合并后的整行（方向、金额与代币以兑换为准），feed 与告警按存储的结果匹配
 func (r *TransactionRepository) Create(tx *models.Transaction) error {
 	query := `
-		INSERT INTO transactions (tx_hash, block_number, block_timestamp, from_address, to_address, 
-			value, tx_type, token_address, token_id, token_symbol, token_decimals)
-		VALUES (:tx_hash, :block_number, :block_timestamp, :from_address, :to_address, 
-			:value, :tx_type, :token_address, :token_id, :token_symbol, :token_decimals)
-		ON CONFLICT (tx_hash) DO UPDATE SET tx_hash = EXCLUDED.tx_hash
-		RETURNING id, created_at`
-
-	rows, err := r.db.NamedQuery(query, tx)
+		INSERT INTO transactions (tx_hash, log_index, block_number, block_timestamp, from_address, to_address, 
+			value, tx_type, token_address, token_id, token_symbol, token_decimals, usd_value, spam_reason, action, swap, approval,
+			status, nonce, fee, gas, input_data)
+		VALUES (:tx_hash, :log_index, :block_number, :block_timestamp, :from_address, :to_address, 
+			:value, :tx_type, :token_address, :token_id, :token_symbol, :token_decimals, :usd_value, :spam_reason, :action, :swap, :approval,
+			:status, :nonce, :fee, :gas, :input_data)
+		ON CONFLICT (tx_hash, log_index) DO UPDATE SET
+			` + onSwapDetected("tx_type") + `,
+			` + onSwapDetected("from_address") + `,
+			` + onSwapDetected("to_address") + `,
+			` + onSwapDetected("value") + `,
+			` + onSwapDetected("token_address") + `,
+			` + onSwapDetected("token_id") + `,
+			` + onSwapDetected("token_symbol") + `,
+			` + onSwapDetected("token_decimals") + `,
+			usd_value = CASE WHEN ` + swapDetected + ` THEN EXCLUDED.usd_value
+				ELSE COALESCE(transactions.usd_value, EXCLUDED.usd_value) END,
+			spam_reason = COALESCE(NULLIF(EXCLUDED.spam_reason, ''), transactions.spam_reason),
+			action = COALESCE(transactions.action, EXCLUDED.action),
+			swap = COALESCE(EXCLUDED.swap, transactions.swap),
+			approval = CASE WHEN transactions.tx_type = 'APPROVAL'
+				THEN COALESCE(EXCLUDED.approval, transactions.approval) ELSE transactions.approval END,
+			status = CASE WHEN transactions.status = '' THEN EXCLUDED.status ELSE transactions.status END,
+			nonce = COALESCE(transactions.nonce, EXCLUDED.nonce),
+			fee = COALESCE(transactions.fee, EXCLUDED.fee),
+			gas = COALESCE(transactions.gas, EXCLUDED.gas),
+			input_data = CASE WHEN transactions.input_data = '' THEN EXCLUDED.input_data ELSE transactions.input_data END
+		RETURNING id, log_index, created_at, from_address, to_address, value, tx_type, token_address, token_id, token_symbol,
+			token_decimals, usd_value, spam_reason, action, swap, approval, status, nonce, fee, gas, input_data`
+
+	dbTx, err := r.db.Beginx()
 	if err != nil {
-		return fmt.Errorf("failed to create transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	if tx.Swap == nil && tx.Approval == nil {
+		var swapIndex int
+		err := dbTx.Get(&swapIndex, `SELECT log_index FROM transactions WHERE tx_hash = $1 AND swap IS NOT NULL`, tx.TxHash)
+		switch {
+		case err == nil:
+			tx.LogIndex = swapIndex
+		case err != sql.ErrNoRows:
+			return fmt.Errorf("failed to get swap: %w", err)
+		}
 	}
-	defer rows.Close()
 
+	tokenAddress := tx.TokenAddress
+	rows, err := dbTx.NamedQuery(query, tx)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
 	if rows.Next() {
-		if err := rows.Scan(&tx.ID, &tx.CreatedAt); err != nil {
+		if err := rows.Scan(&tx.ID, &tx.LogIndex, &tx.CreatedAt, &tx.FromAddress, &tx.ToAddress, &tx.Value, &tx.TxType,
+			&tx.TokenAddress, &tx.TokenID, &tx.TokenSymbol, &tx.TokenDecimals, &tx.USDValue, &tx.SpamReason, &tx.Action,
+			&tx.Swap, &tx.Approval, &tx.Status, &tx.Nonce, &tx.Fee, &tx.Gas, &tx.InputData); err != nil {
+			rows.Close()
 			return fmt.Errorf("failed to scan transaction: %w", err)
 		}
 	}
+	rows.Close()
+	// 合并到 SWAP 后代币改为 token_out，原有的代币元数据不再对应
+	if tx.TokenAddress != tokenAddress {
+		tx.Token = nil
+	}
+
+	if tx.Swap != nil {
+		if _, err := dbTx.Exec(`
+			DELETE FROM transactions
+			WHERE tx_hash = $1 AND id <> $2 AND swap IS NULL AND tx_type <> 'APPROVAL'`, tx.TxHash, tx.ID); err != nil {
+			return fmt.Errorf("failed to delete swap legs: %w", err)
+		}
+	}
 
-	return nil
+	return dbTx.Commit()
 }
 
+// GetByHash 获取交易的第一行（交易级记录或序号最小的转账日志）
 func (r *TransactionRepository) GetByHash(hash string) (*models.Transaction, error) {
 	var tx models.Transaction
-	query := `SELECT * FROM transactions WHERE tx_hash = $1`
+	query := `SELECT * FROM transactions WHERE tx_hash = $1 ORDER BY log_index LIMIT 1`
 
 	err := r.db.Get(&tx, query, hash)
 	if err != nil {
@@ -56,18 +127,55 @@ func (r *TransactionRepository) GetByHash(hash string) (*models.Transaction, err
 	return &tx, nil
 }
 
-func (r *TransactionRepository) GetByAddress(address string, limit, offset int) ([]models.Transaction, error) {
-	var txs []models.Transaction
-	query := `
+// ListByAddress 按 (block_timestamp, id) 键集分页获取地址相关交易，结果按时间降序；
+// includeSpam 为 false 时排除垃圾交易，excludeReverted 为 true 时排除执行失败的交易。
+// 接口轮询（q.Overlap）改按入库顺序 (created_at, id)：回填与补推的交易区块时间远早于入库时间，
+// 按区块时间轮询永远取不到；新数据之后附带游标前 pollOverlap 内入库的交易（见 lateWindow）
+func (r *TransactionRepository) ListByAddress(ctx context.Context, address string, includeSpam, excludeReverted bool, q *pagination.Query) ([]models.Transaction, bool, error) {
+	base := `
 		SELECT * FROM transactions 
-		WHERE from_address = $1 OR to_address = $1
-		ORDER BY block_timestamp DESC
-		LIMIT $2 OFFSET $3`
+		WHERE (from_address = $1 OR to_address = $1)` + spamFilter(includeSpam) + statusFilter(excludeReverted)
+	timeCol := "block_timestamp"
+	if q.After != nil && q.Overlap {
+		timeCol = "created_at"
+	}
+	where, order, args := keyset(q, timeCol, "id", 2)
+	query := base + where + order + fmt.Sprintf(" LIMIT %d", q.Limit+1)
 
-	err := r.db.Select(&txs, query, address, limit, offset)
+	var txs []models.Transaction
+	err := r.db.SelectContext(ctx, &txs, query, append([]interface{}{strings.ToLower(address)}, args...)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transactions by address: %w", err)
+		return nil, false, fmt.Errorf("failed to get transactions by address: %w", err)
+	}
+
+	txs, hasMore := trimPage(txs, q)
+	if lateWhere, lateArgs := lateWindow(q, timeCol, "id", 2); lateWhere != "" {
+		var late []models.Transaction
+		query := base + lateWhere + lateOrder(timeCol, "id")
+		if err := r.db.SelectContext(ctx, &late, query, append([]interface{}{strings.ToLower(address)}, lateArgs...)...); err != nil {
+			return nil, false, fmt.Errorf("failed to get late transactions by address: %w", err)
+		}
+		txs = append(txs, late...)
 	}
+	return txs, hasMore, nil
+}
+
+// CountByAddress 统计地址相关交易数，estimated 表示结果为估算值
+func (r *TransactionRepository) CountByAddress(ctx context.Context, address string, includeSpam, excludeReverted bool, mode string) (int64, bool, error) {
+	query := `SELECT 1 FROM transactions WHERE (from_address = $1 OR to_address = $1)` + spamFilter(includeSpam) + statusFilter(excludeReverted)
+	return countRows(ctx, r.db, mode, query, strings.ToLower(address))
+}
 
-	return txs, nil
+func spamFilter(includeSpam bool) string {
+	if includeSpam {
+		return ""
+	}
+	return " AND spam_reason = ''"
+}
+
+func statusFilter(excludeReverted bool) string {
+	if !excludeReverted {
+		return ""
+	}
+	return " AND status <> '" + models.TxStatusReverted + "'"
 }
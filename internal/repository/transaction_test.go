@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/pagination"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// transactionsSchema SQLite 版 transactions 表，列与 PostgreSQL 一致
+const transactionsSchema = `
+	CREATE TABLE transactions (
+		id INTEGER PRIMARY KEY,
+		tx_hash TEXT,
+		log_index INTEGER NOT NULL DEFAULT -1,
+		block_number INTEGER NOT NULL DEFAULT 0,
+		block_timestamp DATETIME,
+		from_address TEXT,
+		to_address TEXT,
+		value TEXT,
+		tx_type TEXT,
+		token_address TEXT NOT NULL DEFAULT '',
+		token_id TEXT NOT NULL DEFAULT '',
+		token_symbol TEXT NOT NULL DEFAULT '',
+		token_decimals INTEGER NOT NULL DEFAULT 0,
+		usd_value TEXT,
+		spam_reason TEXT NOT NULL DEFAULT '',
+		action TEXT,
+		swap TEXT,
+		approval TEXT,
+		status TEXT NOT NULL DEFAULT '',
+		nonce INTEGER,
+		fee TEXT,
+		gas TEXT,
+		input_data TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (tx_hash, log_index)
+	)
+`
+
+// newTestDB 创建内存 SQLite 数据库及 transactions 表
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	return newTestDBWithDriver(t, "sqlite3")
+}
+
+func newTestDBWithDriver(t *testing.T, driverName string) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Connect(driverName, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(transactionsSchema)
+	require.NoError(t, err)
+	return db
+}
+
+func insertTx(t *testing.T, db *sqlx.DB, id int64, hash, from, to string, at time.Time) {
+	t.Helper()
+	_, err := db.Exec(`INSERT INTO transactions (id, tx_hash, block_timestamp, from_address, to_address, value, tx_type, created_at)
+		VALUES ($1, $2, $3, $4, $5, '1000000000000000000', 'ETH', $3)`, id, hash, at.UTC(), from, to)
+	require.NoError(t, err)
+}
+
+func TestListByAddress_PollingReturnsLateRows(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	const addr = "0x1111111111111111111111111111111111111111"
+	const other = "0x2222222222222222222222222222222222222222"
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	insertTx(t, db, 1, "0x01", addr, other, base.Add(-time.Hour))
+	insertTx(t, db, 2, "0x02", addr, other, base.Add(-10*time.Second))
+	insertTx(t, db, 3, "0x03", other, addr, base)
+
+	first, _, err := repo.ListByAddress(ctx, addr, true, false, &pagination.Query{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+	cursor := pagination.Cursor{Time: first[0].CreatedAt, ID: first[0].ID}
+
+	// 游标生成后才提交的交易：一笔排在游标之前（窗口内），一笔晚于游标
+	insertTx(t, db, 4, "0x04", addr, other, base.Add(-30*time.Second))
+	insertTx(t, db, 5, "0x05", addr, other, base.Add(time.Second))
+	// 回填的历史交易：区块时间早于游标，入库时间晚于游标
+	insertTx(t, db, 6, "0x06", addr, other, base.Add(-30*24*time.Hour))
+	_, err = db.Exec(`UPDATE transactions SET created_at = $1 WHERE id = 6`, base.Add(2*time.Second))
+	require.NoError(t, err)
+
+	txs, hasMore, err := repo.ListByAddress(ctx, addr, true, false, &pagination.Query{Limit: 10, After: &cursor, Overlap: true})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+
+	var ids []int64
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	// 新入库的数据在前，随后是窗口内的行（含客户端已有的 3、2，按 id 去重），窗口外的 1 不再返回
+	assert.Equal(t, []int64{6, 5, 3, 2, 4}, ids)
+}
+
+func TestListByAddress_ExportPagesEachRowOnce(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	const addr = "0x1111111111111111111111111111111111111111"
+	const other = "0x2222222222222222222222222222222222222222"
+	const total = 2500
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	dbtx := db.MustBegin()
+	for id := 1; id <= total; id++ {
+		// 每三笔同一区块时间，翻页边界落在相同时间上时按 id 区分
+		at := base.Add(time.Duration(id/3) * time.Second)
+		dbtx.MustExec(`INSERT INTO transactions (id, tx_hash, block_timestamp, from_address, to_address, value, tx_type, created_at)
+			VALUES ($1, $2, $3, $4, $5, '1', 'ETH', $3)`, id, fmt.Sprintf("0x%04d", id), at, addr, other)
+	}
+	require.NoError(t, dbtx.Commit())
+
+	// 与导出相同：不带 Overlap 的 After 按 (block_timestamp, id) 升序遍历
+	seen := make(map[int64]int, total)
+	after := &pagination.Cursor{}
+	var pages int
+	for {
+		txs, hasMore, err := repo.ListByAddress(ctx, addr, true, false, &pagination.Query{Limit: 1000, After: after})
+		require.NoError(t, err)
+		pages++
+		for i := range txs {
+			seen[txs[i].ID]++
+		}
+		if !hasMore || len(txs) == 0 {
+			break
+		}
+		after = &pagination.Cursor{Time: txs[0].BlockTimestamp, ID: txs[0].ID}
+	}
+
+	assert.Equal(t, 3, pages)
+	require.Len(t, seen, total)
+	for id, n := range seen {
+		assert.Equal(t, 1, n, "transaction %d", id)
+	}
+}
+
+func TestCreate_MergesExistingRow(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTransactionRepository(db)
+
+	const trader = "0x1111111111111111111111111111111111111111"
+	const pool = "0x2222222222222222222222222222222222222222"
+	const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	usd := "10.00"
+
+	// 首次推送时解码失败，按普通代币转账入库
+	leg := models.Transaction{TxHash: "0x01", BlockTimestamp: at, FromAddress: pool, ToAddress: trader,
+		Value: "10000000000000000000", TxType: "ERC20", TokenAddress: usdc, TokenSymbol: "USDC", TokenDecimals: 6,
+		USDValue: &usd, Status: models.TxStatusSuccess}
+	first := leg
+	require.NoError(t, repo.Create(&first))
+
+	// 再次推送时识别出兑换，并被标记为垃圾交易
+	swap := &models.Swap{Protocol: "uniswap_v2", Trader: trader, Recipient: trader,
+		TokenIn:  models.SwapAsset{Symbol: "ETH", Amount: "5000000000000000", Decimals: 18},
+		TokenOut: models.SwapAsset{Address: usdc, Symbol: "USDC", Amount: "10000000", Decimals: 6}}
+	swapped := leg
+	swapped.TxType, swapped.Swap, swapped.FromAddress, swapped.USDValue = "SWAP", swap, trader, nil
+	swapped.SpamReason = models.SpamReasonLookalike
+	swapped.Status = ""
+	require.NoError(t, repo.Create(&swapped))
+	assert.Equal(t, first.ID, swapped.ID)
+	assert.Equal(t, "SWAP", swapped.TxType)
+	require.NotNil(t, swapped.Swap)
+	assert.Equal(t, models.SpamReasonLookalike, swapped.SpamReason)
+	assert.Equal(t, models.TxStatusSuccess, swapped.Status)
+
+	var row struct {
+		TxType      string  `db:"tx_type"`
+		FromAddress string  `db:"from_address"`
+		USDValue    *string `db:"usd_value"`
+		SpamReason  string  `db:"spam_reason"`
+		Swap        *string `db:"swap"`
+	}
+	get := func() {
+		require.NoError(t, db.Get(&row, `SELECT tx_type, from_address, usd_value, spam_reason, swap FROM transactions WHERE tx_hash = '0x01'`))
+	}
+	get()
+	assert.Equal(t, "SWAP", row.TxType)
+	assert.Equal(t, trader, row.FromAddress)
+	assert.Nil(t, row.USDValue, "swap is priced again from token_out")
+	assert.Equal(t, models.SpamReasonLookalike, row.SpamReason)
+	require.NotNil(t, row.Swap)
+
+	// 之后的推送缺少兑换与分类信息时不覆盖已有结果，内存中的交易与数据库一致
+	again := leg
+	require.NoError(t, repo.Create(&again))
+	assert.Equal(t, "SWAP", again.TxType)
+	assert.Equal(t, trader, again.FromAddress, "direction, amount and token come from the stored swap")
+	assert.Equal(t, trader, again.ToAddress)
+	assert.Equal(t, usdc, again.TokenAddress)
+	require.NotNil(t, again.Swap)
+	assert.Equal(t, "uniswap_v2", again.Swap.Protocol)
+	assert.Equal(t, models.SpamReasonLookalike, again.SpamReason)
+	get()
+	assert.Equal(t, "SWAP", row.TxType)
+	assert.Equal(t, trader, row.FromAddress)
+	assert.Equal(t, models.SpamReasonLookalike, row.SpamReason)
+	require.NotNil(t, row.Swap)
+}
+
+func TestCreate_KeepsEachTransferLog(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTransactionRepository(db)
+
+	const trader = "0x1111111111111111111111111111111111111111"
+	const pool = "0x2222222222222222222222222222222222222222"
+	const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	const weth = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// 同一交易的两条 Transfer 日志（及一条授权）分行保存，各行保留各自的转账方向
+	in := models.Transaction{TxHash: "0x01", LogIndex: 3, BlockTimestamp: at, FromAddress: pool, ToAddress: trader,
+		Value: "10000000000000000000", TxType: "ERC20", TokenAddress: usdc}
+	out := models.Transaction{TxHash: "0x01", LogIndex: 7, BlockTimestamp: at, FromAddress: trader, ToAddress: pool,
+		Value: "5000000000000000", TxType: "ERC20", TokenAddress: weth}
+	approval := models.Transaction{TxHash: "0x01", LogIndex: models.TxLogIndex, BlockTimestamp: at, FromAddress: trader,
+		ToAddress: pool, Value: "0", TxType: "APPROVAL", TokenAddress: weth, Approval: &models.Approval{Spender: pool}}
+	for _, tx := range []*models.Transaction{&in, &out, &approval} {
+		require.NoError(t, repo.Create(tx))
+	}
+	assert.NotEqual(t, in.ID, out.ID)
+
+	type row struct {
+		ID          int64  `db:"id"`
+		LogIndex    int    `db:"log_index"`
+		FromAddress string `db:"from_address"`
+		TxType      string `db:"tx_type"`
+	}
+	list := func() []row {
+		var rows []row
+		require.NoError(t, db.Select(&rows, `SELECT id, log_index, from_address, tx_type FROM transactions ORDER BY log_index`))
+		return rows
+	}
+	assert.Equal(t, []row{{approval.ID, -1, trader, "APPROVAL"}, {in.ID, 3, pool, "ERC20"}, {out.ID, 7, trader, "ERC20"}}, list())
+
+	// 重复推送的日志合并到原有的行
+	again := out
+	require.NoError(t, repo.Create(&again))
+	assert.Equal(t, out.ID, again.ID)
+
+	// 识别出兑换后沿用最小的日志序号合并，其余 leg 被删除，授权保留
+	swapped := in
+	swapped.TxType, swapped.FromAddress = "SWAP", trader
+	swapped.Swap = &models.Swap{Trader: trader, Recipient: trader,
+		TokenIn:  models.SwapAsset{Address: weth, Amount: "5000000000000000", Decimals: 18},
+		TokenOut: models.SwapAsset{Address: usdc, Amount: "10000000", Decimals: 6}}
+	require.NoError(t, repo.Create(&swapped))
+	assert.Equal(t, in.ID, swapped.ID)
+	assert.Equal(t, []row{{approval.ID, -1, trader, "APPROVAL"}, {in.ID, 3, trader, "SWAP"}}, list())
+
+	// 之后单独推送的 leg 合并到 SWAP，不再另存一行；回填的是兑换的整行
+	late := out
+	late.Token = &models.Token{Address: weth}
+	require.NoError(t, repo.Create(&late))
+	assert.Equal(t, in.ID, late.ID)
+	assert.Equal(t, 3, late.LogIndex)
+	assert.Equal(t, "SWAP", late.TxType)
+	assert.Equal(t, trader, late.ToAddress)
+	assert.Equal(t, "10000000000000000000", late.Value)
+	assert.Equal(t, usdc, late.TokenAddress)
+	assert.Nil(t, late.Token, "token metadata of the leg no longer applies")
+	assert.Equal(t, []row{{approval.ID, -1, trader, "APPROVAL"}, {in.ID, 3, trader, "SWAP"}}, list())
+}
+
+func TestCreate_MergesApproval(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTransactionRepository(db)
+
+	const owner = "0x1111111111111111111111111111111111111111"
+	const spender = "0x2222222222222222222222222222222222222222"
+	const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	approval := models.Transaction{TxHash: "0x07", LogIndex: models.TxLogIndex, BlockTimestamp: at, FromAddress: owner, ToAddress: spender,
+		Value: "0", TxType: "APPROVAL", TokenAddress: usdc, TokenSymbol: "USDC", TokenDecimals: 6,
+		Approval: &models.Approval{Standard: models.ApprovalStandardERC20, Spender: spender, Unlimited: true}}
+	_, err := db.Exec(`INSERT INTO transactions (id, tx_hash, block_timestamp, from_address, to_address, value, tx_type, token_address, approval)
+		VALUES (7, '0x07', $1, $2, $3, '0', 'APPROVAL', $4, $5)`, at, owner, spender, usdc, approval.Approval)
+	require.NoError(t, err)
+
+	approvalOf := func(id int64) *models.Approval {
+		var a models.Approval
+		require.NoError(t, db.Get(&a, `SELECT approval FROM transactions WHERE id = $1`, id))
+		return &a
+	}
+
+	// 再次推送时带有更新后的风险标记
+	risky := approval
+	risky.Approval = &models.Approval{Standard: models.ApprovalStandardERC20, Spender: spender, Unlimited: true, Risky: true}
+	require.NoError(t, repo.Create(&risky))
+	assert.Equal(t, int64(7), risky.ID)
+	require.NotNil(t, risky.Approval)
+	assert.True(t, risky.Approval.Risky)
+	assert.True(t, approvalOf(7).Risky)
+
+	// 不带授权明细的推送不清空已有的授权，内存中的交易与数据库一致
+	again := approval
+	again.Approval = nil
+	require.NoError(t, repo.Create(&again))
+	require.NotNil(t, again.Approval)
+	assert.True(t, again.Approval.Risky)
+	assert.True(t, approvalOf(7).Unlimited)
+
+	// 同一哈希已有转账时不改写为授权
+	insertTx(t, db, 8, "0x08", owner, usdc, at)
+	call := approval
+	call.TxHash = "0x08"
+	require.NoError(t, repo.Create(&call))
+	assert.Equal(t, int64(8), call.ID)
+	assert.Equal(t, "ETH", call.TxType)
+	assert.Nil(t, call.Approval)
+	var stored *string
+	require.NoError(t, db.Get(&stored, `SELECT approval FROM transactions WHERE id = 8`))
+	assert.Nil(t, stored)
+}
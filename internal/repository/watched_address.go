@@ -2,12 +2,23 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/bwmspring/chainfeed-go/internal/models"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+const watchedAddressColumns = `id, user_id, team_id, kind, address, min_amount, label, ens_name, notes, tags,
+	created_at, updated_at`
+
+const watchedAddressColumnsWA = `wa.id, wa.user_id, wa.team_id, wa.kind, wa.address, wa.min_amount, wa.label,
+	wa.ens_name, wa.notes, wa.tags, wa.created_at, wa.updated_at`
+
 type WatchedAddressRepository struct {
 	db *sqlx.DB
 }
@@ -16,51 +27,192 @@ func NewWatchedAddressRepository(db *sqlx.DB) *WatchedAddressRepository {
 	return &WatchedAddressRepository{db: db}
 }
 
+// AddressWatcher 监控记录及其应收到 feed 的用户
+// 个人地址的 WatcherID 即 UserID，团队地址会为每个成员展开一行
+type AddressWatcher struct {
+	models.WatchedAddress
+	WatcherID int64 `db:"watcher_id"`
+}
+
+// GetByUserID 获取用户的个人监控地址（不含团队地址）
 func (r *WatchedAddressRepository) GetByUserID(ctx context.Context, userID int64) ([]models.WatchedAddress, error) {
+	return r.GetByUserIDAndTags(ctx, userID, nil)
+}
+
+// GetByUserIDAndTags 获取用户的个人监控地址，tags 非空时只返回包含全部 tags 的地址
+func (r *WatchedAddressRepository) GetByUserIDAndTags(ctx context.Context, userID int64, tags []string) ([]models.WatchedAddress, error) {
 	var addresses []models.WatchedAddress
 	query := `
-		SELECT id, user_id, address, label, ens_name, created_at
+		SELECT ` + watchedAddressColumns + `
 		FROM watched_addresses
-		WHERE user_id = $1
+		WHERE user_id = $1 AND team_id IS NULL
+		  AND (cardinality($2::TEXT[]) = 0 OR tags @> $2::TEXT[])
 		ORDER BY created_at DESC
 	`
-	err := r.db.SelectContext(ctx, &addresses, query, userID)
+	if tags == nil {
+		tags = []string{}
+	}
+	err := r.db.SelectContext(ctx, &addresses, query, userID, pq.Array(tags))
+	return addresses, err
+}
+
+// GetByTeamID 获取团队的监控地址
+func (r *WatchedAddressRepository) GetByTeamID(ctx context.Context, teamID int64) ([]models.WatchedAddress, error) {
+	var addresses []models.WatchedAddress
+	query := `
+		SELECT ` + watchedAddressColumns + `
+		FROM watched_addresses
+		WHERE team_id = $1
+		ORDER BY created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &addresses, query, teamID)
 	return addresses, err
 }
 
 func (r *WatchedAddressRepository) Create(ctx context.Context, addr *models.WatchedAddress) error {
 	query := `
-		INSERT INTO watched_addresses (user_id, address, label, ens_name, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
-		RETURNING id, created_at
+		INSERT INTO watched_addresses (user_id, team_id, kind, address, min_amount, label, ens_name, notes, tags,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, '{}'::TEXT[]), NOW(), NOW())
+		RETURNING id, created_at, updated_at
 	`
-	return r.db.QueryRowContext(ctx, query, addr.UserID, addr.Address, addr.Label, addr.ENSName).
-		Scan(&addr.ID, &addr.CreatedAt)
+	if addr.Kind == "" {
+		addr.Kind = models.WatchKindWallet
+	}
+	return r.db.QueryRowContext(ctx, query,
+		addr.UserID, addr.TeamID, addr.Kind, addr.Address, addr.MinAmount, addr.Label, addr.ENSName, addr.Notes, addr.Tags).
+		Scan(&addr.ID, &addr.CreatedAt, &addr.UpdatedAt)
 }
 
-func (r *WatchedAddressRepository) Delete(ctx context.Context, id, userID int64) error {
-	query := `DELETE FROM watched_addresses WHERE id = $1 AND user_id = $2`
-	result, err := r.db.ExecContext(ctx, query, id, userID)
+// WatchedAddressUpdate 可编辑字段，nil 表示不修改
+type WatchedAddressUpdate struct {
+	Label     *string
+	Notes     *string
+	Tags      []string
+	MinAmount *string // 仅 token 监控，空字符串表示清除
+}
+
+// GetByID 按 ID 获取监控地址
+func (r *WatchedAddressRepository) GetByID(ctx context.Context, id int64) (*models.WatchedAddress, error) {
+	var addr models.WatchedAddress
+	query := `
+		SELECT ` + watchedAddressColumns + `
+		FROM watched_addresses
+		WHERE id = $1
+	`
+	err := r.db.GetContext(ctx, &addr, query, id)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// Update 更新标签、备注与 tags，返回更新后的记录
+func (r *WatchedAddressRepository) Update(ctx context.Context, id int64, update WatchedAddressUpdate) (*models.WatchedAddress, error) {
+	var tags interface{}
+	if update.Tags != nil {
+		tags = pq.Array(update.Tags)
 	}
 
-	rows, err := result.RowsAffected()
+	var addr models.WatchedAddress
+	query := `
+		UPDATE watched_addresses SET
+			label = COALESCE($2, label),
+			notes = COALESCE($3, notes),
+			tags = COALESCE($4, tags),
+			min_amount = CASE WHEN $5::BOOLEAN THEN NULLIF($6, '')::NUMERIC ELSE min_amount END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + watchedAddressColumns + `
+	`
+	var minAmount string
+	if update.MinAmount != nil {
+		minAmount = *update.MinAmount
+	}
+	err := r.db.GetContext(ctx, &addr, query, id, update.Label, update.Notes, tags,
+		update.MinAmount != nil, minAmount)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
 	}
+	return &addr, nil
+}
+
+// Delete 删除个人监控地址；feed 条目只在不再命中任何监控地址时删除（见 deleteWatchedAddress）
+func (r *WatchedAddressRepository) Delete(ctx context.Context, id, userID int64) error {
+	return r.deleteWatchedAddress(ctx, `user_id = $2 AND team_id IS NULL`, id, userID)
+}
 
-	if rows == 0 {
-		return nil // 或返回自定义错误
+// DeleteFromTeam 删除团队监控地址
+func (r *WatchedAddressRepository) DeleteFromTeam(ctx context.Context, id, teamID int64) error {
+	return r.deleteWatchedAddress(ctx, `team_id = $2`, id, teamID)
+}
+
+// repointFeedItemsQuery 将以 $1 为主地址、但仍命中其他监控地址的 feed 条目改指向其余命中地址中 id 最小的一个
+const repointFeedItemsQuery = `
+	UPDATE feed_items
+	SET watched_address_id = (
+		SELECT MIN(fia.watched_address_id) FROM feed_item_addresses fia
+		WHERE fia.feed_item_id = feed_items.id AND fia.watched_address_id <> $1
+	)
+	WHERE watched_address_id = $1
+	  AND EXISTS (
+		SELECT 1 FROM feed_item_addresses fia
+		WHERE fia.feed_item_id = feed_items.id AND fia.watched_address_id <> $1
+	  )`
+
+// deleteWatchedAddress 删除 id 为 $1 且满足 cond 的监控地址，不存在时返回 ErrNotFound。
+// feed_items.watched_address_id 为级联删除：删除前先将仍命中其他地址的条目改指向其余地址，
+// 级联删除的只剩不再命中任何监控地址的条目
+func (r *WatchedAddressRepository) deleteWatchedAddress(ctx context.Context, cond string, id, ownerID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM watched_addresses WHERE id = $1 AND ` + cond + `)`
+	if err := tx.GetContext(ctx, &exists, query, id, ownerID); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, repointFeedItemsQuery, id); err != nil {
+		return fmt.Errorf("failed to repoint feed items: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM watched_addresses WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// Exists 检查用户是否已个人监控该钱包地址
 func (r *WatchedAddressRepository) Exists(ctx context.Context, userID int64, address string) (bool, error) {
+	return r.ExistsOfKind(ctx, userID, models.WatchKindWallet, address)
+}
+
+func (r *WatchedAddressRepository) ExistsOfKind(ctx context.Context, userID int64, kind, address string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS(SELECT 1 FROM watched_addresses
+		WHERE user_id = $1 AND kind = $2 AND address = $3 AND team_id IS NULL)
+	`
+	err := r.db.GetContext(ctx, &exists, query, userID, kind, address)
+	return exists, err
+}
+
+func (r *WatchedAddressRepository) ExistsInTeam(ctx context.Context, teamID int64, kind, address string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM watched_addresses WHERE user_id = $1 AND address = $2)`
-	err := r.db.GetContext(ctx, &exists, query, userID, address)
+	query := `SELECT EXISTS(SELECT 1 FROM watched_addresses WHERE team_id = $1 AND kind = $2 AND address = $3)`
+	err := r.db.GetContext(ctx, &exists, query, teamID, kind, address)
 	return exists, err
 }
 
@@ -73,7 +225,7 @@ func (r *WatchedAddressRepository) UpdateENS(ctx context.Context, id int64, ensN
 func (r *WatchedAddressRepository) FindByAddress(address string) ([]models.WatchedAddress, error) {
 	var addresses []models.WatchedAddress
 	query := `
-		SELECT id, user_id, address, label, ens_name, created_at
+		SELECT ` + watchedAddressColumns + `
 		FROM watched_addresses
 		WHERE LOWER(address) = LOWER($1)
 	`
@@ -81,9 +233,90 @@ func (r *WatchedAddressRepository) FindByAddress(address string) ([]models.Watch
 	return addresses, err
 }
 
+// FindWatchers 一次查询出监控任一地址的所有用户，团队地址按成员展开
+func (r *WatchedAddressRepository) FindWatchers(addresses []string) ([]AddressWatcher, error) {
+	lowered := make([]string, len(addresses))
+	for i, addr := range addresses {
+		lowered[i] = strings.ToLower(addr)
+	}
+
+	var watchers []AddressWatcher
+	query := `
+		SELECT ` + watchedAddressColumnsWA + `,
+			COALESCE(tm.user_id, wa.user_id) AS watcher_id
+		FROM watched_addresses wa
+		LEFT JOIN team_members tm ON wa.team_id IS NOT NULL AND tm.team_id = wa.team_id
+		WHERE wa.kind = 'wallet' AND LOWER(wa.address) = ANY($1)
+	`
+	err := r.db.Select(&watchers, query, pq.Array(lowered))
+	return watchers, err
+}
+
+// ListWalletAddresses 返回所有被监控的钱包地址（小写、去重）
+func (r *WatchedAddressRepository) ListWalletAddresses(ctx context.Context) ([]string, error) {
+	var addresses []string
+	query := `SELECT DISTINCT LOWER(address) FROM watched_addresses WHERE kind = 'wallet'`
+	err := r.db.SelectContext(ctx, &addresses, query)
+	return addresses, err
+}
+
+// FindTokenWatchers 查询监控某个代币合约的所有用户，团队地址按成员展开
+func (r *WatchedAddressRepository) FindTokenWatchers(tokenAddress string) ([]AddressWatcher, error) {
+	var watchers []AddressWatcher
+	query := `
+		SELECT ` + watchedAddressColumnsWA + `,
+			COALESCE(tm.user_id, wa.user_id) AS watcher_id
+		FROM watched_addresses wa
+		LEFT JOIN team_members tm ON wa.team_id IS NOT NULL AND tm.team_id = wa.team_id
+		WHERE wa.kind = 'token' AND LOWER(wa.address) = LOWER($1)
+	`
+	err := r.db.Select(&watchers, query, tokenAddress)
+	return watchers, err
+}
+
+// GetAccessibleByAddress 查找用户可访问的监控记录：优先个人地址，其次所在团队的地址
+func (r *WatchedAddressRepository) GetAccessibleByAddress(ctx context.Context, userID int64, address string) (*models.WatchedAddress, error) {
+	var addr models.WatchedAddress
+	query := `
+		SELECT ` + watchedAddressColumnsWA + `
+		FROM watched_addresses wa
+		LEFT JOIN team_members tm ON tm.team_id = wa.team_id AND tm.user_id = $1
+		WHERE wa.kind = 'wallet' AND LOWER(wa.address) = LOWER($2)
+		  AND ((wa.team_id IS NULL AND wa.user_id = $1) OR tm.user_id IS NOT NULL)
+		ORDER BY wa.team_id NULLS FIRST
+		LIMIT 1
+	`
+	err := r.db.GetContext(ctx, &addr, query, userID, address)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// ListAccessibleIDsByAddress 获取用户可访问的（个人及所在团队的）该地址的全部监控记录 ID
+func (r *WatchedAddressRepository) ListAccessibleIDsByAddress(ctx context.Context, userID int64, address string) ([]int64, error) {
+	var ids []int64
+	query := `
+		SELECT wa.id
+		FROM watched_addresses wa
+		LEFT JOIN team_members tm ON tm.team_id = wa.team_id AND tm.user_id = $1
+		WHERE LOWER(wa.address) = LOWER($2)
+		  AND ((wa.team_id IS NULL AND wa.user_id = $1) OR tm.user_id IS NOT NULL)
+		ORDER BY wa.id
+	`
+	err := r.db.SelectContext(ctx, &ids, query, userID, address)
+	return ids, err
+}
+
 func (r *WatchedAddressRepository) GetByUserAndAddress(ctx context.Context, userID int64, address string) (*models.WatchedAddress, error) {
 	var addr models.WatchedAddress
-	query := `SELECT * FROM watched_addresses WHERE user_id = $1 AND LOWER(address) = LOWER($2)`
+	query := `
+		SELECT * FROM watched_addresses
+		WHERE user_id = $1 AND LOWER(address) = LOWER($2) AND team_id IS NULL AND kind = 'wallet'
+	`
 	err := r.db.GetContext(ctx, &addr, query, userID, address)
 	if err != nil {
 		return nil, err
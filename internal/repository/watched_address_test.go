@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFeedAddressDB 创建监控地址、feed 条目及其命中地址表，外键与 PostgreSQL 一样级联删除
+func newFeedAddressDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Connect("sqlite3", ":memory:?_foreign_keys=on")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE watched_addresses (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			team_id INTEGER,
+			address TEXT NOT NULL
+		);
+		CREATE TABLE feed_items (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			transaction_id INTEGER NOT NULL,
+			watched_address_id INTEGER NOT NULL REFERENCES watched_addresses(id) ON DELETE CASCADE
+		);
+		CREATE TABLE feed_item_addresses (
+			feed_item_id INTEGER NOT NULL REFERENCES feed_items(id) ON DELETE CASCADE,
+			watched_address_id INTEGER NOT NULL REFERENCES watched_addresses(id) ON DELETE CASCADE,
+			PRIMARY KEY (feed_item_id, watched_address_id)
+		);
+		CREATE TABLE team_members (
+			team_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			PRIMARY KEY (team_id, user_id)
+		);
+	`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestDelete_KeepsFeedItemsWithOtherAddresses(t *testing.T) {
+	db := newFeedAddressDB(t)
+	repo := NewWatchedAddressRepository(db)
+	ctx := context.Background()
+
+	// 条目 1 同时命中地址 1、2（主地址为 1），条目 2 只命中地址 1
+	_, err := db.Exec(`
+		INSERT INTO watched_addresses (id, user_id, address) VALUES
+			(1, 1, '0x1111111111111111111111111111111111111111'),
+			(2, 1, '0x2222222222222222222222222222222222222222');
+		INSERT INTO feed_items (id, user_id, transaction_id, watched_address_id) VALUES (1, 1, 1, 1), (2, 1, 2, 1);
+		INSERT INTO feed_item_addresses (feed_item_id, watched_address_id) VALUES (1, 1), (1, 2), (2, 1);
+	`)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Delete(ctx, 1, 2), ErrNotFound, "other user's address")
+	assert.ErrorIs(t, repo.Delete(ctx, 99, 1), ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteFromTeam(ctx, 1, 1), ErrNotFound, "personal address is not a team address")
+
+	require.NoError(t, repo.Delete(ctx, 1, 1))
+	var primary []int64
+	require.NoError(t, db.Select(&primary, `SELECT watched_address_id FROM feed_items ORDER BY id`))
+	assert.Equal(t, []int64{2}, primary, "item 1 moves to the remaining address, item 2 has none left")
+	var matched []int64
+	require.NoError(t, db.Select(&matched, `SELECT watched_address_id FROM feed_item_addresses WHERE feed_item_id = 1`))
+	assert.Equal(t, []int64{2}, matched)
+
+	require.NoError(t, repo.Delete(ctx, 2, 1))
+	var items int
+	require.NoError(t, db.Get(&items, `SELECT COUNT(*) FROM feed_items`))
+	assert.Zero(t, items)
+}
+
+func TestRemoveMember_KeepsFeedItemsWithOtherAddresses(t *testing.T) {
+	db := newFeedAddressDB(t)
+	ctx := context.Background()
+
+	// 地址 1 为团队地址，地址 2 为成员的个人地址；条目 1 两者都命中，条目 2 只命中团队地址
+	_, err := db.Exec(`
+		INSERT INTO watched_addresses (id, user_id, team_id, address) VALUES
+			(1, 1, 7, '0x1111111111111111111111111111111111111111'),
+			(2, 2, NULL, '0x2222222222222222222222222222222222222222');
+		INSERT INTO team_members (team_id, user_id, role) VALUES (7, 1, 'owner'), (7, 2, 'viewer');
+		INSERT INTO feed_items (id, user_id, transaction_id, watched_address_id) VALUES (1, 2, 1, 1), (2, 2, 2, 1), (3, 1, 1, 1);
+		INSERT INTO feed_item_addresses (feed_item_id, watched_address_id) VALUES (1, 1), (1, 2), (2, 1), (3, 1);
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, NewTeamRepository(db).RemoveMember(ctx, 7, 2))
+
+	var rows []struct {
+		ID               int64 `db:"id"`
+		WatchedAddressID int64 `db:"watched_address_id"`
+	}
+	require.NoError(t, db.Select(&rows, `SELECT id, watched_address_id FROM feed_items ORDER BY id`))
+	require.Len(t, rows, 2)
+	assert.Equal(t, int64(1), rows[0].ID)
+	assert.Equal(t, int64(2), rows[0].WatchedAddressID, "kept with the member's own address")
+	assert.Equal(t, int64(3), rows[1].ID, "other members' items are untouched")
+	var matched int
+	require.NoError(t, db.Get(&matched, `SELECT COUNT(*) FROM feed_item_addresses WHERE feed_item_id = 1`))
+	assert.Equal(t, 1, matched)
+}
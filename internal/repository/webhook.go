@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const webhookEndpointColumns = `id, user_id, url, secret, description, event_types, enabled, consecutive_failures,
+	disabled_reason, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_type, payload, status, attempts, next_attempt_at,
+	last_attempt_at, last_status_code, last_error, last_duration_ms, delivered_at, created_at`
+
+// deliveryLease 投递被领取后的租约，worker 异常退出时到期后可被重新领取
+const deliveryLease = 5 * time.Minute
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// WebhookEndpointUpdate 可编辑字段，nil 表示不修改
+type WebhookEndpointUpdate struct {
+	URL         *string
+	Description *string
+	EventTypes  []string
+	Enabled     *bool
+}
+
+// DeliveryJob 待投递的记录及其端点信息
+type DeliveryJob struct {
+	models.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// DeliveryAttempt 一次投递尝试的结果
+type DeliveryAttempt struct {
+	DeliveryID    int64
+	EndpointID    int64
+	Success       bool
+	StatusCode    int // 0 表示未收到响应
+	Error         string
+	Duration      time.Duration
+	NextAttemptAt *time.Time // 失败且可重试时的下次时间，nil 表示不再重试
+}
+
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, userID int64) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	query := `
+		SELECT ` + webhookEndpointColumns + `
+		FROM webhook_endpoints
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	err := r.db.SelectContext(ctx, &endpoints, query, userID)
+	return endpoints, err
+}
+
+func (r *WebhookRepository) CountEndpoints(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM webhook_endpoints WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// GetEndpoint 获取用户的端点，不存在时返回 nil
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id, userID int64) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1 AND user_id = $2`
+	err := r.db.GetContext(ctx, &endpoint, query, id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (user_id, url, secret, description, event_types, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5, '{}'::TEXT[]), TRUE, NOW(), NOW())
+		RETURNING id, enabled, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		endpoint.UserID, endpoint.URL, endpoint.Secret, endpoint.Description, endpoint.EventTypes).
+		Scan(&endpoint.ID, &endpoint.Enabled, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// UpdateEndpoint 更新端点；重新启用时清空失败计数
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, id, userID int64, update WebhookEndpointUpdate) (*models.WebhookEndpoint, error) {
+	var eventTypes interface{}
+	if update.EventTypes != nil {
+		eventTypes = pq.Array(update.EventTypes)
+	}
+
+	var endpoint models.WebhookEndpoint
+	query := `
+		UPDATE webhook_endpoints SET
+			url = COALESCE($3, url),
+			description = COALESCE($4, description),
+			event_types = COALESCE($5, event_types),
+			enabled = COALESCE($6, enabled),
+			consecutive_failures = CASE WHEN $6 THEN 0 ELSE consecutive_failures END,
+			disabled_reason = CASE WHEN $6 THEN '' ELSE disabled_reason END,
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + webhookEndpointColumns
+	err := r.db.GetContext(ctx, &endpoint, query, id, userID, update.URL, update.Description, eventTypes, update.Enabled)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *WebhookRepository) RotateSecret(ctx context.Context, id, userID int64, secret string) error {
+	query := `UPDATE webhook_endpoints SET secret = $3, updated_at = NOW() WHERE id = $1 AND user_id = $2`
+	result, err := r.db.ExecContext(ctx, query, id, userID, secret)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id, userID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// EnqueueForUser 为用户所有订阅该事件的已启用端点创建待投递记录，返回创建数量
+func (r *WebhookRepository) EnqueueForUser(ctx context.Context, userID int64, eventType string, payload json.RawMessage) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_type, payload)
+		SELECT id, $2, $3::JSONB
+		FROM webhook_endpoints
+		WHERE user_id = $1 AND enabled
+		  AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+	`
+	result, err := r.db.ExecContext(ctx, query, userID, eventType, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ClaimDueDeliveries 领取到期的待投递记录，领取后在租约期内不会被其他 worker 重复领取
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int) ([]DeliveryJob, error) {
+	query := `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND e.enabled
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries d
+			SET next_attempt_at = NOW() + $2::INTERVAL
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.*
+		)
+		SELECT c.id, c.endpoint_id, c.event_type, c.payload, c.status, c.attempts, c.next_attempt_at,
+			c.last_attempt_at, c.last_status_code, c.last_error, c.last_duration_ms, c.delivered_at, c.created_at,
+			e.url, e.secret
+		FROM claimed c
+		JOIN webhook_endpoints e ON e.id = c.endpoint_id
+	`
+	var jobs []DeliveryJob
+	err := r.db.SelectContext(ctx, &jobs, query, limit, fmt.Sprintf("%d seconds", int(deliveryLease.Seconds())))
+	return jobs, err
+}
+
+// RecordDeliveryAttempt 记录投递结果并更新端点失败计数，连续失败达到 disableAfter 时停用端点
+// 返回端点是否因此被停用
+func (r *WebhookRepository) RecordDeliveryAttempt(ctx context.Context, attempt DeliveryAttempt, disableAfter int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status := models.DeliveryStatusPending
+	switch {
+	case attempt.Success:
+		status = models.DeliveryStatusSucceeded
+	case attempt.NextAttemptAt == nil:
+		status = models.DeliveryStatusFailed
+	}
+
+	var statusCode *int
+	if attempt.StatusCode != 0 {
+		statusCode = &attempt.StatusCode
+	}
+
+	deliveryQuery := `
+		UPDATE webhook_deliveries SET
+			status = $2,
+			attempts = attempts + 1,
+			next_attempt_at = COALESCE($3, next_attempt_at),
+			last_attempt_at = NOW(),
+			last_status_code = $4,
+			last_error = $5,
+			last_duration_ms = $6,
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, deliveryQuery, attempt.DeliveryID, status, attempt.NextAttemptAt,
+		statusCode, attempt.Error, attempt.Duration.Milliseconds()); err != nil {
+		return false, fmt.Errorf("failed to update delivery: %w", err)
+	}
+
+	if attempt.Success {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE webhook_endpoints SET consecutive_failures = 0 WHERE id = $1`, attempt.EndpointID); err != nil {
+			return false, fmt.Errorf("failed to reset endpoint failures: %w", err)
+		}
+		return false, tx.Commit()
+	}
+
+	var disabled bool
+	endpointQuery := `
+		UPDATE webhook_endpoints SET
+			consecutive_failures = consecutive_failures + 1,
+			enabled = enabled AND consecutive_failures + 1 < $2,
+			disabled_reason = CASE WHEN enabled AND consecutive_failures + 1 >= $2
+				THEN 'too many consecutive failures' ELSE disabled_reason END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING NOT enabled AND consecutive_failures = $2
+	`
+	if err := tx.QueryRowContext(ctx, endpointQuery, attempt.EndpointID, disableAfter).Scan(&disabled); err != nil {
+		return false, fmt.Errorf("failed to update endpoint failures: %w", err)
+	}
+
+	return disabled, tx.Commit()
+}
+
+// ListDeliveries 获取端点的投递记录
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID int64, limit, offset int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE endpoint_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+	err := r.db.SelectContext(ctx, &deliveries, query, endpointID, limit, offset)
+	return deliveries, err
+}
+
+// Redeliver 复制一条投递记录为新的待投递记录
+func (r *WebhookRepository) Redeliver(ctx context.Context, deliveryID, endpointID int64) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_type, payload)
+		SELECT endpoint_id, event_type, payload
+		FROM webhook_deliveries
+		WHERE id = $1 AND endpoint_id = $2
+		RETURNING ` + webhookDeliveryColumns
+	err := r.db.GetContext(ctx, &delivery, query, deliveryID, endpointID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func requireAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
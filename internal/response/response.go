@@ -22,6 +22,15 @@ func Success(c *gin.Context, data interface{}) {
 	})
 }
 
+// Accepted 请求已受理、将在后台完成时的响应
+func Accepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Code:    0,
+		Message: "accepted",
+		Data:    data,
+	})
+}
+
 // SuccessWithMessage 成功响应（自定义消息）
 func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusOK, Response{
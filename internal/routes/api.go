@@ -3,6 +3,7 @@ package routes
 import (
 	"net/http"
 
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
@@ -12,10 +13,15 @@ import (
 
 	"github.com/bwmspring/chainfeed-go/internal/auth"
 	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/decoder"
 	"github.com/bwmspring/chainfeed-go/internal/handler"
+	"github.com/bwmspring/chainfeed-go/internal/ingest"
 	"github.com/bwmspring/chainfeed-go/internal/middleware"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/pricing"
 	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/service"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
 	"github.com/bwmspring/chainfeed-go/internal/websocket"
 )
 
@@ -26,17 +32,36 @@ type APIRoutes struct {
 	authHandler           *handler.AuthHandler
 	watchedAddressHandler *handler.WatchedAddressHandler
 	feedHandler           *handler.FeedHandler
+	exportHandler         *handler.ExportHandler
+	syndicationHandler    *handler.SyndicationHandler
+	tokenHandler          *handler.TokenHandler
+	contractABIHandler    *handler.ContractABIHandler
 	transactionHandler    *handler.TransactionHandler
+	allowanceHandler      *handler.AllowanceHandler
+	profileHandler        *handler.AddressProfileHandler
+	teamHandler           *handler.TeamHandler
+	alertHandler          *handler.AlertHandler
+	webhookHandler        *handler.WebhookEndpointHandler
+	channelHandler        *handler.ChannelHandler
+	digestHandler         *handler.DigestHandler
+	preferenceHandler     *handler.PreferenceHandler
 	wsHandler             *handler.WebSocketHandler
 	jwtService            *auth.JWTService
 }
 
-func NewAPIRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client, hub *websocket.Hub) *APIRoutes {
+func NewAPIRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client, hub *websocket.Hub, pipeline *ingest.Pipeline) *APIRoutes {
 	// 初始化 repositories
 	userRepo := repository.NewUserRepository(db)
 	watchedAddrRepo := repository.NewWatchedAddressRepository(db)
 	feedRepo := repository.NewFeedRepository(db)
 	txRepo := repository.NewTransactionRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	channelRepo := repository.NewChannelRepository(db)
+	digestRepo := repository.NewDigestRepository(db)
+	prefRepo := repository.NewPreferenceRepository(db)
+	exportRepo := repository.NewExportRepository(db)
 
 	// 初始化 services
 	web3Svc := auth.NewWeb3Service(cfg.Auth.SignMessage)
@@ -52,6 +77,16 @@ func NewAPIRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *re
 		}
 	}
 
+	// 初始化以太坊客户端（可选，用于判断地址是否为合约）
+	var ethClient *ethclient.Client
+	if cfg.Ethereum.RPCURL != "" {
+		var err error
+		ethClient, err = ethclient.Dial(cfg.Ethereum.RPCURL)
+		if err != nil {
+			logger.Warn("Failed to connect to ethereum", zap.Error(err))
+		}
+	}
+
 	// 初始化 Alchemy service
 	var alchemyService *service.AlchemyService
 	if cfg.Alchemy.APIKey != "" {
@@ -61,12 +96,45 @@ func NewAPIRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *re
 		logger.Warn("Alchemy API key not configured")
 	}
 
+	// 初始化价格服务（可选）
+	pricer, err := pricing.New(cfg.Pricing, repository.NewPriceRepository(db), redis, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize price service, USD values disabled", zap.Error(err))
+	}
+
+	// 初始化代币注册表（可选）
+	tokenRepo := repository.NewTokenRepository(db)
+	registry, err := tokens.New(cfg.Tokens, cfg.Ethereum, tokenRepo, logger)
+	if err != nil {
+		logger.Warn("Failed to initialize token registry", zap.Error(err))
+	}
+
+	// 用户上传的合约 ABI 在读取交易时按用户解码
+	abiRepo := repository.NewContractABIRepository(db)
+	uploadedABIs := decoder.NewUploaded(abiRepo, logger)
+
 	// 初始化 handlers
 	authHandler := handler.NewAuthHandler(userRepo, web3Svc, jwtSvc, logger, cfg.Auth.NonceExpiry)
-	watchedAddressHandler := handler.NewWatchedAddressHandler(watchedAddrRepo, ensService, alchemyService, txRepo, feedRepo, redis, logger)
-	feedHandler := handler.NewFeedHandler(feedRepo)
-	transactionHandler := handler.NewTransactionHandler(txRepo, watchedAddrRepo, logger)
-	wsHandler := handler.NewWebSocketHandler(hub, logger)
+	watchedAddressHandler := handler.NewWatchedAddressHandler(watchedAddrRepo, teamRepo, ensService, alchemyService, txRepo, feedRepo, pricer, redis, logger)
+	feedHandler := handler.NewFeedHandler(feedRepo, service.NewStreamService(redis, hub, logger), uploadedABIs, logger)
+	exportHandler := handler.NewExportHandler(feedRepo, txRepo, exportRepo, watchedAddrRepo, cfg.Export, cfg.Digest.PublicURL, logger)
+	syndicationHandler := handler.NewSyndicationHandler(feedRepo, watchedAddrRepo,
+		notify.ExplorerURL(cfg.Ethereum.Network), cfg.Digest.PublicURL, logger)
+	tokenHandler := handler.NewTokenHandler(tokenRepo, registry, logger)
+	contractABIHandler := handler.NewContractABIHandler(abiRepo, logger)
+	transactionHandler := handler.NewTransactionHandler(txRepo, watchedAddrRepo, uploadedABIs, logger)
+	allowanceHandler := handler.NewAllowanceHandler(repository.NewTokenAllowanceRepository(db), watchedAddrRepo, logger)
+	profileHandler := handler.NewAddressProfileHandler(repository.NewProfileRepository(db), watchedAddrRepo,
+		ensService, alchemyService, ethClient, pipeline, redis, logger)
+	teamHandler := handler.NewTeamHandler(teamRepo, logger)
+	alertHandler := handler.NewAlertHandler(alertRepo, logger)
+	webhookHandler := handler.NewWebhookEndpointHandler(webhookRepo, logger)
+	channelHandler := handler.NewChannelHandler(channelRepo, redis, cfg.Telegram.BotUsername, logger,
+		notify.EnabledChannels(cfg.Telegram.BotToken)...)
+	digestHandler := handler.NewDigestHandler(digestRepo, cfg.SMTP.Host != "",
+		notify.ExplorerURL(cfg.Ethereum.Network), cfg.Digest.PublicURL, logger)
+	preferenceHandler := handler.NewPreferenceHandler(prefRepo, watchedAddrRepo, teamRepo, logger)
+	wsHandler := handler.NewWebSocketHandler(hub, feedRepo, logger)
 
 	return &APIRoutes{
 		cfg:                   cfg,
@@ -75,7 +143,19 @@ func NewAPIRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *re
 		authHandler:           authHandler,
 		watchedAddressHandler: watchedAddressHandler,
 		feedHandler:           feedHandler,
+		exportHandler:         exportHandler,
+		syndicationHandler:    syndicationHandler,
+		tokenHandler:          tokenHandler,
+		contractABIHandler:    contractABIHandler,
 		transactionHandler:    transactionHandler,
+		allowanceHandler:      allowanceHandler,
+		profileHandler:        profileHandler,
+		teamHandler:           teamHandler,
+		alertHandler:          alertHandler,
+		webhookHandler:        webhookHandler,
+		channelHandler:        channelHandler,
+		digestHandler:         digestHandler,
+		preferenceHandler:     preferenceHandler,
 		wsHandler:             wsHandler,
 		jwtService:            jwtSvc,
 	}
@@ -100,6 +180,14 @@ func (r *APIRoutes) RegisterRoutes(router *gin.RouterGroup) {
 			auth.POST("/verify", r.authHandler.VerifySignature)
 		}
 
+		// Digest unsubscribe link (public, token-based)
+		api.GET("/digest/unsubscribe", r.digestHandler.Unsubscribe)
+		api.POST("/digest/unsubscribe", r.digestHandler.Unsubscribe)
+
+		// Atom / RSS feeds (public, token-based)
+		api.GET("/syndication/atom", r.syndicationHandler.Atom)
+		api.GET("/syndication/rss", r.syndicationHandler.RSS)
+
 		// Protected routes
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware(r.jwtService))
@@ -112,14 +200,132 @@ func (r *APIRoutes) RegisterRoutes(router *gin.RouterGroup) {
 			{
 				addresses.GET("", r.watchedAddressHandler.List)
 				addresses.POST("", r.watchedAddressHandler.Add)
+				addresses.POST("/import", r.watchedAddressHandler.Import)
+				addresses.GET("/export", r.watchedAddressHandler.Export)
+				addresses.PATCH("/:id", r.watchedAddressHandler.Update)
 				addresses.DELETE("/:id", r.watchedAddressHandler.Remove)
+				addresses.POST("/:id/mute", r.preferenceHandler.Mute)
+				addresses.DELETE("/:id/mute", r.preferenceHandler.Unmute)
 				addresses.GET("/:address/transactions", r.transactionHandler.GetByAddress)
+				addresses.GET("/:address/transactions/export", r.exportHandler.ExportAddress)
+				addresses.GET("/:address/ledger", r.exportHandler.ExportLedger)
+				addresses.GET("/:address/allowances", r.allowanceHandler.List)
+				addresses.GET("/:address/profile", r.profileHandler.Get)
 			}
 
 			// Feed routes
 			feed := protected.Group("/feed")
 			{
 				feed.GET("", r.feedHandler.GetFeed)
+				feed.GET("/unread-count", r.feedHandler.UnreadCount)
+				feed.POST("/read", r.feedHandler.MarkRead)
+				feed.PATCH("/items/:id", r.feedHandler.UpdateItem)
+				feed.GET("/presets", r.feedHandler.ListPresets)
+				feed.POST("/presets", r.feedHandler.CreatePreset)
+				feed.PATCH("/presets/:id", r.feedHandler.UpdatePreset)
+				feed.DELETE("/presets/:id", r.feedHandler.DeletePreset)
+				feed.GET("/export", r.exportHandler.ExportFeed)
+				feed.GET("/exports", r.exportHandler.ListJobs)
+				feed.POST("/exports", r.exportHandler.CreateJob)
+				feed.GET("/exports/:id", r.exportHandler.GetJob)
+				feed.GET("/exports/:id/download", r.exportHandler.Download)
+				feed.DELETE("/exports/:id", r.exportHandler.DeleteJob)
+				feed.GET("/tokens", r.syndicationHandler.ListTokens)
+				feed.POST("/tokens", r.syndicationHandler.CreateToken)
+				feed.DELETE("/tokens/:id", r.syndicationHandler.DeleteToken)
+				feed.GET("/hidden-tokens", r.feedHandler.ListHiddenTokens)
+				feed.POST("/hidden-tokens", r.feedHandler.HideToken)
+				feed.DELETE("/hidden-tokens/:address", r.feedHandler.UnhideToken)
+			}
+
+			// Token metadata
+			protected.GET("/tokens/:address", r.tokenHandler.Get)
+
+			// Contract ABIs (used to decode contract calls)
+			abis := protected.Group("/abis")
+			{
+				abis.GET("", r.contractABIHandler.List)
+				abis.GET("/:address", r.contractABIHandler.Get)
+				abis.PUT("/:address", r.contractABIHandler.Upload)
+				abis.DELETE("/:address", r.contractABIHandler.Delete)
+			}
+
+			// Teams
+			teams := protected.Group("/teams")
+			{
+				teams.GET("", r.teamHandler.List)
+				teams.POST("", r.teamHandler.Create)
+				teams.GET("/:id", r.teamHandler.Get)
+				teams.PATCH("/:id", r.teamHandler.Update)
+				teams.DELETE("/:id", r.teamHandler.Delete)
+				teams.PATCH("/:id/members/:user_id", r.teamHandler.UpdateMember)
+				teams.DELETE("/:id/members/:user_id", r.teamHandler.RemoveMember)
+				teams.GET("/:id/invites", r.teamHandler.ListInvites)
+				teams.POST("/:id/invites", r.teamHandler.CreateInvite)
+				teams.DELETE("/:id/invites/:invite_id", r.teamHandler.RevokeInvite)
+				teams.GET("/:id/addresses", r.watchedAddressHandler.ListTeam)
+				teams.POST("/:id/addresses", r.watchedAddressHandler.AddTeam)
+				teams.DELETE("/:id/addresses/:address_id", r.watchedAddressHandler.RemoveTeam)
+			}
+
+			// Team invites for the current wallet
+			invites := protected.Group("/invites")
+			{
+				invites.GET("", r.teamHandler.MyInvites)
+				invites.POST("/:invite_id/accept", r.teamHandler.AcceptInvite)
+				invites.POST("/:invite_id/decline", r.teamHandler.DeclineInvite)
+			}
+
+			// Alert rules
+			alerts := protected.Group("/alerts")
+			{
+				alerts.GET("", r.alertHandler.ListAlerts)
+				alerts.GET("/rules", r.alertHandler.ListRules)
+				alerts.POST("/rules", r.alertHandler.CreateRule)
+				alerts.GET("/rules/:id", r.alertHandler.GetRule)
+				alerts.PATCH("/rules/:id", r.alertHandler.UpdateRule)
+				alerts.DELETE("/rules/:id", r.alertHandler.DeleteRule)
+			}
+
+			// Outbound webhooks
+			webhooks := protected.Group("/webhooks")
+			{
+				webhooks.GET("", r.webhookHandler.List)
+				webhooks.POST("", r.webhookHandler.Create)
+				webhooks.GET("/:id", r.webhookHandler.Get)
+				webhooks.PATCH("/:id", r.webhookHandler.Update)
+				webhooks.DELETE("/:id", r.webhookHandler.Delete)
+				webhooks.POST("/:id/rotate-secret", r.webhookHandler.RotateSecret)
+				webhooks.GET("/:id/deliveries", r.webhookHandler.ListDeliveries)
+				webhooks.POST("/:id/deliveries/:delivery_id/redeliver", r.webhookHandler.Redeliver)
+			}
+
+			// Notification channels (Telegram / Slack)
+			channels := protected.Group("/channels")
+			{
+				channels.GET("", r.channelHandler.List)
+				channels.POST("/slack", r.channelHandler.CreateSlack)
+				channels.POST("/telegram/link", r.channelHandler.CreateTelegramLink)
+				channels.PATCH("/:id", r.channelHandler.Update)
+				channels.DELETE("/:id", r.channelHandler.Delete)
+				channels.POST("/:id/test", r.channelHandler.Test)
+			}
+
+			// Notification preferences (quiet hours, throttling, mutes)
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("/preferences", r.preferenceHandler.GetPreferences)
+				notifications.PUT("/preferences", r.preferenceHandler.UpdatePreferences)
+				notifications.GET("/mutes", r.preferenceHandler.ListMutes)
+			}
+
+			// Email digest
+			digest := protected.Group("/digest")
+			{
+				digest.GET("", r.digestHandler.Get)
+				digest.PUT("", r.digestHandler.Update)
+				digest.DELETE("", r.digestHandler.Delete)
+				digest.GET("/preview", r.digestHandler.Preview)
 			}
 		}
 	}
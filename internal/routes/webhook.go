@@ -7,23 +7,32 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/ingest"
 	"github.com/bwmspring/chainfeed-go/internal/webhook"
 )
 
 type WebhookRoutes struct {
-	handler *webhook.Handler
+	handler         *webhook.Handler
+	telegramHandler *webhook.TelegramHandler
 }
 
-func NewWebhookRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client) *WebhookRoutes {
-	return &WebhookRoutes{
-		handler: webhook.NewHandler(cfg, logger, db, redis),
+func NewWebhookRoutes(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client, pipeline *ingest.Pipeline) *WebhookRoutes {
+	r := &WebhookRoutes{
+		handler: webhook.NewHandler(cfg, logger, db, redis, pipeline),
 	}
+	if cfg.Telegram.BotToken != "" {
+		r.telegramHandler = webhook.NewTelegramHandler(cfg, logger, db, redis)
+	}
+	return r
 }
 
 func (r *WebhookRoutes) RegisterRoutes(router *gin.RouterGroup) {
 	webhooks := router.Group("/webhooks")
 	{
 		webhooks.POST("/alchemy", r.handler.HandleAlchemy)
+		if r.telegramHandler != nil {
+			webhooks.POST("/telegram", r.telegramHandler.HandleUpdate)
+		}
 	}
 }
 
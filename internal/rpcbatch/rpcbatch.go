@@ -0,0 +1,52 @@
+package rpcbatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// MaxCalls 单次 JSON-RPC 批量请求的调用数，低于常见节点服务的批量上限
+const MaxCalls = 50
+
+// Caller JSON-RPC 批量调用，由 *rpc.Client 实现
+type Caller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// Call 按 MaxCalls 分批调用 RPC 方法，args[i] 为第 i 个调用的参数，result(i) 返回其结果指针。
+// 单个调用失败时交给 failed 处理并继续，对应结果保持为空；failed 为 nil 时任一调用失败即返回错误，
+// 适用于不能把失败当作节点查不到的场景
+func Call(ctx context.Context, caller Caller, method string, args [][]interface{},
+	result func(i int) interface{}, failed func(i int, err error)) error {
+	for start := 0; start < len(args); start += MaxCalls {
+		end := min(start+MaxCalls, len(args))
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{Method: method, Args: args[start+i], Result: result(start + i)}
+		}
+		if err := caller.BatchCallContext(ctx, elems); err != nil {
+			return fmt.Errorf("failed to call %s: %w", method, err)
+		}
+		for i, elem := range elems {
+			if elem.Error == nil {
+				continue
+			}
+			if failed == nil {
+				return fmt.Errorf("failed to call %s: %w", method, elem.Error)
+			}
+			failed(start+i, elem.Error)
+		}
+	}
+	return nil
+}
+
+// HashArgs 以交易哈希为唯一参数的调用参数
+func HashArgs(hashes []string) [][]interface{} {
+	args := make([][]interface{}, len(hashes))
+	for i, hash := range hashes {
+		args[i] = []interface{}{hash}
+	}
+	return args
+}
@@ -0,0 +1,39 @@
+package rpcbatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+func TestCall(t *testing.T) {
+	caller := &rpctest.Caller{Missing: errors.New("not found")}
+	hashes := make([]string, MaxCalls+1)
+	for i := range hashes {
+		hashes[i] = fmt.Sprintf("0x%02x", i)
+		if i != 3 {
+			caller.Set("eth_getTransactionByHash", hashes[i], map[string]string{"hash": hashes[i]})
+		}
+	}
+	results := make([]*struct{ Hash string }, len(hashes))
+	result := func(i int) interface{} { return &results[i] }
+
+	var failed []int
+	err := Call(context.Background(), caller, "eth_getTransactionByHash", HashArgs(hashes), result,
+		func(i int, err error) { failed = append(failed, i) })
+	require.NoError(t, err)
+	assert.Equal(t, 2, caller.Batches(), "split into batches of MaxCalls")
+	assert.Equal(t, []int{3}, failed)
+	assert.Nil(t, results[3])
+	require.NotNil(t, results[MaxCalls])
+	assert.Equal(t, hashes[MaxCalls], results[MaxCalls].Hash)
+
+	err = Call(context.Background(), caller, "eth_getTransactionByHash", HashArgs(hashes), result, nil)
+	assert.ErrorContains(t, err, "not found", "strict mode fails on a single call")
+}
@@ -0,0 +1,90 @@
+// Package rpctest 提供测试用的 JSON-RPC 批量调用替身，替代节点返回预置的结果
+package rpctest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Caller 按方法与第一个参数返回 Results 中预置的 JSON 结果，键为 "方法 参数"（eth_call 的参数见 CallArg）。
+// 未预置的调用返回 null；Missing 不为 nil 时改为以该错误失败
+type Caller struct {
+	Results map[string]string
+	Missing error
+
+	mu      sync.Mutex
+	calls   int
+	batches int
+}
+
+// Set 预置一个调用的结果，result 按 JSON 编码
+func (c *Caller) Set(method, arg string, result interface{}) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		panic(fmt.Sprintf("rpctest: failed to marshal %s result: %v", method, err))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Results == nil {
+		c.Results = make(map[string]string)
+	}
+	c.Results[method+" "+arg] = string(raw)
+}
+
+// CallArg eth_call 结果的键中使用的参数
+func CallArg(to, data string) string {
+	return to + " " + data
+}
+
+// Calls 返回已执行的调用数
+func (c *Caller) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+// Batches 返回已执行的批量请求数
+func (c *Caller) Batches() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.batches
+}
+
+func (c *Caller) BatchCallContext(_ context.Context, b []rpc.BatchElem) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches++
+	for i := range b {
+		c.calls++
+		raw, ok := c.Results[b[i].Method+" "+argKey(b[i].Args)]
+		if !ok {
+			if c.Missing != nil {
+				b[i].Error = c.Missing
+				continue
+			}
+			raw = "null"
+		}
+		if err := json.Unmarshal([]byte(raw), b[i].Result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func argKey(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	switch arg := args[0].(type) {
+	case string:
+		return arg
+	case map[string]string:
+		return CallArg(arg["to"], arg["data"])
+	default:
+		return fmt.Sprint(arg)
+	}
+}
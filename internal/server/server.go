@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/ingest"
 	"github.com/bwmspring/chainfeed-go/internal/middleware"
 	"github.com/bwmspring/chainfeed-go/internal/routes"
 	"github.com/bwmspring/chainfeed-go/internal/websocket"
@@ -77,8 +78,10 @@ func (s *Server) setupRoutes() {
 	s.router.GET("/health", s.healthCheck)
 
 	// Initialize route modules
-	apiRoutes := routes.NewAPIRoutes(s.cfg, s.logger, s.db, s.redis, s.hub)
-	webhookRoutes := routes.NewWebhookRoutes(s.cfg, s.logger, s.db, s.redis)
+	// Ingestion pipeline shared by webhooks and address profile backfill
+	pipeline := ingest.New(s.cfg, s.db, s.redis, s.logger)
+	apiRoutes := routes.NewAPIRoutes(s.cfg, s.logger, s.db, s.redis, s.hub, pipeline)
+	webhookRoutes := routes.NewWebhookRoutes(s.cfg, s.logger, s.db, s.redis, pipeline)
 
 	// Register routes
 	apiRoutes.RegisterRoutes(s.router.Group(""))
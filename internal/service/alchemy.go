@@ -108,6 +108,7 @@ func (s *AlchemyService) getTransfers(ctx context.Context, fromAddr, toAddr, fro
 		"withMetadata":     true,
 		"excludeZeroValue": true,
 		"maxCount":         "0x64", // 100 条
+		"order":            "desc", // 最新的在前
 	}
 	
 	if fromAddr != "" {
@@ -184,6 +185,7 @@ func (s *AlchemyService) getTransfers(ctx context.Context, fromAddr, toAddr, fro
 		
 		tx := &models.Transaction{
 			TxHash:         transfer.Hash,
+			LogIndex:       models.TxLogIndex,
 			BlockNumber:    blockNum,
 			BlockTimestamp: blockTime,
 			FromAddress:    transfer.From,
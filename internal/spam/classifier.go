@@ -0,0 +1,191 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+const (
+	defaultMassTransferThreshold = 10
+	classifyTimeout              = 10 * time.Second
+
+	// nativeSymbol 原生资产符号，任何代币使用该符号都视为仿冒
+	nativeSymbol = "ETH"
+)
+
+// Classifier 在交易入库前判断是否为垃圾交易，结果写入 Transaction.SpamReason；
+// 依次检查拒绝列表、零金额转账、仿冒符号与批量空投，允许列表与已认证代币不做代币级判断
+type Classifier struct {
+	list      *List
+	protected map[string]map[string]bool // 归一化符号 -> 使用该符号的已认证代币地址
+	receipts  *ReceiptCounter            // 为 nil 时只统计同一批次内的接收地址
+	threshold int
+	logger    *zap.Logger
+}
+
+// NewClassifier verified 为代币列表中的已认证代币，其符号受仿冒保护；list 与 receipts 可为 nil
+func NewClassifier(list *List, verified []models.Token, receipts *ReceiptCounter, threshold int, logger *zap.Logger) *Classifier {
+	if list == nil {
+		list = &List{}
+	}
+	if threshold <= 0 {
+		threshold = defaultMassTransferThreshold
+	}
+	c := &Classifier{
+		list:      list,
+		protected: map[string]map[string]bool{normalizeSymbol(nativeSymbol): {}},
+		receipts:  receipts,
+		threshold: threshold,
+		logger:    logger,
+	}
+	for _, t := range verified {
+		symbol := normalizeSymbol(t.Symbol)
+		if symbol == "" {
+			continue
+		}
+		if c.protected[symbol] == nil {
+			c.protected[symbol] = make(map[string]bool)
+		}
+		c.protected[symbol][strings.ToLower(t.Address)] = true
+	}
+	return c
+}
+
+// New 按配置创建分类器；仿冒保护的符号来自代币列表文件，配置了 RPC 时通过交易回执统计空投的接收地址
+func New(cfg config.SpamConfig, tokensCfg config.TokensConfig, eth config.EthereumConfig, logger *zap.Logger) (*Classifier, error) {
+	var list *List
+	if cfg.ListFile != "" {
+		var err error
+		if list, err = LoadList(cfg.ListFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var verified []models.Token
+	if tokensCfg.ListFile != "" {
+		var err error
+		if verified, err = tokens.LoadList(tokensCfg.ListFile, eth.ChainID); err != nil {
+			return nil, err
+		}
+	}
+
+	var receipts *ReceiptCounter
+	if eth.RPCURL != "" {
+		client, err := ethclient.Dial(eth.RPCURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ethereum: %w", err)
+		}
+		receipts = NewReceiptCounter(client.Client())
+	}
+
+	return NewClassifier(list, verified, receipts, cfg.MassTransferThreshold, logger), nil
+}
+
+// trusted 允许列表中的代币与代币列表中的已认证代币
+func (c *Classifier) trusted(tx *models.Transaction) bool {
+	return c.list.Allow[tx.TokenAddress] || (tx.Token != nil && tx.Token.Verified)
+}
+
+// Classify 为一批交易设置 SpamReason；已有分类的交易保持不变
+func (c *Classifier) Classify(ctx context.Context, txs []*models.Transaction) {
+	var candidates []*models.Transaction
+	for _, tx := range txs {
+		if tx.SpamReason != "" || tx.TokenAddress == "" {
+			continue
+		}
+		tx.SpamReason = c.classifyTransfer(tx)
+		if tx.SpamReason == "" && !c.trusted(tx) {
+			candidates = append(candidates, tx)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	// 同一交易中同一代币的接收地址：先统计本批次，不足阈值时再查询交易回执
+	counts := recipientsInBatch(txs)
+	var lookup []*models.Transaction
+	for _, tx := range candidates {
+		if counts[keyOf(tx)] < c.threshold {
+			lookup = append(lookup, tx)
+		}
+	}
+	if len(lookup) > 0 && c.receipts != nil {
+		ctx, cancel := context.WithTimeout(ctx, classifyTimeout)
+		defer cancel()
+		fetched, err := c.receipts.Count(ctx, lookup)
+		if err != nil {
+			c.logger.Warn("Failed to count transfer recipients", zap.Int("count", len(lookup)), zap.Error(err))
+		}
+		for key, n := range fetched {
+			counts[key] = max(counts[key], n)
+		}
+	}
+
+	for _, tx := range candidates {
+		if counts[keyOf(tx)] >= c.threshold {
+			tx.SpamReason = models.SpamReasonMassTransfer
+		}
+	}
+}
+
+// classifyTransfer 不依赖其他交易的判断
+func (c *Classifier) classifyTransfer(tx *models.Transaction) string {
+	if c.list.Deny[tx.TokenAddress] {
+		return models.SpamReasonDenylist
+	}
+	// 地址投毒常使用真实代币的零金额 transferFrom，因此不受允许列表豁免
+	if tx.TxType == "ERC20" && isZero(tx.Value) {
+		return models.SpamReasonZeroValue
+	}
+	if c.trusted(tx) {
+		return ""
+	}
+	if owners, ok := c.protected[normalizeSymbol(tx.TokenSymbol)]; ok && !owners[tx.TokenAddress] {
+		return models.SpamReasonLookalike
+	}
+	return ""
+}
+
+func isZero(value string) bool {
+	v, ok := new(big.Int).SetString(value, 10)
+	return ok && v.Sign() == 0
+}
+
+type transferKey struct {
+	txHash       string
+	tokenAddress string
+}
+
+func keyOf(tx *models.Transaction) transferKey {
+	return transferKey{strings.ToLower(tx.TxHash), tx.TokenAddress}
+}
+
+func recipientsInBatch(txs []*models.Transaction) map[transferKey]int {
+	seen := make(map[transferKey]map[string]bool)
+	for _, tx := range txs {
+		if tx.TokenAddress == "" {
+			continue
+		}
+		key := keyOf(tx)
+		if seen[key] == nil {
+			seen[key] = make(map[string]bool)
+		}
+		seen[key][tx.ToAddress] = true
+	}
+	counts := make(map[transferKey]int, len(seen))
+	for key, recipients := range seen {
+		counts[key] = len(recipients)
+	}
+	return counts
+}
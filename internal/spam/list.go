@@ -0,0 +1,78 @@
+package spam
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// List 人工维护的代币允许 / 拒绝列表，键为小写合约地址
+type List struct {
+	Allow map[string]bool
+	Deny  map[string]bool
+}
+
+// LoadList 读取列表文件，格式为 {"allow": ["0x..."], "deny": ["0x..."]}；同时出现在两个列表中的地址视为拒绝
+func LoadList(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spam list: %w", err)
+	}
+	var raw struct {
+		Allow []string `json:"allow"`
+		Deny  []string `json:"deny"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse spam list: %w", err)
+	}
+
+	list := &List{Allow: make(map[string]bool, len(raw.Allow)), Deny: make(map[string]bool, len(raw.Deny))}
+	for _, entries := range []struct {
+		addresses []string
+		set       map[string]bool
+	}{{raw.Allow, list.Allow}, {raw.Deny, list.Deny}} {
+		for _, addr := range entries.addresses {
+			if !common.IsHexAddress(addr) {
+				return nil, fmt.Errorf("invalid address in spam list: %s", addr)
+			}
+			entries.set[strings.ToLower(addr)] = true
+		}
+	}
+	return list, nil
+}
+
+// confusables 常见的仿冒字符（西里尔、希腊字母与易混淆数字），映射为对应的拉丁字母
+var confusables = map[rune]rune{
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P',
+	'С': 'C', 'Т': 'T', 'У': 'Y', 'Х': 'X', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J', 'Ԁ': 'D',
+	'а': 'A', 'в': 'B', 'е': 'E', 'к': 'K', 'м': 'M', 'н': 'H', 'о': 'O', 'р': 'P',
+	'с': 'C', 'т': 'T', 'у': 'Y', 'х': 'X', 'ѕ': 'S', 'і': 'I', 'ј': 'J', 'ԁ': 'D',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M',
+	'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X', 'ο': 'O', 'ν': 'V',
+	'0': 'O', '1': 'I', '5': 'S',
+}
+
+// normalizeSymbol 将符号归一化用于仿冒比较：全角转半角、仿冒字符转拉丁字母、转大写，并去除其他字符
+// （如 "USDТ"、"U.S.D.C"、"$USDC" 均归一化为与 USDT / USDC 相同的形式）
+func normalizeSymbol(symbol string) string {
+	var b strings.Builder
+	for _, r := range symbol {
+		// 全角 ASCII（U+FF01–U+FF5E）
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		if c, ok := confusables[r]; ok {
+			r = c
+		}
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
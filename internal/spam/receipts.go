@@ -0,0 +1,77 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/parser"
+	"github.com/bwmspring/chainfeed-go/internal/rpcbatch"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+)
+
+// ReceiptCounter 通过交易回执中的 Transfer 日志统计每个代币的接收地址数
+type ReceiptCounter struct {
+	caller tokens.Caller
+}
+
+func NewReceiptCounter(caller tokens.Caller) *ReceiptCounter {
+	return &ReceiptCounter{caller: caller}
+}
+
+type receipt struct {
+	Logs []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+	} `json:"logs"`
+}
+
+// Count 批量获取交易回执，返回 (交易, 代币) 的不同接收地址数；回执不存在的交易不出现在结果中
+func (c *ReceiptCounter) Count(ctx context.Context, txs []*models.Transaction) (map[transferKey]int, error) {
+	var hashes []string
+	seen := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		hash := strings.ToLower(tx.TxHash)
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+
+	counts := make(map[transferKey]int)
+	for start := 0; start < len(hashes); start += rpcbatch.MaxCalls {
+		chunk := hashes[start:min(start+rpcbatch.MaxCalls, len(hashes))]
+		receipts := make([]*receipt, len(chunk))
+		elems := make([]rpc.BatchElem, len(chunk))
+		for i, hash := range chunk {
+			elems[i] = rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{hash}, Result: &receipts[i]}
+		}
+		if err := c.caller.BatchCallContext(ctx, elems); err != nil {
+			return counts, fmt.Errorf("failed to get transaction receipts: %w", err)
+		}
+
+		for i, hash := range chunk {
+			if elems[i].Error != nil || receipts[i] == nil {
+				continue
+			}
+			recipients := make(map[transferKey]map[string]bool)
+			for _, log := range receipts[i].Logs {
+				if len(log.Topics) < 3 || !strings.EqualFold(log.Topics[0], parser.TransferEventTopic) {
+					continue
+				}
+				key := transferKey{hash, strings.ToLower(log.Address)}
+				if recipients[key] == nil {
+					recipients[key] = make(map[string]bool)
+				}
+				recipients[key][strings.ToLower(log.Topics[2])] = true
+			}
+			for key, set := range recipients {
+				counts[key] = len(set)
+			}
+		}
+	}
+	return counts, nil
+}
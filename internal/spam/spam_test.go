@@ -0,0 +1,96 @@
+package spam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/parser"
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+const (
+	usdt     = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	fakeUSDT = "0x2222222222222222222222222222222222222222"
+	airdrop  = "0x3333333333333333333333333333333333333333"
+	denied   = "0x4444444444444444444444444444444444444444"
+	allowed  = "0x5555555555555555555555555555555555555555"
+)
+
+func transfer(hash, token, symbol, to, value string) *models.Transaction {
+	return &models.Transaction{TxHash: hash, TxType: "ERC20", TokenAddress: token, TokenSymbol: symbol, ToAddress: to, Value: value}
+}
+
+func recipient(i int) string {
+	return fmt.Sprintf("0x%040x", i+1)
+}
+
+func TestClassify(t *testing.T) {
+	list := &List{Allow: map[string]bool{allowed: true}, Deny: map[string]bool{denied: true}}
+	classifier := NewClassifier(list, []models.Token{{Address: usdt, Symbol: "USDT", Verified: true}}, nil, 3, zap.NewNop())
+
+	verified := &models.Token{Address: usdt, Symbol: "USDT", Verified: true}
+	real := transfer("0xa1", usdt, "USDT", recipient(0), "1000")
+	real.Token = verified
+	poisoned := transfer("0xa2", usdt, "USDT", recipient(0), "0")
+	poisoned.Token = verified
+	// 符号中的 Т 为西里尔字母
+	lookalike := transfer("0xa3", fakeUSDT, "USDТ", recipient(0), "1000")
+	deny := transfer("0xa4", denied, "GOOD", recipient(0), "1000")
+	allow := transfer("0xa5", allowed, "ETH", recipient(0), "1000")
+	eth := &models.Transaction{TxHash: "0xa6", TxType: "ETH", ToAddress: recipient(0), Value: "0"}
+
+	classifier.Classify(context.Background(), []*models.Transaction{real, poisoned, lookalike, deny, allow, eth})
+
+	assert.Empty(t, real.SpamReason)
+	assert.Equal(t, models.SpamReasonZeroValue, poisoned.SpamReason, "zero value transfers are spam even for verified tokens")
+	assert.Equal(t, models.SpamReasonLookalike, lookalike.SpamReason)
+	assert.Equal(t, models.SpamReasonDenylist, deny.SpamReason)
+	assert.Empty(t, allow.SpamReason, "allowlisted tokens skip token checks")
+	assert.Empty(t, eth.SpamReason)
+}
+
+func TestClassifyMassTransfer(t *testing.T) {
+	// 同一批次内已有 3 个接收地址
+	var batch []*models.Transaction
+	for i := 0; i < 3; i++ {
+		batch = append(batch, transfer("0xb1", airdrop, "CLAIM", recipient(i), "1"))
+	}
+
+	// 批次内只有 1 个接收地址，回执中有 4 个
+	var logs []map[string]interface{}
+	for i := 0; i < 4; i++ {
+		logs = append(logs, map[string]interface{}{
+			"address": airdrop,
+			"topics":  []string{parser.TransferEventTopic, recipient(100), "0x000000000000000000000000" + recipient(i)[2:]},
+		})
+	}
+	raw, err := json.Marshal(map[string]interface{}{"logs": logs})
+	require.NoError(t, err)
+	caller := &rpctest.Caller{Results: map[string]string{"eth_getTransactionReceipt 0xb2": string(raw)}}
+	viaReceipt := transfer("0xB2", airdrop, "CLAIM", recipient(0), "1")
+	single := transfer("0xb3", airdrop, "CLAIM", recipient(0), "1")
+
+	classifier := NewClassifier(nil, nil, NewReceiptCounter(caller), 3, zap.NewNop())
+	classifier.Classify(context.Background(), append(batch, viaReceipt, single))
+
+	for _, tx := range batch {
+		assert.Equal(t, models.SpamReasonMassTransfer, tx.SpamReason)
+	}
+	assert.Equal(t, models.SpamReasonMassTransfer, viaReceipt.SpamReason)
+	assert.Empty(t, single.SpamReason)
+	assert.Equal(t, 2, caller.Calls(), "receipts are only fetched for transfers below the threshold")
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	for _, symbol := range []string{"USDC", "usdc", "U.S.D.C", "$USDC", "ＵＳＤＣ", "UЅDС"} {
+		assert.Equal(t, "USDC", normalizeSymbol(symbol), symbol)
+	}
+	assert.NotEqual(t, normalizeSymbol("USDC"), normalizeSymbol("USDT"))
+}
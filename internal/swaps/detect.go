@@ -0,0 +1,158 @@
+package swaps
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// 识别兑换所用的事件签名
+const (
+	sigSwapV2     = "Swap(address,uint256,uint256,uint256,uint256,address)"
+	sigSwapV3     = "Swap(address,address,int256,int256,uint160,uint128,int24)"
+	sigTransfer   = "Transfer(address,address,uint256)"
+	sigDeposit    = "Deposit(address,uint256)"
+	sigWithdrawal = "Withdrawal(address,uint256)"
+
+	// weth 主网 WETH9，Deposit / Withdrawal 表示兑换的一端为原生 ETH
+	weth = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+)
+
+// Detect 从解码后的事件识别 DEX 兑换：发出 Uniswap V2/V3 Swap 事件的合约视为池，
+// 按流入与流出池的 ERC20 Transfer 计算各代币的净额，净流出钱包最多的为 token_in，净流入最多的为 token_out；
+// 多跳兑换中间代币的净额为 0。不是兑换时返回 nil。返回的 SwapAsset 只包含地址与数量
+func Detect(action *models.Action) *models.Swap {
+	if action == nil {
+		return nil
+	}
+
+	swap := &models.Swap{Trader: action.From}
+	pools := make(map[string]bool)
+	var v2, v3 bool
+	for _, ev := range action.Events {
+		switch ev.Signature {
+		case sigSwapV2:
+			v2 = true
+		case sigSwapV3:
+			v3 = true
+		default:
+			continue
+		}
+		if !pools[ev.Address] {
+			pools[ev.Address] = true
+			swap.Pools = append(swap.Pools, ev.Address)
+		}
+	}
+	switch {
+	case v2 && v3:
+		swap.Protocol = models.SwapProtocolMixed
+	case v2:
+		swap.Protocol = models.SwapProtocolUniswapV2
+	case v3:
+		swap.Protocol = models.SwapProtocolUniswapV3
+	default:
+		return nil
+	}
+	if !pools[action.Contract] {
+		swap.Router, swap.RouterName = action.Contract, action.ContractName
+	}
+
+	// 代币净额：池转出为正，转入池为负
+	net := make(map[string]*big.Int)
+	var order []string
+	var deposit, withdrawal bool
+	for _, ev := range action.Events {
+		switch ev.Signature {
+		case sigDeposit:
+			deposit = deposit || ev.Address == weth
+			continue
+		case sigWithdrawal:
+			withdrawal = withdrawal || ev.Address == weth
+			continue
+		}
+		from, to, amount, ok := transfer(ev)
+		if !ok || pools[from] == pools[to] {
+			continue
+		}
+		if net[ev.Address] == nil {
+			net[ev.Address] = new(big.Int)
+			order = append(order, ev.Address)
+		}
+		if pools[from] {
+			net[ev.Address].Add(net[ev.Address], amount)
+		} else {
+			net[ev.Address].Sub(net[ev.Address], amount)
+		}
+	}
+
+	var in, out string
+	for _, token := range order {
+		if n := net[token]; n.Sign() < 0 && (in == "" || n.Cmp(net[in]) < 0) {
+			in = token
+		}
+		if n := net[token]; n.Sign() > 0 && (out == "" || n.Cmp(net[out]) > 0) {
+			out = token
+		}
+	}
+	if in == "" || out == "" {
+		return nil
+	}
+
+	swap.TokenIn = models.SwapAsset{Address: in, Amount: new(big.Int).Neg(net[in]).String()}
+	swap.TokenOut = models.SwapAsset{Address: out, Amount: net[out].String()}
+	if in == weth && deposit {
+		swap.TokenIn.Address = ""
+	}
+	if out == weth && withdrawal {
+		swap.TokenOut.Address = ""
+	}
+	swap.Recipient = recipient(action, swap, pools)
+	return swap
+}
+
+// recipient token_out 的接收地址：转给池与路由以外地址的最大一笔 token_out；
+// 原生 ETH 由路由通过内部转账发出，不产生日志，取调用参数中的 to / recipient，否则为发起地址
+func recipient(action *models.Action, swap *models.Swap, pools map[string]bool) string {
+	if swap.TokenOut.Address != "" {
+		var best string
+		var max *big.Int
+		for _, ev := range action.Events {
+			if ev.Address != swap.TokenOut.Address {
+				continue
+			}
+			_, to, amount, ok := transfer(ev)
+			if !ok || pools[to] || to == swap.Router {
+				continue
+			}
+			if max == nil || amount.Cmp(max) > 0 {
+				best, max = to, amount
+			}
+		}
+		if best != "" {
+			return best
+		}
+	}
+
+	for _, arg := range action.Args {
+		if arg.Type != "address" || (arg.Name != "to" && arg.Name != "recipient") {
+			continue
+		}
+		if to, ok := arg.Value.(string); ok && to != "" {
+			return to
+		}
+	}
+	return swap.Trader
+}
+
+// transfer 解析 Transfer 事件；ERC721 的签名相同，但 NFT 不会转入或转出池，不影响净额
+func transfer(ev models.ActionEvent) (from, to string, amount *big.Int, ok bool) {
+	if ev.Signature != sigTransfer || len(ev.Args) != 3 {
+		return "", "", nil, false
+	}
+	from, _ = ev.Args[0].Value.(string)
+	to, _ = ev.Args[1].Value.(string)
+	value, _ := ev.Args[2].Value.(string)
+	amount, ok = new(big.Int).SetString(value, 10)
+	return strings.ToLower(from), strings.ToLower(to), amount, ok
+}
@@ -0,0 +1,178 @@
+package swaps
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/tokens"
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+const (
+	// TxType 兑换合并后的交易类型
+	TxType = "SWAP"
+
+	defaultDecimals = 18
+	lookupTimeout   = 5 * time.Second
+)
+
+// Detector 将同一交易哈希的兑换转账合并为一条 SWAP 交易
+type Detector struct {
+	tokens *tokens.Registry // 为 nil 时只使用本批次交易中的代币信息
+	logger *zap.Logger
+}
+
+func NewDetector(registry *tokens.Registry, logger *zap.Logger) *Detector {
+	return &Detector{tokens: registry, logger: logger}
+}
+
+// Apply 识别一批已解码的交易中的兑换，返回替换后的交易列表：同一哈希的各条转账合并为一条 SWAP，
+// 位置为其中第一条；from 为发起地址，to 为 token_out 的接收地址，代币字段与 Value 取自 token_out
+func (d *Detector) Apply(ctx context.Context, txs []*models.Transaction) []*models.Transaction {
+	groups := make(map[string][]*models.Transaction)
+	var hashes []string
+	for _, tx := range txs {
+		hash := strings.ToLower(tx.TxHash)
+		if _, ok := groups[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+		groups[hash] = append(groups[hash], tx)
+	}
+
+	swaps := make(map[string]*models.Swap)
+	var unknown []string
+	for _, hash := range hashes {
+		rows := groups[hash]
+		swap := detectRows(rows)
+		if swap == nil {
+			continue
+		}
+		swaps[hash] = swap
+		for _, asset := range []*models.SwapAsset{&swap.TokenIn, &swap.TokenOut} {
+			if !fromRows(asset, rows) {
+				unknown = append(unknown, asset.Address)
+			}
+		}
+	}
+	if len(swaps) == 0 {
+		return txs
+	}
+
+	var metadata map[string]*models.Token
+	if len(unknown) > 0 && d.tokens != nil {
+		ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+		defer cancel()
+		var err error
+		if metadata, err = d.tokens.Lookup(ctx, unknown); err != nil {
+			d.logger.Warn("Failed to look up swap tokens", zap.Int("count", len(unknown)), zap.Error(err))
+		}
+	}
+
+	result := make([]*models.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		hash := strings.ToLower(tx.TxHash)
+		swap := swaps[hash]
+		if swap == nil {
+			result = append(result, tx)
+			continue
+		}
+		if tx != groups[hash][0] {
+			continue
+		}
+		for _, asset := range []*models.SwapAsset{&swap.TokenIn, &swap.TokenOut} {
+			if asset.Symbol == "" && asset.Address != "" {
+				fromToken(asset, metadata[asset.Address])
+			}
+		}
+		merged := merge(groups[hash], swap)
+		if merged.Token == nil && swap.TokenOut.Address != "" {
+			merged.Token = metadata[swap.TokenOut.Address]
+		}
+		result = append(result, merged)
+		d.logger.Debug("Swap detected",
+			zap.String("tx_hash", tx.TxHash),
+			zap.String("protocol", swap.Protocol),
+			zap.Int("legs", len(groups[hash])))
+	}
+	return result
+}
+
+// detectRows 同一哈希的各条转账共享一个 Action；垃圾交易与未解码的交易不参与识别
+func detectRows(rows []*models.Transaction) *models.Swap {
+	for _, tx := range rows {
+		if tx.Action == nil || tx.SpamReason != "" {
+			return nil
+		}
+	}
+	swap := Detect(rows[0].Action)
+	if swap != nil && swap.Trader == "" {
+		swap.Trader = strings.ToLower(rows[0].FromAddress)
+	}
+	return swap
+}
+
+// fromRows 用本批次中同一代币的转账（已由代币注册表补全）设置符号与精度，原生 ETH 直接设置；找不到符号时返回 false
+func fromRows(asset *models.SwapAsset, rows []*models.Transaction) bool {
+	if asset.Address == "" {
+		asset.Symbol, asset.Decimals = "ETH", defaultDecimals
+		return true
+	}
+	asset.Decimals = defaultDecimals
+	for _, tx := range rows {
+		if tx.TxType != "ERC20" || !strings.EqualFold(tx.TokenAddress, asset.Address) {
+			continue
+		}
+		asset.Symbol = tx.TokenSymbol
+		// TokenDecimals 为 0 表示来源未提供精度
+		if tx.TokenDecimals > 0 {
+			asset.Decimals = tx.TokenDecimals
+		}
+		return asset.Symbol != ""
+	}
+	return false
+}
+
+func fromToken(asset *models.SwapAsset, token *models.Token) {
+	if token == nil {
+		return
+	}
+	asset.Symbol = token.Symbol
+	if token.Decimals != nil {
+		asset.Decimals = *token.Decimals
+	}
+}
+
+// merge 以第一条转账为基础生成 SWAP 交易，保留区块信息与 Action
+func merge(rows []*models.Transaction, swap *models.Swap) *models.Transaction {
+	tx := *rows[0]
+	tx.TxType = TxType
+	// 兑换为交易级记录，沿用最小的日志序号：之前已按转账单独入库的一条 leg 被合并为 SWAP，其余 leg 在入库时删除
+	for _, row := range rows[1:] {
+		tx.LogIndex = min(tx.LogIndex, row.LogIndex)
+	}
+	tx.Swap = swap
+	tx.FromAddress = swap.Trader
+	tx.ToAddress = swap.Recipient
+	tx.TokenAddress = swap.TokenOut.Address
+	tx.TokenID = ""
+	tx.TokenSymbol = swap.TokenOut.Symbol
+	tx.TokenDecimals = swap.TokenOut.Decimals
+	tx.Token = nil
+	for _, row := range rows {
+		if row.Token != nil && strings.EqualFold(row.TokenAddress, swap.TokenOut.Address) {
+			tx.Token = row.Token
+			break
+		}
+	}
+	tx.USDValue = nil
+	tx.Value = "0"
+	if amount, ok := new(big.Int).SetString(swap.TokenOut.Amount, 10); ok {
+		tx.Value = units.ScaleTo18(amount, swap.TokenOut.Decimals).String()
+	}
+	return &tx
+}
@@ -0,0 +1,158 @@
+package swaps
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/decoder"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+const (
+	wallet    = "0x8ba1f109551bd432803012645ac136ddd64dba72"
+	v2Router  = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	universal = "0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad"
+	router02  = "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45"
+	usdc      = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	dai       = "0x6b175474e89094c44da98b954eedeac495271d0f"
+	v2UsdcEth = "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc"
+	v2DaiEth  = "0xa478c2975ab1ea89e8196811f51a7b7ade33eb11"
+	v3UsdcEth = "0x88e6a0c2ddd26feeb64f039a2c41296fcb3f5640"
+)
+
+// fixture testdata 中录制的交易与回执
+type fixture struct {
+	Transaction json.RawMessage `json:"transaction"`
+	Receipt     json.RawMessage `json:"receipt"`
+}
+
+// loadFixtures 读取录制的交易，返回交易哈希
+func loadFixtures(t *testing.T, caller *rpctest.Caller, names ...string) map[string]string {
+	hashes := make(map[string]string)
+	for _, name := range names {
+		raw, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+		require.NoError(t, err)
+		var fx fixture
+		require.NoError(t, json.Unmarshal(raw, &fx))
+		var tx struct {
+			Hash string `json:"hash"`
+		}
+		require.NoError(t, json.Unmarshal(fx.Transaction, &tx))
+		caller.Set("eth_getTransactionByHash", tx.Hash, fx.Transaction)
+		caller.Set("eth_getTransactionReceipt", tx.Hash, fx.Receipt)
+		hashes[name] = tx.Hash
+	}
+	return hashes
+}
+
+func erc20(hash, token, symbol string, decimals int, from, to, value string) *models.Transaction {
+	return &models.Transaction{TxHash: hash, TxType: "ERC20", TokenAddress: token, TokenSymbol: symbol,
+		TokenDecimals: decimals, FromAddress: from, ToAddress: to, Value: value}
+}
+
+func TestApply(t *testing.T) {
+	caller := &rpctest.Caller{}
+	hashes := loadFixtures(t, caller, "uniswap_v2_eth_for_usdc", "uniswap_v3_usdc_for_eth", "mixed_usdc_for_dai")
+	d, err := decoder.NewDecoder(caller, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	// Webhook 推送的各条转账：V2 兑换的 ETH 转出与 USDC 转入、V3 兑换的 USDC 转出（ETH 为内部转账，不推送）、
+	// 混合路由的 USDC 转出与 DAI 转入，以及一笔普通转账
+	v2 := hashes["uniswap_v2_eth_for_usdc"]
+	v3 := hashes["uniswap_v3_usdc_for_eth"]
+	mixed := hashes["mixed_usdc_for_dai"]
+	plain := erc20("0xfeed", usdc, "USDC", 6, wallet, v2UsdcEth, "5000000000000000000")
+	v2Out := erc20(v2, usdc, "USDC", 6, v2UsdcEth, wallet, "2012345678000000000000")
+	v2Out.LogIndex = 2
+	mixedIn := erc20(mixed, usdc, "USDC", 6, wallet, v3UsdcEth, "2500000000000000000000")
+	mixedIn.LogIndex = 9
+	mixedOut := erc20(mixed, dai, "DAI", 18, v2DaiEth, wallet, "2490500000000000000000")
+	mixedOut.LogIndex = 4
+	txs := []*models.Transaction{
+		{TxHash: v2, LogIndex: models.TxLogIndex, TxType: "ETH", FromAddress: wallet, ToAddress: v2Router, Value: "1000000000000000000"},
+		v2Out,
+		erc20(v3, usdc, "USDC", 6, wallet, v3UsdcEth, "3000000000000000000000"),
+		plain,
+		mixedIn,
+		mixedOut,
+	}
+	d.Decode(context.Background(), txs)
+
+	result := NewDetector(nil, zap.NewNop()).Apply(context.Background(), txs)
+	require.Len(t, result, 4)
+	assert.Same(t, plain, result[2], "transfers outside swaps are kept")
+	assert.Equal(t, "ERC20", plain.TxType)
+
+	swap := result[0]
+	assert.Equal(t, TxType, swap.TxType)
+	assert.Equal(t, models.TxLogIndex, swap.LogIndex, "the swap keeps the lowest log index of its legs")
+	assert.Equal(t, wallet, swap.FromAddress)
+	assert.Equal(t, wallet, swap.ToAddress)
+	assert.Equal(t, usdc, swap.TokenAddress)
+	assert.Equal(t, "USDC", swap.TokenSymbol)
+	assert.Equal(t, 6, swap.TokenDecimals)
+	assert.Equal(t, "2012345678000000000000", swap.Value)
+	assert.Equal(t, "swapExactETHForTokens on Uniswap V2", swap.Action.String())
+	assert.Equal(t, &models.Swap{
+		Protocol:   models.SwapProtocolUniswapV2,
+		Pools:      []string{v2UsdcEth},
+		Router:     v2Router,
+		RouterName: "Uniswap V2",
+		Trader:     wallet,
+		Recipient:  wallet,
+		TokenIn:    models.SwapAsset{Symbol: "ETH", Decimals: 18, Amount: "1000000000000000000"},
+		TokenOut:   models.SwapAsset{Address: usdc, Symbol: "USDC", Decimals: 6, Amount: "2012345678"},
+	}, swap.Swap)
+
+	swap = result[1]
+	require.NotNil(t, swap.Swap)
+	assert.Equal(t, models.SwapProtocolUniswapV3, swap.Swap.Protocol)
+	assert.Equal(t, universal, swap.Swap.Router)
+	assert.Equal(t, wallet, swap.Swap.Recipient, "native ETH output falls back to the trader")
+	assert.Equal(t, models.SwapAsset{Address: usdc, Symbol: "USDC", Decimals: 6, Amount: "3000000000"}, swap.Swap.TokenIn)
+	assert.Equal(t, models.SwapAsset{Symbol: "ETH", Decimals: 18, Amount: "1200000000000000000"}, swap.Swap.TokenOut)
+	assert.Empty(t, swap.TokenAddress)
+	assert.Equal(t, "ETH", swap.TokenSymbol)
+	assert.Equal(t, "1200000000000000000", swap.Value)
+
+	swap = result[3]
+	require.NotNil(t, swap.Swap)
+	assert.Equal(t, models.SwapProtocolMixed, swap.Swap.Protocol)
+	assert.Equal(t, []string{v3UsdcEth, v2DaiEth}, swap.Swap.Pools)
+	assert.Equal(t, router02, swap.Swap.Router)
+	assert.Equal(t, models.SwapAsset{Address: usdc, Symbol: "USDC", Decimals: 6, Amount: "2500000000"}, swap.Swap.TokenIn)
+	assert.Equal(t, models.SwapAsset{Address: dai, Symbol: "DAI", Decimals: 18, Amount: "2490500000000000000000"}, swap.Swap.TokenOut)
+	assert.Equal(t, dai, swap.TokenAddress)
+	assert.Equal(t, 4, swap.LogIndex)
+	assert.True(t, strings.EqualFold(mixed, swap.TxHash))
+}
+
+func TestDetectNotSwap(t *testing.T) {
+	assert.Nil(t, Detect(nil))
+
+	// 只有 Transfer 而没有池的 Swap 事件
+	action := &models.Action{From: wallet, Contract: usdc, Method: "transfer", Events: []models.ActionEvent{{
+		Address:   usdc,
+		Event:     "Transfer",
+		Signature: sigTransfer,
+		Args: []models.ActionArg{
+			{Name: "from", Type: "address", Value: wallet},
+			{Name: "to", Type: "address", Value: v2UsdcEth},
+			{Name: "value", Type: "uint256", Value: "5"},
+		},
+	}}}
+	assert.Nil(t, Detect(action))
+
+	// 有 Swap 事件，但只有转入池的一端
+	action.Events = append(action.Events, models.ActionEvent{Address: v2UsdcEth, Event: "Swap", Signature: sigSwapV2})
+	assert.Nil(t, Detect(action))
+}
@@ -0,0 +1,291 @@
+package syndication
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+// 响应类型
+const (
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+)
+
+const tokenPrefix = "cfeed_"
+
+// GenerateToken 生成订阅令牌，返回明文（只展示一次）与保存到数据库的摘要
+func GenerateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := tokenPrefix + hex.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken 令牌的 SHA-256 摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Feed 渲染 Atom / RSS 所需的信息，Items 按时间降序
+type Feed struct {
+	ID       string // 稳定的 feed 标识
+	Title    string
+	SelfURL  string // 订阅地址（含令牌）
+	SiteURL  string
+	Explorer string
+	Updated  time.Time
+	Items    []repository.FeedItemDetail
+}
+
+// ItemGUID feed 条目的稳定标识，不随令牌或展示名称变化
+func ItemGUID(item *repository.FeedItemDetail) string {
+	return fmt.Sprintf("urn:chainfeed:feed-item:%d", item.ID)
+}
+
+// LastModified feed 的最后修改时间：最新条目的入库时间与条目中监控地址（标签）的最后更新时间中较晚者，
+// 没有条目时为 fallback
+func LastModified(items []repository.FeedItemDetail, fallback time.Time) time.Time {
+	if len(items) == 0 {
+		return fallback.UTC()
+	}
+	latest := items[0].CreatedAt
+	for i := range items {
+		for _, wa := range items[i].WatchedAddresses {
+			if wa.UpdatedAt.After(latest) {
+				latest = wa.UpdatedAt
+			}
+		}
+	}
+	return latest.UTC()
+}
+
+// Title 条目标题，如 "vitalik.eth sent 5 ETH to 0x1234…abcd"；
+// 发送方与接收方均被监控时以发送方为主语，对手方使用其展示名称
+func Title(item *repository.FeedItemDetail) string {
+	tx := &item.Transaction
+	watched := item.WatchedAddresses
+	if len(watched) == 0 {
+		watched = []models.WatchedAddress{item.WatchedAddress}
+	}
+
+	var sender, receiver, token *models.WatchedAddress
+	for i := range watched {
+		wa := &watched[i]
+		switch {
+		case wa.Kind == models.WatchKindToken:
+			if token == nil {
+				token = wa
+			}
+		case strings.EqualFold(wa.Address, tx.FromAddress):
+			if sender == nil {
+				sender = wa
+			}
+		case strings.EqualFold(wa.Address, tx.ToAddress):
+			if receiver == nil {
+				receiver = wa
+			}
+		}
+	}
+
+	name := func(addr string) string {
+		for i := range watched {
+			if watched[i].Kind != models.WatchKindToken && strings.EqualFold(watched[i].Address, addr) {
+				return notify.DisplayName(&watched[i])
+			}
+		}
+		return notify.ShortAddress(addr)
+	}
+
+	amount := notify.FormatAmountWithUSD(tx)
+	switch {
+	case sender != nil:
+		return fmt.Sprintf("%s sent %s to %s", notify.DisplayName(sender), amount, name(tx.ToAddress))
+	case receiver != nil:
+		return fmt.Sprintf("%s received %s from %s", notify.DisplayName(receiver), amount, name(tx.FromAddress))
+	}
+
+	asset := notify.AssetSymbol(tx)
+	if token != nil {
+		asset = notify.DisplayName(token)
+	}
+	return fmt.Sprintf("%s transfer: %s from %s to %s", asset, amount, name(tx.FromAddress), name(tx.ToAddress))
+}
+
+func summary(item *repository.FeedItemDetail) string {
+	tx := &item.Transaction
+	return strings.Join([]string{
+		"From: " + tx.FromAddress,
+		"To: " + tx.ToAddress,
+		"Amount: " + notify.FormatAmountWithUSD(tx),
+		fmt.Sprintf("Block: %d (%s)", tx.BlockNumber, tx.BlockTimestamp.UTC().Format(time.RFC3339)),
+		"Transaction: " + tx.TxHash,
+	}, "\n")
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Published string   `xml:"published"`
+	Link      atomLink `xml:"link"`
+	Summary   atomText `xml:"summary"`
+}
+
+// RenderAtom 渲染 Atom 1.0；条目的 updated 为入库时间，published 为区块时间
+func RenderAtom(f *Feed) ([]byte, error) {
+	feed := atomFeed{
+		ID:      f.ID,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "ChainFeed"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL},
+			{Rel: "alternate", Type: "text/html", Href: f.SiteURL},
+		},
+	}
+	for i := range f.Items {
+		item := &f.Items[i]
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        ItemGUID(item),
+			Title:     Title(item),
+			Updated:   item.CreatedAt.UTC().Format(time.RFC3339),
+			Published: item.Transaction.BlockTimestamp.UTC().Format(time.RFC3339),
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: f.Explorer + "/tx/" + item.Transaction.TxHash},
+			Summary:   atomText{Type: "text", Body: summary(item)},
+		})
+	}
+	return marshal(feed)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RenderRSS 渲染 RSS 2.0；pubDate 为区块时间
+func RenderRSS(f *Feed) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.SiteURL,
+			Description:   f.Title,
+			AtomLink:      atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfURL},
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+	for i := range f.Items {
+		item := &f.Items[i]
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       Title(item),
+			Link:        f.Explorer + "/tx/" + item.Transaction.TxHash,
+			Description: summary(item),
+			GUID:        rssGUID{IsPermaLink: "false", Value: ItemGUID(item)},
+			PubDate:     item.Transaction.BlockTimestamp.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return marshal(doc)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ETag 响应体的强校验值；标签等展示信息变化时也会改变
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified 按 RFC 9110 §13.1.3 处理条件请求：存在 If-None-Match 时只比较 ETag，忽略 If-Modified-Since；
+// 否则 If-Modified-Since 不早于 lastModified 时视为未修改。删除条目后最新条目的时间可能倒退，
+// 只有不支持 ETag 的客户端会因此继续使用旧内容，直到出现更新的条目
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// HTTP 日期精度为秒
+	return !lastModified.Truncate(time.Second).After(t)
+}
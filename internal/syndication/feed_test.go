@@ -0,0 +1,127 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const (
+	vitalik = "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"
+	other   = "0x1111111111111111111111111111111111111111"
+)
+
+func feedItem(id int64, from, to string, watched ...models.WatchedAddress) repository.FeedItemDetail {
+	created := time.Date(2026, 3, 1, 12, 0, int(id), 0, time.UTC)
+	return repository.FeedItemDetail{
+		FeedItem: models.FeedItem{ID: id, CreatedAt: created},
+		Transaction: models.Transaction{
+			TxHash: "0xabc", TxType: "ETH", Value: "5000000000000000000",
+			FromAddress: from, ToAddress: to, BlockNumber: 100, BlockTimestamp: created,
+		},
+		WatchedAddresses: watched,
+	}
+}
+
+func TestTitle(t *testing.T) {
+	wallet := models.WatchedAddress{Kind: models.WatchKindWallet, Address: vitalik, ENSName: "vitalik.eth"}
+	treasury := models.WatchedAddress{Kind: models.WatchKindWallet, Address: other, Label: "Treasury"}
+
+	item := feedItem(1, vitalik, other, wallet)
+	assert.Equal(t, "vitalik.eth sent 5 ETH to 0x1111…1111", Title(&item))
+
+	item = feedItem(1, other, vitalik, wallet)
+	assert.Equal(t, "vitalik.eth received 5 ETH from 0x1111…1111", Title(&item))
+
+	item = feedItem(1, vitalik, other, treasury, wallet)
+	assert.Equal(t, "vitalik.eth sent 5 ETH to Treasury", Title(&item))
+
+	token := models.WatchedAddress{Kind: models.WatchKindToken, Address: "0xa0b8", Label: "USDC"}
+	item = feedItem(1, vitalik, other, token)
+	assert.Equal(t, "USDC transfer: 5 ETH from 0xd8da…6045 to 0x1111…1111", Title(&item))
+}
+
+func TestRender(t *testing.T) {
+	wallet := models.WatchedAddress{Kind: models.WatchKindWallet, Address: vitalik, ENSName: "vitalik.eth"}
+	items := []repository.FeedItemDetail{feedItem(2, vitalik, other, wallet), feedItem(1, other, vitalik, wallet)}
+	f := &Feed{
+		ID:       "urn:chainfeed:feed:user:1",
+		Title:    "ChainFeed",
+		SelfURL:  "https://api.example.com/api/v1/syndication/atom?token=t",
+		SiteURL:  "https://api.example.com",
+		Explorer: "https://etherscan.io",
+		Updated:  LastModified(items, time.Time{}),
+		Items:    items,
+	}
+
+	body, err := RenderAtom(f)
+	require.NoError(t, err)
+	var atom atomFeed
+	require.NoError(t, xml.Unmarshal(body, &atom))
+	require.Len(t, atom.Entries, 2)
+	assert.Equal(t, "urn:chainfeed:feed-item:2", atom.Entries[0].ID)
+	assert.Equal(t, "https://etherscan.io/tx/0xabc", atom.Entries[0].Link.Href)
+	assert.Equal(t, "2026-03-01T12:00:02Z", atom.Updated)
+
+	body, err = RenderRSS(f)
+	require.NoError(t, err)
+	var rss rssDocument
+	require.NoError(t, xml.Unmarshal(body, &rss))
+	require.Len(t, rss.Channel.Items, 2)
+	assert.Equal(t, "urn:chainfeed:feed-item:1", rss.Channel.Items[1].GUID.Value)
+	assert.Equal(t, "vitalik.eth received 5 ETH from 0x1111…1111", rss.Channel.Items[1].Title)
+}
+
+func TestNotModified(t *testing.T) {
+	etag := ETag([]byte("feed"))
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.False(t, NotModified(req, etag, modified))
+
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	assert.True(t, NotModified(req, etag, modified))
+	req.Header.Set("If-None-Match", `W/`+etag)
+	assert.True(t, NotModified(req, etag, modified))
+
+	// 两者同时存在时只比较 ETag：ETag 不匹配时即使时间未变也返回完整内容
+	req.Header.Set("If-None-Match", `"other"`)
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	assert.False(t, NotModified(req, etag, modified))
+	req.Header.Set("If-None-Match", etag)
+	req.Header.Set("If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat))
+	assert.True(t, NotModified(req, etag, modified))
+
+	// 没有 If-None-Match 时按时间判断
+	req.Header.Del("If-None-Match")
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	assert.True(t, NotModified(req, etag, modified))
+	req.Header.Set("If-Modified-Since", modified.Add(-time.Second).Format(http.TimeFormat))
+	assert.False(t, NotModified(req, etag, modified))
+	req.Header.Set("If-Modified-Since", "invalid")
+	assert.False(t, NotModified(req, etag, modified))
+}
+
+func TestLastModified(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fallback := created.Add(-24 * time.Hour)
+	assert.Equal(t, fallback, LastModified(nil, fallback))
+
+	items := []repository.FeedItemDetail{
+		{FeedItem: models.FeedItem{CreatedAt: created}, WatchedAddresses: []models.WatchedAddress{{UpdatedAt: created.Add(-time.Hour)}}},
+		{FeedItem: models.FeedItem{CreatedAt: created.Add(-time.Minute)}, WatchedAddresses: []models.WatchedAddress{{UpdatedAt: created.Add(-time.Hour)}}},
+	}
+	assert.Equal(t, created, LastModified(items, fallback))
+
+	// 修改标签后最后修改时间随之更新
+	items[1].WatchedAddresses[0].UpdatedAt = created.Add(time.Hour)
+	assert.Equal(t, created.Add(time.Hour), LastModified(items, fallback))
+}
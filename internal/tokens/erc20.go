@@ -0,0 +1,84 @@
+package tokens
+
+import (
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/bwmspring/chainfeed-go/internal/units"
+)
+
+// ERC20 元数据方法的函数选择器
+const (
+	selectorName     = "0x06fdde03" // name()
+	selectorSymbol   = "0x95d89b41" // symbol()
+	selectorDecimals = "0x313ce567" // decimals()
+)
+
+// 与 tokens 表的列长度一致
+const (
+	maxNameLength   = 255
+	maxSymbolLength = 64
+)
+
+// decodeString 解码 name() / symbol() 的返回值；兼容返回 bytes32 的早期合约（如 MKR）
+func decodeString(b []byte) string {
+	var raw []byte
+	if len(b) >= 64 {
+		offset := new(big.Int).SetBytes(b[:32])
+		if offset.IsUint64() && offset.Uint64()+32 <= uint64(len(b)) {
+			start := offset.Uint64() + 32
+			length := new(big.Int).SetBytes(b[start-32 : start])
+			if length.IsUint64() && start+length.Uint64() <= uint64(len(b)) {
+				raw = b[start : start+length.Uint64()]
+			}
+		}
+	}
+	if raw == nil && len(b) == 32 {
+		raw = b
+	}
+	return sanitize(string(raw))
+}
+
+// decodeDecimals 解码 decimals() 的返回值，超出 uint8 范围时视为无效
+func decodeDecimals(b []byte) *int {
+	if len(b) < 32 {
+		return nil
+	}
+	v := new(big.Int).SetBytes(b[:32])
+	if !v.IsUint64() || v.Uint64() > 255 {
+		return nil
+	}
+	d := int(v.Uint64())
+	return &d
+}
+
+// sanitize 去除无效 UTF-8、控制字符（含 bytes32 末尾的 0）与首尾空白
+func sanitize(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// rescale 将按 from 位精度换算的 18 位 Value 改为按 to 位精度换算
+func rescale(value string, from, to int) (string, bool) {
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return "", false
+	}
+	raw := units.Rescale(v, units.Decimals, from)
+	return units.ScaleTo18(raw, to).String(), true
+}
@@ -0,0 +1,61 @@
+package tokens
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+// tokenList Uniswap Token Lists 格式（https://tokenlists.org）
+type tokenList struct {
+	Tokens []struct {
+		ChainID  int64  `json:"chainId"`
+		Address  string `json:"address"`
+		Name     string `json:"name"`
+		Symbol   string `json:"symbol"`
+		Decimals *int   `json:"decimals"`
+		LogoURI  string `json:"logoURI"`
+	} `json:"tokens"`
+}
+
+// LoadList 读取代币列表文件，只保留 chainID 上的代币（chainID 为 0 时不过滤）
+func LoadList(path string, chainID int64) ([]models.Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token list: %w", err)
+	}
+	var list tokenList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse token list: %w", err)
+	}
+
+	seen := make(map[string]bool, len(list.Tokens))
+	tokens := make([]models.Token, 0, len(list.Tokens))
+	for _, t := range list.Tokens {
+		if chainID != 0 && t.ChainID != chainID {
+			continue
+		}
+		if !common.IsHexAddress(t.Address) {
+			return nil, fmt.Errorf("invalid token address in list: %s", t.Address)
+		}
+		address := strings.ToLower(t.Address)
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		tokens = append(tokens, models.Token{
+			Address:  address,
+			Name:     truncate(sanitize(t.Name), maxNameLength),
+			Symbol:   truncate(sanitize(t.Symbol), maxSymbolLength),
+			Decimals: t.Decimals,
+			LogoURL:  t.LogoURI,
+			Verified: true,
+		})
+	}
+	return tokens, nil
+}
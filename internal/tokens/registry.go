@@ -0,0 +1,280 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+)
+
+const (
+	// maxBatchCalls 单次 JSON-RPC 批量请求的调用数（每个代币 3 次），低于常见节点服务的批量上限
+	maxBatchCalls = 90
+	// maxCached 进程内缓存的代币数，超过时清空重建
+	maxCached     = 10000
+	enrichTimeout = 10 * time.Second
+)
+
+// Store 代币元数据的持久化存储，由 repository.TokenRepository 实现
+type Store interface {
+	GetByAddresses(ctx context.Context, addresses []string) ([]models.Token, error)
+	SaveFetched(ctx context.Context, token *models.Token) error
+	SyncList(ctx context.Context, tokens []models.Token) error
+}
+
+// Caller 批量 JSON-RPC 调用，由 *rpc.Client 实现
+type Caller interface {
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// Registry 代币元数据注册表；依次从进程内缓存、tokens 表与链上 eth_call 获取
+type Registry struct {
+	store  Store
+	caller Caller // 为 nil 时只使用代币列表与已保存的数据
+	list   []models.Token
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	cache map[string]*models.Token
+}
+
+func NewRegistry(store Store, caller Caller, list []models.Token, logger *zap.Logger) *Registry {
+	return &Registry{
+		store:  store,
+		caller: caller,
+		list:   list,
+		logger: logger,
+		cache:  make(map[string]*models.Token),
+	}
+}
+
+// New 按配置创建注册表，既没有 RPC 地址也没有代币列表时返回 nil
+func New(cfg config.TokensConfig, eth config.EthereumConfig, store Store, logger *zap.Logger) (*Registry, error) {
+	if cfg.ListFile == "" && eth.RPCURL == "" {
+		return nil, nil
+	}
+
+	var list []models.Token
+	if cfg.ListFile != "" {
+		var err error
+		if list, err = LoadList(cfg.ListFile, eth.ChainID); err != nil {
+			return nil, err
+		}
+	}
+
+	var caller Caller
+	if eth.RPCURL != "" {
+		client, err := ethclient.Dial(eth.RPCURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ethereum: %w", err)
+		}
+		caller = client.Client()
+	}
+
+	return NewRegistry(store, caller, list, logger), nil
+}
+
+// SyncList 将代币列表中的 logo 与认证状态写入 tokens 表，应在启动时调用一次
+func (r *Registry) SyncList(ctx context.Context) error {
+	if err := r.store.SyncList(ctx, r.list); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cache = make(map[string]*models.Token)
+	r.mu.Unlock()
+	return nil
+}
+
+// Get 获取单个代币的元数据，无法获取时返回 nil
+func (r *Registry) Get(ctx context.Context, address string) (*models.Token, error) {
+	tokens, err := r.Lookup(ctx, []string{address})
+	if err != nil {
+		return nil, err
+	}
+	return tokens[strings.ToLower(address)], nil
+}
+
+// Lookup 批量获取代币元数据，结果以小写地址为键；缺失的代币合并为一次批量 eth_call 查询。
+// 链上查询失败时返回已获取的部分结果与错误
+func (r *Registry) Lookup(ctx context.Context, addresses []string) (map[string]*models.Token, error) {
+	result := make(map[string]*models.Token, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+	var missing []string
+	r.mu.Lock()
+	for _, addr := range addresses {
+		addr = strings.ToLower(addr)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		if t, ok := r.cache[addr]; ok {
+			result[addr] = t
+			continue
+		}
+		missing = append(missing, addr)
+	}
+	r.mu.Unlock()
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	stored, err := r.store.GetByAddresses(ctx, missing)
+	if err != nil {
+		return result, err
+	}
+	byAddress := make(map[string]*models.Token, len(stored))
+	for i := range stored {
+		byAddress[stored[i].Address] = &stored[i]
+	}
+
+	var fetch []string
+	for _, addr := range missing {
+		t := byAddress[addr]
+		// 仅来自代币列表的记录仍需链上确认
+		if t != nil && (t.FetchedAt != nil || r.caller == nil) {
+			result[addr] = t
+			r.remember(t)
+			continue
+		}
+		fetch = append(fetch, addr)
+	}
+	if len(fetch) == 0 || r.caller == nil {
+		return result, nil
+	}
+
+	fetched, err := r.fetch(ctx, fetch)
+	for _, t := range fetched {
+		if saveErr := r.store.SaveFetched(ctx, t); saveErr != nil {
+			r.logger.Warn("Failed to save token", zap.String("address", t.Address), zap.Error(saveErr))
+			continue
+		}
+		result[t.Address] = t
+		r.remember(t)
+	}
+	// 链上查询失败的代币退回到代币列表中的数据，不缓存，下次重试
+	for _, addr := range fetch {
+		if _, ok := result[addr]; !ok && byAddress[addr] != nil {
+			result[addr] = byAddress[addr]
+		}
+	}
+	return result, err
+}
+
+func (r *Registry) remember(t *models.Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cache) >= maxCached {
+		r.cache = make(map[string]*models.Token)
+	}
+	r.cache[t.Address] = t
+}
+
+// fetch 通过批量 eth_call 查询 name()/symbol()/decimals()；
+// 调用 revert 表示合约未实现该方法，其他错误（如限流）时该代币本次不返回
+func (r *Registry) fetch(ctx context.Context, addresses []string) ([]*models.Token, error) {
+	selectors := []string{selectorName, selectorSymbol, selectorDecimals}
+	perBatch := maxBatchCalls / len(selectors)
+
+	var tokens []*models.Token
+	for start := 0; start < len(addresses); start += perBatch {
+		chunk := addresses[start:min(start+perBatch, len(addresses))]
+		results := make([]hexutil.Bytes, len(chunk)*len(selectors))
+		elems := make([]rpc.BatchElem, len(results))
+		for i, addr := range chunk {
+			for j, selector := range selectors {
+				k := i*len(selectors) + j
+				elems[k] = rpc.BatchElem{
+					Method: "eth_call",
+					Args:   []interface{}{map[string]string{"to": addr, "data": selector}, "latest"},
+					Result: &results[k],
+				}
+			}
+		}
+
+		if err := r.caller.BatchCallContext(ctx, elems); err != nil {
+			return tokens, fmt.Errorf("failed to call token contracts: %w", err)
+		}
+
+	chunk:
+		for i, addr := range chunk {
+			for j := range selectors {
+				if err := elems[i*len(selectors)+j].Error; err != nil && !isRevert(err) {
+					r.logger.Warn("Failed to fetch token metadata", zap.String("address", addr), zap.Error(err))
+					continue chunk
+				}
+			}
+			k := i * len(selectors)
+			tokens = append(tokens, &models.Token{
+				Address:  addr,
+				Name:     truncate(decodeString(results[k]), maxNameLength),
+				Symbol:   truncate(decodeString(results[k+1]), maxSymbolLength),
+				Decimals: decodeDecimals(results[k+2]),
+			})
+		}
+	}
+	return tokens, nil
+}
+
+// isRevert 判断 eth_call 是否因合约执行失败（而非节点错误）返回
+func isRevert(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "revert") || strings.Contains(msg, "invalid opcode")
+}
+
+// Enrich 用注册表中的元数据替换 Alchemy 提供的 symbol 与 decimals，并关联 Token；
+// ERC20 的 Value 按 TokenDecimals 换算，精度不一致时按链上 decimals 重新换算
+func (r *Registry) Enrich(ctx context.Context, txs []*models.Transaction) {
+	var addresses []string
+	for _, tx := range txs {
+		if tx.TokenAddress != "" {
+			addresses = append(addresses, tx.TokenAddress)
+		}
+	}
+	if len(addresses) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, enrichTimeout)
+	defer cancel()
+	tokens, err := r.Lookup(ctx, addresses)
+	if err != nil {
+		r.logger.Warn("Failed to look up tokens", zap.Int("count", len(addresses)), zap.Error(err))
+	}
+
+	for _, tx := range txs {
+		token := tokens[strings.ToLower(tx.TokenAddress)]
+		if token == nil {
+			continue
+		}
+		tx.Token = token
+		if token.Symbol != "" {
+			tx.TokenSymbol = token.Symbol
+		}
+		if tx.TxType != "ERC20" || token.Decimals == nil || *token.Decimals == tx.TokenDecimals {
+			continue
+		}
+		// TokenDecimals 为 0 表示来源未提供精度，Value 无法据此换算
+		if tx.TokenDecimals > 0 {
+			value, ok := rescale(tx.Value, tx.TokenDecimals, *token.Decimals)
+			if !ok {
+				continue
+			}
+			tx.Value = value
+		}
+		tx.TokenDecimals = *token.Decimals
+	}
+}
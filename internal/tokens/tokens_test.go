@@ -0,0 +1,168 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/rpctest"
+)
+
+const (
+	usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	mkr  = "0x9f8f72aa9304c8b593d555f12ef6589cc3a579a2"
+	eoa  = "0x1111111111111111111111111111111111111111"
+)
+
+// abiString ABI 编码的 string 返回值
+func abiString(s string) string {
+	return "0x" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		padHex(hexutil.EncodeUint64(uint64(len(s)))[2:]) +
+		rightPad(hexutil.Encode([]byte(s))[2:])
+}
+
+func padHex(h string) string {
+	for len(h) < 64 {
+		h = "0" + h
+	}
+	return h
+}
+
+func rightPad(h string) string {
+	for len(h)%64 != 0 || h == "" {
+		h += "0"
+	}
+	return h
+}
+
+type memoryStore struct {
+	tokens map[string]models.Token
+}
+
+func (s *memoryStore) GetByAddresses(_ context.Context, addresses []string) ([]models.Token, error) {
+	var tokens []models.Token
+	for _, addr := range addresses {
+		if t, ok := s.tokens[addr]; ok {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+func (s *memoryStore) SaveFetched(_ context.Context, token *models.Token) error {
+	now := time.Now()
+	token.FetchedAt = &now
+	if listed, ok := s.tokens[token.Address]; ok {
+		token.LogoURL, token.Verified = listed.LogoURL, listed.Verified
+	}
+	s.tokens[token.Address] = *token
+	return nil
+}
+
+func (s *memoryStore) SyncList(_ context.Context, tokens []models.Token) error {
+	for _, t := range tokens {
+		s.tokens[t.Address] = t
+	}
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	responses := map[string]map[string]string{ // address -> selector -> 返回值
+		usdc: {
+			selectorName:     abiString("USD Coin"),
+			selectorSymbol:   abiString("USDC"),
+			selectorDecimals: "0x" + padHex("6"),
+		},
+		// MKR 的 name/symbol 返回 bytes32
+		mkr: {
+			selectorName:     "0x" + rightPad(hexutil.Encode([]byte("Maker"))[2:]),
+			selectorSymbol:   "0x" + rightPad(hexutil.Encode([]byte("MKR"))[2:]),
+			selectorDecimals: "0x" + padHex("12"),
+		},
+		eoa: {selectorName: "0x", selectorSymbol: "0x", selectorDecimals: "0x"},
+	}
+	caller := &rpctest.Caller{Missing: errors.New("execution reverted")}
+	for addr, results := range responses {
+		for selector, result := range results {
+			caller.Set("eth_call", rpctest.CallArg(addr, selector), result)
+		}
+	}
+	store := &memoryStore{tokens: map[string]models.Token{}}
+	decimals := 6
+	registry := NewRegistry(store, caller, []models.Token{
+		{Address: usdc, Symbol: "USDC", Decimals: &decimals, LogoURL: "https://example.com/usdc.png", Verified: true},
+	}, zap.NewNop())
+	ctx := context.Background()
+	require.NoError(t, registry.SyncList(ctx))
+
+	tokens, err := registry.Lookup(ctx, []string{"0xA0b86991c6218b36c1d19d4a2e9eB0cE3606eB48", mkr, eoa, mkr})
+	require.NoError(t, err)
+	assert.Equal(t, 1, caller.Batches(), "all missing tokens are fetched in one batch")
+
+	require.Contains(t, tokens, usdc)
+	assert.Equal(t, "USD Coin", tokens[usdc].Name)
+	assert.True(t, tokens[usdc].Verified)
+	assert.Equal(t, "https://example.com/usdc.png", tokens[usdc].LogoURL)
+
+	require.Contains(t, tokens, mkr)
+	assert.Equal(t, "Maker", tokens[mkr].Name)
+	assert.Equal(t, "MKR", tokens[mkr].Symbol)
+	assert.Equal(t, 18, *tokens[mkr].Decimals)
+	assert.False(t, tokens[mkr].Verified)
+
+	require.Contains(t, tokens, eoa)
+	assert.Empty(t, tokens[eoa].Symbol)
+	assert.Nil(t, tokens[eoa].Decimals)
+
+	_, err = registry.Lookup(ctx, []string{usdc, mkr, eoa})
+	require.NoError(t, err)
+	assert.Equal(t, 1, caller.Batches(), "second lookup is served from the cache")
+}
+
+func TestEnrich(t *testing.T) {
+	eight := 8
+	store := &memoryStore{tokens: map[string]models.Token{
+		usdc: {Address: usdc, Symbol: "USDC", Decimals: &eight, FetchedAt: &time.Time{}},
+	}}
+	registry := NewRegistry(store, nil, nil, zap.NewNop())
+
+	// 按默认 18 位换算的 1.5 个代币，链上 decimals 为 8
+	parsed := &models.Transaction{TxType: "ERC20", TokenAddress: usdc, TokenSymbol: "FAKE", TokenDecimals: 18, Value: "150000000"}
+	eth := &models.Transaction{TxType: "ETH", Value: "1"}
+	registry.Enrich(context.Background(), []*models.Transaction{parsed, eth})
+
+	assert.Equal(t, "USDC", parsed.TokenSymbol)
+	assert.Equal(t, 8, parsed.TokenDecimals)
+	assert.Equal(t, "1500000000000000000", parsed.Value)
+	require.NotNil(t, parsed.Token)
+	assert.Nil(t, eth.Token)
+}
+
+func TestLoadList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"name": "test", "tokens": [
+		{"chainId": 1, "address": "0xA0b86991c6218b36c1d19d4a2e9eB0cE3606eB48", "name": "USD Coin", "symbol": "USDC", "decimals": 6, "logoURI": "https://example.com/usdc.png"},
+		{"chainId": 11155111, "address": "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238", "name": "USD Coin", "symbol": "USDC", "decimals": 6}
+	]}`), 0o600))
+
+	tokens, err := LoadList(path, 1)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, usdc, tokens[0].Address)
+	assert.Equal(t, 6, *tokens[0].Decimals)
+	assert.True(t, tokens[0].Verified)
+
+	all, err := LoadList(path, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
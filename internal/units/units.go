@@ -0,0 +1,45 @@
+package units
+
+import "math/big"
+
+// Decimals transactions.value 等金额的统一精度：无论代币实际精度如何，均存储为放大 1e18 的整数
+const Decimals = 18
+
+var scale = Pow10(Decimals)
+
+// Pow10 返回 10^n
+func Pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Rescale 将按 from 位精度表示的整数换算为按 to 位精度表示，精度降低时截断多余的位数；返回新值
+func Rescale(v *big.Int, from, to int) *big.Int {
+	result := new(big.Int).Set(v)
+	switch {
+	case from < to:
+		result.Mul(result, Pow10(to-from))
+	case from > to:
+		result.Quo(result, Pow10(from-to))
+	}
+	return result
+}
+
+// ScaleTo18 将代币最小单位的数量换算为放大 1e18 的存储金额
+func ScaleTo18(raw *big.Int, decimals int) *big.Int {
+	return Rescale(raw, decimals, Decimals)
+}
+
+// ToDecimal 将最小单位数量除以 10^decimals 得到人类可读的数量，直接修改并返回 r
+func ToDecimal(r *big.Rat, decimals int) *big.Rat {
+	return r.Quo(r, new(big.Rat).SetInt(Pow10(decimals)))
+}
+
+// FromScaled 将放大 1e18 的整数金额换算为人类可读的数量，直接修改并返回 r
+func FromScaled(r *big.Rat) *big.Rat {
+	return r.Quo(r, new(big.Rat).SetInt(scale))
+}
+
+// ToScaled 将人类可读的数量放大 1e18，直接修改并返回 r
+func ToScaled(r *big.Rat) *big.Rat {
+	return r.Mul(r, new(big.Rat).SetInt(scale))
+}
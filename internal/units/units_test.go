@@ -0,0 +1,28 @@
+package units
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversions(t *testing.T) {
+	assert.Equal(t, "1000000", Pow10(6).String())
+
+	r, _ := new(big.Rat).SetString("1500000000000000000")
+	assert.Equal(t, "1.5", FromScaled(r).FloatString(1))
+	assert.Equal(t, "1500000000000000000", ToScaled(r).FloatString(0))
+
+	usdc, _ := new(big.Rat).SetString("2500000")
+	assert.Equal(t, "2.50", ToDecimal(usdc, 6).FloatString(2))
+
+	raw := big.NewInt(2500000)
+	assert.Equal(t, "2500000000000000000", ScaleTo18(raw, 6).String())
+	assert.Equal(t, "2", ScaleTo18(big.NewInt(2500), 21).String())
+	assert.Equal(t, "2500000", raw.String(), "input is not modified")
+	assert.Equal(t, "2500", Rescale(big.NewInt(2500000), 6, 3).String())
+
+	// 共享的常量不会被修改
+	assert.Equal(t, "1000000000000000000", scale.String())
+}
@@ -3,49 +3,63 @@ package webhook
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/bwmspring/chainfeed-go/internal/alert"
+	"github.com/bwmspring/chainfeed-go/internal/ingest"
 	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
 	"github.com/bwmspring/chainfeed-go/internal/repository"
 	"github.com/bwmspring/chainfeed-go/internal/service"
+	"github.com/bwmspring/chainfeed-go/internal/units"
 	"github.com/bwmspring/chainfeed-go/internal/websocket"
 )
 
 // BatchProcessor 批量处理交易以提高吞吐量
 type BatchProcessor struct {
-	txRepo          *repository.TransactionRepository
+	pipeline        *ingest.Pipeline
 	feedRepo        *repository.FeedRepository
 	watchedAddrRepo *repository.WatchedAddressRepository
+	alertRepo       *repository.AlertRepository
+	gate            *notify.Gate
 	redis           *redis.Client
 	logger          *zap.Logger
 	batchSize       int
 	flushTime       time.Duration
 	buffer          []*models.Transaction
-	mutex           sync.Mutex
+	mutex           sync.Mutex    // 只保护 buffer，补全与入库在锁外进行
+	flushCh         chan struct{} // 缓冲区已满时通知 flushLoop 立即处理
 	stopCh          chan struct{}
 	wg              sync.WaitGroup
 }
 
 func NewBatchProcessor(
-	txRepo *repository.TransactionRepository,
+	pipeline *ingest.Pipeline,
 	feedRepo *repository.FeedRepository,
 	watchedAddrRepo *repository.WatchedAddressRepository,
+	alertRepo *repository.AlertRepository,
+	gate *notify.Gate,
 	redis *redis.Client,
 	logger *zap.Logger,
 ) *BatchProcessor {
 	bp := &BatchProcessor{
-		txRepo:          txRepo,
+		pipeline:        pipeline,
 		feedRepo:        feedRepo,
 		watchedAddrRepo: watchedAddrRepo,
+		alertRepo:       alertRepo,
+		gate:            gate,
 		redis:           redis,
 		logger:          logger,
 		batchSize:       100,             // 批量大小
 		flushTime:       5 * time.Second, // 最大等待时间
 		buffer:          make([]*models.Transaction, 0, 100),
+		flushCh:         make(chan struct{}, 1),
 		stopCh:          make(chan struct{}),
 	}
 
@@ -66,12 +80,15 @@ func (bp *BatchProcessor) Stop() {
 
 func (bp *BatchProcessor) AddTransaction(tx *models.Transaction) {
 	bp.mutex.Lock()
-	defer bp.mutex.Unlock()
-
 	bp.buffer = append(bp.buffer, tx)
+	full := len(bp.buffer) >= bp.batchSize
+	bp.mutex.Unlock()
 
-	if len(bp.buffer) >= bp.batchSize {
-		bp.flushBuffer()
+	if full {
+		select {
+		case bp.flushCh <- struct{}{}:
+		default:
+		}
 	}
 }
 
@@ -83,28 +100,39 @@ func (bp *BatchProcessor) flushLoop() {
 	for {
 		select {
 		case <-ticker.C:
-			bp.mutex.Lock()
-			if len(bp.buffer) > 0 {
-				bp.flushBuffer()
-			}
-			bp.mutex.Unlock()
+			bp.flushBuffer()
+		case <-bp.flushCh:
+			bp.flushBuffer()
 		case <-bp.stopCh:
 			return
 		}
 	}
 }
 
+// takeBuffer 取出当前缓冲的交易并换上新的缓冲区
+func (bp *BatchProcessor) takeBuffer() []*models.Transaction {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	batch := bp.buffer
+	bp.buffer = make([]*models.Transaction, 0, bp.batchSize)
+	return batch
+}
+
+// flushBuffer 处理缓冲的交易；补全（RPC、价格接口）与入库在锁外进行，期间新到的交易写入新的缓冲区
 func (bp *BatchProcessor) flushBuffer() {
-	if len(bp.buffer) == 0 {
+	batch := bp.takeBuffer()
+	if len(batch) == 0 {
 		return
 	}
 
 	start := time.Now()
 	ctx := context.Background()
 
+	batch = bp.pipeline.Enrich(ctx, batch)
+
 	// 批量插入交易到数据库
-	for _, tx := range bp.buffer {
-		if err := bp.txRepo.Create(tx); err != nil {
+	for _, tx := range batch {
+		if err := bp.pipeline.Store(ctx, tx); err != nil {
 			bp.logger.Error("Failed to store transaction",
 				zap.String("tx_hash", tx.TxHash),
 				zap.Error(err))
@@ -116,11 +144,8 @@ func (bp *BatchProcessor) flushBuffer() {
 	}
 
 	bp.logger.Info("Batch processed",
-		zap.Int("count", len(bp.buffer)),
+		zap.Int("count", len(batch)),
 		zap.Duration("duration", time.Since(start)))
-
-	// 清空缓冲区
-	bp.buffer = bp.buffer[:0]
 }
 
 func (bp *BatchProcessor) createFeedItems(ctx context.Context, tx *models.Transaction) {
@@ -130,63 +155,214 @@ func (bp *BatchProcessor) createFeedItems(ctx context.Context, tx *models.Transa
 		addresses = append(addresses, tx.ToAddress)
 	}
 
-	for _, addr := range addresses {
-		// 查询监控该地址的所有记录
-		watchedAddrs, err := bp.watchedAddrRepo.FindByAddress(addr)
+	// 一次查询解析个人与团队监控者，团队地址已按成员展开
+	watchers, err := bp.watchedAddrRepo.FindWatchers(addresses)
+	if err != nil {
+		bp.logger.Error("Failed to find watched addresses",
+			zap.Strings("addresses", addresses),
+			zap.Error(err))
+		return
+	}
+
+	// 代币合约监控：按最小金额过滤
+	if tx.TokenAddress != "" {
+		tokenWatchers, err := bp.watchedAddrRepo.FindTokenWatchers(tx.TokenAddress)
 		if err != nil {
-			bp.logger.Error("Failed to find watched addresses",
-				zap.String("address", addr),
+			bp.logger.Error("Failed to find token watchers",
+				zap.String("token_address", tx.TokenAddress),
+				zap.Error(err))
+		}
+		for _, w := range tokenWatchers {
+			ok, err := meetsMinAmount(tx.Value, w.MinAmount)
+			if err != nil {
+				bp.logger.Error("Invalid token watch min amount, skipping",
+					zap.Int64("watched_address_id", w.ID),
+					zap.String("tx_hash", tx.TxHash),
+					zap.Error(err))
+				continue
+			}
+			if ok {
+				watchers = append(watchers, w)
+			}
+		}
+	}
+
+	// 每个用户一条 feed_item，关联该用户命中的全部监控地址（如同时监控了发送方与接收方）
+	userIDs, matched := groupWatchers(watchers)
+	hidden := bp.usersHidingToken(ctx, tx, userIDs)
+	for _, userID := range userIDs {
+		addresses := matched[userID]
+		ids := make([]int64, len(addresses))
+		for i, wa := range addresses {
+			ids[i] = wa.ID
+		}
+
+		feedItem := &models.FeedItem{
+			UserID:           userID,
+			TransactionID:    tx.ID,
+			WatchedAddressID: ids[0],
+		}
+
+		created, err := bp.feedRepo.Create(ctx, feedItem, ids)
+		if err != nil {
+			bp.logger.Error("Failed to create feed item",
+				zap.Int64("user_id", userID),
+				zap.String("tx_hash", tx.TxHash),
 				zap.Error(err))
 			continue
 		}
+		// 垃圾交易与用户隐藏的代币只写入 feed（可通过 include_spam 查看），不推送
+		if !created || tx.SpamReason != "" || hidden[userID] {
+			continue
+		}
 
-		// 为每个监控该地址的用户创建 feed_item
-		for _, wa := range watchedAddrs {
-			feedItem := &models.FeedItem{
-				UserID:           wa.UserID,
-				TransactionID:    tx.ID,
-				WatchedAddressID: wa.ID,
+		// 通过 Redis Stream 推送消息
+		bp.publishFeedUpdate(ctx, feedItem, tx, addresses)
+	}
+
+	if tx.SpamReason == "" {
+		bp.evaluateAlerts(ctx, tx, watchers, hidden)
+	}
+}
+
+// usersHidingToken 返回隐藏了交易代币的用户
+func (bp *BatchProcessor) usersHidingToken(ctx context.Context, tx *models.Transaction, userIDs []int64) map[int64]bool {
+	if tx.TokenAddress == "" || len(userIDs) == 0 {
+		return nil
+	}
+	hidden, err := bp.feedRepo.UsersHidingToken(ctx, tx.TokenAddress, userIDs)
+	if err != nil {
+		bp.logger.Error("Failed to load hidden tokens",
+			zap.String("token_address", tx.TokenAddress),
+			zap.Error(err))
+	}
+	return hidden
+}
+
+// evaluateAlerts 按监控者的告警规则评估交易，命中时记录告警并推送 alert 事件；hidden 中的用户隐藏了该代币，不评估
+func (bp *BatchProcessor) evaluateAlerts(ctx context.Context, tx *models.Transaction, watchers []repository.AddressWatcher, hidden map[int64]bool) {
+	if len(watchers) == 0 {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(watchers))
+	seen := make(map[int64]bool, len(watchers))
+	for _, w := range watchers {
+		if !seen[w.WatcherID] && !hidden[w.WatcherID] {
+			seen[w.WatcherID] = true
+			userIDs = append(userIDs, w.WatcherID)
+		}
+	}
+
+	rules, err := bp.alertRepo.ListEnabledRulesByUsers(ctx, userIDs)
+	if err != nil {
+		bp.logger.Error("Failed to load alert rules", zap.Error(err))
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	rulesByUser := make(map[int64][]*models.AlertRule)
+	for i := range rules {
+		rulesByUser[rules[i].UserID] = append(rulesByUser[rules[i].UserID], &rules[i])
+	}
+
+	for _, w := range watchers {
+		for _, rule := range rulesByUser[w.WatcherID] {
+			if !alert.Match(rule, tx, &w.WatchedAddress) {
+				continue
 			}
 
-			if err := bp.feedRepo.Create(feedItem); err != nil {
-				bp.logger.Error("Failed to create feed item",
-					zap.Int64("user_id", wa.UserID),
+			a := &models.Alert{
+				RuleID:           rule.ID,
+				UserID:           w.WatcherID,
+				TransactionID:    tx.ID,
+				WatchedAddressID: w.ID,
+				Severity:         rule.Severity,
+			}
+			// 同一交易可能经由多个监控地址命中同一规则，只推送一次
+			created, err := bp.alertRepo.CreateAlert(ctx, a)
+			if err != nil {
+				bp.logger.Error("Failed to create alert",
+					zap.Int64("rule_id", rule.ID),
 					zap.String("tx_hash", tx.TxHash),
 					zap.Error(err))
 				continue
 			}
+			if !created || !bp.gate.Allow(ctx, w.WatcherID, &w.WatchedAddress, "alert") {
+				continue
+			}
+
+			bp.publish(ctx, &websocket.Message{
+				UserID: w.WatcherID,
+				Type:   "alert",
+				Payload: map[string]interface{}{
+					"id":              a.ID,
+					"rule_id":         rule.ID,
+					"rule_name":       rule.Name,
+					"severity":        rule.Severity,
+					"created_at":      a.CreatedAt,
+					"transaction":     tx,
+					"watched_address": &w.WatchedAddress,
+				},
+			})
+		}
+	}
+}
 
-			// 通过 Redis Pub/Sub 推送消息
-			bp.publishFeedUpdate(ctx, feedItem, tx, &wa)
+// groupWatchers 按用户分组命中的监控地址，保持首次出现的顺序并去重
+func groupWatchers(watchers []repository.AddressWatcher) ([]int64, map[int64][]*models.WatchedAddress) {
+	var userIDs []int64
+	matched := make(map[int64][]*models.WatchedAddress)
+	seen := make(map[[2]int64]bool)
+	for i := range watchers {
+		w := &watchers[i]
+		if seen[[2]int64{w.WatcherID, w.ID}] {
+			continue
+		}
+		seen[[2]int64{w.WatcherID, w.ID}] = true
+		if _, ok := matched[w.WatcherID]; !ok {
+			userIDs = append(userIDs, w.WatcherID)
 		}
+		matched[w.WatcherID] = append(matched[w.WatcherID], &w.WatchedAddress)
 	}
+	return userIDs, matched
 }
 
-func (bp *BatchProcessor) publishFeedUpdate(ctx context.Context, feedItem *models.FeedItem, tx *models.Transaction, wa *models.WatchedAddress) {
-	// 构造前端期望的数据格式
+func (bp *BatchProcessor) publishFeedUpdate(ctx context.Context, feedItem *models.FeedItem, tx *models.Transaction, addresses []*models.WatchedAddress) {
+	// 静音、免打扰与限流只影响推送，feed 条目已写入；任一命中地址未静音即推送，每个条目只计一次限流
+	if !bp.gate.AllowAny(ctx, feedItem.UserID, addresses, "new_transaction") {
+		return
+	}
+
+	// 构造前端期望的数据格式，watched_address 为首个命中的地址
 	payload := map[string]interface{}{
-		"id":              feedItem.ID,
-		"created_at":      feedItem.CreatedAt,
-		"transaction":     tx,
-		"watched_address": wa,
+		"id":                feedItem.ID,
+		"created_at":        feedItem.CreatedAt,
+		"transaction":       tx,
+		"watched_address":   addresses[0],
+		"watched_addresses": addresses,
 	}
 
-	msg := &websocket.Message{
+	bp.publish(ctx, &websocket.Message{
 		UserID:  feedItem.UserID,
 		Type:    "new_transaction",
 		Payload: payload,
-	}
+	})
+}
 
+// publish 发布消息到 Redis Stream，由 StreamService 转发给 WebSocket 客户端
+func (bp *BatchProcessor) publish(ctx context.Context, msg *websocket.Message) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		bp.logger.Error("Failed to marshal message", zap.Error(err))
 		return
 	}
 
-	// 发布到 Redis Stream
 	values := map[string]interface{}{
-		"user_id": feedItem.UserID,
-		"type":    "new_transaction",
+		"user_id": msg.UserID,
+		"type":    msg.Type,
 		"payload": string(data),
 	}
 
@@ -197,3 +373,23 @@ func (bp *BatchProcessor) publishFeedUpdate(ctx context.Context, feedItem *model
 		bp.logger.Error("Failed to publish to stream", zap.Error(err))
 	}
 }
+
+// meetsMinAmount 判断交易金额是否达到监控设置的最小金额
+// tx.Value 为放大 1e18 后的整数，minAmount 为人类可读的十进制数；任一无法解析时返回错误，调用方不推送
+func meetsMinAmount(value string, minAmount *string) (bool, error) {
+	if minAmount == nil || *minAmount == "" {
+		return true, nil
+	}
+
+	min, ok := new(big.Rat).SetString(*minAmount)
+	if !ok {
+		return false, fmt.Errorf("invalid min amount %q", *minAmount)
+	}
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return false, fmt.Errorf("invalid transaction value %q", value)
+	}
+	units.FromScaled(amount)
+
+	return amount.Cmp(min) >= 0, nil
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+func TestGroupWatchers(t *testing.T) {
+	sender := models.WatchedAddress{ID: 1, Address: "0x1111111111111111111111111111111111111111"}
+	receiver := models.WatchedAddress{ID: 2, Address: "0x2222222222222222222222222222222222222222"}
+	team := models.WatchedAddress{ID: 3, Address: "0x2222222222222222222222222222222222222222"}
+
+	// 用户 10 同时监控发送方与接收方，用户 20 经由团队地址命中
+	watchers := []repository.AddressWatcher{
+		{WatchedAddress: sender, WatcherID: 10},
+		{WatchedAddress: team, WatcherID: 20},
+		{WatchedAddress: receiver, WatcherID: 10},
+		{WatchedAddress: team, WatcherID: 10},
+		{WatchedAddress: team, WatcherID: 10},
+	}
+
+	userIDs, matched := groupWatchers(watchers)
+	assert.Equal(t, []int64{10, 20}, userIDs)
+
+	ids := func(addresses []*models.WatchedAddress) []int64 {
+		var out []int64
+		for _, wa := range addresses {
+			out = append(out, wa.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []int64{1, 2, 3}, ids(matched[10]))
+	assert.Equal(t, []int64{3}, ids(matched[20]))
+}
+
+func TestMeetsMinAmount(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	ok, err := meetsMinAmount("1500000000000000000000000", str("1000000"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = meetsMinAmount("999999000000000000000000", str("1000000"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = meetsMinAmount("1", nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// 无法解析时不推送
+	ok, err = meetsMinAmount("1500000000000000000000000", str("1e6x"))
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
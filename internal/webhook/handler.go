@@ -15,6 +15,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/ingest"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
 	"github.com/bwmspring/chainfeed-go/internal/parser"
 	"github.com/bwmspring/chainfeed-go/internal/repository"
 )
@@ -26,11 +28,12 @@ type Handler struct {
 	batchProcessor *BatchProcessor
 }
 
-func NewHandler(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client) *Handler {
-	txRepo := repository.NewTransactionRepository(db)
+func NewHandler(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client, pipeline *ingest.Pipeline) *Handler {
 	feedRepo := repository.NewFeedRepository(db)
 	watchedAddrRepo := repository.NewWatchedAddressRepository(db)
-	batchProcessor := NewBatchProcessor(txRepo, feedRepo, watchedAddrRepo, redis, logger)
+	alertRepo := repository.NewAlertRepository(db)
+	gate := notify.NewGate(repository.NewPreferenceRepository(db), redis, logger)
+	batchProcessor := NewBatchProcessor(pipeline, feedRepo, watchedAddrRepo, alertRepo, gate, redis, logger)
 
 	return &Handler{
 		cfg:            cfg,
@@ -33,7 +33,8 @@ func TestHandleAlchemy(t *testing.T) {
 	_, err = db.Exec(`
 		CREATE TABLE transactions (
 			id INTEGER PRIMARY KEY,
-			tx_hash TEXT UNIQUE,
+			tx_hash TEXT,
+			log_index INTEGER NOT NULL DEFAULT -1,
 			block_number INTEGER,
 			block_timestamp DATETIME,
 			from_address TEXT,
@@ -44,7 +45,18 @@ func TestHandleAlchemy(t *testing.T) {
 			token_id TEXT,
 			token_symbol TEXT,
 			token_decimals INTEGER,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			usd_value TEXT,
+			spam_reason TEXT NOT NULL DEFAULT '',
+			action TEXT,
+			swap TEXT,
+			approval TEXT,
+			status TEXT NOT NULL DEFAULT '',
+			nonce INTEGER,
+			fee TEXT,
+			gas TEXT,
+			input_data TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (tx_hash, log_index)
 		)
 	`)
 	require.NoError(t, err)
@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/bwmspring/chainfeed-go/internal/config"
+	"github.com/bwmspring/chainfeed-go/internal/models"
+	"github.com/bwmspring/chainfeed-go/internal/notify"
+	"github.com/bwmspring/chainfeed-go/internal/repository"
+)
+
+const telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramUpdate Bot API 推送的 Update，只解析绑定流程需要的字段
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message"`
+}
+
+type TelegramMessage struct {
+	Text string       `json:"text"`
+	Chat TelegramChat `json:"chat"`
+}
+
+type TelegramChat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+// TelegramHandler 处理 bot 收到的消息：/start <code> 绑定聊天，/stop 停止通知
+type TelegramHandler struct {
+	cfg         *config.Config
+	logger      *zap.Logger
+	redis       *redis.Client
+	channelRepo *repository.ChannelRepository
+	bot         *notify.TelegramChannel
+}
+
+func NewTelegramHandler(cfg *config.Config, logger *zap.Logger, db *sqlx.DB, redis *redis.Client) *TelegramHandler {
+	return &TelegramHandler{
+		cfg:         cfg,
+		logger:      logger,
+		redis:       redis,
+		channelRepo: repository.NewChannelRepository(db),
+		bot:         notify.NewTelegramChannel(cfg.Telegram.BotToken, nil),
+	}
+}
+
+func (h *TelegramHandler) HandleUpdate(c *gin.Context) {
+	secret := h.cfg.Telegram.WebhookSecret
+	if secret == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(telegramSecretHeader)), []byte(secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid secret token"})
+		return
+	}
+
+	var update TelegramUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid update"})
+		return
+	}
+
+	// Telegram 对非 2xx 会重复推送，处理失败也返回 200
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+
+	if update.Message == nil {
+		return
+	}
+	h.handleMessage(c.Request.Context(), update.Message)
+}
+
+func (h *TelegramHandler) handleMessage(ctx context.Context, msg *TelegramMessage) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(msg.Text), " ")
+	// 群组中的命令形如 /start@BotName
+	command, _, _ = strings.Cut(command, "@")
+	chatID := strconv.FormatInt(msg.Chat.ID, 10)
+
+	switch command {
+	case "/start":
+		h.link(ctx, msg, chatID, strings.TrimSpace(arg))
+	case "/stop":
+		if err := h.channelRepo.DisableByTarget(ctx, models.ChannelTypeTelegram, chatID, "stopped by user"); err != nil {
+			h.logger.Error("Failed to disable telegram channel", zap.Error(err))
+			return
+		}
+		h.reply(ctx, chatID, "Notifications stopped. Re-enable them from ChainFeed settings.")
+	}
+}
+
+func (h *TelegramHandler) link(ctx context.Context, msg *TelegramMessage, chatID, code string) {
+	if code == "" {
+		h.reply(ctx, chatID, "Open ChainFeed → Settings → Notifications and use the Telegram link button to connect this chat.")
+		return
+	}
+
+	userID, err := notify.ConsumeTelegramLinkCode(ctx, h.redis, code)
+	if err != nil {
+		h.logger.Error("Failed to consume telegram link code", zap.Error(err))
+		return
+	}
+	if userID == 0 {
+		h.reply(ctx, chatID, "This link code is invalid or has expired. Please generate a new one.")
+		return
+	}
+
+	channel := &models.NotificationChannel{
+		UserID: userID,
+		Type:   models.ChannelTypeTelegram,
+		Name:   chatName(&msg.Chat),
+		Target: chatID,
+	}
+	if err := h.channelRepo.Create(ctx, channel); err != nil {
+		h.logger.Error("Failed to create telegram channel", zap.Int64("user_id", userID), zap.Error(err))
+		h.reply(ctx, chatID, "Failed to link this chat, please try again.")
+		return
+	}
+
+	h.logger.Info("Telegram chat linked", zap.Int64("user_id", userID), zap.Int64("channel_id", channel.ID))
+	h.reply(ctx, chatID, "✅ Linked! ChainFeed notifications will be delivered to this chat.")
+}
+
+func (h *TelegramHandler) reply(ctx context.Context, chatID, text string) {
+	if err := h.bot.SendText(ctx, chatID, text); err != nil {
+		h.logger.Warn("Failed to reply telegram message", zap.Error(err))
+	}
+}
+
+func chatName(chat *TelegramChat) string {
+	switch {
+	case chat.Title != "":
+		return chat.Title
+	case chat.Username != "":
+		return "@" + chat.Username
+	default:
+		return chat.FirstName
+	}
+}
@@ -14,6 +14,8 @@ type Client struct {
 	Conn   *websocket.Conn
 	Send   chan []byte
 	Hub    *Hub
+	// Filter 为 nil 时接收该用户的全部消息，否则只推送返回 true 的消息
+	Filter func(*Message) bool
 }
 
 type Hub struct {
@@ -97,6 +99,9 @@ func (h *Hub) Run() {
 			}
 
 			for client := range clients {
+				if client.Filter != nil && !client.Filter(message) {
+					continue
+				}
 				select {
 				case client.Send <- data:
 				default:
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_watched_addresses_address_lower;
+DROP INDEX IF EXISTS idx_watched_addresses_team_address;
+DROP INDEX IF EXISTS idx_watched_addresses_user_address;
+
+DELETE FROM watched_addresses WHERE team_id IS NOT NULL;
+ALTER TABLE watched_addresses DROP COLUMN IF EXISTS team_id;
+ALTER TABLE watched_addresses ADD CONSTRAINT watched_addresses_user_id_address_key UNIQUE (user_id, address);
+
+DROP TABLE IF EXISTS team_invites CASCADE;
+DROP TABLE IF EXISTS team_members CASCADE;
+DROP TABLE IF EXISTS teams CASCADE;
@@ -0,0 +1,46 @@
+-- Teams table
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_teams_owner_id ON teams(owner_id);
+
+-- Team members table
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX idx_team_members_user_id ON team_members(user_id);
+
+-- Team invites table
+CREATE TABLE IF NOT EXISTS team_invites (
+    id BIGSERIAL PRIMARY KEY,
+    team_id BIGINT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    wallet_address VARCHAR(42) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    invited_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_team_invites_pending ON team_invites(team_id, wallet_address) WHERE status = 'pending';
+CREATE INDEX idx_team_invites_wallet_address ON team_invites(wallet_address, status);
+
+-- Team-owned watched addresses: user_id 记录创建者，team_id 非空时归团队所有
+ALTER TABLE watched_addresses ADD COLUMN team_id BIGINT REFERENCES teams(id) ON DELETE CASCADE;
+
+ALTER TABLE watched_addresses DROP CONSTRAINT IF EXISTS watched_addresses_user_id_address_key;
+CREATE UNIQUE INDEX idx_watched_addresses_user_address ON watched_addresses(user_id, address) WHERE team_id IS NULL;
+CREATE UNIQUE INDEX idx_watched_addresses_team_address ON watched_addresses(team_id, address) WHERE team_id IS NOT NULL;
+
+-- Webhook 匹配按小写地址查找
+CREATE INDEX idx_watched_addresses_address_lower ON watched_addresses(LOWER(address));
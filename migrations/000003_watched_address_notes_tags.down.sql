@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_watched_addresses_tags;
+ALTER TABLE watched_addresses DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE watched_addresses DROP COLUMN IF EXISTS tags;
+ALTER TABLE watched_addresses DROP COLUMN IF EXISTS notes;
@@ -0,0 +1,10 @@
+-- 监控地址备注与标签
+ALTER TABLE watched_addresses ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE watched_addresses ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE watched_addresses ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE watched_addresses SET label = '' WHERE label IS NULL;
+UPDATE watched_addresses SET ens_name = '' WHERE ens_name IS NULL;
+UPDATE watched_addresses SET updated_at = created_at;
+
+CREATE INDEX idx_watched_addresses_tags ON watched_addresses USING GIN(tags);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_transactions_token_address;
+
+DELETE FROM watched_addresses WHERE kind = 'token';
+DROP INDEX IF EXISTS idx_watched_addresses_user_address;
+DROP INDEX IF EXISTS idx_watched_addresses_team_address;
+CREATE UNIQUE INDEX idx_watched_addresses_user_address ON watched_addresses(user_id, address) WHERE team_id IS NULL;
+CREATE UNIQUE INDEX idx_watched_addresses_team_address ON watched_addresses(team_id, address) WHERE team_id IS NOT NULL;
+
+ALTER TABLE watched_addresses DROP COLUMN IF EXISTS min_amount;
+ALTER TABLE watched_addresses DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,12 @@
+-- 监控类型：wallet 按 from/to 匹配，token 按 token_address 匹配合约的所有转账
+ALTER TABLE watched_addresses ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'wallet'
+    CHECK (kind IN ('wallet', 'token'));
+-- token 监控的最小转账数量（按代币单位，已除以 decimals），NULL 表示不过滤
+ALTER TABLE watched_addresses ADD COLUMN min_amount NUMERIC(78, 18);
+
+DROP INDEX IF EXISTS idx_watched_addresses_user_address;
+DROP INDEX IF EXISTS idx_watched_addresses_team_address;
+CREATE UNIQUE INDEX idx_watched_addresses_user_address ON watched_addresses(user_id, kind, address) WHERE team_id IS NULL;
+CREATE UNIQUE INDEX idx_watched_addresses_team_address ON watched_addresses(team_id, kind, address) WHERE team_id IS NOT NULL;
+
+CREATE INDEX idx_transactions_token_address ON transactions(token_address);
@@ -0,0 +1,17 @@
+-- 通知渠道（Telegram 聊天 / Slack incoming webhook）
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('telegram', 'slack')),
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    target VARCHAR(2048) NOT NULL, -- Telegram chat_id 或 Slack webhook URL
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, type, target)
+);
+
+CREATE INDEX idx_notification_channels_user_id ON notification_channels(user_id);
@@ -0,0 +1,18 @@
+-- 邮件摘要订阅（每用户一条）
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(254) NOT NULL,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    send_hour SMALLINT NOT NULL DEFAULT 8 CHECK (send_hour BETWEEN 0 AND 23),
+    weekday SMALLINT NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6), -- 仅 weekly 使用，0 = 周日
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+    next_run_at TIMESTAMP NOT NULL,
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_digest_subscriptions_next_run_at ON digest_subscriptions(next_run_at) WHERE enabled;
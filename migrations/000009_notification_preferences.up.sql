@@ -0,0 +1,21 @@
+-- 用户通知偏好：免打扰时段与单地址限流（仅影响推送，feed 历史不受影响）
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    quiet_hours_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    quiet_hours_start VARCHAR(5) NOT NULL DEFAULT '22:00', -- HH:MM，本地时间
+    quiet_hours_end VARCHAR(5) NOT NULL DEFAULT '07:00',
+    rate_limit_count INT NOT NULL DEFAULT 0 CHECK (rate_limit_count >= 0), -- 每个地址每窗口最多推送条数，0 表示不限
+    rate_limit_window INT NOT NULL DEFAULT 3600 CHECK (rate_limit_window > 0), -- 秒
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 按用户静音监控地址（团队地址各成员独立静音）
+CREATE TABLE IF NOT EXISTS address_mutes (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    watched_address_id BIGINT NOT NULL REFERENCES watched_addresses(id) ON DELETE CASCADE,
+    muted_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, watched_address_id)
+);
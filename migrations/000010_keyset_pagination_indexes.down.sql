@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_to_timestamp_id;
+DROP INDEX IF EXISTS idx_transactions_from_timestamp_id;
+
+DROP INDEX IF EXISTS idx_feed_items_user_created_id;
+CREATE INDEX idx_feed_items_user_id ON feed_items(user_id, created_at DESC);
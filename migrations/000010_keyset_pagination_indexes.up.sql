@@ -0,0 +1,6 @@
+-- 键集分页按 (时间, id) 排序，索引需包含 id 以避免额外排序
+DROP INDEX IF EXISTS idx_feed_items_user_id;
+CREATE INDEX idx_feed_items_user_created_id ON feed_items(user_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_transactions_from_timestamp_id ON transactions(from_address, block_timestamp DESC, id DESC);
+CREATE INDEX idx_transactions_to_timestamp_id ON transactions(to_address, block_timestamp DESC, id DESC);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_transactions_value;
+DROP INDEX IF EXISTS idx_transactions_block_number;
+DROP INDEX IF EXISTS idx_transactions_tx_type;
+DROP INDEX IF EXISTS idx_transactions_token_symbol;
+DROP INDEX IF EXISTS idx_transactions_token_address;
+DROP INDEX IF EXISTS idx_feed_items_user_watched_created_id;
+
+DROP TABLE IF EXISTS feed_filter_presets;
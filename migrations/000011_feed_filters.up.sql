@@ -0,0 +1,19 @@
+-- Feed filter presets table（保存的 feed 过滤条件，可用于 GET /feed 与 WebSocket 订阅）
+CREATE TABLE IF NOT EXISTS feed_filter_presets (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_feed_filter_presets_user_id ON feed_filter_presets(user_id);
+
+-- feed 过滤条件的索引：按监控地址分页，以及交易侧的代币、类型、区块与金额条件
+CREATE INDEX idx_feed_items_user_watched_created_id ON feed_items(user_id, watched_address_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_token_address ON transactions(token_address) WHERE token_address <> '';
+CREATE INDEX idx_transactions_token_symbol ON transactions(UPPER(token_symbol));
+CREATE INDEX idx_transactions_tx_type ON transactions(tx_type);
+CREATE INDEX idx_transactions_block_number ON transactions(block_number);
+CREATE INDEX idx_transactions_value ON transactions(value);
@@ -0,0 +1,3 @@
+CREATE INDEX idx_feed_items_user_watched_created_id ON feed_items(user_id, watched_address_id, created_at DESC, id DESC);
+
+DROP TABLE IF EXISTS feed_item_addresses;
@@ -0,0 +1,37 @@
+-- Feed item addresses table（feed 条目命中的全部监控地址；feed_items.watched_address_id 保留为首个命中的地址）
+CREATE TABLE IF NOT EXISTS feed_item_addresses (
+    feed_item_id BIGINT NOT NULL REFERENCES feed_items(id) ON DELETE CASCADE,
+    watched_address_id BIGINT NOT NULL REFERENCES watched_addresses(id) ON DELETE CASCADE,
+    PRIMARY KEY (feed_item_id, watched_address_id)
+);
+
+CREATE INDEX idx_feed_item_addresses_watched_address_id ON feed_item_addresses(watched_address_id);
+
+-- 回填已有条目的首个命中地址
+INSERT INTO feed_item_addresses (feed_item_id, watched_address_id)
+SELECT id, watched_address_id FROM feed_items
+ON CONFLICT DO NOTHING;
+
+-- 回填此前因 UNIQUE(user_id, transaction_id) 被丢弃的钱包地址命中，团队地址按成员展开（与入库逻辑一致）
+INSERT INTO feed_item_addresses (feed_item_id, watched_address_id)
+SELECT fi.id, wa.id
+FROM feed_items fi
+JOIN transactions t ON fi.transaction_id = t.id
+JOIN watched_addresses wa ON wa.kind = 'wallet' AND LOWER(wa.address) IN (t.from_address, t.to_address)
+LEFT JOIN team_members tm ON wa.team_id IS NOT NULL AND tm.team_id = wa.team_id
+WHERE COALESCE(tm.user_id, wa.user_id) = fi.user_id
+ON CONFLICT DO NOTHING;
+
+-- 代币合约监控按最小金额过滤
+INSERT INTO feed_item_addresses (feed_item_id, watched_address_id)
+SELECT fi.id, wa.id
+FROM feed_items fi
+JOIN transactions t ON fi.transaction_id = t.id
+JOIN watched_addresses wa ON wa.kind = 'token' AND LOWER(wa.address) = t.token_address
+LEFT JOIN team_members tm ON wa.team_id IS NOT NULL AND tm.team_id = wa.team_id
+WHERE COALESCE(tm.user_id, wa.user_id) = fi.user_id
+  AND (wa.min_amount IS NULL OR t.value >= wa.min_amount * 1000000000000000000)
+ON CONFLICT DO NOTHING;
+
+-- 按监控地址过滤改为经由 feed_item_addresses 匹配
+DROP INDEX IF EXISTS idx_feed_items_user_watched_created_id;
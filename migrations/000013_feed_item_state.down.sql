@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_feed_items_user_starred;
+DROP INDEX IF EXISTS idx_feed_items_user_unread;
+
+ALTER TABLE feed_items
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS starred_at,
+    DROP COLUMN IF EXISTS seen_at;
@@ -0,0 +1,11 @@
+-- 条目级状态：已读时间、星标时间与备注
+ALTER TABLE feed_items
+    ADD COLUMN seen_at TIMESTAMP,
+    ADD COLUMN starred_at TIMESTAMP,
+    ADD COLUMN note TEXT NOT NULL DEFAULT '';
+
+-- 未读数量与未读过滤
+CREATE INDEX idx_feed_items_user_unread ON feed_items(user_id, created_at DESC, id DESC) WHERE seen_at IS NULL;
+
+-- 星标列表
+CREATE INDEX idx_feed_items_user_starred ON feed_items(user_id, created_at DESC, id DESC) WHERE starred_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Feed tokens table（Atom / RSS 订阅令牌，只保存 SHA-256 摘要）
+CREATE TABLE IF NOT EXISTS feed_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    address VARCHAR(42) NOT NULL DEFAULT '',
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX idx_feed_tokens_user_id ON feed_tokens(user_id);
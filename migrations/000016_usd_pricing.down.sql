@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS asset_prices;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS usd_value;
@@ -0,0 +1,12 @@
+-- 交易在区块时间的美元价值，无法计价时为 NULL
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS usd_value NUMERIC(38, 2);
+
+-- Asset prices table（按小时缓存的历史美元价格，asset 为 ETH 或代币合约地址）
+CREATE TABLE IF NOT EXISTS asset_prices (
+    asset VARCHAR(42) NOT NULL,
+    bucket TIMESTAMP NOT NULL,
+    usd NUMERIC(38, 18) NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (asset, bucket)
+);
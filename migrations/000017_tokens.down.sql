@@ -0,0 +1,3 @@
+ALTER TABLE transactions ALTER COLUMN token_symbol TYPE VARCHAR(20) USING LEFT(token_symbol, 20);
+
+DROP TABLE IF EXISTS tokens;
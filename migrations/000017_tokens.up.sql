@@ -0,0 +1,15 @@
+-- Tokens table（代币元数据，name/symbol/decimals 来自链上调用，logo 与认证状态来自代币列表文件）
+CREATE TABLE IF NOT EXISTS tokens (
+    address VARCHAR(42) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    symbol VARCHAR(64) NOT NULL DEFAULT '',
+    decimals INT,
+    logo_url TEXT NOT NULL DEFAULT '',
+    verified BOOLEAN NOT NULL DEFAULT FALSE,
+    fetched_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 交易通过 token_address 引用 tokens；链上 symbol 可能超过原有长度
+ALTER TABLE transactions ALTER COLUMN token_symbol TYPE VARCHAR(64);
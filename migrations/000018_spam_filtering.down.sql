@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS hidden_tokens;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS spam_reason;
@@ -0,0 +1,10 @@
+-- 垃圾交易分类，空字符串表示正常交易（denylist / zero_value / lookalike / mass_transfer）
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS spam_reason VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Hidden tokens table（用户隐藏的代币，feed 默认不显示其转账）
+CREATE TABLE IF NOT EXISTS hidden_tokens (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_address VARCHAR(42) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, token_address)
+);
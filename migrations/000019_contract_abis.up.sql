@@ -0,0 +1,14 @@
+-- Contract ABIs table（用户上传的合约 ABI，用于解码合约调用与事件；内置 ABI 不入库）
+CREATE TABLE IF NOT EXISTS contract_abis (
+    address VARCHAR(42) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    abi JSONB NOT NULL,
+    uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_abis_uploaded_by ON contract_abis(uploaded_by);
+
+-- 解码后的合约调用（方法、参数与事件），非合约调用时为 NULL
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS action JSONB;
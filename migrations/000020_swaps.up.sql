@@ -0,0 +1,2 @@
+-- DEX 兑换明细（tx_type 为 SWAP 时的协议、池与 token_in / token_out），其他交易为 NULL
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS swap JSONB;
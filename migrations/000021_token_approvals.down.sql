@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS token_allowances;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS approval;
@@ -0,0 +1,23 @@
+-- 代币授权明细（spender、额度、是否无限与风险标记），tx_type 为 APPROVAL 以外的交易为 NULL
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS approval JSONB;
+
+-- Token allowances table（由 Approval / ApprovalForAll 事件重建的钱包当前授权，按区块与日志顺序只保留最新一次）
+CREATE TABLE IF NOT EXISTS token_allowances (
+    owner VARCHAR(42) NOT NULL,
+    token_address VARCHAR(42) NOT NULL,
+    spender VARCHAR(42) NOT NULL,
+    token_symbol VARCHAR(64) NOT NULL DEFAULT '',
+    token_decimals INTEGER NOT NULL DEFAULT 0,
+    spender_name VARCHAR(255) NOT NULL DEFAULT '',
+    standard VARCHAR(10) NOT NULL,
+    amount VARCHAR(78) NOT NULL DEFAULT '',
+    unlimited BOOLEAN NOT NULL DEFAULT FALSE,
+    risky BOOLEAN NOT NULL DEFAULT FALSE,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    tx_hash VARCHAR(66) NOT NULL,
+    block_number BIGINT NOT NULL,
+    log_index INTEGER NOT NULL,
+    approved_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner, token_address, spender)
+);
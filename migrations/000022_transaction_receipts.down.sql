@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS gas;
+ALTER TABLE transactions DROP COLUMN IF EXISTS input_data;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee;
+ALTER TABLE transactions DROP COLUMN IF EXISTS nonce;
+ALTER TABLE transactions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,8 @@
+-- 交易回执：执行状态（success / reverted，空字符串表示未获取回执）、发起方 nonce、手续费（wei）与 input
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS nonce BIGINT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee NUMERIC(78, 0);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS input_data TEXT NOT NULL DEFAULT '';
+
+-- gas 与 EIP-1559 / EIP-4844 费用明细（gas limit/used、base fee、priority fee、blob gas 等）
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS gas JSONB;
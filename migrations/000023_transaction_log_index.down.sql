@@ -0,0 +1,5 @@
+-- 同一交易只保留最早入库的一行（其余行的 feed 条目随外键删除）
+DELETE FROM transactions t USING transactions o WHERE t.tx_hash = o.tx_hash AND t.id > o.id;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_tx_hash_log_index_key;
+ALTER TABLE transactions ADD CONSTRAINT transactions_tx_hash_key UNIQUE (tx_hash);
+ALTER TABLE transactions DROP COLUMN IF EXISTS log_index;
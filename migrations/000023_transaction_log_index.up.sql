@@ -0,0 +1,5 @@
+-- 代币转账按日志记录：同一交易中的多条 Transfer 日志各占一行，唯一键改为 (tx_hash, log_index)。
+-- ETH 转账、授权与兑换等交易级记录的 log_index 为 -1；已有记录无法补全日志序号，保持 -1
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS log_index INTEGER NOT NULL DEFAULT -1;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_tx_hash_key;
+ALTER TABLE transactions ADD CONSTRAINT transactions_tx_hash_log_index_key UNIQUE (tx_hash, log_index);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_contract_abis_address;
+CREATE INDEX IF NOT EXISTS idx_contract_abis_uploaded_by ON contract_abis(uploaded_by);
+
+-- 每个合约只保留最近更新的一份
+DELETE FROM contract_abis a USING contract_abis b
+WHERE a.address = b.address AND (a.updated_at, a.uploaded_by) < (b.updated_at, b.uploaded_by);
+ALTER TABLE contract_abis DROP CONSTRAINT IF EXISTS contract_abis_pkey;
+ALTER TABLE contract_abis ADD PRIMARY KEY (address);
+ALTER TABLE contract_abis DROP CONSTRAINT IF EXISTS contract_abis_uploaded_by_fkey;
+ALTER TABLE contract_abis ALTER COLUMN uploaded_by DROP NOT NULL;
+ALTER TABLE contract_abis ADD CONSTRAINT contract_abis_uploaded_by_fkey
+    FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE SET NULL;
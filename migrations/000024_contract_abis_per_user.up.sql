@@ -0,0 +1,12 @@
+-- 上传的合约 ABI 按用户保存：同一合约可由不同用户各自上传，只用于上传者及其团队成员查看交易时解码，
+-- 不再参与入库解码（transactions.action 由所有用户共享）
+DELETE FROM contract_abis WHERE uploaded_by IS NULL;
+ALTER TABLE contract_abis DROP CONSTRAINT IF EXISTS contract_abis_pkey;
+ALTER TABLE contract_abis DROP CONSTRAINT IF EXISTS contract_abis_uploaded_by_fkey;
+ALTER TABLE contract_abis ALTER COLUMN uploaded_by SET NOT NULL;
+ALTER TABLE contract_abis ADD CONSTRAINT contract_abis_uploaded_by_fkey
+    FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE contract_abis ADD PRIMARY KEY (uploaded_by, address);
+
+DROP INDEX IF EXISTS idx_contract_abis_uploaded_by;
+CREATE INDEX IF NOT EXISTS idx_contract_abis_address ON contract_abis(address);